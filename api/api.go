@@ -4,9 +4,13 @@ package api
 
 import (
 	"net/http"
+	"sync"
+	"time"
 
 	"github.com/diamondburned/arikawa/api/rate"
+	"github.com/diamondburned/arikawa/discord"
 	"github.com/diamondburned/arikawa/internal/httputil"
+	"github.com/diamondburned/arikawa/metrics"
 )
 
 const (
@@ -24,7 +28,42 @@ type Client struct {
 	httputil.Client
 	Limiter *rate.Limiter
 
+	// Token is the token the Client was created with, or the last one given
+	// to SetToken. Changing it doesn't change the token of requests.
 	Token string
+
+	// auth is the token of requests, shared by the copies made by With.
+	auth *auth
+
+	// AllowedMentions, if not nil, is the default of the messages sent and
+	// edited by the Client, such as &discord.AllowedMentions{} to never ping
+	// anyone unless a message says otherwise.
+	AllowedMentions *discord.AllowedMentions
+
+	// BeforeSend, if not nil, is called with the messages sent by
+	// SendMessage and SendMessageComplex before they're validated and sent,
+	// such as to scan or filter their content. It may change the data, and a
+	// message isn't sent if it returns an error, which is then returned.
+	BeforeSend func(channelID discord.Snowflake, data *SendMessageData) error
+	// BeforeEdit is like BeforeSend, but for the edits of EditMessage and
+	// EditMessageComplex.
+	BeforeEdit func(
+		channelID, messageID discord.Snowflake, data *EditMessageData) error
+
+	// Metrics, if not nil, is given the latency and status of every request.
+	// Copies made by With report to the Metrics of this Client.
+	Metrics metrics.Recorder
+
+	// OnDeprecation, if not nil, is called the first time a response of an
+	// endpoint says that it's deprecated or going away, such as to log it.
+	// Copies made by With report to the OnDeprecation of this Client.
+	OnDeprecation func(Deprecation)
+	deprecations  *deprecations
+}
+
+type auth struct {
+	mutex sync.RWMutex
+	token string
 }
 
 func NewClient(token string) *Client {
@@ -32,25 +71,109 @@ func NewClient(token string) *Client {
 		Client:  httputil.DefaultClient,
 		Limiter: rate.NewLimiter(),
 		Token:   token,
+		auth:    &auth{token: token},
+
+		deprecations: &deprecations{seen: map[string]bool{}},
 	}
 
 	tw := httputil.NewTransportWrapper()
+	tw.Default = instrumentedTransport{tw.Default, cli}
 	tw.Pre = func(r *http.Request) error {
-		if cli.Token != "" {
-			r.Header.Set("Authorization", cli.Token)
+		cli.auth.mutex.RLock()
+		token := cli.auth.token
+		cli.auth.mutex.RUnlock()
+
+		if token != "" {
+			r.Header.Set("Authorization", token)
 		}
 
-		r.Header.Set("User-Agent", UserAgent)
+		// The User-Agent could be overridden by WithUserAgent.
+		if r.Header.Get("User-Agent") == "" {
+			r.Header.Set("User-Agent", UserAgent)
+		}
 		r.Header.Set("X-RateLimit-Precision", "millisecond")
 
 		// Rate limit stuff
 		return cli.Limiter.Acquire(r.Context(), r.URL.Path)
 	}
-	tw.Post = func(r *http.Response) error {
-		return cli.Limiter.Release(r.Request.URL.Path, r.Header)
+	tw.Post = func(r *http.Request, resp *http.Response) error {
+		if resp == nil {
+			return cli.Limiter.Release(r.URL.Path, nil)
+		}
+
+		cli.checkDeprecation(r, resp)
+
+		return cli.Limiter.Release(r.URL.Path, resp.Header)
 	}
 
 	cli.Client.Transport = tw
 
 	return cli
 }
+
+// SetToken changes the token of all requests made after, including the ones
+// made by copies of the Client, for tokens that are rotated while running.
+func (c *Client) SetToken(token string) {
+	c.auth.mutex.Lock()
+	defer c.auth.mutex.Unlock()
+
+	c.auth.token = token
+	c.Token = token
+}
+
+// instrumentedTransport reports requests to the Client's Metrics. It's inside
+// the TransportWrapper, so the rate limits aren't part of the latency.
+type instrumentedTransport struct {
+	http.RoundTripper
+	client *Client
+}
+
+func (t instrumentedTransport) RoundTrip(
+	r *http.Request) (*http.Response, error) {
+
+	if t.client.Metrics == nil {
+		return t.RoundTripper.RoundTrip(r)
+	}
+
+	start := time.Now()
+	resp, err := t.RoundTripper.RoundTrip(r)
+
+	var status int
+	if err == nil {
+		status = resp.StatusCode
+	}
+
+	t.client.Metrics.Request(
+		r.Method, metrics.Route(r.URL.Path), status, time.Since(start))
+
+	return resp, err
+}
+
+// With returns a copy of the Client with the options applied. The copy shares
+// the token and rate limiter with the Client, so it can be used alongside it:
+//
+//	audit := c.With(api.WithReason("Cleanup"), api.WithContext(ctx))
+func (c *Client) With(opts ...ClientOption) *Client {
+	cpy := *c
+
+	// Limit the capacity, so appending copies the slice instead of changing
+	// the Client's.
+	n := len(c.Client.Options)
+	cpy.Client.Options = c.Client.Options[:n:n]
+
+	for _, opt := range opts {
+		opt(&cpy)
+	}
+
+	return &cpy
+}
+
+// WithReason returns a copy of the Client that attaches the audit log reason to
+// all of its requests, which Discord shows in the audit log:
+//
+//	c.WithReason("Spamming").Kick(guildID, userID)
+//
+// The copy shares the token and rate limiter with the Client.
+func (c *Client) WithReason(reason string) *Client {
+	return c.With(WithReason(reason))
+}
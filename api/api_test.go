@@ -0,0 +1,197 @@
+// +build unit
+
+package api
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/diamondburned/arikawa/discord"
+	"github.com/diamondburned/arikawa/metrics"
+	"github.com/pkg/errors"
+)
+
+func TestWithReason(t *testing.T) {
+	c := NewClient("")
+	r := c.WithReason("spam")
+
+	if len(r.Client.Options) != 1 {
+		t.Fatal("Reason wasn't added to the copy")
+	}
+
+	if len(c.Client.Options) != 0 {
+		t.Fatal("Reason was added to the original Client")
+	}
+
+	if r.Limiter != c.Limiter {
+		t.Fatal("Copy doesn't share the rate limiter")
+	}
+}
+
+func TestWith(t *testing.T) {
+	var agents = make(chan string, 1)
+	var srv = httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			agents <- r.Header.Get("User-Agent")
+		},
+	))
+	defer srv.Close()
+
+	c := NewClient("")
+	w := c.With(WithUserAgent("test"), WithRetries(1, 0, 0))
+
+	if w.Client.Retries != 1 || c.Client.Retries == 1 {
+		t.Fatal("Retries weren't only set on the copy")
+	}
+
+	for _, test := range []struct {
+		client *Client
+		agent  string
+	}{
+		{w, "test"},
+		{c, UserAgent},
+	} {
+		if err := test.client.FastRequest("GET", srv.URL+"/gateway"); err != nil {
+			t.Fatal("Failed to request:", err)
+		}
+
+		if agent := <-agents; agent != test.agent {
+			t.Fatalf("Unexpected User-Agent %q, expected %q", agent, test.agent)
+		}
+	}
+}
+
+func TestWithContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	c := NewClient("").With(WithContext(ctx))
+
+	if err := c.FastRequest("GET", "http://localhost/gateway"); err == nil {
+		t.Fatal("Request didn't fail with a cancelled context")
+	}
+}
+
+type requestRecorder struct {
+	metrics.Nop
+	routes chan string
+}
+
+func (r requestRecorder) Request(method, route string, status int,
+	latency time.Duration) {
+
+	r.routes <- method + " " + route + " " + strconv.Itoa(status)
+}
+
+func TestMetrics(t *testing.T) {
+	var srv = httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNoContent)
+		},
+	))
+	defer srv.Close()
+
+	var r = requestRecorder{routes: make(chan string, 1)}
+
+	c := NewClient("")
+	c.Metrics = r
+
+	// Copies report to the same Metrics.
+	if err := c.WithReason("").FastRequest(
+		"DELETE", srv.URL+"/channels/1/messages/2"); err != nil {
+
+		t.Fatal("Failed to request:", err)
+	}
+
+	const expect = "DELETE /channels/:id/messages/:id 204"
+	if route := <-r.routes; route != expect {
+		t.Fatalf("Unexpected request %q, expected %q", route, expect)
+	}
+}
+
+func TestSetToken(t *testing.T) {
+	var tokens = make(chan string, 1)
+	var srv = httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			tokens <- r.Header.Get("Authorization")
+		},
+	))
+	defer srv.Close()
+
+	c := NewClient("Bot old")
+	w := c.WithReason("")
+
+	c.SetToken("Bot new")
+
+	// Copies use the new token too.
+	if err := w.FastRequest("GET", srv.URL+"/gateway"); err != nil {
+		t.Fatal("Failed to request:", err)
+	}
+
+	if token := <-tokens; token != "Bot new" {
+		t.Fatalf("Unexpected token %q", token)
+	}
+}
+
+type roundTripFunc func(r *http.Request) *http.Response
+
+func (fn roundTripFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return fn(r), nil
+}
+
+func TestAllowedMentions(t *testing.T) {
+	var bodies = make(chan string, 1)
+
+	c := NewClient("").With(WithAllowedMentions(discord.AllowedMentions{}))
+	c.Client.Client.Transport = roundTripFunc(
+		func(r *http.Request) *http.Response {
+			b, _ := ioutil.ReadAll(r.Body)
+			bodies <- strings.TrimSpace(string(b))
+
+			return &http.Response{
+				StatusCode: 200,
+				Header:     http.Header{},
+				Body:       ioutil.NopCloser(strings.NewReader("{}")),
+			}
+		},
+	)
+
+	for _, test := range []struct {
+		mentions *discord.AllowedMentions
+		expect   string
+	}{{
+		mentions: nil,
+		expect:   `"allowed_mentions":{"parse":[]}`,
+	}, {
+		mentions: &discord.AllowedMentions{Users: []discord.Snowflake{1}},
+		expect:   `"allowed_mentions":{"parse":[],"users":["1"]}`,
+	}} {
+		_, err := c.SendMessageComplex(1, SendMessageData{
+			Content:         "@everyone",
+			AllowedMentions: test.mentions,
+		})
+		if err != nil {
+			t.Fatal("Failed to send message:", err)
+		}
+
+		if body := <-bodies; !strings.Contains(body, test.expect) {
+			t.Fatalf("Body %s doesn't have %s", body, test.expect)
+		}
+	}
+
+	_, err := c.SendMessageComplex(1, SendMessageData{
+		AllowedMentions: &discord.AllowedMentions{
+			Parse: []discord.AllowedMentionType{discord.AllowUserMentions},
+			Users: []discord.Snowflake{1},
+		},
+	})
+	if errors.Cause(err) != discord.ErrMentionConflict {
+		t.Fatal("Unexpected error:", err)
+	}
+}
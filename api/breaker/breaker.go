@@ -0,0 +1,111 @@
+// Package breaker provides a circuit breaker for the REST client, so that bots
+// fail fast instead of piling up requests while the Discord API is down:
+//
+//	c.Client.Breaker = breaker.New(5, 30*time.Second)
+//
+// Each route has its own circuit, which opens after a number of consecutive
+// server errors or timeouts. While a circuit is open, requests to its route
+// fail with an *OpenError. Once the cooldown is over, a single request is let
+// through to probe the route, and the circuit closes again if it succeeds.
+package breaker
+
+import (
+	"sync"
+	"time"
+)
+
+// OpenError is returned for requests to a route whose circuit is open.
+type OpenError struct {
+	Route string
+	// Until is when the next request is let through to probe the route. It
+	// could be in the past if a probe is already being made.
+	Until time.Time
+}
+
+func (err *OpenError) Error() string {
+	return "Circuit breaker is open for " + err.Route
+}
+
+type Breaker struct {
+	// Threshold is the number of consecutive failures that opens a circuit.
+	Threshold int
+	// Cooldown is how long a circuit stays open before it's probed.
+	Cooldown time.Duration
+
+	mutex    sync.Mutex
+	circuits map[string]*circuit
+}
+
+type circuit struct {
+	failures int
+	// openUntil is zero if the circuit is closed.
+	openUntil time.Time
+	probing   bool
+}
+
+func New(threshold int, cooldown time.Duration) *Breaker {
+	return &Breaker{
+		Threshold: threshold,
+		Cooldown:  cooldown,
+		circuits:  map[string]*circuit{},
+	}
+}
+
+// Allow returns an *OpenError if the request to the route should fail fast.
+// If nil is returned, the result of the request must be given to Success,
+// Failure or Cancel.
+func (b *Breaker) Allow(route string) error {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	c, ok := b.circuits[route]
+	if !ok || c.openUntil.IsZero() {
+		return nil
+	}
+
+	if c.probing || time.Now().Before(c.openUntil) {
+		return &OpenError{route, c.openUntil}
+	}
+
+	c.probing = true
+	return nil
+}
+
+// Success closes the circuit of the route.
+func (b *Breaker) Success(route string) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	delete(b.circuits, route)
+}
+
+// Failure counts a server error or a timeout. The circuit is opened if there
+// are Threshold failures in a row, or if the failure was a probe.
+func (b *Breaker) Failure(route string) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	c, ok := b.circuits[route]
+	if !ok {
+		c = &circuit{}
+		b.circuits[route] = c
+	}
+
+	c.failures++
+	c.probing = false
+
+	if !c.openUntil.IsZero() || c.failures >= b.Threshold {
+		c.openUntil = time.Now().Add(b.Cooldown)
+	}
+}
+
+// Cancel is called if the request was cancelled before it got a result, so
+// that another probe can be made.
+func (b *Breaker) Cancel(route string) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if c, ok := b.circuits[route]; ok {
+		c.probing = false
+	}
+}
@@ -0,0 +1,52 @@
+// +build unit
+
+package breaker
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBreaker(t *testing.T) {
+	const route = "GET /gateway"
+	b := New(2, 10*time.Millisecond)
+
+	for i := 0; i < 2; i++ {
+		if err := b.Allow(route); err != nil {
+			t.Fatal("Closed circuit failed:", err)
+		}
+		b.Failure(route)
+	}
+
+	if err := b.Allow(route); err == nil {
+		t.Fatal("Circuit didn't open after 2 failures")
+	}
+
+	time.Sleep(10 * time.Millisecond)
+
+	// Only one probe is let through.
+	if err := b.Allow(route); err != nil {
+		t.Fatal("Probe failed:", err)
+	}
+	if err := b.Allow(route); err == nil {
+		t.Fatal("Second probe was let through")
+	}
+
+	// A failed probe opens the circuit again right away.
+	b.Failure(route)
+
+	if err := b.Allow(route); err == nil {
+		t.Fatal("Circuit didn't open after a failed probe")
+	}
+
+	time.Sleep(10 * time.Millisecond)
+
+	if err := b.Allow(route); err != nil {
+		t.Fatal("Probe failed:", err)
+	}
+	b.Success(route)
+
+	if err := b.Allow(route); err != nil {
+		t.Fatal("Circuit didn't close after a successful probe:", err)
+	}
+}
@@ -95,7 +95,8 @@ type ModifyChannelData struct {
 	VoiceUserLimit json.OptionUint `json:"user_limit,omitempty"`
 
 	// Text OR Voice
-	CategoryID discord.Snowflake `json:"parent_id,string,omitempty"`
+	// A pointer to 0 removes the channel from its category.
+	CategoryID *discord.Snowflake `json:"parent_id,omitempty"`
 }
 
 func (c *Client) ModifyChannel(data ModifyChannelData) error {
@@ -109,16 +110,45 @@ func (c *Client) DeleteChannel(channelID discord.Snowflake) error {
 	return c.FastRequest("DELETE", EndpointChannels+channelID.String())
 }
 
-func (c *Client) EditChannelPermission(
-	channelID discord.Snowflake, overwrite discord.Overwrite) error {
+// https://discord.com/developers/docs/resources/channel#edit-channel-permissions-json-params
+type EditChannelPermissionData struct {
+	Type  discord.OverwriteType `json:"type"`
+	Allow discord.Permissions   `json:"allow"`
+	Deny  discord.Permissions   `json:"deny"`
+}
 
-	url := EndpointChannels + channelID.String() + "/permissions/" +
-		overwrite.ID.String()
-	overwrite.ID = 0
+// EditChannelPermission creates or replaces the permission overwrite of a role
+// or a member in the channel. It requires MANAGE_ROLES.
+func (c *Client) EditChannelPermission(channelID, overwriteID discord.Snowflake,
+	data EditChannelPermissionData) error {
 
-	return c.FastRequest("PUT", url, httputil.WithJSONBody(c, overwrite))
+	return c.FastRequest(
+		"PUT",
+		EndpointChannels+channelID.String()+"/permissions/"+
+			overwriteID.String(),
+		httputil.WithJSONBody(c, data),
+	)
+}
+
+// SetChannelOverwrite sets an overwrite with EditChannelPermission, such as
+// one built with discord.RoleOverwrite or discord.MemberOverwrite:
+//
+//	err := c.SetChannelOverwrite(channelID, discord.RoleOverwrite(roleID).
+//		Denying(discord.PermissionSendMessages))
+func (c *Client) SetChannelOverwrite(
+	channelID discord.Snowflake, overwrite discord.Overwrite) error {
+
+	return c.EditChannelPermission(channelID, overwrite.ID,
+		EditChannelPermissionData{
+			Type:  overwrite.Type,
+			Allow: overwrite.Allow,
+			Deny:  overwrite.Deny,
+		},
+	)
 }
 
+// DeleteChannelPermission deletes the permission overwrite of a role or a
+// member in the channel. It requires MANAGE_ROLES.
 func (c *Client) DeleteChannelPermission(
 	channelID, overwriteID discord.Snowflake) error {
 
@@ -0,0 +1,151 @@
+package api
+
+import (
+	"github.com/diamondburned/arikawa/discord"
+	"github.com/diamondburned/arikawa/internal/httputil"
+)
+
+const EndpointApplications = Endpoint + "applications/"
+
+// https://discord.com/developers/docs/interactions/slash-commands#create-global-application-command-json-params
+type CreateCommandData struct {
+	Name        string                  `json:"name"`
+	Description string                  `json:"description"`
+	Options     []discord.CommandOption `json:"options,omitempty"`
+}
+
+// https://discord.com/developers/docs/interactions/slash-commands#edit-global-application-command-json-params
+type EditCommandData struct {
+	Name        string                  `json:"name,omitempty"`
+	Description string                  `json:"description,omitempty"`
+	Options     []discord.CommandOption `json:"options,omitempty"`
+}
+
+// Commands returns all global commands of the application.
+func (c *Client) Commands(appID discord.Snowflake) ([]discord.Command, error) {
+	var cmds []discord.Command
+	return cmds, c.RequestJSON(&cmds, "GET",
+		EndpointApplications+appID.String()+"/commands")
+}
+
+func (c *Client) Command(
+	appID, commandID discord.Snowflake) (*discord.Command, error) {
+
+	var cmd *discord.Command
+	return cmd, c.RequestJSON(&cmd, "GET",
+		EndpointApplications+appID.String()+"/commands/"+commandID.String())
+}
+
+// CreateCommand creates a new global command. Creating a command with the same
+// name as an existing command overwrites the old command. Global commands may
+// take up to an hour to show up in all guilds.
+func (c *Client) CreateCommand(
+	appID discord.Snowflake, data CreateCommandData) (*discord.Command, error) {
+
+	var cmd *discord.Command
+	return cmd, c.RequestJSON(
+		&cmd, "POST",
+		EndpointApplications+appID.String()+"/commands",
+		httputil.WithJSONBody(c, data),
+	)
+}
+
+func (c *Client) EditCommand(
+	appID, commandID discord.Snowflake,
+	data EditCommandData) (*discord.Command, error) {
+
+	var cmd *discord.Command
+	return cmd, c.RequestJSON(
+		&cmd, "PATCH",
+		EndpointApplications+appID.String()+"/commands/"+commandID.String(),
+		httputil.WithJSONBody(c, data),
+	)
+}
+
+func (c *Client) DeleteCommand(appID, commandID discord.Snowflake) error {
+	return c.FastRequest("DELETE",
+		EndpointApplications+appID.String()+"/commands/"+commandID.String())
+}
+
+// BulkOverwriteCommands replaces all global commands of the application with
+// the given ones. Commands that aren't given are deleted.
+func (c *Client) BulkOverwriteCommands(
+	appID discord.Snowflake,
+	commands []CreateCommandData) ([]discord.Command, error) {
+
+	var cmds []discord.Command
+	return cmds, c.RequestJSON(
+		&cmds, "PUT",
+		EndpointApplications+appID.String()+"/commands",
+		httputil.WithJSONBody(c, commands),
+	)
+}
+
+// GuildCommands returns all commands of the application in the guild. Global
+// commands are not included.
+func (c *Client) GuildCommands(
+	appID, guildID discord.Snowflake) ([]discord.Command, error) {
+
+	var cmds []discord.Command
+	return cmds, c.RequestJSON(&cmds, "GET", guildCommandsURL(appID, guildID))
+}
+
+func (c *Client) GuildCommand(
+	appID, guildID, commandID discord.Snowflake) (*discord.Command, error) {
+
+	var cmd *discord.Command
+	return cmd, c.RequestJSON(&cmd, "GET",
+		guildCommandsURL(appID, guildID)+"/"+commandID.String())
+}
+
+// CreateGuildCommand creates a new guild command. Unlike global commands,
+// guild commands show up instantly.
+func (c *Client) CreateGuildCommand(
+	appID, guildID discord.Snowflake,
+	data CreateCommandData) (*discord.Command, error) {
+
+	var cmd *discord.Command
+	return cmd, c.RequestJSON(
+		&cmd, "POST",
+		guildCommandsURL(appID, guildID),
+		httputil.WithJSONBody(c, data),
+	)
+}
+
+func (c *Client) EditGuildCommand(
+	appID, guildID, commandID discord.Snowflake,
+	data EditCommandData) (*discord.Command, error) {
+
+	var cmd *discord.Command
+	return cmd, c.RequestJSON(
+		&cmd, "PATCH",
+		guildCommandsURL(appID, guildID)+"/"+commandID.String(),
+		httputil.WithJSONBody(c, data),
+	)
+}
+
+func (c *Client) DeleteGuildCommand(
+	appID, guildID, commandID discord.Snowflake) error {
+
+	return c.FastRequest("DELETE",
+		guildCommandsURL(appID, guildID)+"/"+commandID.String())
+}
+
+// BulkOverwriteGuildCommands replaces all commands of the application in the
+// guild with the given ones.
+func (c *Client) BulkOverwriteGuildCommands(
+	appID, guildID discord.Snowflake,
+	commands []CreateCommandData) ([]discord.Command, error) {
+
+	var cmds []discord.Command
+	return cmds, c.RequestJSON(
+		&cmds, "PUT",
+		guildCommandsURL(appID, guildID),
+		httputil.WithJSONBody(c, commands),
+	)
+}
+
+func guildCommandsURL(appID, guildID discord.Snowflake) string {
+	return EndpointApplications + appID.String() +
+		"/guilds/" + guildID.String() + "/commands"
+}
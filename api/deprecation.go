@@ -0,0 +1,100 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/diamondburned/arikawa/metrics"
+)
+
+// Deprecation is a notice that an endpoint is deprecated or going away, taken
+// from the Deprecation, Sunset and Warning headers of a response.
+type Deprecation struct {
+	Method string
+	// Route is the path of the endpoint without its IDs, such as
+	// /channels/:id/messages.
+	Route string
+
+	// Deprecated is true if the endpoint is deprecated. Since is when it was
+	// or will be deprecated, if the header has a date.
+	Deprecated bool
+	Since      time.Time
+	// Sunset, if not zero, is when the endpoint stops working.
+	Sunset time.Time
+	// Warnings are the texts of the Warning headers.
+	Warnings []string
+}
+
+// deprecations keeps the routes already reported, shared by the copies made by
+// With, so each route is only reported once.
+type deprecations struct {
+	mutex sync.Mutex
+	seen  map[string]bool
+}
+
+// checkDeprecation calls OnDeprecation if the response has a deprecation
+// notice that wasn't reported yet for the route.
+func (c *Client) checkDeprecation(r *http.Request, resp *http.Response) {
+	if c.OnDeprecation == nil {
+		return
+	}
+
+	d, ok := parseDeprecation(resp.Header)
+	if !ok {
+		return
+	}
+
+	d.Method = r.Method
+	d.Route = metrics.Route(r.URL.Path)
+
+	c.deprecations.mutex.Lock()
+	var seen = c.deprecations.seen[d.Method+" "+d.Route]
+	c.deprecations.seen[d.Method+" "+d.Route] = true
+	c.deprecations.mutex.Unlock()
+
+	if !seen {
+		c.OnDeprecation(d)
+	}
+}
+
+// parseDeprecation reads the deprecation headers. False is returned if there
+// are none.
+func parseDeprecation(h http.Header) (Deprecation, bool) {
+	var d Deprecation
+
+	// The header is either a date, an @ followed by a Unix time, or "true"
+	// for older drafts.
+	if v := h.Get("Deprecation"); v != "" && v != "false" {
+		d.Deprecated = true
+
+		if strings.HasPrefix(v, "@") {
+			if sec, err := strconv.ParseInt(v[1:], 10, 64); err == nil {
+				d.Since = time.Unix(sec, 0).UTC()
+			}
+		} else if t, err := http.ParseTime(v); err == nil {
+			d.Since = t
+		}
+	}
+
+	if v := h.Get("Sunset"); v != "" {
+		if t, err := http.ParseTime(v); err == nil {
+			d.Sunset = t
+		}
+	}
+
+	// Warnings look like 299 - "Deprecated API", where the text is quoted.
+	for _, v := range h["Warning"] {
+		if i := strings.IndexByte(v, '"'); i >= 0 {
+			if text, err := strconv.Unquote(v[i:]); err == nil {
+				v = text
+			}
+		}
+		d.Warnings = append(d.Warnings, v)
+	}
+
+	var ok = d.Deprecated || !d.Sunset.IsZero() || len(d.Warnings) > 0
+	return d, ok
+}
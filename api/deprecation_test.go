@@ -0,0 +1,57 @@
+// +build unit
+
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestOnDeprecation(t *testing.T) {
+	var srv = httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != "/gateway" {
+				w.Header().Set("Deprecation", "@1688169599")
+				w.Header().Set("Sunset", "Sun, 01 Dec 2030 00:00:00 GMT")
+				w.Header().Add("Warning", `299 - "Use the new endpoint"`)
+			}
+			w.WriteHeader(http.StatusNoContent)
+		},
+	))
+	defer srv.Close()
+
+	var notices []Deprecation
+
+	c := NewClient("")
+	c.OnDeprecation = func(d Deprecation) {
+		notices = append(notices, d)
+	}
+
+	// Each route is only reported once, including by copies.
+	for _, path := range []string{
+		"/channels/1/messages/2",
+		"/channels/3/messages/4",
+		"/gateway",
+	} {
+		if err := c.WithReason("").FastRequest(
+			"DELETE", srv.URL+path); err != nil {
+
+			t.Fatal("Failed to request:", err)
+		}
+	}
+
+	var expect = []Deprecation{{
+		Method:     "DELETE",
+		Route:      "/channels/:id/messages/:id",
+		Deprecated: true,
+		Since:      time.Unix(1688169599, 0).UTC(),
+		Sunset:     time.Date(2030, 12, 1, 0, 0, 0, 0, time.UTC),
+		Warnings:   []string{"Use the new endpoint"},
+	}}
+	if !reflect.DeepEqual(notices, expect) {
+		t.Fatalf("Unexpected notices %+v, expected %+v", notices, expect)
+	}
+}
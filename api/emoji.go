@@ -1,21 +1,22 @@
 package api
 
 import (
-	"errors"
-
 	"github.com/diamondburned/arikawa/discord"
 	"github.com/diamondburned/arikawa/internal/httputil"
+	"github.com/pkg/errors"
 )
 
 // EmojiAPI is a special format that the API wants.
 type EmojiAPI = string
 
+// FormatEmojiAPI formats the emoji for the API, which is the name for unicode
+// emojis and name:id for custom emojis. It's the same as Emoji.APIString.
 func FormatEmojiAPI(id discord.Snowflake, name string) string {
 	if id == 0 {
 		return name
 	}
 
-	return id.String() + ":" + name
+	return name + ":" + id.String()
 }
 
 func (c *Client) Emojis(
@@ -34,56 +35,54 @@ func (c *Client) Emoji(
 		EndpointGuilds+guildID.String()+"/emojis/"+emojiID.String())
 }
 
-var (
-	ErrEmojiTooLarge = errors.New("Emoji is larger than 256k")
-)
+// MaxEmojiSize is the maximum size of an emoji image, in bytes.
+const MaxEmojiSize = 256 * 1000
+
+// https://discord.com/developers/docs/resources/emoji#create-guild-emoji-json-params
+type CreateEmojiData struct {
+	Name  string `json:"name"`
+	Image Image  `json:"image"`
+	// Roles restricts the emoji to the members of these roles. Everyone can
+	// use the emoji if it's empty.
+	Roles []discord.Snowflake `json:"roles,omitempty"`
+}
 
 // CreateEmoji creates a new emoji in the guild. This endpoint requires
-// MANAGE_EMOJIS. ContentType must be "image/jpeg", "image/png", or
-// "image/gif". However, ContentType can also be automatically detected
-// (though shouldn't be relied on). Roles slice is optional.
+// MANAGE_EMOJIS. The image must be a JPEG, PNG or GIF of at most 256kb, which
+// can be read with ReadImage.
 func (c *Client) CreateEmoji(
-	guildID discord.Snowflake, name string, image Image,
-	roles []discord.Snowflake) (*discord.Emoji, error) {
-
-	image.MaxSize = 256 * 1000
-	if err := image.Validate(); err != nil {
-		return nil, err
-	}
+	guildID discord.Snowflake, data CreateEmojiData) (*discord.Emoji, error) {
 
-	var param struct {
-		Name  string              `json:"name"`
-		Image Image               `json:"image"`
-		Roles []discord.Snowflake `json:"roles"`
+	data.Image.MaxSize = MaxEmojiSize
+	if err := data.Image.Validate(); err != nil {
+		return nil, errors.Wrap(err, "Invalid emoji image")
 	}
 
-	param.Name = name
-	param.Roles = roles
-	param.Image = image
-
 	var emj *discord.Emoji
 	return emj, c.RequestJSON(
 		&emj, "POST",
 		EndpointGuilds+guildID.String()+"/emojis",
-		httputil.WithJSONBody(c, param),
+		httputil.WithJSONBody(c, data),
 	)
 }
 
-// ModifyEmoji changes an existing emoji. This requires MANAGE_EMOJIS. Name and
-// roles are optional fields (though you'd want to change either though).
-func (c *Client) ModifyEmoji(
-	guildID, emojiID discord.Snowflake, name string,
-	roles []discord.Snowflake) error {
+// https://discord.com/developers/docs/resources/emoji#modify-guild-emoji-json-params
+type ModifyEmojiData struct {
+	Name string `json:"name,omitempty"`
+	// Roles are left unchanged if nil. A pointer to an empty slice lifts the
+	// role restriction of the emoji.
+	Roles *[]discord.Snowflake `json:"roles,omitempty"`
+}
 
-	var param struct {
-		Name  string              `json:"name,omitempty"`
-		Roles []discord.Snowflake `json:"roles,omitempty"`
-	}
+// ModifyEmoji changes an existing emoji. This requires MANAGE_EMOJIS.
+func (c *Client) ModifyEmoji(guildID, emojiID discord.Snowflake,
+	data ModifyEmojiData) (*discord.Emoji, error) {
 
-	return c.FastRequest(
-		"PATCH",
+	var emj *discord.Emoji
+	return emj, c.RequestJSON(
+		&emj, "PATCH",
 		EndpointGuilds+guildID.String()+"/emojis/"+emojiID.String(),
-		httputil.WithJSONBody(c, param),
+		httputil.WithJSONBody(c, data),
 	)
 }
 
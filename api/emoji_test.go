@@ -0,0 +1,64 @@
+// +build unit
+
+package api
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/diamondburned/arikawa/discord"
+)
+
+func TestEmojiRequests(t *testing.T) {
+	var bodies = make(chan string, 1)
+
+	c := NewClient("")
+	c.Client.Client.Transport = roundTripFunc(
+		func(r *http.Request) *http.Response {
+			b, _ := ioutil.ReadAll(r.Body)
+			bodies <- strings.TrimSpace(string(b))
+
+			return &http.Response{
+				StatusCode: 200,
+				Header:     http.Header{},
+				Body:       ioutil.NopCloser(strings.NewReader(`{"id":"3"}`)),
+			}
+		},
+	)
+
+	var png = []byte("\x89PNG\r\n\x1a\n")
+
+	img, err := ReadImage(bytes.NewReader(png))
+	if err != nil {
+		t.Fatal("Failed to read image:", err)
+	}
+
+	_, err = c.CreateEmoji(1, CreateEmojiData{Name: "emoji", Image: *img})
+	if err != nil {
+		t.Fatal("Failed to create emoji:", err)
+	}
+
+	var expected = `{"name":"emoji","image":"data:image/png;base64,iVBORw0KGgo="}`
+	if body := <-bodies; body != expected {
+		t.Fatalf("Unexpected body %s, expected %s", body, expected)
+	}
+
+	_, err = c.ModifyEmoji(1, 3, ModifyEmojiData{
+		Roles: &[]discord.Snowflake{},
+	})
+	if err != nil {
+		t.Fatal("Failed to modify emoji:", err)
+	}
+
+	if body := <-bodies; body != `{"roles":[]}` {
+		t.Fatal("Unexpected body", body)
+	}
+
+	img.Content = bytes.Repeat(img.Content, MaxEmojiSize)
+	if _, err := c.CreateEmoji(1, CreateEmojiData{Image: *img}); err == nil {
+		t.Fatal("Created an emoji larger than the limit")
+	}
+}
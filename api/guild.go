@@ -6,6 +6,7 @@ import (
 	"github.com/diamondburned/arikawa/discord" // for clarity
 	d "github.com/diamondburned/arikawa/discord"
 	"github.com/diamondburned/arikawa/internal/httputil"
+	"github.com/diamondburned/arikawa/internal/json"
 )
 
 const EndpointGuilds = Endpoint + "guilds/"
@@ -41,38 +42,42 @@ func (c *Client) Guild(guildID discord.Snowflake) (*discord.Guild, error) {
 	return g, c.RequestJSON(&g, "GET", EndpointGuilds+guildID.String())
 }
 
+// GuildWithCount returns the guild with the approximate member and presence
+// counts filled.
+func (c *Client) GuildWithCount(
+	guildID discord.Snowflake) (*discord.Guild, error) {
+
+	var g *discord.Guild
+	return g, c.RequestJSON(
+		&g, "GET",
+		EndpointGuilds+guildID.String()+"?with_counts=true",
+	)
+}
+
 // Guilds returns all guilds, automatically paginating. Be careful, as this
 // method may abuse the API by requesting thousands or millions of guilds. For
 // lower-level access, usee GuildsRange. Guilds returned have some fields
-// filled only (ID, Name, Icon, Owner, Permissions).
+// filled only (ID, Name, Icon, Owner, Permissions). Max can be 0, in which
+// case all guilds are fetched. GuildsIter fetches them a page at a time.
 func (c *Client) Guilds(max uint) ([]discord.Guild, error) {
 	var guilds []discord.Guild
 	var after discord.Snowflake = 0
 
-	const hardLimit int = 100
-
-	for fetch := uint(hardLimit); max > 0; fetch = uint(hardLimit) {
-		if max > 0 {
-			if fetch > max {
-				fetch = max
-			}
-			max -= fetch
-		}
-
-		g, err := c.GuildsAfter(after, fetch)
+	err := paginate(max, 100, func(limit uint) (int, error) {
+		g, err := c.GuildsAfter(after, limit)
 		if err != nil {
-			return guilds, err
+			return 0, err
 		}
 		guilds = append(guilds, g...)
 
-		if len(g) < hardLimit {
-			break
+		if len(g) > 0 {
+			after = g[len(g)-1].ID
 		}
 
-		after = g[hardLimit-1].ID
-	}
+		return len(g), nil
+	})
 
-	return guilds, nil
+	return guilds, err
 }
 
 // GuildsBefore fetches guilds. Check GuildsRange.
@@ -93,24 +98,7 @@ func (c *Client) GuildsAfter(
 func (c *Client) GuildsRange(
 	before, after discord.Snowflake, limit uint) ([]discord.Guild, error) {
 
-	if limit == 0 {
-		limit = 100
-	}
-
-	if limit > 100 {
-		limit = 100
-	}
-
-	var param struct {
-		Before discord.Snowflake `schema:"before"`
-		After  discord.Snowflake `schema:"after"`
-
-		Limit uint `schema:"limit"`
-	}
-
-	param.Before = before
-	param.After = after
-	param.Limit = limit
+	var param = newRangeParam(before, after, limit, 100, 100)
 
 	var gs []discord.Guild
 	return gs, c.RequestJSON(
@@ -246,6 +234,79 @@ func (c *Client) ModifyGuildEmbed(
 		EndpointGuilds+guildID.String()+"/embed")
 }
 
+// GuildPreview returns the public information of the guild. The guild must be
+// discoverable if the current user isn't in it.
+func (c *Client) GuildPreview(
+	guildID discord.Snowflake) (*discord.GuildPreview, error) {
+
+	var p *discord.GuildPreview
+	return p, c.RequestJSON(&p, "GET",
+		EndpointGuilds+guildID.String()+"/preview")
+}
+
+// GuildWelcomeScreen returns the welcome screen of the guild. It requires the
+// MANAGE_GUILD permission if the welcome screen isn't enabled.
+func (c *Client) GuildWelcomeScreen(
+	guildID discord.Snowflake) (*discord.WelcomeScreen, error) {
+
+	var ws *discord.WelcomeScreen
+	return ws, c.RequestJSON(&ws, "GET",
+		EndpointGuilds+guildID.String()+"/welcome-screen")
+}
+
+// https://discord.com/developers/docs/resources/guild#modify-guild-welcome-screen-json-params
+type ModifyWelcomeScreenData struct {
+	Enabled json.OptionBool `json:"enabled,omitempty"`
+	// WelcomeChannels are left unchanged if nil.
+	WelcomeChannels []discord.WelcomeChannel `json:"welcome_channels,omitempty"`
+	Description     json.OptionString        `json:"description,omitempty"`
+}
+
+// ModifyGuildWelcomeScreen changes the welcome screen of the guild. It
+// requires the MANAGE_GUILD permission.
+func (c *Client) ModifyGuildWelcomeScreen(
+	guildID discord.Snowflake,
+	data ModifyWelcomeScreenData) (*discord.WelcomeScreen, error) {
+
+	var ws *discord.WelcomeScreen
+	return ws, c.RequestJSON(
+		&ws, "PATCH",
+		EndpointGuilds+guildID.String()+"/welcome-screen",
+		httputil.WithJSONBody(c, data),
+	)
+}
+
+// GuildMembershipScreening returns the membership screening form of the
+// guild.
+func (c *Client) GuildMembershipScreening(
+	guildID discord.Snowflake) (*discord.MembershipScreening, error) {
+
+	var ms *discord.MembershipScreening
+	return ms, c.RequestJSON(&ms, "GET",
+		EndpointGuilds+guildID.String()+"/member-verification")
+}
+
+// https://discord.com/developers/docs/resources/guild#modify-guild-membership-screening-form-json-params
+type ModifyMembershipScreeningData struct {
+	Enabled json.OptionBool `json:"enabled,omitempty"`
+	// FormFields are left unchanged if nil.
+	FormFields  []discord.MembershipScreeningField `json:"form_fields,omitempty"`
+	Description json.OptionString                  `json:"description,omitempty"`
+}
+
+// ModifyGuildMembershipScreening changes the membership screening form of the
+// guild. It requires the MANAGE_GUILD permission.
+func (c *Client) ModifyGuildMembershipScreening(guildID discord.Snowflake,
+	data ModifyMembershipScreeningData) (*discord.MembershipScreening, error) {
+
+	var ms *discord.MembershipScreening
+	return ms, c.RequestJSON(
+		&ms, "PATCH",
+		EndpointGuilds+guildID.String()+"/member-verification",
+		httputil.WithJSONBody(c, data),
+	)
+}
+
 // GuildVanityURL returns *Invite, but only Code and Uses are filled. Requires
 // MANAGE_GUILD.
 func (c *Client) GuildVanityURL(
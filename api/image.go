@@ -5,6 +5,8 @@ import (
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
+	"io"
+	"io/ioutil"
 	"net/http"
 
 	"github.com/pkg/errors"
@@ -37,6 +39,19 @@ type Image struct {
 	MaxSize int // bytes
 }
 
+// ReadImage reads the whole image from r and detects its content type.
+func ReadImage(r io.Reader) (*Image, error) {
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to read image")
+	}
+
+	var img = Image{Content: b}
+	img.ContentType = img.detectContentType()
+
+	return &img, nil
+}
+
 func DecodeImage(data []byte) (*Image, error) {
 	parts := bytes.SplitN(data, []byte{';'}, 2)
 	if len(parts) < 2 {
@@ -61,7 +76,13 @@ func DecodeImage(data []byte) (*Image, error) {
 	return &img, nil
 }
 
+// Validate checks the size and the content type of the image, which is
+// detected if it's empty.
 func (i Image) Validate() error {
+	if i.ContentType == "" {
+		i.ContentType = i.detectContentType()
+	}
+
 	if i.MaxSize > 0 && len(i.Content) > i.MaxSize {
 		return ErrImageTooLarge{len(i.Content), i.MaxSize}
 	}
@@ -76,11 +97,7 @@ func (i Image) Validate() error {
 
 func (i Image) Encode() ([]byte, error) {
 	if i.ContentType == "" {
-		var max = 512
-		if len(i.Content) < max {
-			max = len(i.Content)
-		}
-		i.ContentType = http.DetectContentType(i.Content[:max])
+		i.ContentType = i.detectContentType()
 	}
 
 	if err := i.Validate(); err != nil {
@@ -98,6 +115,14 @@ func (i Image) Encode() ([]byte, error) {
 	}, nil), nil
 }
 
+func (i Image) detectContentType() string {
+	var max = 512
+	if len(i.Content) < max {
+		max = len(i.Content)
+	}
+	return http.DetectContentType(i.Content[:max])
+}
+
 var _ json.Marshaler = (*Image)(nil)
 var _ json.Unmarshaler = (*Image)(nil)
 
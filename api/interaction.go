@@ -0,0 +1,174 @@
+package api
+
+import (
+	"strconv"
+
+	"github.com/diamondburned/arikawa/discord"
+	"github.com/diamondburned/arikawa/internal/httputil"
+	"github.com/pkg/errors"
+)
+
+const EndpointInteractions = Endpoint + "interactions/"
+
+// RespondInteraction responds to an interaction. This has to be done within 3
+// seconds of receiving the interaction; use a deferred response type if it
+// takes longer.
+func (c *Client) RespondInteraction(
+	id discord.Snowflake, token string,
+	resp discord.InteractionResponse) error {
+
+	if resp.Data != nil {
+		// The data is copied, so the caller's isn't changed.
+		var data = *resp.Data
+		resp.Data = &data
+
+		err := c.validateInteraction(
+			data.Embeds, &data.Components, &data.AllowedMentions)
+		if err != nil {
+			return err
+		}
+	}
+
+	return c.FastRequest(
+		"POST",
+		EndpointInteractions+id.String()+"/"+token+"/callback",
+		httputil.WithJSONBody(c, resp),
+	)
+}
+
+// InteractionResponse returns the initial response to the interaction.
+func (c *Client) InteractionResponse(
+	appID discord.Snowflake, token string) (*discord.Message, error) {
+
+	var msg *discord.Message
+	return msg, c.RequestJSON(&msg, "GET",
+		interactionURL(appID, token)+"/messages/@original")
+}
+
+// https://discord.com/developers/docs/interactions/slash-commands#edit-original-interaction-response
+type EditInteractionResponseData struct {
+	Content string          `json:"content,omitempty"`
+	Embeds  []discord.Embed `json:"embeds,omitempty"`
+
+	// Components replaces the message's components if it's not nil.
+	Components *discord.Components `json:"components,omitempty"`
+
+	// AllowedMentions controls who is pinged by the new content. It defaults
+	// to the AllowedMentions of the Client if nil.
+	AllowedMentions *discord.AllowedMentions `json:"allowed_mentions,omitempty"`
+}
+
+// EditInteractionResponse edits the initial response to the interaction. This
+// is also used to send the message after a deferred response.
+func (c *Client) EditInteractionResponse(
+	appID discord.Snowflake, token string,
+	data EditInteractionResponseData) (*discord.Message, error) {
+
+	err := c.validateInteraction(
+		data.Embeds, data.Components, &data.AllowedMentions)
+	if err != nil {
+		return nil, err
+	}
+
+	var msg *discord.Message
+	return msg, c.RequestJSON(
+		&msg, "PATCH",
+		interactionURL(appID, token)+"/messages/@original",
+		httputil.WithJSONBody(c, data),
+	)
+}
+
+// DeleteInteractionResponse deletes the initial response to the interaction.
+func (c *Client) DeleteInteractionResponse(
+	appID discord.Snowflake, token string) error {
+
+	return c.FastRequest("DELETE",
+		interactionURL(appID, token)+"/messages/@original")
+}
+
+// CreateFollowupMessage sends a new message after the initial response. Set
+// Flags to EphemeralMessage to only show it to the user.
+func (c *Client) CreateFollowupMessage(
+	appID discord.Snowflake, token string,
+	data discord.InteractionResponseData) (*discord.Message, error) {
+
+	err := c.validateInteraction(
+		data.Embeds, &data.Components, &data.AllowedMentions)
+	if err != nil {
+		return nil, err
+	}
+
+	var msg *discord.Message
+	return msg, c.RequestJSON(
+		&msg, "POST",
+		interactionURL(appID, token)+"?wait=true",
+		httputil.WithJSONBody(c, data),
+	)
+}
+
+// EditFollowupMessage edits a followup message.
+func (c *Client) EditFollowupMessage(
+	appID discord.Snowflake, token string, messageID discord.Snowflake,
+	data EditInteractionResponseData) (*discord.Message, error) {
+
+	err := c.validateInteraction(
+		data.Embeds, data.Components, &data.AllowedMentions)
+	if err != nil {
+		return nil, err
+	}
+
+	var msg *discord.Message
+	return msg, c.RequestJSON(
+		&msg, "PATCH",
+		interactionURL(appID, token)+"/messages/"+messageID.String(),
+		httputil.WithJSONBody(c, data),
+	)
+}
+
+// DeleteFollowupMessage deletes a followup message.
+func (c *Client) DeleteFollowupMessage(
+	appID discord.Snowflake, token string, messageID discord.Snowflake) error {
+
+	return c.FastRequest("DELETE",
+		interactionURL(appID, token)+"/messages/"+messageID.String())
+}
+
+// interactionURL returns the webhook URL used for interaction responses and
+// followups.
+func interactionURL(appID discord.Snowflake, token string) string {
+	return EndpointWebhooks + appID.String() + "/" + token
+}
+
+// validateInteraction validates a message of an interaction like
+// validateMessage, and defaults its allowed mentions to the Client's.
+func (c *Client) validateInteraction(embeds []discord.Embed,
+	components *discord.Components, am **discord.AllowedMentions) error {
+
+	if err := validateMessage(embeds, components); err != nil {
+		return err
+	}
+
+	var err error
+	*am, err = c.allowedMentions(*am)
+	return err
+}
+
+// validateMessage validates the embeds and, if they're not nil, the
+// components of a message.
+func validateMessage(
+	embeds []discord.Embed, components *discord.Components) error {
+
+	for i, embed := range embeds {
+		if err := embed.Validate(); err != nil {
+			return errors.Wrap(err, "Embed error at "+strconv.Itoa(i))
+		}
+	}
+
+	if components != nil {
+		if err := components.Validate(); err != nil {
+			return errors.Wrap(err, "Components error")
+		}
+	}
+
+	return nil
+}
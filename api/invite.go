@@ -3,6 +3,7 @@ package api
 import (
 	"github.com/diamondburned/arikawa/discord"
 	"github.com/diamondburned/arikawa/internal/httputil"
+	"github.com/diamondburned/arikawa/internal/json"
 )
 
 const EndpointInvites = Endpoint + "invites/"
@@ -19,68 +20,84 @@ type MetaInvite struct {
 	CreatedAt discord.Timestamp `json:"created_at"`
 }
 
+// Invite returns the invite with the code, with its approximate member counts
+// and its expiration.
 func (c *Client) Invite(code string) (*discord.Invite, error) {
-	var params struct {
-		WithCounts bool `schema:"with_counts,omitempty"`
-	}
+	return c.InviteWithOptions(code, InviteOptions{
+		WithCounts:     true,
+		WithExpiration: true,
+	})
+}
+
+// InviteOptions are the optional fields of an invite to fetch.
+type InviteOptions struct {
+	// WithCounts fills the approximate member and presence counts.
+	WithCounts bool `schema:"with_counts,omitempty"`
+	// WithExpiration fills ExpiresAt.
+	WithExpiration bool `schema:"with_expiration,omitempty"`
+}
 
-	// Nothing says I can't!
-	params.WithCounts = true
+// InviteWithOptions returns the invite with the code, with only the optional
+// fields asked for.
+func (c *Client) InviteWithOptions(
+	code string, opts InviteOptions) (*discord.Invite, error) {
 
 	var inv *discord.Invite
 	return inv, c.RequestJSON(
 		&inv, "GET",
 		EndpointInvites+code,
-		httputil.WithSchema(c, params),
+		httputil.WithSchema(c, opts),
 	)
 }
 
 // ChannelInvites is only for guild channels. GuildInvites is for guilds.
 func (c *Client) ChannelInvites(
-	channelID discord.Snowflake) ([]discord.Invite, error) {
+	channelID discord.Snowflake) ([]discord.InviteMeta, error) {
 
-	var invs []discord.Invite
+	var invs []discord.InviteMeta
 	return invs, c.RequestJSON(&invs, "GET",
 		EndpointChannels+channelID.String()+"/invites")
 }
 
 // GuildInvites is for guilds.
 func (c *Client) GuildInvites(
-	guildID discord.Snowflake) ([]discord.Invite, error) {
+	guildID discord.Snowflake) ([]discord.InviteMeta, error) {
 
-	var invs []discord.Invite
+	var invs []discord.InviteMeta
 	return invs, c.RequestJSON(&invs, "GET",
 		EndpointGuilds+guildID.String()+"/invites")
 }
 
+// https://discord.com/developers/docs/resources/channel#create-channel-invite-json-params
+type CreateInviteData struct {
+	// MaxAge is the duration before the invite expires, 0 for never. It
+	// defaults to 24 hours if unset.
+	MaxAge json.OptionUint `json:"max_age,omitempty"`
+	// MaxUses is the maximum number of uses, 0 for unlimited.
+	MaxUses uint `json:"max_uses,omitempty"`
+	// Temporary invites kick their members once they disconnect, unless they
+	// were given a role.
+	Temporary bool `json:"temporary,omitempty"`
+	// Unique, if true, doesn't reuse a similar invite, which is useful for
+	// one time use invites.
+	Unique bool `json:"unique,omitempty"`
+
+	// TargetType is the type of the target of a voice channel invite, such as
+	// InviteUserStream for the stream of TargetUserID.
+	TargetType   discord.InviteUserType `json:"target_type,omitempty"`
+	TargetUserID discord.Snowflake      `json:"target_user_id,string,omitempty"`
+}
+
 // CreateInvite is only for guild channels. This endpoint requires
 // CREATE_INSTANT_INVITE.
-//
-// MaxAge is the duration before expiry, 0 for never. MaxUses is the maximum
-// number of uses, 0 for unlimited. Temporary is whether this invite grants
-// temporary membership. Unique, if true, tries not to reuse a similar invite,
-// useful for creating unique one time use invites.
-func (c *Client) CreateInvite(
-	channelID discord.Snowflake, maxAge discord.Seconds,
-	maxUses uint, temp, unique bool) (*discord.Invite, error) {
-
-	var param struct {
-		MaxAge    int  `json:"max_age"`
-		MaxUses   uint `json:"max_uses"`
-		Temporary bool `json:"temporary"`
-		Unique    bool `json:"unique"`
-	}
-
-	param.MaxAge = int(maxAge)
-	param.MaxUses = maxUses
-	param.Temporary = temp
-	param.Unique = unique
+func (c *Client) CreateInvite(channelID discord.Snowflake,
+	data CreateInviteData) (*discord.Invite, error) {
 
 	var inv *discord.Invite
 	return inv, c.RequestJSON(
 		&inv, "POST",
 		EndpointChannels+channelID.String()+"/invites",
-		httputil.WithSchema(c, param),
+		httputil.WithJSONBody(c, data),
 	)
 }
 
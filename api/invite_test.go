@@ -0,0 +1,51 @@
+// +build unit
+
+package api
+
+import (
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/diamondburned/arikawa/internal/json"
+)
+
+func TestCreateInvite(t *testing.T) {
+	var requests = make(chan string, 1)
+
+	c := NewClient("")
+	c.Client.Client.Transport = roundTripFunc(
+		func(r *http.Request) *http.Response {
+			b, _ := ioutil.ReadAll(r.Body)
+			requests <- r.Method + " " + r.URL.Path + " " +
+				strings.TrimSpace(string(b))
+
+			return &http.Response{
+				StatusCode: 200,
+				Header:     http.Header{},
+				Body: ioutil.NopCloser(strings.NewReader(
+					`{"code":"abc","approximate_member_count":5}`)),
+			}
+		},
+	)
+
+	inv, err := c.CreateInvite(1, CreateInviteData{
+		MaxAge:       json.Uint(0),
+		TargetType:   1,
+		TargetUserID: 2,
+	})
+	if err != nil {
+		t.Fatal("Failed to create invite:", err)
+	}
+
+	var expected = "POST /api/v6/channels/1/invites " +
+		`{"max_age":0,"target_type":1,"target_user_id":"2"}`
+	if req := <-requests; req != expected {
+		t.Fatalf("Unexpected request %s, expected %s", req, expected)
+	}
+
+	if inv.Code != "abc" || inv.ApproxMembers != 5 {
+		t.Fatal("Unexpected invite:", inv)
+	}
+}
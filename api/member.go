@@ -15,66 +15,66 @@ func (c *Client) Member(
 
 // Members returns members until it reaches max. This function automatically
 // paginates, meaning the normal 1000 limit is handled internally. Max can be 0,
-// in which the function will try and fetch everything.
+// in which the function will try and fetch everything. MembersIter fetches
+// them a page at a time.
 func (c *Client) Members(
 	guildID discord.Snowflake, max uint) ([]discord.Member, error) {
 
 	var mems []discord.Member
 	var after discord.Snowflake = 0
 
-	const hardLimit int = 1000
-
-	for fetch := uint(hardLimit); max > 0; fetch = uint(hardLimit) {
-		if max > 0 {
-			if fetch > max {
-				fetch = max
-			}
-			max -= fetch
-		}
-
-		m, err := c.MembersAfter(guildID, after, fetch)
+	err := paginate(max, 1000, func(limit uint) (int, error) {
+		m, err := c.MembersAfter(guildID, after, limit)
 		if err != nil {
-			return mems, err
+			return 0, err
 		}
 		mems = append(mems, m...)
 
-		// There aren't any to fetch, even if this is less than max.
-		if len(mems) < hardLimit {
-			break
+		if len(m) > 0 {
+			after = m[len(m)-1].User.ID
 		}
 
-		after = mems[hardLimit-1].User.ID
-	}
+		return len(m), nil
+	})
 
-	return mems, nil
+	return mems, err
 }
 
 // MembersAfter returns a list of all guild members, from 1-1000 for limits. The
-// default limit is 1 and the maximum limit is 1000.
+// default limit is 1 and the maximum limit is 1000. Unlike other list
+// endpoints, Discord only supports paginating members forwards, so there's no
+// MembersBefore or MembersRange.
 func (c *Client) MembersAfter(
 	guildID, after discord.Snowflake, limit uint) ([]discord.Member, error) {
 
-	if limit == 0 {
-		limit = 1
-	}
+	var param = newRangeParam(0, after, limit, 1, 1000)
 
-	if limit > 1000 {
-		limit = 1000
-	}
+	var mems []discord.Member
+	return mems, c.RequestJSON(
+		&mems, "GET",
+		EndpointGuilds+guildID.String()+"/members",
+		httputil.WithSchema(c, param),
+	)
+}
 
-	var param struct {
-		After discord.Snowflake `schema:"after,omitempty"`
+// SearchMembers returns the members whose username or nickname starts with
+// the query, case-insensitively. The limit is 1-1000, and defaults to 1.
+func (c *Client) SearchMembers(
+	guildID discord.Snowflake, query string,
+	limit uint) ([]discord.Member, error) {
 
-		Limit uint `schema:"limit"`
+	var param struct {
+		Query string `schema:"query"`
+		Limit uint   `schema:"limit"`
 	}
 
-	param.Limit = limit
-	param.After = after
+	param.Query = query
+	param.Limit = clampLimit(limit, 1, 1000)
 
 	var mems []discord.Member
 	return mems, c.RequestJSON(
 		&mems, "GET",
-		EndpointGuilds+guildID.String()+"/members",
+		EndpointGuilds+guildID.String()+"/members/search",
 		httputil.WithSchema(c, param),
 	)
 }
@@ -156,17 +156,30 @@ func (c *Client) PruneCount(
 func (c *Client) Prune(
 	guildID discord.Snowflake, days uint) (uint, error) {
 
-	if days == 0 {
-		days = 7
-	}
+	return c.PruneComplex(guildID, PruneData{
+		Days:         days,
+		ComputeCount: true,
+	})
+}
 
-	var param struct {
-		Count    uint `schema:"count"`
-		RetCount bool `schema:"compute_prune_count"`
-	}
+// https://discordapp.com/developers/docs/resources/guild#begin-guild-prune-query-string-params
+type PruneData struct {
+	// Days must be 1 or more, default 7.
+	Days uint `schema:"days"`
+	// ComputeCount, if false, skips counting the removed members, which is
+	// recommended for large guilds. The returned count is 0 then.
+	ComputeCount bool `schema:"compute_prune_count"`
+}
 
-	param.Count = days
-	param.RetCount = true // maybe expose this later?
+// PruneComplex removes the members that haven't been seen in data.Days, and
+// returns the number of members removed if data.ComputeCount is true.
+// Requires KICK_MEMBERS.
+func (c *Client) PruneComplex(
+	guildID discord.Snowflake, data PruneData) (uint, error) {
+
+	if data.Days == 0 {
+		data.Days = 7
+	}
 
 	var resp struct {
 		Pruned uint `json:"pruned"`
@@ -175,7 +188,7 @@ func (c *Client) Prune(
 	return resp.Pruned, c.RequestJSON(
 		&resp, "POST",
 		EndpointGuilds+guildID.String()+"/prune",
-		httputil.WithSchema(c, param),
+		httputil.WithSchema(c, data),
 	)
 }
 
@@ -185,12 +198,45 @@ func (c *Client) Kick(guildID, userID discord.Snowflake) error {
 		EndpointGuilds+guildID.String()+"/members/"+userID.String())
 }
 
+// Bans returns all bans of the guild in a single request. Use BansIter or
+// BansRange to paginate. Requires BAN_MEMBERS.
 func (c *Client) Bans(guildID discord.Snowflake) ([]discord.Ban, error) {
 	var bans []discord.Ban
 	return bans, c.RequestJSON(&bans, "GET",
 		EndpointGuilds+guildID.String()+"/bans")
 }
 
+// BansBefore fetches bans. Check BansRange.
+func (c *Client) BansBefore(
+	guildID, before discord.Snowflake, limit uint) ([]discord.Ban, error) {
+
+	return c.BansRange(guildID, before, 0, limit)
+}
+
+// BansAfter fetches bans. Check BansRange.
+func (c *Client) BansAfter(
+	guildID, after discord.Snowflake, limit uint) ([]discord.Ban, error) {
+
+	return c.BansRange(guildID, 0, after, limit)
+}
+
+// BansRange fetches bans before and after the given user IDs. The limit is
+// 1-1000, and defaults to 1000. Requires BAN_MEMBERS.
+func (c *Client) BansRange(
+	guildID, before, after discord.Snowflake,
+	limit uint) ([]discord.Ban, error) {
+
+	var param = newRangeParam(before, after, limit, 1000, 1000)
+
+	var bans []discord.Ban
+	return bans, c.RequestJSON(
+		&bans, "GET",
+		EndpointGuilds+guildID.String()+"/bans",
+		httputil.WithSchema(c, param),
+	)
+}
+
+// GetBan returns the ban of the user. Requires BAN_MEMBERS.
 func (c *Client) GetBan(
 	guildID, userID discord.Snowflake) (*discord.Ban, error) {
 
@@ -1,46 +1,43 @@
 package api
 
 import (
+	"fmt"
+	"io"
 	"mime/multipart"
+	"time"
 
 	"github.com/diamondburned/arikawa/discord"
 	"github.com/diamondburned/arikawa/internal/httputil"
+	"github.com/diamondburned/arikawa/internal/json"
 	"github.com/pkg/errors"
 )
 
 // Messages gets all mesesages, automatically paginating. Use with care, as
 // this could get as many as hundred thousands of messages, making a lot of
-// queries.
+// queries. Messages are fetched from the latest one backwards, and max can be
+// 0, in which case all messages are fetched.
 func (c *Client) Messages(
 	channelID discord.Snowflake, max uint) ([]discord.Message, error) {
 
 	var msgs []discord.Message
-	var after discord.Snowflake = 0
+	var before discord.Snowflake = 0
 
-	const hardLimit int = 100
-
-	for fetch := uint(hardLimit); max > 0; fetch = uint(hardLimit) {
-		if max > 0 {
-			if fetch > max {
-				fetch = max
-			}
-			max -= fetch
-		}
-
-		m, err := c.messagesRange(channelID, 0, after, 0, fetch)
+	err := paginate(max, 100, func(limit uint) (int, error) {
+		m, err := c.messagesRange(channelID, before, 0, 0, limit)
 		if err != nil {
-			return msgs, err
+			return 0, err
 		}
 		msgs = append(msgs, m...)
 
-		if len(m) < hardLimit {
-			break
+		// Messages are returned newest first.
+		if len(m) > 0 {
+			before = m[len(m)-1].ID
 		}
 
-		after = m[hardLimit-1].Author.ID
-	}
+		return len(m), nil
+	})
 
-	return msgs, nil
+	return msgs, err
 }
 
 // MessagesAround returns messages around the ID, with a limit of 1-100.
@@ -70,13 +67,6 @@ func (c *Client) MessagesAfter(
 func (c *Client) messagesRange(channelID, before, after,
 	around discord.Snowflake, limit uint) ([]discord.Message, error) {
 
-	switch {
-	case limit == 0:
-		limit = 50
-	case limit > 100:
-		limit = 100
-	}
-
 	var param struct {
 		Before discord.Snowflake `schema:"before,omitempty"`
 		After  discord.Snowflake `schema:"after,omitempty"`
@@ -88,7 +78,7 @@ func (c *Client) messagesRange(channelID, before, after,
 	param.Before = before
 	param.After = after
 	param.Around = around
-	param.Limit = limit
+	param.Limit = clampLimit(limit, 50, 100)
 
 	var msgs []discord.Message
 	return msgs, c.RequestJSON(
@@ -116,16 +106,65 @@ func (c *Client) SendMessage(
 	})
 }
 
+// SendText sends a message with only the content.
+func (c *Client) SendText(
+	channelID discord.Snowflake, content string) (*discord.Message, error) {
+
+	return c.SendMessageComplex(channelID, SendMessageData{Content: content})
+}
+
+// Sendf sends a message with the content formatted like fmt.Sprintf.
+func (c *Client) Sendf(channelID discord.Snowflake,
+	format string, v ...interface{}) (*discord.Message, error) {
+
+	return c.SendText(channelID, fmt.Sprintf(format, v...))
+}
+
+// SendEmbed sends a message with only the embed.
+func (c *Client) SendEmbed(channelID discord.Snowflake,
+	embed discord.Embed) (*discord.Message, error) {
+
+	return c.SendMessageComplex(channelID, SendMessageData{Embed: &embed})
+}
+
+// SendFile uploads a file in a message, with an optional content. The file is
+// read as it's uploaded. Refer to SendMessageFile.
+func (c *Client) SendFile(channelID discord.Snowflake,
+	content, name string, r io.Reader) (*discord.Message, error) {
+
+	return c.SendMessageComplex(channelID, SendMessageData{
+		Content: content,
+		Files:   []SendMessageFile{{Name: name, Reader: r}},
+	})
+}
+
 func (c *Client) SendMessageComplex(
 	channelID discord.Snowflake,
 	data SendMessageData) (*discord.Message, error) {
 
+	if c.BeforeSend != nil {
+		if err := c.BeforeSend(channelID, &data); err != nil {
+			return nil, errors.Wrap(err, "Message rejected")
+		}
+	}
+
 	if data.Embed != nil {
 		if err := data.Embed.Validate(); err != nil {
 			return nil, errors.Wrap(err, "Embed error")
 		}
 	}
 
+	if err := data.Components.Validate(); err != nil {
+		return nil, errors.Wrap(err, "Components error")
+	}
+
+	var err error
+	if data.AllowedMentions, err = c.allowedMentions(
+		data.AllowedMentions); err != nil {
+
+		return nil, err
+	}
+
 	var URL = EndpointChannels + channelID.String() + "/messages"
 	var msg *discord.Message
 
@@ -153,26 +192,91 @@ func (c *Client) EditMessage(
 	channelID, messageID discord.Snowflake, content string,
 	embed *discord.Embed, suppressEmbeds bool) (*discord.Message, error) {
 
-	var param struct {
-		Content string               `json:"content,omitempty"`
-		Embed   *discord.Embed       `json:"embed,omitempty"`
-		Flags   discord.MessageFlags `json:"flags,omitempty"`
+	var data = EditMessageData{Embed: embed}
+	if content != "" {
+		data.Content = json.String(content)
 	}
-
-	param.Content = content
-	param.Embed = embed
 	if suppressEmbeds {
-		param.Flags = discord.SuppressEmbeds
+		var flags = discord.SuppressEmbeds
+		data.Flags = &flags
+	}
+
+	return c.EditMessageComplex(channelID, messageID, data)
+}
+
+// https://discord.com/developers/docs/resources/channel#edit-message-json-params
+type EditMessageData struct {
+	Content json.OptionString `json:"content,omitempty"`
+	Embed   *discord.Embed    `json:"embed,omitempty"`
+
+	// Components replaces the message's components if it's not nil. Set it to
+	// an empty slice to remove them.
+	Components *discord.Components `json:"components,omitempty"`
+
+	Flags *discord.MessageFlags `json:"flags,omitempty"`
+
+	// AllowedMentions controls who is pinged by the new content. It defaults
+	// to the AllowedMentions of the Client if nil.
+	AllowedMentions *discord.AllowedMentions `json:"allowed_mentions,omitempty"`
+}
+
+// EditMessageComplex edits a message. Only the fields that are set are
+// changed.
+func (c *Client) EditMessageComplex(
+	channelID, messageID discord.Snowflake,
+	data EditMessageData) (*discord.Message, error) {
+
+	if c.BeforeEdit != nil {
+		if err := c.BeforeEdit(channelID, messageID, &data); err != nil {
+			return nil, errors.Wrap(err, "Message edit rejected")
+		}
+	}
+
+	if data.Embed != nil {
+		if err := data.Embed.Validate(); err != nil {
+			return nil, errors.Wrap(err, "Embed error")
+		}
+	}
+
+	if data.Components != nil {
+		if err := data.Components.Validate(); err != nil {
+			return nil, errors.Wrap(err, "Components error")
+		}
+	}
+
+	var err error
+	if data.AllowedMentions, err = c.allowedMentions(
+		data.AllowedMentions); err != nil {
+
+		return nil, err
 	}
 
 	var msg *discord.Message
 	return msg, c.RequestJSON(
 		&msg, "PATCH",
 		EndpointChannels+channelID.String()+"/messages/"+messageID.String(),
-		httputil.WithJSONBody(c, param),
+		httputil.WithJSONBody(c, data),
 	)
 }
 
+// allowedMentions returns the allowed mentions of a message, which default to
+// the Client's, and validates them.
+func (c *Client) allowedMentions(
+	am *discord.AllowedMentions) (*discord.AllowedMentions, error) {
+
+	if am == nil {
+		am = c.AllowedMentions
+	}
+
+	if am != nil {
+		if err := am.Validate(); err != nil {
+			return nil, errors.Wrap(err, "Allowed mentions error")
+		}
+	}
+
+	return am, nil
+}
+
 // DeleteMessage deletes a message. Requires MANAGE_MESSAGES if the message is
 // not made by yourself.
 func (c *Client) DeleteMessage(channelID, messageID discord.Snowflake) error {
@@ -180,11 +284,66 @@ func (c *Client) DeleteMessage(channelID, messageID discord.Snowflake) error {
 		"/messages/"+messageID.String())
 }
 
-// DeleteMessages only works for bots. It can't delete messages older than 2
-// weeks, and will fail if tried. This endpoint requires MANAGE_MESSAGES.
+// BulkDeleteMaxAge is the age of the oldest message DeleteMessages bulk
+// deletes. Discord refuses to bulk delete messages older than 2 weeks, so it's
+// an hour short of that, in case the clock is off or a batch takes a while.
+var BulkDeleteMaxAge = 14*24*time.Hour - time.Hour
+
+// DeleteMessages deletes the messages, splitting them into batches of 100.
+// Messages older than BulkDeleteMaxAge are deleted one by one with
+// DeleteMessage, as one of them would fail the whole batch. A batch of a single
+// message is also deleted with DeleteMessage, since bulk deletes need at least
+// 2. This requires MANAGE_MESSAGES.
 func (c *Client) DeleteMessages(
 	channelID discord.Snowflake, messageIDs []discord.Snowflake) error {
 
+	var oldest = time.Now().Add(-BulkDeleteMaxAge)
+
+	var ids = make([]discord.Snowflake, 0, len(messageIDs))
+	var old []discord.Snowflake
+
+	for _, id := range messageIDs {
+		if id.Time().After(oldest) {
+			ids = append(ids, id)
+		} else {
+			old = append(old, id)
+		}
+	}
+
+	for len(ids) > 0 {
+		var n = len(ids)
+		if n > 100 {
+			n = 100
+		}
+
+		var err error
+		if n == 1 {
+			err = c.DeleteMessage(channelID, ids[0])
+		} else {
+			err = c.BulkDeleteMessages(channelID, ids[:n])
+		}
+		if err != nil {
+			return err
+		}
+
+		ids = ids[n:]
+	}
+
+	for _, id := range old {
+		if err := c.DeleteMessage(channelID, id); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// BulkDeleteMessages deletes 2-100 messages in one request, and only works for
+// bots. It can't delete messages older than 2 weeks, and will fail if tried.
+// This endpoint requires MANAGE_MESSAGES.
+func (c *Client) BulkDeleteMessages(
+	channelID discord.Snowflake, messageIDs []discord.Snowflake) error {
+
 	var param struct {
 		Messages []discord.Snowflake `json:"messages"`
 	}
@@ -0,0 +1,98 @@
+package api
+
+import "github.com/diamondburned/arikawa/discord"
+
+// MessageIterator goes through the history of a channel backwards, from the
+// latest message to the first one, fetching 100 messages at a time. The
+// requests go through the rate limiter of the Client like any other, so they
+// wait when the channel is rate limited. It's used like a bufio.Scanner:
+//
+//	it := c.MessagesIter(channelID)
+//	for it.Next() {
+//		log.Println(it.Message().Content)
+//	}
+//	if err := it.Err(); err != nil {
+//		return err
+//	}
+type MessageIterator struct {
+	client    *Client
+	channelID discord.Snowflake
+	before    discord.Snowflake
+
+	page []discord.Message
+	msg  *discord.Message
+	done bool
+	err  error
+}
+
+// MessagesIter returns an iterator over all the messages of the channel,
+// starting from the latest one.
+func (c *Client) MessagesIter(channelID discord.Snowflake) *MessageIterator {
+	return c.MessagesIterBefore(channelID, 0)
+}
+
+// MessagesIterBefore returns an iterator over the messages of the channel that
+// are before the ID, or all messages if it's 0.
+func (c *Client) MessagesIterBefore(
+	channelID, before discord.Snowflake) *MessageIterator {
+
+	return &MessageIterator{
+		client:    c,
+		channelID: channelID,
+		before:    before,
+	}
+}
+
+// Next advances to the next message, which is then returned by Message. False
+// is returned once there are no messages left or if a request failed, which
+// Err tells apart.
+func (it *MessageIterator) Next() bool {
+	if len(it.page) == 0 {
+		if it.done || it.err != nil {
+			it.msg = nil
+			return false
+		}
+
+		it.page, it.err = it.client.messagesRange(
+			it.channelID, it.before, 0, 0, 100)
+		if it.err != nil {
+			it.msg = nil
+			return false
+		}
+
+		// A short page is the last one.
+		it.done = len(it.page) < 100
+		if len(it.page) == 0 {
+			it.msg = nil
+			return false
+		}
+
+		// Messages are returned newest first.
+		it.before = it.page[len(it.page)-1].ID
+	}
+
+	it.msg = &it.page[0]
+	it.page = it.page[1:]
+
+	return true
+}
+
+// Message returns the current message, which is nil before Next is called or
+// after it returned false.
+func (it *MessageIterator) Message() *discord.Message {
+	return it.msg
+}
+
+// Before returns the ID to give to MessagesIterBefore to continue after the
+// current message later, such as after a restart.
+func (it *MessageIterator) Before() discord.Snowflake {
+	if it.msg != nil {
+		return it.msg.ID
+	}
+	return it.before
+}
+
+// Err returns the error of the request that failed, if any.
+func (it *MessageIterator) Err() error {
+	return it.err
+}
@@ -0,0 +1,66 @@
+// +build unit
+
+package api
+
+import (
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/diamondburned/arikawa/discord"
+)
+
+// messagePages serves pages of messages with decreasing IDs, the last page
+// having the given size.
+func messagePages(pages, last int) roundTripFunc {
+	var id = (pages-1)*100 + last
+
+	return func(r *http.Request) *http.Response {
+		var n = 100
+		if pages--; pages < 0 {
+			n = 0
+		} else if pages == 0 {
+			n = last
+		}
+
+		var msgs []string
+		for i := 0; i < n; i++ {
+			msgs = append(msgs, `{"id":"`+strconv.Itoa(id)+`"}`)
+			id--
+		}
+
+		return &http.Response{
+			StatusCode: 200,
+			Header:     http.Header{},
+			Body: ioutil.NopCloser(
+				strings.NewReader("[" + strings.Join(msgs, ",") + "]")),
+		}
+	}
+}
+
+func TestMessagesIter(t *testing.T) {
+	c := NewClient("")
+	c.Client.Client.Transport = messagePages(3, 5)
+
+	var n int
+	var last discord.Snowflake = 206
+
+	it := c.MessagesIter(1)
+	for it.Next() {
+		if it.Message().ID != last-1 {
+			t.Fatal("Unexpected message:", it.Message().ID, "after", last)
+		}
+		last = it.Message().ID
+		n++
+	}
+
+	if err := it.Err(); err != nil {
+		t.Fatal("Failed to iterate:", err)
+	}
+
+	if n != 205 || it.Before() != 1 {
+		t.Fatal("Unexpected number of messages:", n, it.Before())
+	}
+}
@@ -1,19 +1,29 @@
 package api
 
 import (
+	"net/url"
+
 	"github.com/diamondburned/arikawa/discord"
 	"github.com/diamondburned/arikawa/internal/httputil"
 )
 
+// reactionsURL returns the endpoint of the reactions of a message with the
+// emoji. The emoji is escaped, since unicode emojis can't be in a path as is.
+func reactionsURL(
+	channelID, messageID discord.Snowflake, emoji EmojiAPI) string {
+
+	return EndpointChannels + channelID.String() +
+		"/messages/" + messageID.String() +
+		"/reactions/" + url.PathEscape(emoji)
+}
+
 // React adds a reaction to the message. This requires READ_MESSAGE_HISTORY (and
 // additionally ADD_REACTIONS) to react.
 func (c *Client) React(
 	channelID, messageID discord.Snowflake, emoji EmojiAPI) error {
 
-	var msgURL = EndpointChannels + channelID.String() +
-		"/messages/" + messageID.String() +
-		"/reactions/" + emoji + "/@me"
-	return c.FastRequest("PUT", msgURL)
+	return c.FastRequest("PUT",
+		reactionsURL(channelID, messageID, emoji)+"/@me")
 }
 
 // Unreact removes own's reaction from the message.
@@ -21,7 +31,9 @@ func (c *Client) Unreact(chID, msgID discord.Snowflake, emoji EmojiAPI) error {
 	return c.DeleteUserReaction(chID, msgID, 0, emoji)
 }
 
-// Reactions returns all reactions. It will paginate automatically.
+// Reactions returns the users that reacted with the emoji. It paginates
+// automatically, 100 users at a time. Max can be 0, in which case all users are
+// fetched. ReactionsIter fetches them a page at a time.
 func (c *Client) Reactions(
 	channelID, messageID discord.Snowflake,
 	max uint, emoji EmojiAPI) ([]discord.User, error) {
@@ -29,30 +41,21 @@ func (c *Client) Reactions(
 	var users []discord.User
 	var after discord.Snowflake = 0
 
-	const hardLimit int = 100
-
-	for fetch := uint(hardLimit); max > 0; fetch = uint(hardLimit) {
-		if max > 0 {
-			if fetch > max {
-				fetch = max
-			}
-			max -= fetch
-		}
-
-		r, err := c.ReactionsRange(channelID, messageID, 0, after, fetch, emoji)
+	err := paginate(max, 100, func(limit uint) (int, error) {
+		r, err := c.ReactionsAfter(channelID, messageID, after, limit, emoji)
 		if err != nil {
-			return users, err
+			return 0, err
 		}
 		users = append(users, r...)
 
-		if len(r) < hardLimit {
-			break
+		if len(r) > 0 {
+			after = r[len(r)-1].ID
 		}
 
-		after = r[hardLimit-1].ID
-	}
+		return len(r), nil
+	})
 
-	return users, nil
+	return users, err
 }
 
 // Refer to ReactionsRange.
@@ -77,35 +80,17 @@ func (c *Client) ReactionsRange(
 	channelID, messageID, before, after discord.Snowflake,
 	limit uint, emoji EmojiAPI) ([]discord.User, error) {
 
-	if limit == 0 {
-		limit = 25
-	}
-
-	if limit > 100 {
-		limit = 100
-	}
-
-	var param struct {
-		Before discord.Snowflake `schema:"before,omitempty"`
-		After  discord.Snowflake `schema:"after,omitempty"`
-
-		Limit uint `schema:"limit"`
-	}
-
-	param.Before = before
-	param.After = after
-	param.Limit = limit
+	var param = newRangeParam(before, after, limit, 25, 100)
 
 	var users []discord.User
 	return users, c.RequestJSON(
-		&users, "GET", EndpointChannels+channelID.String()+
-			"/messages/"+messageID.String()+
-			"/reactions/"+emoji,
+		&users, "GET", reactionsURL(channelID, messageID, emoji),
 		httputil.WithSchema(c, param),
 	)
 }
 
-// DeleteReaction requires MANAGE_MESSAGES if not @me.
+// DeleteUserReaction removes the user's reaction. A userID of 0 removes own's
+// reaction. This requires MANAGE_MESSAGES if not @me.
 func (c *Client) DeleteUserReaction(
 	chID, msgID, userID discord.Snowflake, emoji EmojiAPI) error {
 
@@ -114,22 +99,27 @@ func (c *Client) DeleteUserReaction(
 		user = userID.String()
 	}
 
-	return c.FastRequest("DELETE", EndpointChannels+chID.String()+
-		"/messages/"+msgID.String()+
-		"/reactions/"+emoji+"/"+user)
+	return c.FastRequest("DELETE", reactionsURL(chID, msgID, emoji)+"/"+user)
 }
 
-// DeleteReactions equires MANAGE_MESSAGE.
+// DeleteAllReactionsForEmoji removes all reactions with the emoji. This
+// requires MANAGE_MESSAGES.
+func (c *Client) DeleteAllReactionsForEmoji(
+	chID, msgID discord.Snowflake, emoji EmojiAPI) error {
+
+	return c.FastRequest("DELETE", reactionsURL(chID, msgID, emoji))
+}
+
+// DeleteReactions is the same as DeleteAllReactionsForEmoji.
 func (c *Client) DeleteReactions(
 	chID, msgID discord.Snowflake, emoji EmojiAPI) error {
 
-	return c.FastRequest("DELETE", EndpointChannels+chID.String()+
-		"/messages/"+msgID.String()+
-		"/reactions/"+emoji)
+	return c.DeleteAllReactionsForEmoji(chID, msgID, emoji)
 }
 
-// DeleteAllReactions equires MANAGE_MESSAGE.
+// DeleteAllReactions removes all reactions from the message. This requires
+// MANAGE_MESSAGES.
 func (c *Client) DeleteAllReactions(chID, msgID discord.Snowflake) error {
 	return c.FastRequest("DELETE", EndpointChannels+chID.String()+
-		"/messages/"+msgID.String()+"/reactions/")
+		"/messages/"+msgID.String()+"/reactions")
 }
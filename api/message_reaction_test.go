@@ -0,0 +1,31 @@
+// +build unit
+
+package api
+
+import (
+	"testing"
+
+	"github.com/diamondburned/arikawa/discord"
+)
+
+func TestReactionsURL(t *testing.T) {
+	var tests = []struct {
+		emoji EmojiAPI
+		url   string
+	}{
+		{"⭐", "1/messages/2/reactions/%E2%AD%90"},
+		{FormatEmojiAPI(3, "star"), "1/messages/2/reactions/star:3"},
+	}
+
+	for _, test := range tests {
+		u := reactionsURL(1, 2, test.emoji)
+		if u != EndpointChannels+test.url {
+			t.Errorf("Unexpected URL %q for %q", u, test.emoji)
+		}
+	}
+
+	var emoji = discord.Emoji{ID: 3, Name: "star"}
+	if FormatEmojiAPI(emoji.ID, emoji.Name) != emoji.APIString() {
+		t.Error("FormatEmojiAPI doesn't match Emoji.APIString")
+	}
+}
@@ -15,6 +15,9 @@ const AttachmentSpoilerPrefix = "SPOILER_"
 
 var quoteEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`)
 
+// SendMessageFile is a file to upload. The Reader is streamed into the request
+// as it's sent, so it's never read into memory whole. To mark the file as a
+// spoiler, prefix its Name with AttachmentSpoilerPrefix.
 type SendMessageFile struct {
 	Name   string
 	Reader io.Reader
@@ -25,8 +28,15 @@ type SendMessageData struct {
 	Nonce   string `json:"nonce,omitempty"`
 	TTS     bool   `json:"tts"`
 
-	Embed *discord.Embed `json:"embed,omitempty"`
+	Embed      *discord.Embed     `json:"embed,omitempty"`
+	Components discord.Components `json:"components,omitempty"`
 
+	// AllowedMentions controls who is pinged. It defaults to the
+	// AllowedMentions of the Client if nil.
+	AllowedMentions *discord.AllowedMentions `json:"allowed_mentions,omitempty"`
+
+	// Files are uploaded in a multipart body, with the rest of the data as its
+	// payload_json.
 	Files []SendMessageFile `json:"-"`
 }
 
@@ -0,0 +1,182 @@
+// +build unit
+
+package api
+
+import (
+	"io/ioutil"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/diamondburned/arikawa/discord"
+	"github.com/diamondburned/arikawa/internal/json"
+	"github.com/pkg/errors"
+)
+
+func TestDeleteMessages(t *testing.T) {
+	var requests []string
+
+	c := NewClient("")
+	c.Client.Client.Transport = roundTripFunc(
+		func(r *http.Request) *http.Response {
+			var req = r.Method + " " + strings.TrimPrefix(r.URL.Path, "/api/v6")
+
+			if r.Body != nil {
+				var body struct {
+					Messages []discord.Snowflake `json:"messages"`
+				}
+				b, _ := ioutil.ReadAll(r.Body)
+				if err := (json.Default{}).Unmarshal(b, &body); err != nil {
+					t.Error("Failed to decode body:", err)
+				}
+				req += " " + strconv.Itoa(len(body.Messages))
+			}
+
+			requests = append(requests, req)
+
+			return &http.Response{
+				StatusCode: 204,
+				Header:     http.Header{},
+				Body:       ioutil.NopCloser(strings.NewReader("")),
+			}
+		},
+	)
+
+	var now = time.Now()
+	// Messages older than 2 weeks, or close to it, can't be bulk deleted.
+	var ids = []discord.Snowflake{
+		discord.NewSnowflake(now.Add(-15 * 24 * time.Hour)),
+		discord.NewSnowflake(now.Add(-14*24*time.Hour + time.Minute)),
+	}
+	for i := 0; i < 201; i++ {
+		ids = append(ids, discord.NewSnowflake(now)+discord.Snowflake(i))
+	}
+
+	if err := c.DeleteMessages(1, ids); err != nil {
+		t.Fatal("Failed to delete messages:", err)
+	}
+
+	var expect = []string{
+		"POST /channels/1/messages/bulk-delete 100",
+		"POST /channels/1/messages/bulk-delete 100",
+		"DELETE /channels/1/messages/" + ids[202].String(),
+		"DELETE /channels/1/messages/" + ids[0].String(),
+		"DELETE /channels/1/messages/" + ids[1].String(),
+	}
+	if !reflect.DeepEqual(requests, expect) {
+		t.Fatalf("Unexpected requests %q, expected %q", requests, expect)
+	}
+}
+
+func TestBeforeSend(t *testing.T) {
+	var sent []string
+
+	c := NewClient("")
+	c.Client.Client.Transport = roundTripFunc(
+		func(r *http.Request) *http.Response {
+			var body SendMessageData
+			b, _ := ioutil.ReadAll(r.Body)
+			if err := (json.Default{}).Unmarshal(b, &body); err != nil {
+				t.Error("Failed to decode body:", err)
+			}
+			sent = append(sent, body.Content)
+
+			return &http.Response{
+				StatusCode: 200,
+				Header:     http.Header{},
+				Body:       ioutil.NopCloser(strings.NewReader(`{"id":"1"}`)),
+			}
+		},
+	)
+
+	var errSecret = errors.New("Message contains a secret")
+
+	c.BeforeSend = func(channelID discord.Snowflake, d *SendMessageData) error {
+		if strings.Contains(d.Content, "hunter2") {
+			return errSecret
+		}
+		d.Content = strings.ReplaceAll(d.Content, "darn", "****")
+		return nil
+	}
+
+	if _, err := c.SendMessage(1, "my password is hunter2", nil); err == nil ||
+		errors.Cause(err) != errSecret {
+
+		t.Fatal("Unexpected error sending a secret:", err)
+	}
+
+	if _, err := c.SendMessage(1, "darn it", nil); err != nil {
+		t.Fatal("Failed to send message:", err)
+	}
+
+	if !reflect.DeepEqual(sent, []string{"**** it"}) {
+		t.Fatalf("Unexpected messages sent: %q", sent)
+	}
+}
+
+func TestSendHelpers(t *testing.T) {
+	var sent []SendMessageData
+	var files []string
+
+	c := NewClient("")
+	c.Client.Client.Transport = roundTripFunc(
+		func(r *http.Request) *http.Response {
+			var payload = []byte{}
+
+			if err := r.ParseMultipartForm(1 << 20); err == nil {
+				payload = []byte(r.FormValue("payload_json"))
+				for _, fhs := range r.MultipartForm.File {
+					files = append(files, fhs[0].Filename)
+				}
+			} else {
+				payload, _ = ioutil.ReadAll(r.Body)
+			}
+
+			var data SendMessageData
+			if err := (json.Default{}).Unmarshal(payload, &data); err != nil {
+				t.Error("Failed to decode body:", err)
+			}
+			sent = append(sent, data)
+
+			return &http.Response{
+				StatusCode: 200,
+				Header:     http.Header{},
+				Body:       ioutil.NopCloser(strings.NewReader(`{"id":"1"}`)),
+			}
+		},
+	)
+
+	if _, err := c.SendText(1, "hi"); err != nil {
+		t.Fatal("Failed to send text:", err)
+	}
+	if _, err := c.Sendf(1, "%d apples", 3); err != nil {
+		t.Fatal("Failed to send formatted text:", err)
+	}
+	if _, err := c.SendEmbed(1, discord.Embed{Title: "Title"}); err != nil {
+		t.Fatal("Failed to send embed:", err)
+	}
+	_, err := c.SendFile(1, "file", "a.txt", strings.NewReader("hello"))
+	if err != nil {
+		t.Fatal("Failed to send file:", err)
+	}
+
+	if len(sent) != 4 {
+		t.Fatal("Unexpected number of messages:", len(sent))
+	}
+
+	if sent[0].Content != "hi" || sent[1].Content != "3 apples" {
+		t.Fatalf("Unexpected contents %q and %q",
+			sent[0].Content, sent[1].Content)
+	}
+
+	if sent[2].Embed == nil || sent[2].Embed.Title != "Title" {
+		t.Fatal("Unexpected embed:", sent[2].Embed)
+	}
+
+	if sent[3].Content != "file" || len(files) != 1 || files[0] != "a.txt" {
+		t.Fatal("Unexpected file message:", sent[3].Content, files)
+	}
+}
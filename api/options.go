@@ -0,0 +1,72 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/diamondburned/arikawa/discord"
+	"github.com/diamondburned/arikawa/internal/httputil"
+)
+
+// ClientOption changes the copy of a Client made by With.
+type ClientOption func(*Client)
+
+func addOption(c *Client, opt httputil.RequestOption) {
+	c.Client.Options = append(c.Client.Options, opt)
+}
+
+// WithHeaders sets the headers on every request, replacing the existing values
+// of the same keys.
+func WithHeaders(headers http.Header) ClientOption {
+	return func(c *Client) {
+		addOption(c, func(r *http.Request) error {
+			for key, values := range headers {
+				r.Header[key] = values
+			}
+			return nil
+		})
+	}
+}
+
+// WithUserAgent replaces the UserAgent of every request.
+func WithUserAgent(userAgent string) ClientOption {
+	return func(c *Client) {
+		addOption(c, func(r *http.Request) error {
+			r.Header.Set("User-Agent", userAgent)
+			return nil
+		})
+	}
+}
+
+// WithReason sets the audit log reason of every request.
+func WithReason(reason string) ClientOption {
+	return func(c *Client) {
+		addOption(c, httputil.WithReason(reason))
+	}
+}
+
+// WithAllowedMentions sets the default allowed mentions of the messages sent
+// and edited.
+func WithAllowedMentions(am discord.AllowedMentions) ClientOption {
+	return func(c *Client) {
+		c.AllowedMentions = &am
+	}
+}
+
+// WithContext makes every request use ctx, so they're all cancelled with it.
+func WithContext(ctx context.Context) ClientOption {
+	return func(c *Client) {
+		c.Client.Context = ctx
+	}
+}
+
+// WithRetries sets the maximum number of attempts of a request, and the
+// backoff between them, which is doubled after each retry up to maxBackoff.
+func WithRetries(retries uint, backoff, maxBackoff time.Duration) ClientOption {
+	return func(c *Client) {
+		c.Client.Retries = retries
+		c.Client.Backoff = backoff
+		c.Client.MaxBackoff = maxBackoff
+	}
+}
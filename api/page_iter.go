@@ -0,0 +1,211 @@
+package api
+
+import "github.com/diamondburned/arikawa/discord"
+
+// pager is the state shared by the page iterators, which paginate forwards
+// from after, a page of limit items at a time.
+type pager struct {
+	limit uint
+	after discord.Snowflake
+	done  bool
+	err   error
+}
+
+// paged is called after fetching a page of n items into the iterator, and
+// returns true if there's a page. The iteration ends if the request failed or
+// if the page is short, as it's then the last one.
+func (p *pager) paged(n int) bool {
+	p.done = p.err != nil || n < int(p.limit)
+	return p.err == nil && n > 0
+}
+
+// GuildIterator goes through the guilds of the current user, a page of 100 at
+// a time. Unlike Guilds, the caller can stop whenever it's done:
+//
+//	it := c.GuildsIter()
+//	for it.Next() {
+//		for _, g := range it.Page() {
+//			log.Println(g.Name)
+//		}
+//	}
+//	if err := it.Err(); err != nil {
+//		return err
+//	}
+type GuildIterator struct {
+	pager
+	client *Client
+	page   []discord.Guild
+}
+
+// GuildsIter returns an iterator over the guilds of the current user.
+func (c *Client) GuildsIter() *GuildIterator {
+	return &GuildIterator{
+		pager:  pager{limit: 100},
+		client: c,
+	}
+}
+
+// Next fetches the next page, which is then returned by Page. False is
+// returned once there are no guilds left or if the request failed, which Err
+// tells apart.
+func (it *GuildIterator) Next() bool {
+	if it.done {
+		it.page = nil
+		return false
+	}
+
+	it.page, it.err = it.client.GuildsAfter(it.after, it.limit)
+	if len(it.page) > 0 {
+		it.after = it.page[len(it.page)-1].ID
+	}
+
+	return it.paged(len(it.page))
+}
+
+// Page returns the current page.
+func (it *GuildIterator) Page() []discord.Guild {
+	return it.page
+}
+
+// Err returns the error of the request that failed, if any.
+func (it *GuildIterator) Err() error {
+	return it.err
+}
+
+// MemberIterator goes through the members of a guild, a page of 1000 at a
+// time, like GuildIterator.
+type MemberIterator struct {
+	pager
+	client  *Client
+	guildID discord.Snowflake
+	page    []discord.Member
+}
+
+// MembersIter returns an iterator over the members of the guild.
+func (c *Client) MembersIter(guildID discord.Snowflake) *MemberIterator {
+	return &MemberIterator{
+		pager:   pager{limit: 1000},
+		client:  c,
+		guildID: guildID,
+	}
+}
+
+// Next fetches the next page, like GuildIterator.Next.
+func (it *MemberIterator) Next() bool {
+	if it.done {
+		it.page = nil
+		return false
+	}
+
+	it.page, it.err = it.client.MembersAfter(it.guildID, it.after, it.limit)
+	if len(it.page) > 0 {
+		it.after = it.page[len(it.page)-1].User.ID
+	}
+
+	return it.paged(len(it.page))
+}
+
+// Page returns the current page.
+func (it *MemberIterator) Page() []discord.Member {
+	return it.page
+}
+
+// Err returns the error of the request that failed, if any.
+func (it *MemberIterator) Err() error {
+	return it.err
+}
+
+// BanIterator goes through the bans of a guild, a page of 1000 at a time,
+// like GuildIterator. It requires BAN_MEMBERS.
+type BanIterator struct {
+	pager
+	client  *Client
+	guildID discord.Snowflake
+	page    []discord.Ban
+}
+
+// BansIter returns an iterator over the bans of the guild.
+func (c *Client) BansIter(guildID discord.Snowflake) *BanIterator {
+	return &BanIterator{
+		pager:   pager{limit: 1000},
+		client:  c,
+		guildID: guildID,
+	}
+}
+
+// Next fetches the next page, like GuildIterator.Next.
+func (it *BanIterator) Next() bool {
+	if it.done {
+		it.page = nil
+		return false
+	}
+
+	it.page, it.err = it.client.BansAfter(it.guildID, it.after, it.limit)
+	if len(it.page) > 0 {
+		it.after = it.page[len(it.page)-1].User.ID
+	}
+
+	return it.paged(len(it.page))
+}
+
+// Page returns the current page.
+func (it *BanIterator) Page() []discord.Ban {
+	return it.page
+}
+
+// Err returns the error of the request that failed, if any.
+func (it *BanIterator) Err() error {
+	return it.err
+}
+
+// ReactionIterator goes through the users that reacted to a message with an
+// emoji, a page of 100 at a time, like GuildIterator.
+type ReactionIterator struct {
+	pager
+	client    *Client
+	channelID discord.Snowflake
+	messageID discord.Snowflake
+	emoji     EmojiAPI
+	page      []discord.User
+}
+
+// ReactionsIter returns an iterator over the users that reacted to the
+// message with the emoji.
+func (c *Client) ReactionsIter(
+	channelID, messageID discord.Snowflake,
+	emoji EmojiAPI) *ReactionIterator {
+
+	return &ReactionIterator{
+		pager:     pager{limit: 100},
+		client:    c,
+		channelID: channelID,
+		messageID: messageID,
+		emoji:     emoji,
+	}
+}
+
+// Next fetches the next page, like GuildIterator.Next.
+func (it *ReactionIterator) Next() bool {
+	if it.done {
+		it.page = nil
+		return false
+	}
+
+	it.page, it.err = it.client.ReactionsAfter(
+		it.channelID, it.messageID, it.after, it.limit, it.emoji)
+	if len(it.page) > 0 {
+		it.after = it.page[len(it.page)-1].ID
+	}
+
+	return it.paged(len(it.page))
+}
+
+// Page returns the current page.
+func (it *ReactionIterator) Page() []discord.User {
+	return it.page
+}
+
+// Err returns the error of the request that failed, if any.
+func (it *ReactionIterator) Err() error {
+	return it.err
+}
@@ -0,0 +1,101 @@
+// +build unit
+
+package api
+
+import (
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// idPages serves pages of objects made by format from increasing IDs, the
+// last page having the given size.
+func idPages(size, pages, last int, format func(id int) string) (
+	roundTripFunc, *int) {
+
+	var requests int
+	var id int
+
+	return func(r *http.Request) *http.Response {
+		requests++
+
+		var n = size
+		if pages--; pages < 0 {
+			n = 0
+		} else if pages == 0 {
+			n = last
+		}
+
+		var items []string
+		for i := 0; i < n; i++ {
+			id++
+			items = append(items, format(id))
+		}
+
+		return &http.Response{
+			StatusCode: 200,
+			Header:     http.Header{},
+			Body: ioutil.NopCloser(
+				strings.NewReader("[" + strings.Join(items, ",") + "]")),
+		}
+	}, &requests
+}
+
+func TestGuildsIter(t *testing.T) {
+	rt, requests := idPages(100, 3, 100, func(id int) string {
+		return `{"id":"` + strconv.Itoa(id) + `"}`
+	})
+
+	c := NewClient("")
+	c.Client.Client.Transport = rt
+
+	// Stopping early doesn't fetch the other pages.
+	it := c.GuildsIter()
+	if !it.Next() || len(it.Page()) != 100 || it.Page()[99].ID != 100 {
+		t.Fatal("Unexpected first page:", len(it.Page()), it.Err())
+	}
+
+	if *requests != 1 {
+		t.Fatal("Unexpected number of requests:", *requests)
+	}
+
+	// A full last page needs one more request to know it's the last.
+	var n = 1
+	for it.Next() {
+		n++
+	}
+
+	if err := it.Err(); err != nil {
+		t.Fatal("Failed to iterate:", err)
+	}
+
+	if n != 3 || *requests != 4 {
+		t.Fatal("Unexpected number of pages:", n, *requests)
+	}
+}
+
+func TestMembersIter(t *testing.T) {
+	rt, requests := idPages(1000, 2, 5, func(id int) string {
+		return `{"user":{"id":"` + strconv.Itoa(id) + `"}}`
+	})
+
+	c := NewClient("")
+	c.Client.Client.Transport = rt
+
+	var members int
+
+	it := c.MembersIter(1)
+	for it.Next() {
+		members += len(it.Page())
+	}
+
+	if err := it.Err(); err != nil {
+		t.Fatal("Failed to iterate:", err)
+	}
+
+	if members != 1005 || *requests != 2 {
+		t.Fatal("Unexpected members:", members, *requests)
+	}
+}
@@ -0,0 +1,70 @@
+package api
+
+import "github.com/diamondburned/arikawa/discord"
+
+// rangeParam is the query string shared by all paginated list endpoints. Every
+// XRange method builds one of these, while XBefore and XAfter call XRange with
+// the other bound set to 0.
+type rangeParam struct {
+	Before discord.Snowflake `schema:"before,omitempty"`
+	After  discord.Snowflake `schema:"after,omitempty"`
+
+	Limit uint `schema:"limit"`
+}
+
+// newRangeParam creates a rangeParam. A limit of 0 is replaced with def, and
+// limits above max are capped to max.
+func newRangeParam(
+	before, after discord.Snowflake, limit, def, max uint) rangeParam {
+
+	return rangeParam{
+		Before: before,
+		After:  after,
+		Limit:  clampLimit(limit, def, max),
+	}
+}
+
+func clampLimit(limit, def, max uint) uint {
+	switch {
+	case limit == 0:
+		return def
+	case limit > max:
+		return max
+	default:
+		return limit
+	}
+}
+
+// paginate is the loop behind all auto-paginating methods. It calls fetch with
+// the number of items to fetch, which should be at most hardLimit, until either
+// max items are fetched or fetch returns less than what was asked. If max is 0,
+// paginate fetches until there's nothing left.
+//
+// fetch is expected to append the results and advance its own cursor.
+func paginate(max, hardLimit uint, fetch func(limit uint) (int, error)) error {
+	var unlimited = max == 0
+
+	for {
+		var limit = hardLimit
+
+		if !unlimited {
+			if max == 0 {
+				return nil
+			}
+			if limit > max {
+				limit = max
+			}
+			max -= limit
+		}
+
+		n, err := fetch(limit)
+		if err != nil {
+			return err
+		}
+
+		// There aren't any left to fetch.
+		if n < int(limit) {
+			return nil
+		}
+	}
+}
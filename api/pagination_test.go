@@ -0,0 +1,49 @@
+// +build unit
+
+package api
+
+import "testing"
+
+func TestPaginate(t *testing.T) {
+	var tests = []struct {
+		max    uint
+		total  int
+		limits []uint
+	}{
+		// Stops at max.
+		{250, 1000, []uint{100, 100, 50}},
+		// Stops when there's nothing left.
+		{0, 150, []uint{100, 100}},
+		{500, 100, []uint{100, 100}},
+	}
+
+	for _, test := range tests {
+		var left = test.total
+		var limits []uint
+
+		err := paginate(test.max, 100, func(limit uint) (int, error) {
+			limits = append(limits, limit)
+
+			n := int(limit)
+			if n > left {
+				n = left
+			}
+			left -= n
+
+			return n, nil
+		})
+
+		if err != nil {
+			t.Fatal("Unexpected error:", err)
+		}
+
+		if len(limits) != len(test.limits) {
+			t.Fatalf("Unexpected limits %v, expected %v", limits, test.limits)
+		}
+		for i := range limits {
+			if limits[i] != test.limits[i] {
+				t.Fatalf("Unexpected limits %v, expected %v", limits, test.limits)
+			}
+		}
+	}
+}
@@ -0,0 +1,147 @@
+package rate
+
+import (
+	"context"
+	"sync"
+
+	"github.com/pkg/errors"
+	"golang.org/x/time/rate"
+)
+
+// Budget caps the requests of all Limiters that share it, for processes that
+// run many bots and want to stay well below Discord's limits. Requests are
+// let through one caller at a time in turns, so a busy caller can't starve the
+// others:
+//
+//	budget := rate.NewBudget(40, 10)
+//	for name, c := range clients {
+//		c.Limiter.Budget = budget
+//		c.Limiter.Caller = name
+//	}
+type Budget struct {
+	limiter *rate.Limiter
+
+	mutex   sync.Mutex
+	queues  map[string][]chan error
+	callers []string // callers with waiting requests, in turn order
+	running bool
+}
+
+// NewBudget creates a Budget that allows perSecond requests per second, with
+// bursts of up to burst requests. A burst below 1 is raised to 1, as no
+// request could ever be let through otherwise.
+func NewBudget(perSecond float64, burst int) *Budget {
+	if burst < 1 {
+		burst = 1
+	}
+
+	return &Budget{
+		limiter: rate.NewLimiter(rate.Limit(perSecond), burst),
+		queues:  map[string][]chan error{},
+	}
+}
+
+// Wait blocks until it's the caller's turn and the budget allows another
+// request. An error is returned if the budget can't ever allow it.
+func (b *Budget) Wait(ctx context.Context, caller string) error {
+	ch := make(chan error, 1)
+
+	b.mutex.Lock()
+	if len(b.queues[caller]) == 0 {
+		b.callers = append(b.callers, caller)
+	}
+	b.queues[caller] = append(b.queues[caller], ch)
+
+	if !b.running {
+		b.running = true
+		go b.dispatch()
+	}
+	b.mutex.Unlock()
+
+	select {
+	case err := <-ch:
+		return err
+	case <-ctx.Done():
+		b.cancel(caller, ch)
+		return ctx.Err()
+	}
+}
+
+// cancel removes the request from the caller's queue, if it's still there.
+func (b *Budget) cancel(caller string, ch chan error) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	queue := b.queues[caller]
+
+	for i, q := range queue {
+		if q == ch {
+			b.queues[caller] = append(queue[:i], queue[i+1:]...)
+			break
+		}
+	}
+
+	if len(b.queues[caller]) == 0 {
+		b.removeCaller(caller)
+	}
+}
+
+// dispatch lets the waiting requests through as the budget allows, until
+// there are none left.
+func (b *Budget) dispatch() {
+	for {
+		b.mutex.Lock()
+		if len(b.callers) == 0 {
+			b.running = false
+			b.mutex.Unlock()
+			return
+		}
+		b.mutex.Unlock()
+
+		// The Budget's limiter is never cancelled, so this only fails if the
+		// limiter can't ever allow the request, which then fails instead of
+		// going through unthrottled.
+		err := b.limiter.Wait(context.Background())
+		if err != nil {
+			err = errors.Wrap(err, "Budget can't allow the request")
+		}
+
+		b.mutex.Lock()
+		// The requests could have been cancelled while waiting.
+		if len(b.callers) > 0 {
+			b.next() <- err
+		}
+		b.mutex.Unlock()
+	}
+}
+
+// next pops the request of the caller whose turn it is, then moves the caller
+// to the back of the line. It must be called with the mutex locked.
+func (b *Budget) next() chan error {
+	caller := b.callers[0]
+	queue := b.queues[caller]
+
+	b.queues[caller] = queue[1:]
+	b.callers = b.callers[1:]
+
+	if len(queue) > 1 {
+		b.callers = append(b.callers, caller)
+	} else {
+		delete(b.queues, caller)
+	}
+
+	return queue[0]
+}
+
+// removeCaller takes the caller out of the line. It must be called with the
+// mutex locked.
+func (b *Budget) removeCaller(caller string) {
+	delete(b.queues, caller)
+
+	for i, c := range b.callers {
+		if c == caller {
+			b.callers = append(b.callers[:i], b.callers[i+1:]...)
+			return
+		}
+	}
+}
@@ -0,0 +1,99 @@
+// +build unit
+
+package rate
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+func TestBudgetTurns(t *testing.T) {
+	var chs = make([]chan error, 4)
+	for i := range chs {
+		chs[i] = make(chan error)
+	}
+
+	b := NewBudget(1, 1)
+	b.queues["a"] = []chan error{chs[0], chs[1], chs[2]}
+	b.queues["b"] = []chan error{chs[3]}
+	b.callers = []string{"a", "b"}
+
+	// b doesn't have to wait for all of a's requests.
+	for i, expect := range []int{0, 3, 1, 2} {
+		if ch := b.next(); ch != chs[expect] {
+			t.Fatalf("Request %d isn't request %d", i, expect)
+		}
+	}
+
+	if len(b.callers) > 0 || len(b.queues) > 0 {
+		t.Fatal("Callers are left after all requests:", b.callers)
+	}
+}
+
+func TestBudgetWait(t *testing.T) {
+	b := NewBudget(100, 1)
+
+	var start = time.Now()
+
+	for i := 0; i < 3; i++ {
+		if err := b.Wait(context.Background(), "a"); err != nil {
+			t.Fatal("Failed to wait:", err)
+		}
+	}
+
+	// The first request is let through immediately.
+	if d := time.Since(start); d < 20*time.Millisecond {
+		t.Fatal("Requests weren't throttled, took", d)
+	}
+}
+
+func TestBudgetCancel(t *testing.T) {
+	b := NewBudget(0.001, 1)
+	b.Wait(context.Background(), "a")
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+
+	if err := b.Wait(ctx, "a"); err != context.DeadlineExceeded {
+		t.Fatal("Unexpected error:", err)
+	}
+
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if len(b.callers) > 0 || len(b.queues) > 0 {
+		t.Fatal("Cancelled request is still queued")
+	}
+}
+
+func TestBudgetNoBurst(t *testing.T) {
+	b := NewBudget(100, 0)
+
+	var start = time.Now()
+
+	for i := 0; i < 3; i++ {
+		if err := b.Wait(context.Background(), "a"); err != nil {
+			t.Fatal("Failed to wait:", err)
+		}
+	}
+
+	if d := time.Since(start); d < 20*time.Millisecond {
+		t.Fatal("Requests weren't throttled, took", d)
+	}
+}
+
+func TestBudgetWaitError(t *testing.T) {
+	b := NewBudget(100, 1)
+	// A limiter without a burst can't let any request through.
+	b.limiter = rate.NewLimiter(100, 0)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	if err := b.Wait(ctx, "a"); err == nil || err == ctx.Err() {
+		t.Fatal("Unexpected error:", err)
+	}
+}
@@ -5,8 +5,7 @@ import (
 	"strings"
 )
 
-// TODO: webhook
-var MajorRootPaths = []string{"channels", "guilds"}
+var MajorRootPaths = []string{"channels", "guilds", "webhooks"}
 
 func ParseBucketKey(path string) string {
 	path = strings.SplitN(path, "?", 2)[0]
@@ -39,3 +38,20 @@ func ParseBucketKey(path string) string {
 	path = strings.Join(parts, "/")
 	return "/" + path
 }
+
+// majorParameter returns the ID after the major root path of the bucket key,
+// or an empty string if the path doesn't start with a major root path.
+func majorParameter(key string) string {
+	parts := strings.SplitN(key, "/", 4)
+	if len(parts) < 3 {
+		return ""
+	}
+
+	for _, part := range MajorRootPaths {
+		if part == parts[1] {
+			return parts[2]
+		}
+	}
+
+	return ""
+}
@@ -21,3 +21,19 @@ func TestBucketKey(t *testing.T) {
 		}
 	}
 }
+
+func TestMajorParameter(t *testing.T) {
+	var tests = [][2]string{
+		{"/guilds/123123/messages", "123123"},
+		{"/channels/123131231", "123131231"},
+		{"/webhooks/123/token", "123"},
+		{"/user/", ""},
+		{"/gateway", ""},
+	}
+
+	for _, conds := range tests {
+		if major := majorParameter(conds[0]); major != conds[1] {
+			t.Fatalf("Expected/got\n%s\n%s", conds[1], major)
+		}
+	}
+}
@@ -21,13 +21,28 @@ const ExtraDelay = 250 * time.Millisecond
 // This makes me suicidal.
 // https://github.com/bwmarrin/discordgo/blob/master/ratelimit.go
 
+// Limiter keeps track of the rate limits of each route. Requests to the same
+// route are done one at a time, and requests are held back until their bucket
+// has a request remaining, so that Discord never has to return a 429.
+//
+// Routes start with a bucket of their own. Once Discord returns the
+// X-RateLimit-Bucket of a route, the route moves to the bucket shared by all
+// routes with the same bucket and major parameter, which is the channel, guild
+// or webhook ID in the path.
 type Limiter struct {
 	// Only 1 per bucket
 	CustomLimits []*CustomRateLimit
 
-	global     *int64 // atomic guarded, unixnano
-	buckets    sync.Map
-	globalRate time.Duration
+	// Budget, if not nil, is waited on before every request, so that the
+	// requests of all Limiters sharing it are capped. Caller is the name of
+	// the Limiter in the Budget's turns.
+	Budget *Budget
+	Caller string
+
+	global  *int64   // atomic guarded, unixnano
+	waiting int32    // atomic guarded
+	routes  sync.Map // bucket key -> *route
+	buckets sync.Map // bucket hash and major parameter -> *bucket
 }
 
 type CustomRateLimit struct {
@@ -38,12 +53,21 @@ type CustomRateLimit struct {
 	Reset time.Duration
 }
 
-type bucket struct {
+// route is held from Acquire to Release.
+type route struct {
 	lock   csync.Mutex
+	bucket *bucket // guarded by lock
+}
+
+type bucket struct {
+	mutex  sync.Mutex
 	custom *CustomRateLimit
 
+	// hash is empty if the bucket isn't shared.
+	hash string
+
 	remaining uint64
-	limit     uint
+	limit     uint64
 
 	reset     time.Time
 	lastReset time.Time // only for custom
@@ -52,89 +76,173 @@ type bucket struct {
 func NewLimiter() *Limiter {
 	return &Limiter{
 		global:       new(int64),
-		buckets:      sync.Map{},
 		CustomLimits: []*CustomRateLimit{},
 	}
 }
 
-func (l *Limiter) getBucket(path string, store bool) *bucket {
+func (l *Limiter) getRoute(path string, store bool) *route {
 	path = ParseBucketKey(path)
 
-	bc, ok := l.buckets.Load(path)
-	if !ok && !store {
+	r, ok := l.routes.Load(path)
+	if ok {
+		return r.(*route)
+	}
+
+	if !store {
 		return nil
 	}
 
-	if !ok {
-		bc := &bucket{
-			remaining: 1,
-		}
+	// The limit isn't known until the first response, so only 1 request is
+	// allowed until then.
+	b := &bucket{
+		remaining: 1,
+		limit:     1,
+	}
 
-		for _, limit := range l.CustomLimits {
-			if strings.Contains(path, limit.Contains) {
-				bc.custom = limit
-				break
-			}
+	for _, limit := range l.CustomLimits {
+		if strings.Contains(path, limit.Contains) {
+			b.custom = limit
+			break
 		}
+	}
+
+	r, _ = l.routes.LoadOrStore(path, &route{bucket: b})
+	return r.(*route)
+}
+
+// sharedBucket moves the route to the bucket with the given hash. It must be
+// called with the route locked.
+func (l *Limiter) sharedBucket(r *route, path, hash string) *bucket {
+	hash += ":" + majorParameter(path)
 
-		l.buckets.Store(path, bc)
-		return bc
+	if r.bucket.hash == hash {
+		return r.bucket
 	}
 
-	return bc.(*bucket)
+	b, _ := l.buckets.LoadOrStore(hash, &bucket{
+		hash:      hash,
+		remaining: r.bucket.remaining,
+		limit:     r.bucket.limit,
+		reset:     r.bucket.reset,
+	})
+
+	r.bucket = b.(*bucket)
+	return r.bucket
 }
 
 func (l *Limiter) Acquire(ctx context.Context, path string) error {
-	b := l.getBucket(path, true)
+	r := l.getRoute(path, true)
 
 	// Acquire lock with a timeout
-	if err := b.lock.CLock(ctx); err != nil {
+	if err := r.lock.CLock(ctx); err != nil {
 		return err
 	}
 
-	// Time to sleep
-	var sleep time.Duration
-
-	if b.remaining == 0 && b.reset.After(time.Now()) {
-		// out of turns, gotta wait
-		sleep = b.reset.Sub(time.Now())
-	} else {
-		// maybe global rate limit has it
-		now := time.Now()
-		until := time.Unix(0, atomic.LoadInt64(l.global))
-
-		if until.After(now) {
-			sleep = until.Sub(now)
+	if l.Budget != nil {
+		if err := l.Budget.Wait(ctx, l.Caller); err != nil {
+			r.lock.Unlock()
+			return err
 		}
 	}
 
-	if sleep > 0 {
+	sleep := l.reserve(r.bucket)
+	if sleep <= 0 {
+		return nil
+	}
+
+	atomic.AddInt32(&l.waiting, 1)
+	defer atomic.AddInt32(&l.waiting, -1)
+
+	for sleep > 0 {
 		select {
 		case <-ctx.Done():
+			r.lock.Unlock()
 			return ctx.Err()
 		case <-time.After(sleep):
 		}
+
+		sleep = l.reserve(r.bucket)
+	}
+
+	return nil
+}
+
+// Stats is a snapshot of the rate limit pressure on a Limiter.
+type Stats struct {
+	// Waiting is the number of requests waiting for a rate limit to reset.
+	// Requests waiting for another request to the same route aren't counted.
+	Waiting int
+	// GlobalReset is when the global rate limit resets, or zero if the
+	// Limiter isn't globally rate limited.
+	GlobalReset time.Time
+}
+
+// Stats returns the rate limit pressure on the Limiter.
+func (l *Limiter) Stats() Stats {
+	var stats = Stats{
+		Waiting: int(atomic.LoadInt32(&l.waiting)),
+	}
+
+	until := time.Unix(0, atomic.LoadInt64(l.global))
+	if until.After(time.Now()) {
+		stats.GlobalReset = until
+	}
+
+	return stats
+}
+
+// reserve takes a request from the bucket. If there's none left or if there's
+// a global rate limit, nothing is taken, and the time to wait is returned.
+func (l *Limiter) reserve(b *bucket) time.Duration {
+	now := time.Now()
+
+	// maybe global rate limit has it
+	if until := time.Unix(0, atomic.LoadInt64(l.global)); until.After(now) {
+		return until.Sub(now)
+	}
+
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if b.remaining == 0 {
+		if b.reset.After(now) {
+			// out of turns, gotta wait
+			return b.reset.Sub(now)
+		}
+
+		// The bucket was reset since the last response.
+		b.remaining = b.limit
 	}
 
 	if b.remaining > 0 {
 		b.remaining--
 	}
 
-	return nil
+	return 0
 }
 
 // Release releases the URL from the locks. This doesn't need a context for
-// timing out, it doesn't block that much.
+// timing out, it doesn't block that much. The headers are nil if the request
+// failed without a response.
 func (l *Limiter) Release(path string, headers http.Header) error {
-	b := l.getBucket(path, false)
-	if b == nil {
+	r := l.getRoute(path, false)
+	if r == nil {
+		return nil
+	}
+
+	defer r.lock.Unlock()
+
+	if headers == nil {
 		return nil
 	}
 
-	defer b.lock.Unlock()
+	b := r.bucket
 
 	// Check custom limiter
 	if b.custom != nil {
+		b.mutex.Lock()
+		defer b.mutex.Unlock()
+
 		now := time.Now()
 
 		if now.Sub(b.lastReset) >= b.custom.Reset {
@@ -149,12 +257,23 @@ func (l *Limiter) Release(path string, headers http.Header) error {
 		// boolean
 		global = headers.Get("X-RateLimit-Global")
 
+		hash = headers.Get("X-RateLimit-Bucket")
+
 		// seconds
+		limit      = headers.Get("X-RateLimit-Limit")
 		remaining  = headers.Get("X-RateLimit-Remaining")
 		reset      = headers.Get("X-RateLimit-Reset")
+		resetAfter = headers.Get("X-RateLimit-Reset-After")
 		retryAfter = headers.Get("Retry-After")
 	)
 
+	if hash != "" {
+		b = l.sharedBucket(r, path, hash)
+	}
+
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
 	switch {
 	case retryAfter != "":
 		i, err := strconv.Atoi(retryAfter)
@@ -168,8 +287,19 @@ func (l *Limiter) Release(path string, headers http.Header) error {
 			atomic.StoreInt64(l.global, at.UnixNano())
 		} else {
 			b.reset = at
+			b.remaining = 0
+		}
+
+	case resetAfter != "":
+		f, err := strconv.ParseFloat(resetAfter, 64)
+		if err != nil {
+			return errors.Wrap(err, "Invalid resetAfter "+resetAfter)
 		}
 
+		b.reset = time.Now().
+			Add(time.Duration(f * float64(time.Second))).
+			Add(ExtraDelay)
+
 	case reset != "":
 		unix, err := strconv.ParseFloat(reset, 64)
 		if err != nil {
@@ -180,7 +310,17 @@ func (l *Limiter) Release(path string, headers http.Header) error {
 			Add(ExtraDelay)
 	}
 
-	if remaining != "" {
+	if limit != "" {
+		u, err := strconv.ParseUint(limit, 10, 64)
+		if err != nil {
+			return errors.Wrap(err, "Invalid limit "+limit)
+		}
+
+		b.limit = u
+	}
+
+	// A 429 already has nothing remaining.
+	if remaining != "" && retryAfter == "" {
 		u, err := strconv.ParseUint(remaining, 10, 64)
 		if err != nil {
 			return errors.Wrap(err, "Invalid remaining "+remaining)
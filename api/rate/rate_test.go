@@ -76,3 +76,114 @@ func TestRatelimitGlobal(t *testing.T) {
 		t.Error("Did not ratelimit correctly, got:", time.Since(sent))
 	}
 }
+
+func TestRatelimitResetAfter(t *testing.T) {
+	l := NewLimiter()
+
+	headers := http.Header{}
+	headers.Set("X-RateLimit-Remaining", "0")
+	headers.Set("X-RateLimit-Reset-After", "0.5")
+
+	sent := time.Now()
+	mockRequest(t, l, "/guilds/99/channels", headers)
+	mockRequest(t, l, "/guilds/99/channels", headers)
+
+	if since := time.Since(sent); since < 500*time.Millisecond {
+		t.Error("Did not wait for the reset, took", since)
+	}
+}
+
+func TestRatelimitSharedBucket(t *testing.T) {
+	l := NewLimiter()
+
+	headers := http.Header{}
+	headers.Set("X-RateLimit-Bucket", "abcd")
+	headers.Set("X-RateLimit-Limit", "1")
+	headers.Set("X-RateLimit-Remaining", "0")
+	headers.Set("X-RateLimit-Reset-After", "0.5")
+
+	// Both routes learn that they're in the same bucket.
+	mockRequest(t, l, "/channels/1/messages", headers)
+	mockRequest(t, l, "/channels/1/pins", headers)
+
+	// The bucket is exhausted, so the other route has to wait.
+	sent := time.Now()
+	mockRequest(t, l, "/channels/1/messages", headers)
+
+	if since := time.Since(sent); since < 250*time.Millisecond {
+		t.Error("Shared bucket wasn't waited for, took", since)
+	}
+
+	// Another channel has its own bucket.
+	sent = time.Now()
+	mockRequest(t, l, "/channels/2/messages", headers)
+
+	if since := time.Since(sent); since > 100*time.Millisecond {
+		t.Error("Different major parameter was limited, took", since)
+	}
+}
+
+func TestRatelimitRemaining(t *testing.T) {
+	l := NewLimiter()
+
+	headers := http.Header{}
+	headers.Set("X-RateLimit-Limit", "5")
+	headers.Set("X-RateLimit-Remaining", "4")
+	headers.Set("X-RateLimit-Reset-After", "5")
+
+	// Requests are let through without waiting while there's some remaining.
+	sent := time.Now()
+	mockRequest(t, l, "/guilds/99/channels", headers)
+
+	for i := 0; i < 4; i++ {
+		mockRequest(t, l, "/guilds/99/channels", nil)
+	}
+
+	if since := time.Since(sent); since > 100*time.Millisecond {
+		t.Error("Waited while requests were remaining, took", since)
+	}
+
+	// The 6th request has to wait for the reset.
+	ctx, cancel := context.WithTimeout(
+		context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	if err := l.Acquire(ctx, "/guilds/99/channels"); err == nil {
+		t.Fatal("Acquired without any request remaining")
+	}
+}
+
+func TestRatelimitStats(t *testing.T) {
+	l := NewLimiter()
+
+	headers := http.Header{}
+	headers.Set("X-RateLimit-Global", "true")
+	headers.Set("Retry-After", "500")
+
+	mockRequest(t, l, "/guilds/99/channels", headers)
+
+	if stats := l.Stats(); stats.GlobalReset.IsZero() || stats.Waiting != 0 {
+		t.Fatal("Unexpected stats:", stats)
+	}
+
+	var done = make(chan struct{})
+	go func() {
+		if err := l.Acquire(context.Background(), "/guilds/55"); err != nil {
+			t.Error("Failed to acquire lock:", err)
+		}
+		close(done)
+	}()
+
+	for l.Stats().Waiting != 1 {
+		time.Sleep(time.Millisecond)
+	}
+
+	<-done
+
+	if stats := l.Stats(); !stats.GlobalReset.IsZero() {
+		t.Fatal("Global rate limit didn't reset:", stats)
+	}
+	if stats := l.Stats(); stats.Waiting != 0 {
+		t.Fatal("Request is still waiting:", stats)
+	}
+}
@@ -3,6 +3,7 @@ package api
 import (
 	"github.com/diamondburned/arikawa/discord"
 	"github.com/diamondburned/arikawa/internal/httputil"
+	"github.com/diamondburned/arikawa/internal/json"
 )
 
 func (c *Client) AddRole(guildID, userID, roleID discord.Snowflake) error {
@@ -62,9 +63,19 @@ func (c *Client) MoveRole(
 	)
 }
 
+// ModifyRoleData is like AnyRoleData, but its fields are optional, so that
+// they can also be set to their zero values, such as to turn Hoist off.
+type ModifyRoleData struct {
+	Name        json.OptionString    `json:"name,omitempty"`
+	Color       *discord.Color       `json:"color,omitempty"`
+	Hoist       json.OptionBool      `json:"hoist,omitempty"`
+	Mentionable json.OptionBool      `json:"mentionable,omitempty"`
+	Permissions *discord.Permissions `json:"permissions,omitempty"`
+}
+
 func (c *Client) ModifyRole(
 	guildID, roleID discord.Snowflake,
-	data AnyRoleData) (*discord.Role, error) {
+	data ModifyRoleData) (*discord.Role, error) {
 
 	var role *discord.Role
 	return role, c.RequestJSON(
@@ -0,0 +1,181 @@
+package api
+
+import (
+	"github.com/diamondburned/arikawa/discord"
+	"github.com/diamondburned/arikawa/internal/httputil"
+	"github.com/diamondburned/arikawa/internal/json"
+)
+
+// GuildScheduledEvents returns the scheduled events of the guild. The number
+// of users subscribed to each event is only returned if withUserCount is true.
+func (c *Client) GuildScheduledEvents(
+	guildID discord.Snowflake,
+	withUserCount bool) ([]discord.GuildScheduledEvent, error) {
+
+	var param struct {
+		WithUserCount bool `schema:"with_user_count,omitempty"`
+	}
+
+	param.WithUserCount = withUserCount
+
+	var events []discord.GuildScheduledEvent
+	return events, c.RequestJSON(
+		&events, "GET",
+		EndpointGuilds+guildID.String()+"/scheduled-events",
+		httputil.WithSchema(c, param),
+	)
+}
+
+// https://discord.com/developers/docs/resources/guild-scheduled-event#create-guild-scheduled-event-json-params
+type CreateScheduledEventData struct {
+	// ChannelID is required for stage and voice events.
+	ChannelID      discord.Snowflake                     `json:"channel_id,string,omitempty"`
+	EntityMetadata *discord.ScheduledEventEntityMetadata `json:"entity_metadata,omitempty"`
+
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	// Image is the cover image of the event.
+	Image *Image `json:"image,omitempty"`
+
+	// PrivacyLevel is GuildOnlyEvent if 0.
+	PrivacyLevel discord.ScheduledEventPrivacyLevel `json:"privacy_level"`
+	StartTime    discord.Timestamp                  `json:"scheduled_start_time"`
+	// EndTime is required for external events.
+	EndTime *discord.Timestamp `json:"scheduled_end_time,omitempty"`
+
+	EntityType discord.ScheduledEventEntityType `json:"entity_type"`
+}
+
+// CreateGuildScheduledEvent schedules an event in the guild. It requires the
+// "manage_events" permission.
+func (c *Client) CreateGuildScheduledEvent(
+	guildID discord.Snowflake,
+	data CreateScheduledEventData) (*discord.GuildScheduledEvent, error) {
+
+	if data.PrivacyLevel == 0 {
+		data.PrivacyLevel = discord.GuildOnlyEvent
+	}
+
+	var event *discord.GuildScheduledEvent
+	return event, c.RequestJSON(
+		&event, "POST",
+		EndpointGuilds+guildID.String()+"/scheduled-events",
+		httputil.WithJSONBody(c, data),
+	)
+}
+
+// GuildScheduledEvent returns the scheduled event. The number of users
+// subscribed to it is only returned if withUserCount is true.
+func (c *Client) GuildScheduledEvent(
+	guildID, eventID discord.Snowflake,
+	withUserCount bool) (*discord.GuildScheduledEvent, error) {
+
+	var param struct {
+		WithUserCount bool `schema:"with_user_count,omitempty"`
+	}
+
+	param.WithUserCount = withUserCount
+
+	var event *discord.GuildScheduledEvent
+	return event, c.RequestJSON(
+		&event, "GET",
+		EndpointGuilds+guildID.String()+"/scheduled-events/"+eventID.String(),
+		httputil.WithSchema(c, param),
+	)
+}
+
+// https://discord.com/developers/docs/resources/guild-scheduled-event#modify-guild-scheduled-event-json-params
+type ModifyScheduledEventData struct {
+	ChannelID      discord.Snowflake                     `json:"channel_id,string,omitempty"`
+	EntityMetadata *discord.ScheduledEventEntityMetadata `json:"entity_metadata,omitempty"`
+
+	Name        string            `json:"name,omitempty"`
+	Description json.OptionString `json:"description,omitempty"`
+	Image       *Image            `json:"image,omitempty"`
+
+	PrivacyLevel discord.ScheduledEventPrivacyLevel `json:"privacy_level,omitempty"`
+	StartTime    *discord.Timestamp                 `json:"scheduled_start_time,omitempty"`
+	EndTime      *discord.Timestamp                 `json:"scheduled_end_time,omitempty"`
+
+	EntityType discord.ScheduledEventEntityType `json:"entity_type,omitempty"`
+	// Status can only go from scheduled to active or canceled, and from
+	// active to completed.
+	Status discord.ScheduledEventStatus `json:"status,omitempty"`
+}
+
+// ModifyGuildScheduledEvent changes the scheduled event, such as to start or
+// end it with Status. It requires the "manage_events" permission.
+func (c *Client) ModifyGuildScheduledEvent(
+	guildID, eventID discord.Snowflake,
+	data ModifyScheduledEventData) (*discord.GuildScheduledEvent, error) {
+
+	var event *discord.GuildScheduledEvent
+	return event, c.RequestJSON(
+		&event, "PATCH",
+		EndpointGuilds+guildID.String()+"/scheduled-events/"+eventID.String(),
+		httputil.WithJSONBody(c, data),
+	)
+}
+
+// DeleteGuildScheduledEvent deletes the scheduled event. It requires the
+// "manage_events" permission.
+func (c *Client) DeleteGuildScheduledEvent(
+	guildID, eventID discord.Snowflake) error {
+
+	return c.FastRequest("DELETE",
+		EndpointGuilds+guildID.String()+"/scheduled-events/"+eventID.String())
+}
+
+// GuildScheduledEventUsers gets all the users subscribed to the scheduled
+// event, automatically paginating. Users are fetched from the lowest ID
+// upwards, and max can be 0, in which case all users are fetched. Their
+// members are returned too if withMember is true.
+func (c *Client) GuildScheduledEventUsers(
+	guildID, eventID discord.Snowflake, max uint,
+	withMember bool) ([]discord.GuildScheduledEventUser, error) {
+
+	var users []discord.GuildScheduledEventUser
+	var after discord.Snowflake = 0
+
+	err := paginate(max, 100, func(limit uint) (int, error) {
+		u, err := c.GuildScheduledEventUsersAfter(
+			guildID, eventID, after, limit, withMember)
+		if err != nil {
+			return 0, err
+		}
+		users = append(users, u...)
+
+		if len(u) > 0 {
+			after = u[len(u)-1].User.ID
+		}
+
+		return len(u), nil
+	})
+
+	return users, err
+}
+
+// GuildScheduledEventUsersAfter returns the users subscribed to the scheduled
+// event after the user ID, with a limit of 1-100.
+func (c *Client) GuildScheduledEventUsersAfter(
+	guildID, eventID, after discord.Snowflake, limit uint,
+	withMember bool) ([]discord.GuildScheduledEventUser, error) {
+
+	var param struct {
+		After      discord.Snowflake `schema:"after,omitempty"`
+		Limit      uint              `schema:"limit"`
+		WithMember bool              `schema:"with_member,omitempty"`
+	}
+
+	param.After = after
+	param.Limit = clampLimit(limit, 100, 100)
+	param.WithMember = withMember
+
+	var users []discord.GuildScheduledEventUser
+	return users, c.RequestJSON(
+		&users, "GET",
+		EndpointGuilds+guildID.String()+"/scheduled-events/"+
+			eventID.String()+"/users",
+		httputil.WithSchema(c, param),
+	)
+}
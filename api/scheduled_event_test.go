@@ -0,0 +1,46 @@
+// +build unit
+
+package api
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestGuildScheduledEventUsers(t *testing.T) {
+	var pages = []int{100, 50}
+	var requests int
+
+	c := NewClient("")
+	c.Client.Client.Transport = roundTripFunc(
+		func(r *http.Request) *http.Response {
+			requests++
+
+			var users []string
+			for i := 0; i < pages[0]; i++ {
+				users = append(users, fmt.Sprintf(`{"user":{"id":"%d"}}`, i+1))
+			}
+			pages = pages[1:]
+
+			var body = "[" + strings.Join(users, ",") + "]"
+
+			return &http.Response{
+				StatusCode: 200,
+				Header:     http.Header{},
+				Body:       ioutil.NopCloser(strings.NewReader(body)),
+			}
+		},
+	)
+
+	users, err := c.GuildScheduledEventUsers(1, 2, 0, false)
+	if err != nil {
+		t.Fatal("Failed to get users:", err)
+	}
+
+	if len(users) != 150 || requests != 2 {
+		t.Fatalf("Got %d users in %d requests", len(users), requests)
+	}
+}
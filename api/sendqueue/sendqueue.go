@@ -0,0 +1,203 @@
+// Package sendqueue sends the messages of each channel one at a time, in the
+// order they're queued. Messages sent from multiple goroutines with the Client
+// can otherwise arrive out of order, as their requests run concurrently:
+//
+//	q := sendqueue.New(s)
+//
+//	q.SendMessage(channelID, "first", nil)
+//	q.SendMessage(channelID, "second", nil)
+//
+//	// Wait until both are sent.
+//	q.Flush(channelID)
+//
+// Each channel has its own queue, so a slow channel doesn't hold back the
+// others.
+package sendqueue
+
+import (
+	"sync"
+
+	"github.com/diamondburned/arikawa/api"
+	"github.com/diamondburned/arikawa/discord"
+)
+
+// Sender sends messages, and is implemented by api.Client, and by the Session
+// and State that embed it.
+type Sender interface {
+	SendMessageComplex(
+		channelID discord.Snowflake,
+		data api.SendMessageData) (*discord.Message, error)
+}
+
+// Pending is a queued message.
+type Pending struct {
+	ChannelID discord.Snowflake
+
+	data api.SendMessageData
+	done chan struct{}
+	msg  *discord.Message
+	err  error
+}
+
+// Done returns a channel that's closed once the message is sent or failed to
+// be.
+func (p *Pending) Done() <-chan struct{} {
+	return p.done
+}
+
+// Wait waits until the message is sent, and returns it.
+func (p *Pending) Wait() (*discord.Message, error) {
+	<-p.done
+	return p.msg, p.err
+}
+
+// Queue queues the messages of each channel. The zero value isn't valid; use
+// New.
+type Queue struct {
+	Sender Sender
+
+	// ErrorLog, if not nil, is called with the errors of the messages that
+	// failed to be sent, which are also returned by Wait. The following
+	// messages are still sent.
+	ErrorLog func(channelID discord.Snowflake, err error)
+
+	mutex    sync.Mutex
+	channels map[discord.Snowflake]*channel
+}
+
+// channel is the queue of a channel. It's only in the map while it has
+// pending messages.
+type channel struct {
+	pending []*Pending
+}
+
+// New creates a Queue that sends messages with the Sender.
+func New(s Sender) *Queue {
+	return &Queue{
+		Sender:   s,
+		channels: map[discord.Snowflake]*channel{},
+	}
+}
+
+// SendMessage queues a message with the content and the embed, which may be
+// nil.
+func (q *Queue) SendMessage(
+	channelID discord.Snowflake, content string,
+	embed *discord.Embed) *Pending {
+
+	return q.SendMessageComplex(channelID, api.SendMessageData{
+		Content: content,
+		Embed:   embed,
+	})
+}
+
+// SendMessageComplex queues the message, which is sent after all the messages
+// queued in the channel before it.
+func (q *Queue) SendMessageComplex(
+	channelID discord.Snowflake, data api.SendMessageData) *Pending {
+
+	var p = &Pending{
+		ChannelID: channelID,
+		data:      data,
+		done:      make(chan struct{}),
+	}
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	ch, ok := q.channels[channelID]
+	if !ok {
+		ch = &channel{}
+		q.channels[channelID] = ch
+	}
+
+	ch.pending = append(ch.pending, p)
+
+	// Start a worker if the channel doesn't have one.
+	if len(ch.pending) == 1 {
+		go q.run(channelID, ch)
+	}
+
+	return p
+}
+
+// Len returns the number of messages of the channel that aren't sent yet,
+// including the one being sent.
+func (q *Queue) Len(channelID discord.Snowflake) int {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	if ch, ok := q.channels[channelID]; ok {
+		return len(ch.pending)
+	}
+	return 0
+}
+
+// Flush waits until all the messages queued in the channel so far are sent.
+// It returns the error of the last one if it wasn't sent yet; Wait returns the
+// result of each message.
+func (q *Queue) Flush(channelID discord.Snowflake) error {
+	q.mutex.Lock()
+	ch, ok := q.channels[channelID]
+	if !ok {
+		q.mutex.Unlock()
+		return nil
+	}
+	var last = ch.pending[len(ch.pending)-1]
+	q.mutex.Unlock()
+
+	_, err := last.Wait()
+	return err
+}
+
+// FlushAll waits until all the messages queued so far are sent, such as
+// before closing the Session.
+func (q *Queue) FlushAll() {
+	q.mutex.Lock()
+	var lasts = make([]*Pending, 0, len(q.channels))
+	for _, ch := range q.channels {
+		lasts = append(lasts, ch.pending[len(ch.pending)-1])
+	}
+	q.mutex.Unlock()
+
+	for _, p := range lasts {
+		p.Wait()
+	}
+}
+
+// run sends the messages of the channel until there are none left.
+func (q *Queue) run(channelID discord.Snowflake, ch *channel) {
+	q.mutex.Lock()
+	var p = ch.pending[0]
+	q.mutex.Unlock()
+
+	for p != nil {
+		p.msg, p.err = q.Sender.SendMessageComplex(channelID, p.data)
+
+		// The message leaves the queue before it's done, so Len is already
+		// up to date for the ones waiting on it.
+		var sent = p
+
+		q.mutex.Lock()
+
+		ch.pending[0] = nil
+		ch.pending = ch.pending[1:]
+
+		if len(ch.pending) > 0 {
+			p = ch.pending[0]
+		} else {
+			// The channel is forgotten once it's idle, so the map doesn't
+			// grow with every channel ever used.
+			delete(q.channels, channelID)
+			p = nil
+		}
+
+		q.mutex.Unlock()
+
+		close(sent.done)
+
+		if sent.err != nil && q.ErrorLog != nil {
+			q.ErrorLog(channelID, sent.err)
+		}
+	}
+}
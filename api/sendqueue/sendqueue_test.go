@@ -0,0 +1,86 @@
+// +build unit
+
+package sendqueue
+
+import (
+	"errors"
+	"math/rand"
+	"reflect"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/diamondburned/arikawa/api"
+	"github.com/diamondburned/arikawa/discord"
+)
+
+type testSender struct {
+	mutex sync.Mutex
+	sent  map[discord.Snowflake][]string
+}
+
+func (s *testSender) SendMessageComplex(
+	channelID discord.Snowflake,
+	data api.SendMessageData) (*discord.Message, error) {
+
+	// Requests take varying times, which would reorder them if they were
+	// concurrent.
+	time.Sleep(time.Duration(rand.Intn(1000)) * time.Microsecond)
+
+	if data.Content == "fail" {
+		return nil, errors.New("Failed to send")
+	}
+
+	s.mutex.Lock()
+	s.sent[channelID] = append(s.sent[channelID], data.Content)
+	s.mutex.Unlock()
+
+	return &discord.Message{ChannelID: channelID, Content: data.Content}, nil
+}
+
+func TestQueue(t *testing.T) {
+	var s = &testSender{sent: map[discord.Snowflake][]string{}}
+	var q = New(s)
+
+	var expect []string
+	for i := 0; i < 20; i++ {
+		expect = append(expect, strconv.Itoa(i))
+		q.SendMessage(1, strconv.Itoa(i), nil)
+	}
+
+	var other = q.SendMessage(2, "other", nil)
+	var failed = q.SendMessage(1, "fail", nil)
+	var last = q.SendMessage(1, "last", nil)
+
+	if err := q.Flush(1); err != nil {
+		t.Fatal("Failed to flush:", err)
+	}
+
+	if _, err := failed.Wait(); err == nil {
+		t.Fatal("Expected an error for the failed message")
+	}
+
+	m, err := last.Wait()
+	if err != nil || m.Content != "last" {
+		t.Fatal("Unexpected last message:", m, err)
+	}
+
+	q.FlushAll()
+
+	select {
+	case <-other.Done():
+	default:
+		t.Fatal("Message of the other channel isn't sent after FlushAll")
+	}
+
+	expect = append(expect, "last")
+
+	if !reflect.DeepEqual(s.sent[1], expect) {
+		t.Fatalf("Unexpected order %q, expected %q", s.sent[1], expect)
+	}
+
+	if q.Len(1) != 0 || q.Len(2) != 0 {
+		t.Fatal("Messages left in the queue")
+	}
+}
@@ -0,0 +1,63 @@
+package api
+
+import (
+	"github.com/diamondburned/arikawa/discord"
+	"github.com/diamondburned/arikawa/internal/httputil"
+)
+
+const EndpointStageInstances = Endpoint + "stage-instances/"
+
+// https://discord.com/developers/docs/resources/stage-instance#create-stage-instance-json-params
+type CreateStageInstanceData struct {
+	ChannelID discord.Snowflake `json:"channel_id,string"`
+	Topic     string            `json:"topic"` // 1-120 chars
+	// PrivacyLevel is GuildOnlyStage if 0.
+	PrivacyLevel discord.StagePrivacyLevel `json:"privacy_level,omitempty"`
+}
+
+// CreateStageInstance starts a stage in the stage channel. The current user
+// must be a moderator of the stage, which means having the "manage_channels",
+// "mute_members" and "move_members" permissions.
+func (c *Client) CreateStageInstance(
+	data CreateStageInstanceData) (*discord.StageInstance, error) {
+
+	var s *discord.StageInstance
+	return s, c.RequestJSON(
+		&s, "POST", EndpointStageInstances,
+		httputil.WithJSONBody(c, data),
+	)
+}
+
+// StageInstance returns the live stage of the stage channel.
+func (c *Client) StageInstance(
+	channelID discord.Snowflake) (*discord.StageInstance, error) {
+
+	var s *discord.StageInstance
+	return s, c.RequestJSON(&s, "GET",
+		EndpointStageInstances+channelID.String())
+}
+
+// https://discord.com/developers/docs/resources/stage-instance#update-stage-instance-json-params
+type ModifyStageInstanceData struct {
+	Topic        string                    `json:"topic,omitempty"`
+	PrivacyLevel discord.StagePrivacyLevel `json:"privacy_level,omitempty"`
+}
+
+// ModifyStageInstance changes the live stage of the stage channel. It
+// requires the same permissions as CreateStageInstance.
+func (c *Client) ModifyStageInstance(
+	channelID discord.Snowflake,
+	data ModifyStageInstanceData) (*discord.StageInstance, error) {
+
+	var s *discord.StageInstance
+	return s, c.RequestJSON(
+		&s, "PATCH", EndpointStageInstances+channelID.String(),
+		httputil.WithJSONBody(c, data),
+	)
+}
+
+// DeleteStageInstance ends the live stage of the stage channel. It requires
+// the same permissions as CreateStageInstance.
+func (c *Client) DeleteStageInstance(channelID discord.Snowflake) error {
+	return c.FastRequest("DELETE", EndpointStageInstances+channelID.String())
+}
@@ -0,0 +1,252 @@
+package api
+
+import (
+	"github.com/diamondburned/arikawa/discord"
+	"github.com/diamondburned/arikawa/internal/httputil"
+)
+
+// https://discord.com/developers/docs/resources/channel#start-thread-with-message-json-params
+type StartThreadData struct {
+	Name string `json:"name"` // 1-100 chars
+	// AutoArchiveDuration defaults to the one of the channel if 0.
+	AutoArchiveDuration discord.ArchiveDuration `json:"auto_archive_duration,omitempty"`
+	// Type is GuildPublicThread if 0. It's ignored if the thread is started
+	// from a message, as the type is then decided by the channel.
+	Type discord.ChannelType `json:"type,omitempty"`
+}
+
+// StartThread starts a thread in the channel. The thread is started from the
+// message if messageID is valid, or without a message otherwise.
+func (c *Client) StartThread(
+	channelID, messageID discord.Snowflake,
+	data StartThreadData) (*discord.Channel, error) {
+
+	var url = EndpointChannels + channelID.String()
+	if messageID.Valid() {
+		url += "/messages/" + messageID.String()
+		data.Type = 0
+	} else if data.Type == 0 {
+		data.Type = discord.GuildPublicThread
+	}
+
+	var ch *discord.Channel
+	return ch, c.RequestJSON(
+		&ch, "POST", url+"/threads",
+		httputil.WithJSONBody(c, data),
+	)
+}
+
+// JoinThread adds the current user to the thread, which must not be archived.
+func (c *Client) JoinThread(threadID discord.Snowflake) error {
+	return c.FastRequest("PUT",
+		EndpointChannels+threadID.String()+"/thread-members/@me")
+}
+
+// LeaveThread removes the current user from the thread.
+func (c *Client) LeaveThread(threadID discord.Snowflake) error {
+	return c.FastRequest("DELETE",
+		EndpointChannels+threadID.String()+"/thread-members/@me")
+}
+
+// AddThreadMember adds a user to the thread, which must not be archived. The
+// current user must be able to send messages in the thread.
+func (c *Client) AddThreadMember(threadID, userID discord.Snowflake) error {
+	return c.FastRequest("PUT",
+		EndpointChannels+threadID.String()+"/thread-members/"+userID.String())
+}
+
+// RemoveThreadMember removes a user from the thread. It requires the
+// "manage_threads" permission, or to be the creator of a private thread.
+func (c *Client) RemoveThreadMember(threadID, userID discord.Snowflake) error {
+	return c.FastRequest("DELETE",
+		EndpointChannels+threadID.String()+"/thread-members/"+userID.String())
+}
+
+// ThreadMembers returns the members of the thread. It requires the
+// GUILD_MEMBERS intent.
+func (c *Client) ThreadMembers(
+	threadID discord.Snowflake) ([]discord.ThreadMember, error) {
+
+	var members []discord.ThreadMember
+	return members, c.RequestJSON(&members, "GET",
+		EndpointChannels+threadID.String()+"/thread-members")
+}
+
+// Threads is a list of threads and the thread members of the current user.
+type Threads struct {
+	Threads []discord.Channel      `json:"threads"`
+	Members []discord.ThreadMember `json:"members"`
+}
+
+// ListActiveThreads returns all the active threads in the guild that the
+// current user can see, public and private.
+func (c *Client) ListActiveThreads(
+	guildID discord.Snowflake) (*Threads, error) {
+
+	var threads *Threads
+	return threads, c.RequestJSON(&threads, "GET",
+		EndpointGuilds+guildID.String()+"/threads/active")
+}
+
+// ArchivedThreads is a page of archived threads.
+type ArchivedThreads struct {
+	Threads
+	// HasMore is true if there are more threads to fetch.
+	HasMore bool `json:"has_more"`
+}
+
+// PublicArchivedThreads gets all the public archived threads in the channel,
+// automatically paginating. Threads are fetched from the latest archived one
+// backwards, and max can be 0, in which case all threads are fetched.
+func (c *Client) PublicArchivedThreads(
+	channelID discord.Snowflake, max uint) (*Threads, error) {
+
+	return archivedThreads(max,
+		func(last *discord.Channel, limit uint) (*ArchivedThreads, error) {
+			return c.PublicArchivedThreadsBefore(
+				channelID, archiveTime(last), limit)
+		},
+	)
+}
+
+// PrivateArchivedThreads gets all the private archived threads in the
+// channel, automatically paginating like PublicArchivedThreads. It requires
+// the "manage_threads" permission.
+func (c *Client) PrivateArchivedThreads(
+	channelID discord.Snowflake, max uint) (*Threads, error) {
+
+	return archivedThreads(max,
+		func(last *discord.Channel, limit uint) (*ArchivedThreads, error) {
+			return c.PrivateArchivedThreadsBefore(
+				channelID, archiveTime(last), limit)
+		},
+	)
+}
+
+// JoinedPrivateArchivedThreads gets all the private archived threads in the
+// channel that the current user has joined, automatically paginating. Threads
+// are fetched from the latest created one backwards, and max can be 0, in
+// which case all threads are fetched.
+func (c *Client) JoinedPrivateArchivedThreads(
+	channelID discord.Snowflake, max uint) (*Threads, error) {
+
+	return archivedThreads(max,
+		func(last *discord.Channel, limit uint) (*ArchivedThreads, error) {
+			var before discord.Snowflake
+			if last != nil {
+				before = last.ID
+			}
+			return c.JoinedPrivateArchivedThreadsBefore(
+				channelID, before, limit)
+		},
+	)
+}
+
+// PublicArchivedThreadsBefore returns the public archived threads that were
+// archived before the time, or the latest ones if it's invalid, with a limit
+// of 1-100.
+func (c *Client) PublicArchivedThreadsBefore(
+	channelID discord.Snowflake, before discord.Timestamp,
+	limit uint) (*ArchivedThreads, error) {
+
+	return c.archivedThreadsBefore(
+		channelID, "/threads/archived/public", before, limit)
+}
+
+// PrivateArchivedThreadsBefore returns the private archived threads that were
+// archived before the time, or the latest ones if it's invalid, with a limit
+// of 1-100.
+func (c *Client) PrivateArchivedThreadsBefore(
+	channelID discord.Snowflake, before discord.Timestamp,
+	limit uint) (*ArchivedThreads, error) {
+
+	return c.archivedThreadsBefore(
+		channelID, "/threads/archived/private", before, limit)
+}
+
+// JoinedPrivateArchivedThreadsBefore returns the joined private archived
+// threads that were created before the thread ID, or the latest ones if it's
+// invalid, with a limit of 1-100.
+func (c *Client) JoinedPrivateArchivedThreadsBefore(
+	channelID, before discord.Snowflake,
+	limit uint) (*ArchivedThreads, error) {
+
+	var param struct {
+		Before discord.Snowflake `schema:"before,omitempty"`
+		Limit  uint              `schema:"limit"`
+	}
+
+	param.Before = before
+	param.Limit = clampLimit(limit, 50, 100)
+
+	var threads *ArchivedThreads
+	return threads, c.RequestJSON(
+		&threads, "GET",
+		EndpointChannels+channelID.String()+
+			"/users/@me/threads/archived/private",
+		httputil.WithSchema(c, param),
+	)
+}
+
+// archivedThreads paginates archived threads with fetch, which is given the
+// last thread fetched, or nil for the first page.
+func archivedThreads(max uint,
+	fetch func(last *discord.Channel, limit uint) (*ArchivedThreads, error),
+) (*Threads, error) {
+
+	var all Threads
+	var last *discord.Channel
+
+	err := paginate(max, 100, func(limit uint) (int, error) {
+		page, err := fetch(last, limit)
+		if err != nil {
+			return 0, err
+		}
+
+		all.Threads = append(all.Threads, page.Threads.Threads...)
+		all.Members = append(all.Members, page.Members...)
+
+		var n = len(page.Threads.Threads)
+		if n == 0 || !page.HasMore {
+			return 0, nil
+		}
+
+		last = &page.Threads.Threads[n-1]
+
+		// Pages may be short even if there are more threads.
+		return int(limit), nil
+	})
+
+	return &all, err
+}
+
+// archiveTime returns the time the thread was archived at, or an invalid time
+// if the thread is nil.
+func archiveTime(thread *discord.Channel) discord.Timestamp {
+	if thread == nil || thread.ThreadMetadata == nil {
+		return discord.Timestamp{}
+	}
+	return thread.ThreadMetadata.ArchiveTimestamp
+}
+
+func (c *Client) archivedThreadsBefore(
+	channelID discord.Snowflake, path string,
+	before discord.Timestamp, limit uint) (*ArchivedThreads, error) {
+
+	var param struct {
+		Before string `schema:"before,omitempty"`
+		Limit  uint   `schema:"limit"`
+	}
+
+	if before.Valid() {
+		param.Before = before.Format(discord.TimestampFormat)
+	}
+	param.Limit = clampLimit(limit, 50, 100)
+
+	var threads *ArchivedThreads
+	return threads, c.RequestJSON(
+		&threads, "GET",
+		EndpointChannels+channelID.String()+path,
+		httputil.WithSchema(c, param),
+	)
+}
@@ -0,0 +1,55 @@
+// +build unit
+
+package api
+
+import (
+	"io/ioutil"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestPublicArchivedThreads(t *testing.T) {
+	var pages = []string{
+		`{"threads":[{"id":"2","thread_metadata":{"archived":true,` +
+			`"auto_archive_duration":60,` +
+			`"archive_timestamp":"2021-05-01T00:00:00Z"}}],` +
+			`"members":[],"has_more":true}`,
+		`{"threads":[{"id":"1"}],"members":[],"has_more":false}`,
+	}
+	var paths []string
+
+	c := NewClient("")
+	c.Client.Client.Transport = roundTripFunc(
+		func(r *http.Request) *http.Response {
+			paths = append(paths, strings.TrimPrefix(r.URL.Path, "/api/v6"))
+
+			var page = pages[0]
+			pages = pages[1:]
+
+			return &http.Response{
+				StatusCode: 200,
+				Header:     http.Header{},
+				Body:       ioutil.NopCloser(strings.NewReader(page)),
+			}
+		},
+	)
+
+	threads, err := c.PublicArchivedThreads(3, 0)
+	if err != nil {
+		t.Fatal("Failed to get threads:", err)
+	}
+
+	if len(threads.Threads) != 2 || threads.Threads[1].ID != 1 {
+		t.Fatalf("Unexpected threads: %+v", threads.Threads)
+	}
+
+	var expect = []string{
+		"/channels/3/threads/archived/public",
+		"/channels/3/threads/archived/public",
+	}
+	if !reflect.DeepEqual(paths, expect) {
+		t.Fatalf("Unexpected requests %q, expected %q", paths, expect)
+	}
+}
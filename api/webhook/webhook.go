@@ -0,0 +1,96 @@
+// Package webhook provides a client for executing webhooks without a bot
+// token. Only the webhook's ID and token are needed, which are both in its URL:
+//
+//	c, err := webhook.NewFromURL(url)
+//	if err != nil {
+//		return err
+//	}
+//
+//	m, err := c.ExecuteAndWait(api.ExecuteWebhookData{
+//		Content:  "Hello!",
+//		Username: "Announcer",
+//	})
+package webhook
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/diamondburned/arikawa/api"
+	"github.com/diamondburned/arikawa/discord"
+	"github.com/pkg/errors"
+)
+
+// ErrInvalidURL is returned by NewFromURL if the URL isn't a webhook URL.
+var ErrInvalidURL = errors.New("Invalid webhook URL")
+
+type Client struct {
+	// Client is the API client used for requests. It doesn't have a token,
+	// the webhook token is in the URL instead.
+	Client *api.Client
+
+	ID    discord.Snowflake
+	Token string
+}
+
+// New creates a new webhook client from the webhook's ID and token.
+func New(id discord.Snowflake, token string) *Client {
+	return &Client{
+		Client: api.NewClient(""),
+		ID:     id,
+		Token:  token,
+	}
+}
+
+// NewFromURL creates a new webhook client from the webhook's URL, which looks
+// like https://discordapp.com/api/webhooks/{id}/{token}.
+func NewFromURL(webhookURL string) (*Client, error) {
+	u, err := url.Parse(webhookURL)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to parse webhook URL")
+	}
+
+	// The path could be versioned, such as /api/v6/webhooks.
+	parts := strings.Split(strings.Trim(u.Path, "/"), "/")
+	if len(parts) < 3 || parts[len(parts)-3] != "webhooks" {
+		return nil, ErrInvalidURL
+	}
+
+	id, err := discord.ParseSnowflake(parts[len(parts)-2])
+	if err != nil {
+		return nil, errors.Wrap(err, "Invalid webhook ID")
+	}
+
+	token := parts[len(parts)-1]
+	if token == "" {
+		return nil, ErrInvalidURL
+	}
+
+	return New(id, token), nil
+}
+
+// Get gets the webhook.
+func (c *Client) Get() (*discord.Webhook, error) {
+	return c.Client.WebhookWithToken(c.ID, c.Token)
+}
+
+// Delete deletes the webhook.
+func (c *Client) Delete() error {
+	return c.Client.DeleteWebhookWithToken(c.ID, c.Token)
+}
+
+// Execute sends a message through the webhook, without waiting for it to be
+// created. The username and avatar can be overridden in data, and files are
+// streamed as they're uploaded.
+func (c *Client) Execute(data api.ExecuteWebhookData) error {
+	_, err := c.Client.ExecuteWebhook(c.ID, c.Token, false, data)
+	return err
+}
+
+// ExecuteAndWait sends a message through the webhook, waits for it to be
+// created, then returns it.
+func (c *Client) ExecuteAndWait(
+	data api.ExecuteWebhookData) (*discord.Message, error) {
+
+	return c.Client.ExecuteWebhook(c.ID, c.Token, true, data)
+}
@@ -0,0 +1,35 @@
+// +build unit
+
+package webhook
+
+import "testing"
+
+func TestNewFromURL(t *testing.T) {
+	var valid = []string{
+		"https://discordapp.com/api/webhooks/123/abc-def",
+		"https://discord.com/api/v6/webhooks/123/abc-def/",
+	}
+
+	for _, u := range valid {
+		c, err := NewFromURL(u)
+		if err != nil {
+			t.Fatal("Failed to parse", u+":", err)
+		}
+
+		if c.ID != 123 || c.Token != "abc-def" {
+			t.Fatalf("Unexpected ID %d and token %q", c.ID, c.Token)
+		}
+	}
+
+	var invalid = []string{
+		"https://discordapp.com/api/webhooks/123",
+		"https://discordapp.com/api/channels/123/abc",
+		"https://discordapp.com/api/webhooks/abc/def",
+	}
+
+	for _, u := range invalid {
+		if _, err := NewFromURL(u); err == nil {
+			t.Fatal("Expected an error for", u)
+		}
+	}
+}
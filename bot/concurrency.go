@@ -0,0 +1,130 @@
+package bot
+
+import (
+	"strconv"
+	"sync"
+
+	"github.com/diamondburned/arikawa/discord"
+	"github.com/diamondburned/arikawa/gateway"
+)
+
+// BusyMode is what happens when a command is invoked while it's running at its
+// maximum concurrency.
+type BusyMode uint8
+
+const (
+	// QueueWhenBusy waits until one of the running instances returns. If the
+	// Handler is Synchronous, this blocks the other events.
+	QueueWhenBusy BusyMode = iota
+	// RejectWhenBusy returns an ErrCommandBusy, which is replied like the
+	// other errors.
+	RejectWhenBusy
+)
+
+type ErrCommandBusy struct {
+	Command string
+	Max     int
+}
+
+func (err *ErrCommandBusy) Error() string {
+	return CommandBusyString(err)
+}
+
+var CommandBusyString = func(err *ErrCommandBusy) string {
+	return "Command " + err.Command + " is already running " +
+		strconv.Itoa(err.Max) + " times, try again later."
+}
+
+// Concurrency limits how many instances of a command run at once in each
+// guild, or in each channel for DMs. It's set with SetConcurrency.
+type Concurrency struct {
+	// Max is the number of instances allowed at once. There's no limit if
+	// it's less than 1.
+	Max  int
+	Busy BusyMode
+
+	mutex sync.Mutex
+	slots map[discord.Snowflake]*slots
+}
+
+type slots struct {
+	ch   chan struct{}
+	refs int // guarded by Concurrency's mutex
+}
+
+// acquire takes a slot for the guild or channel, and returns the function to
+// release it. False is returned if the command is busy and rejected.
+func (c *Concurrency) acquire(mc *gateway.MessageCreateEvent) (func(), bool) {
+	// There would be no slots at all.
+	if c.Max < 1 {
+		return func() {}, true
+	}
+
+	var key = mc.GuildID
+	if !key.Valid() {
+		key = mc.ChannelID
+	}
+
+	c.mutex.Lock()
+
+	if c.slots == nil {
+		c.slots = map[discord.Snowflake]*slots{}
+	}
+
+	s, ok := c.slots[key]
+	if !ok {
+		s = &slots{ch: make(chan struct{}, c.Max)}
+		c.slots[key] = s
+	}
+	s.refs++
+
+	c.mutex.Unlock()
+
+	var unref = func() {
+		c.mutex.Lock()
+		defer c.mutex.Unlock()
+
+		// Remove the slots once nothing uses them, so they don't pile up for
+		// every guild.
+		if s.refs--; s.refs == 0 {
+			delete(c.slots, key)
+		}
+	}
+
+	if c.Busy == RejectWhenBusy {
+		select {
+		case s.ch <- struct{}{}:
+		default:
+			unref()
+			return nil, false
+		}
+	} else {
+		s.ch <- struct{}{}
+	}
+
+	return func() {
+		<-s.ch
+		unref()
+	}, true
+}
+
+// SetConcurrency limits the matched methodName to max instances at once in
+// each guild, such as 1 for commands that change a music queue. A max less
+// than 1 removes the limit. The returned bool is true when the method is
+// found.
+func (sub *Subcommand) SetConcurrency(
+	methodName string, max int, busy BusyMode) bool {
+
+	c := sub.findCommand(methodName)
+	if c == nil {
+		return false
+	}
+
+	if max < 1 {
+		c.Concurrency = nil
+		return true
+	}
+
+	c.Concurrency = &Concurrency{Max: max, Busy: busy}
+	return true
+}
@@ -0,0 +1,80 @@
+// +build unit
+
+package bot
+
+import (
+	"testing"
+	"time"
+
+	"github.com/diamondburned/arikawa/gateway"
+)
+
+func TestConcurrencyReject(t *testing.T) {
+	var c = Concurrency{Max: 1, Busy: RejectWhenBusy}
+	var guild1 = &gateway.MessageCreateEvent{GuildID: 1}
+	var guild2 = &gateway.MessageCreateEvent{GuildID: 2}
+
+	release, ok := c.acquire(guild1)
+	if !ok {
+		t.Fatal("First instance was rejected")
+	}
+
+	if _, ok := c.acquire(guild1); ok {
+		t.Fatal("Second instance in the same guild wasn't rejected")
+	}
+
+	release2, ok := c.acquire(guild2)
+	if !ok {
+		t.Fatal("Instance in another guild was rejected")
+	}
+
+	release()
+	release2()
+
+	if len(c.slots) != 0 {
+		t.Fatal("Slots weren't removed:", c.slots)
+	}
+}
+
+func TestConcurrencyQueue(t *testing.T) {
+	var c = Concurrency{Max: 1, Busy: QueueWhenBusy}
+	var mc = &gateway.MessageCreateEvent{ChannelID: 1}
+
+	release, _ := c.acquire(mc)
+
+	var acquired = make(chan func())
+	go func() {
+		release, _ := c.acquire(mc)
+		acquired <- release
+	}()
+
+	select {
+	case <-acquired:
+		t.Fatal("Second instance ran before the first returned")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	release()
+
+	select {
+	case release := <-acquired:
+		release()
+	case <-time.After(time.Second):
+		t.Fatal("Second instance didn't run after the first returned")
+	}
+
+	if len(c.slots) != 0 {
+		t.Fatal("Slots weren't removed:", c.slots)
+	}
+}
+
+func TestConcurrencyNoLimit(t *testing.T) {
+	var c = Concurrency{Max: 0, Busy: RejectWhenBusy}
+	var mc = &gateway.MessageCreateEvent{ChannelID: 1}
+
+	for i := 0; i < 2; i++ {
+		if _, ok := c.acquire(mc); !ok {
+			t.Fatal("Instance was rejected without a limit")
+		}
+	}
+}
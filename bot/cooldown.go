@@ -0,0 +1,93 @@
+package bot
+
+import (
+	"sync"
+	"time"
+
+	"github.com/diamondburned/arikawa/discord"
+)
+
+type ErrCooldown struct {
+	Command string
+	// Remaining is how long the user has to wait.
+	Remaining time.Duration
+}
+
+func (err *ErrCooldown) Error() string {
+	return CooldownString(err)
+}
+
+var CooldownString = func(err *ErrCooldown) string {
+	// Round up, so users aren't told to wait 0s.
+	var wait = (err.Remaining + time.Second - 1).Truncate(time.Second)
+	return "Command " + err.Command + " is on cooldown, try again in " +
+		wait.String() + "."
+}
+
+// Cooldown is how long each user has to wait between invocations of a
+// command. It's set with SetCooldown.
+type Cooldown struct {
+	Duration time.Duration
+
+	mutex  sync.Mutex
+	users  map[discord.Snowflake]time.Time // userID:last invocation
+	pruned time.Time
+}
+
+// use records an invocation of the user, and returns how long they have to
+// wait if they're still on cooldown, in which case it's not recorded.
+func (c *Cooldown) use(userID discord.Snowflake) (time.Duration, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	var now = time.Now()
+
+	if c.users == nil {
+		c.users = map[discord.Snowflake]time.Time{}
+	}
+
+	if last, ok := c.users[userID]; ok {
+		if wait := c.Duration - now.Sub(last); wait > 0 {
+			return wait, false
+		}
+	}
+
+	c.users[userID] = now
+
+	// Forget the users whose cooldown is over once in a while, so they don't
+	// pile up.
+	if now.Sub(c.pruned) > c.Duration {
+		for id, last := range c.users {
+			if now.Sub(last) >= c.Duration {
+				delete(c.users, id)
+			}
+		}
+		c.pruned = now
+	}
+
+	return 0, true
+}
+
+// refund forgets the last invocation of the user, such as when the command was
+// busy and rejected. It must only be called after a successful use, when the
+// user wasn't on cooldown anyway.
+func (c *Cooldown) refund(userID discord.Snowflake) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	delete(c.users, userID)
+}
+
+// SetCooldown makes each user wait for the duration between invocations of
+// the matched methodName. Users that invoke it too early get an ErrCooldown,
+// which is replied like the other errors. The returned bool is true when the
+// method is found.
+func (sub *Subcommand) SetCooldown(methodName string, d time.Duration) bool {
+	c := sub.findCommand(methodName)
+	if c == nil {
+		return false
+	}
+
+	c.Cooldown = &Cooldown{Duration: d}
+	return true
+}
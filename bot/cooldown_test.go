@@ -0,0 +1,97 @@
+// +build unit
+
+package bot
+
+import (
+	"testing"
+	"time"
+
+	"github.com/diamondburned/arikawa/discord"
+	"github.com/diamondburned/arikawa/gateway"
+	"github.com/pkg/errors"
+)
+
+func TestCooldown(t *testing.T) {
+	var c = Cooldown{Duration: 50 * time.Millisecond}
+
+	if _, ok := c.use(1); !ok {
+		t.Fatal("First invocation is on cooldown")
+	}
+
+	if wait, ok := c.use(1); ok || wait <= 0 {
+		t.Fatal("Second invocation isn't on cooldown:", wait)
+	}
+
+	if _, ok := c.use(2); !ok {
+		t.Fatal("Another user is on cooldown")
+	}
+
+	time.Sleep(60 * time.Millisecond)
+
+	if _, ok := c.use(1); !ok {
+		t.Fatal("Invocation after the cooldown is on cooldown")
+	}
+
+	// The other user was pruned once their cooldown was over.
+	if _, ok := c.users[2]; ok {
+		t.Fatal("User whose cooldown is over wasn't pruned")
+	}
+}
+
+func TestCooldownString(t *testing.T) {
+	var err = &ErrCooldown{Command: "!roll", Remaining: 1500 * time.Millisecond}
+
+	const expect = "Command !roll is on cooldown, try again in 2s."
+	if err.Error() != expect {
+		t.Fatalf("Unexpected error %q, expected %q", err.Error(), expect)
+	}
+}
+
+func TestCooldownBusy(t *testing.T) {
+	ctx, _ := newReplyContext(t, false)
+
+	var running = make(chan struct{})
+	var unblock = make(chan struct{})
+
+	ctx.AddFunc("Slow", func(m *gateway.MessageCreateEvent) error {
+		if m.Author.ID == 1 {
+			running <- struct{}{}
+			<-unblock
+		}
+		return nil
+	})
+
+	ctx.SetCooldown("Slow", time.Minute)
+	ctx.SetConcurrency("Slow", 1, RejectWhenBusy)
+
+	var slow = func(userID discord.Snowflake) error {
+		return ctx.callCmd(&gateway.MessageCreateEvent{
+			ChannelID: 1,
+			Author:    discord.User{ID: userID},
+			Content:   "~slow",
+		})
+	}
+
+	go slow(1)
+	<-running
+
+	err := slow(2)
+	if _, ok := errors.Cause(err).(*ErrCommandBusy); !ok {
+		t.Fatal("Expected a busy error, got", err)
+	}
+
+	close(unblock)
+
+	// The rejected invocation didn't start the cooldown of the user. The
+	// first one might not have released its slot yet.
+	for i := 0; ; i++ {
+		err := slow(2)
+		if err == nil {
+			break
+		}
+		if _, ok := errors.Cause(err).(*ErrCommandBusy); !ok || i == 100 {
+			t.Fatal("Unexpected error after the busy rejection:", err)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
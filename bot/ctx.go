@@ -6,7 +6,9 @@ import (
 	"os/signal"
 	"strings"
 	"sync"
+	"time"
 
+	"github.com/diamondburned/arikawa/discord"
 	"github.com/diamondburned/arikawa/gateway"
 	"github.com/diamondburned/arikawa/state"
 	"github.com/pkg/errors"
@@ -76,6 +78,22 @@ type Context struct {
 	// ReplyError when true replies to the user the error.
 	ReplyError bool
 
+	// InvocationHook, if not nil, is called after every command invocation,
+	// whether it succeeded or not, such as for usage metrics or an audit
+	// trail. Commands that the user isn't allowed to run aren't invoked.
+	InvocationHook func(Invocation)
+
+	// SyncSlash, if true, makes Start overwrite the slash commands of the
+	// application with SlashCommands once the bot is first ready. They're
+	// registered in SlashGuildID if it's valid, where they show up instantly,
+	// such as while developing, or globally otherwise.
+	SyncSlash    bool
+	SlashGuildID discord.Snowflake
+
+	// Slash commands being served, by interaction ID.
+	interactions sync.Map // map[discord.Snowflake]*slashInvocation
+	slashSynced  sync.Once
+
 	// Subcommands contains all the registered subcommands. This is not
 	// exported, as it shouldn't be used directly.
 	subcommands []*Subcommand
@@ -85,6 +103,26 @@ type Context struct {
 	typeCache sync.Map // map[reflect.Type][]*CommandContext
 }
 
+// Invocation is a command invocation, given to Context's InvocationHook.
+type Invocation struct {
+	// Command is the name of the command, prefixed with the name of its
+	// subcommand if it has one, such as "starboard reset".
+	Command string
+
+	UserID    discord.Snowflake
+	GuildID   discord.Snowflake
+	ChannelID discord.Snowflake
+
+	// Start is when the command was invoked, and Duration is how long it took
+	// to parse the arguments, call the command and send its reply.
+	Start    time.Time
+	Duration time.Duration
+
+	// Err is the error of the invocation, such as an invalid usage or the
+	// error returned by the command. It's nil if the command succeeded.
+	Err error
+}
+
 // Start quickly starts a bot with the given command. It will prepend "Bot"
 // into the token automatically. Refer to example/ for usage.
 func Start(token string, cmd interface{},
@@ -255,6 +293,15 @@ func (ctx *Context) RegisterSubcommand(cmd interface{}) (*Subcommand, error) {
 // Session handlers.
 func (ctx *Context) Start() func() {
 	return ctx.Session.AddHandler(func(v interface{}) {
+		if ready, ok := v.(*gateway.ReadyEvent); ok && ctx.SyncSlash {
+			ctx.slashSynced.Do(func() {
+				err := ctx.syncSlash(readyAppID(ready), ctx.SlashGuildID)
+				if err != nil {
+					ctx.ErrorLogger(err)
+				}
+			})
+		}
+
 		err := ctx.callCmd(v)
 		if err == nil {
 			return
@@ -3,6 +3,7 @@ package bot
 import (
 	"reflect"
 	"strings"
+	"time"
 
 	"github.com/diamondburned/arikawa/api"
 	"github.com/diamondburned/arikawa/discord"
@@ -115,10 +116,16 @@ func (ctx *Context) callCmd(ev interface{}) error {
 		return ctx.callMessageCreate(ev.(*gateway.MessageCreateEvent))
 	}
 
+	if ev, ok := ev.(*gateway.InteractionCreateEvent); ok {
+		return ctx.callInteraction(ev)
+	}
+
 	return nil
 }
 
-func (ctx *Context) callMessageCreate(mc *gateway.MessageCreateEvent) error {
+func (ctx *Context) callMessageCreate(
+	mc *gateway.MessageCreateEvent) (err error) {
+
 	// check if prefix
 	if !strings.HasPrefix(mc.Content, ctx.Prefix) {
 		// not a command, ignore
@@ -229,6 +236,23 @@ func (ctx *Context) callMessageCreate(mc *gateway.MessageCreateEvent) error {
 			return nil
 		}
 	}
+	if cmd.Permissions != 0 {
+		p, err := ctx.State.Permissions(mc.ChannelID, mc.Author.ID)
+		if err != nil {
+			return errors.Wrap(err, "Failed to get permissions")
+		}
+
+		if !p.Has(cmd.Permissions) {
+			return &ErrMissingPermissions{
+				Command: ctx.Prefix + strings.Join(args[:start], " "),
+				Missing: cmd.Permissions &^ p,
+			}
+		}
+	}
+
+	if ctx.InvocationHook != nil {
+		defer ctx.invoked(mc, sub, cmd, time.Now(), &err)
+	}
 
 	// Start converting
 	var argv []reflect.Value
@@ -319,6 +343,34 @@ func (ctx *Context) callMessageCreate(mc *gateway.MessageCreateEvent) error {
 	}
 
 Call:
+	// Invocations with the wrong usage don't count for the cooldown.
+	if cmd.Cooldown != nil {
+		if wait, ok := cmd.Cooldown.use(mc.Author.ID); !ok {
+			return &ErrCooldown{
+				Command:   ctx.Prefix + strings.Join(args[:start], " "),
+				Remaining: wait,
+			}
+		}
+	}
+
+	if cmd.Concurrency != nil {
+		release, ok := cmd.Concurrency.acquire(mc)
+		if !ok {
+			// Rejected invocations don't count for the cooldown either.
+			if cmd.Cooldown != nil {
+				cmd.Cooldown.refund(mc.Author.ID)
+			}
+
+			return &ErrCommandBusy{
+				Command: ctx.Prefix + strings.Join(args[:start], " "),
+				Max:     cmd.Concurrency.Max,
+			}
+		}
+
+		// The slot is held until the reply is sent.
+		defer release()
+	}
+
 	// Try calling all middlewares first. We don't need to stack middlewares, as
 	// there will only be one command match.
 	for _, mw := range sub.mwMethods {
@@ -337,19 +389,54 @@ Call:
 	switch v := v.(type) {
 	case string:
 		v = sub.SanitizeMessage(v)
-		_, err = ctx.SendMessage(mc.ChannelID, v, nil)
+		err = ctx.sendReply(mc, api.SendMessageData{Content: v})
 	case *discord.Embed:
-		_, err = ctx.SendMessage(mc.ChannelID, "", v)
+		err = ctx.sendReply(mc, api.SendMessageData{Embed: v})
 	case *api.SendMessageData:
 		if v.Content != "" {
 			v.Content = sub.SanitizeMessage(v.Content)
 		}
-		_, err = ctx.SendMessageComplex(mc.ChannelID, *v)
+		err = ctx.sendReply(mc, *v)
 	}
 
 	return err
 }
 
+// sendReply sends the reply returned by a command in the channel, or as the
+// response of the interaction if the command is served as a slash command.
+// Files can't be in responses, so replies with files are always sent in the
+// channel.
+func (ctx *Context) sendReply(
+	mc *gateway.MessageCreateEvent, data api.SendMessageData) error {
+
+	if inv, ok := ctx.interactionOf(mc); ok && len(data.Files) == 0 {
+		return ctx.respondInteraction(inv, data, false)
+	}
+
+	_, err := ctx.SendMessageComplex(mc.ChannelID, data)
+	return err
+}
+
+// invoked reports the invocation to InvocationHook, once the command returns.
+func (ctx *Context) invoked(mc *gateway.MessageCreateEvent,
+	sub *Subcommand, cmd *CommandContext, start time.Time, err *error) {
+
+	var name = cmd.Command
+	if sub.Command != "" {
+		name = sub.Command + " " + name
+	}
+
+	ctx.InvocationHook(Invocation{
+		Command:   name,
+		UserID:    mc.Author.ID,
+		GuildID:   mc.GuildID,
+		ChannelID: mc.ChannelID,
+		Start:     start,
+		Duration:  time.Since(start),
+		Err:       *err,
+	})
+}
+
 func (ctx *Context) eventIsAdmin(ev interface{}, is **bool) bool {
 	if *is != nil {
 		return **is
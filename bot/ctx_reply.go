@@ -0,0 +1,100 @@
+package bot
+
+import (
+	"github.com/diamondburned/arikawa/api"
+	"github.com/diamondburned/arikawa/discord"
+	"github.com/diamondburned/arikawa/gateway"
+	"github.com/pkg/errors"
+)
+
+// Reply replies to a message or an interaction, so the same handler code can
+// answer both prefix and slash commands. The content is sanitized with
+// SanitizeMessage.
+//
+// Interactions are responded to with a message, which has to be done within 3
+// seconds of receiving them. Messages are replied to in their channel.
+func (ctx *Context) Reply(
+	ev interface{}, content string, embed *discord.Embed) error {
+
+	return ctx.reply(ev, content, embed, false)
+}
+
+// ReplyEphemeral replies to a message or an interaction with a message that
+// only the user who invoked it can see:
+//
+//	func (c *Commands) Secret(m *gateway.MessageCreateEvent) error {
+//		return c.Ctx.ReplyEphemeral(m, "Only you can see this.", nil)
+//	}
+//
+// Interactions get an ephemeral response. Messages can't have one, so the
+// reply is sent to the author in a DM instead, or in the channel if the author
+// can't be DMed.
+func (ctx *Context) ReplyEphemeral(
+	ev interface{}, content string, embed *discord.Embed) error {
+
+	return ctx.reply(ev, content, embed, true)
+}
+
+func (ctx *Context) reply(
+	ev interface{}, content string, embed *discord.Embed, ephemeral bool) error {
+
+	content = ctx.SanitizeMessage(content)
+
+	var data = api.SendMessageData{Content: content, Embed: embed}
+
+	switch ev := ev.(type) {
+	case *gateway.InteractionCreateEvent:
+		// Slash commands served as message commands replace their deferred
+		// response.
+		if v, ok := ctx.interactions.Load(ev.ID); ok {
+			inv := v.(*slashInvocation)
+			return ctx.respondInteraction(inv, data, ephemeral)
+		}
+
+		resp, err := ctx.interactionData(ev.ChannelID, data, ephemeral)
+		if err != nil {
+			return err
+		}
+
+		err = ctx.RespondInteraction(ev.ID, ev.Token,
+			discord.InteractionResponse{
+				Type: discord.MessageInteractionWithSource,
+				Data: &resp,
+			},
+		)
+		return errors.Wrap(err, "Failed to respond to interaction")
+
+	case *gateway.MessageCreateEvent:
+		// Slash commands served as message commands are replied to with the
+		// interaction.
+		if inv, ok := ctx.interactionOf(ev); ok {
+			return ctx.respondInteraction(inv, data, ephemeral)
+		}
+
+		if ephemeral && ev.GuildID.Valid() {
+			// The author may have DMs from the guild disabled, in which case
+			// the reply is sent in the channel.
+			if err := ctx.replyDM(ev.Author.ID, content, embed); err == nil {
+				return nil
+			}
+		}
+
+		_, err := ctx.SendMessage(ev.ChannelID, content, embed)
+		return errors.Wrap(err, "Failed to send reply")
+
+	default:
+		return errors.Errorf("Cannot reply to %T", ev)
+	}
+}
+
+func (ctx *Context) replyDM(
+	userID discord.Snowflake, content string, embed *discord.Embed) error {
+
+	dm, err := ctx.CreatePrivateChannel(userID)
+	if err != nil {
+		return err
+	}
+
+	_, err = ctx.SendMessage(dm.ID, content, embed)
+	return err
+}
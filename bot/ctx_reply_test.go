@@ -0,0 +1,138 @@
+// +build unit
+
+package bot
+
+import (
+	"io/ioutil"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/diamondburned/arikawa/api"
+	"github.com/diamondburned/arikawa/discord"
+	"github.com/diamondburned/arikawa/gateway"
+	"github.com/diamondburned/arikawa/session"
+	"github.com/diamondburned/arikawa/state"
+)
+
+type roundTripFunc func(r *http.Request) *http.Response
+
+func (fn roundTripFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return fn(r), nil
+}
+
+// newReplyContext returns a Context whose requests are recorded. Creating a DM
+// fails if failDM is true.
+func newReplyContext(t *testing.T, failDM bool) (*Context, *[]string) {
+	var requests []string
+
+	client := api.NewClient("")
+	client.Client.Client.Transport = roundTripFunc(
+		func(r *http.Request) *http.Response {
+			var body []byte
+			if r.Body != nil {
+				body, _ = ioutil.ReadAll(r.Body)
+			}
+			requests = append(requests,
+				r.Method+" "+strings.TrimPrefix(r.URL.Path, "/api/v6")+" "+
+					string(body))
+
+			var status, resp = 200, `{"id":"3"}`
+			if failDM && strings.HasSuffix(r.URL.Path, "/@me/channels") {
+				status, resp = 403, `{"code":50007}`
+			}
+
+			return &http.Response{
+				StatusCode: status,
+				Header:     http.Header{},
+				Body:       ioutil.NopCloser(strings.NewReader(resp)),
+			}
+		},
+	)
+
+	s := &state.State{
+		Session: &session.Session{Client: client},
+		Store:   state.NewDefaultStore(nil),
+	}
+
+	ctx, err := New(s, &testCommands{})
+	if err != nil {
+		t.Fatal("Failed to create context:", err)
+	}
+
+	return ctx, &requests
+}
+
+func TestReplyEphemeral(t *testing.T) {
+	var guildMessage = &gateway.MessageCreateEvent{
+		ChannelID: 1,
+		GuildID:   2,
+		Author:    discord.User{ID: 4},
+	}
+
+	var tests = []struct {
+		name     string
+		ev       interface{}
+		failDM   bool
+		requests []string
+	}{{
+		name: "interaction",
+		ev: &gateway.InteractionCreateEvent{
+			ID:    5,
+			Token: "token",
+		},
+		requests: []string{
+			`POST /interactions/5/token/callback ` +
+				`{"type":4,"data":{"content":"hi","flags":64}}`,
+		},
+	}, {
+		name: "guild message",
+		ev:   guildMessage,
+		requests: []string{
+			`POST /users/@me/channels {"recipient_id":4}`,
+			`POST /channels/3/messages {"content":"hi","tts":false}`,
+		},
+	}, {
+		name:   "guild message without DMs",
+		ev:     guildMessage,
+		failDM: true,
+		requests: []string{
+			`POST /users/@me/channels {"recipient_id":4}`,
+			`POST /channels/1/messages {"content":"hi","tts":false}`,
+		},
+	}, {
+		name: "direct message",
+		ev:   &gateway.MessageCreateEvent{ChannelID: 1},
+		requests: []string{
+			`POST /channels/1/messages {"content":"hi","tts":false}`,
+		},
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctx, requests := newReplyContext(t, test.failDM)
+
+			if err := ctx.ReplyEphemeral(test.ev, "hi", nil); err != nil {
+				t.Fatal("Failed to reply:", err)
+			}
+
+			for i := range *requests {
+				(*requests)[i] = strings.TrimSpace((*requests)[i])
+			}
+
+			if !reflect.DeepEqual(*requests, test.requests) {
+				t.Fatalf("Unexpected requests:\n%q\nexpected:\n%q",
+					*requests, test.requests)
+			}
+		})
+	}
+}
+
+func TestReplyUnknownEvent(t *testing.T) {
+	ctx, _ := newReplyContext(t, false)
+
+	if err := ctx.Reply(&gateway.TypingStartEvent{}, "hi", nil); err == nil {
+		t.Fatal("Replied to a typing event")
+	}
+}
@@ -218,6 +218,32 @@ func TestContext(t *testing.T) {
 			t.Fatal("unexpected error:", err)
 		}
 	})
+
+	t.Run("invocation hook", func(t *testing.T) {
+		ctx.Prefix = "run "
+
+		var invocations []Invocation
+		ctx.InvocationHook = func(i Invocation) {
+			invocations = append(invocations, i)
+		}
+		defer func() { ctx.InvocationHook = nil }()
+
+		testMessage("run testCommands noop")
+		testMessage("run noArgs")
+		testMessage("run unknown")
+
+		if len(invocations) != 2 {
+			t.Fatalf("Unexpected invocations: %+v", invocations)
+		}
+
+		if i := invocations[0]; i.Command != "testCommands noop" || i.Err != nil {
+			t.Fatalf("Unexpected subcommand invocation: %+v", i)
+		}
+
+		if i := invocations[1]; i.Command != "noArgs" || i.Err == nil {
+			t.Fatalf("Unexpected command invocation: %+v", i)
+		}
+	})
 }
 
 func BenchmarkConstructor(b *testing.B) {
@@ -8,9 +8,9 @@ import (
 )
 
 var (
-	ChannelRegex = regexp.MustCompile(`<#(\d+)>`)
-	UserRegex    = regexp.MustCompile(`<@!?(\d+)>`)
-	RoleRegex    = regexp.MustCompile(`<@&(\d+)>`)
+	ChannelRegex = discord.ChannelMentionRegex
+	UserRegex    = discord.UserMentionRegex
+	RoleRegex    = discord.RoleMentionRegex
 )
 
 //
@@ -0,0 +1,46 @@
+package bot
+
+import "github.com/diamondburned/arikawa/discord"
+
+type ErrMissingPermissions struct {
+	Command string
+	// Missing are the required permissions that the user doesn't have.
+	Missing discord.Permissions
+}
+
+func (err *ErrMissingPermissions) Error() string {
+	return MissingPermissionsString(err)
+}
+
+var MissingPermissionsString = func(err *ErrMissingPermissions) string {
+	return "You don't have the permissions to use " + err.Command + "."
+}
+
+// SetPermissions makes the matched methodName require the permissions in the
+// channel it's invoked in, such as discord.PermissionManageMessages, which
+// also makes it guild-only. Users without them get an ErrMissingPermissions.
+// The returned bool is true when the method is found.
+func (sub *Subcommand) SetPermissions(
+	methodName string, perms discord.Permissions) bool {
+
+	c := sub.findCommand(methodName)
+	if c == nil {
+		return false
+	}
+
+	c.Permissions = perms
+	c.Flag |= GuildOnly
+	return true
+}
+
+// SetGuildOnly makes the matched methodName ignored outside of guilds, like
+// the G flag. The returned bool is true when the method is found.
+func (sub *Subcommand) SetGuildOnly(methodName string) bool {
+	c := sub.findCommand(methodName)
+	if c == nil {
+		return false
+	}
+
+	c.Flag |= GuildOnly
+	return true
+}
@@ -0,0 +1,96 @@
+// +build unit
+
+package bot
+
+import (
+	"testing"
+	"time"
+
+	"github.com/diamondburned/arikawa/api"
+	"github.com/diamondburned/arikawa/discord"
+	"github.com/diamondburned/arikawa/gateway"
+	"github.com/diamondburned/arikawa/session"
+	"github.com/diamondburned/arikawa/state"
+	"github.com/pkg/errors"
+)
+
+func TestCommandRestrictions(t *testing.T) {
+	s := &state.State{
+		Session: &session.Session{Client: api.NewClient("")},
+		Store:   state.NewDefaultStore(nil),
+	}
+
+	// User 2 can manage messages, but user 3 can't.
+	s.Store.GuildSet(&discord.Guild{
+		ID: 1,
+		Roles: []discord.Role{
+			{ID: 1},
+			{ID: 5, Permissions: discord.PermissionManageMessages},
+		},
+	})
+	s.Store.ChannelSet(&discord.Channel{ID: 10, GuildID: 1})
+	s.Store.MemberSet(1, &discord.Member{
+		User:    discord.User{ID: 2},
+		RoleIDs: []discord.Snowflake{5},
+	})
+	s.Store.MemberSet(1, &discord.Member{User: discord.User{ID: 3}})
+
+	sub, err := NewSubcommand(&testCommands{})
+	if err != nil {
+		t.Fatal("Failed to create subcommand:", err)
+	}
+
+	var ctx = &Context{Subcommand: sub, State: s, Prefix: "!"}
+	if err := ctx.Subcommand.InitCommands(ctx); err != nil {
+		t.Fatal("Failed to init commands:", err)
+	}
+
+	var purged int
+	ctx.AddFunc("Purge", func(*gateway.MessageCreateEvent) error {
+		purged++
+		return nil
+	})
+
+	if !ctx.SetPermissions("Purge", discord.PermissionManageMessages) {
+		t.Fatal("Purge command not found")
+	}
+	if !ctx.SetCooldown("Purge", time.Minute) {
+		t.Fatal("Purge command not found")
+	}
+
+	var purge = func(channelID, guildID, userID discord.Snowflake) error {
+		return ctx.callCmd(&gateway.MessageCreateEvent{
+			ChannelID: channelID,
+			GuildID:   guildID,
+			Author:    discord.User{ID: userID},
+			Content:   "!purge",
+		})
+	}
+
+	if err := purge(10, 1, 2); err != nil {
+		t.Fatal("Failed to purge:", err)
+	}
+
+	err = purge(10, 1, 2)
+	if _, ok := errors.Cause(err).(*ErrCooldown); !ok {
+		t.Fatal("Expected a cooldown error, got", err)
+	}
+
+	err = purge(10, 1, 3)
+	missing, ok := errors.Cause(err).(*ErrMissingPermissions)
+	if !ok {
+		t.Fatal("Expected a missing permissions error, got", err)
+	}
+	if missing.Missing != discord.PermissionManageMessages {
+		t.Fatal("Unexpected missing permissions:", missing.Missing)
+	}
+
+	// Commands that require permissions are guild-only.
+	if err := purge(20, 0, 3); err != nil {
+		t.Fatal("Unexpected error in a DM:", err)
+	}
+
+	if purged != 1 {
+		t.Fatal("Unexpected number of purges:", purged)
+	}
+}
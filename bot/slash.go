@@ -0,0 +1,507 @@
+package bot
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/diamondburned/arikawa/api"
+	"github.com/diamondburned/arikawa/discord"
+	"github.com/diamondburned/arikawa/gateway"
+	"github.com/pkg/errors"
+)
+
+// InteractionDoneString is the ephemeral response of the slash commands that
+// didn't reply, as Discord shows interactions without a response as failed.
+var InteractionDoneString = "Done."
+
+var typeSnowflake = reflect.TypeOf(discord.Snowflake(0))
+
+// argQuoter escapes the values of options, which are quoted for ParseArgs.
+var argQuoter = strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+
+// slashInvocation is a slash command being served as a message command. The
+// interaction is deferred until the first reply.
+type slashInvocation struct {
+	ev *gateway.InteractionCreateEvent
+
+	mutex sync.Mutex
+	// responded is true once the deferred response was replaced by a reply.
+	responded bool
+}
+
+// SetSlash makes the matched methodName also served as a slash command, so
+// the same method answers both. The slash command has an option for each
+// argument, named after optionNames in order, or "arg1", "arg2" and so on.
+// Commands of subcommands are slash subcommands.
+//
+// The options of the interaction are turned into the arguments of a message
+// command, which is run like one, with a MessageCreateEvent whose ID is the
+// ID of the interaction. The interaction is deferred first, as commands could
+// take longer than the 3 seconds Discord waits for a response. The returned
+// reply, and the ones of Reply, then replace the deferred response, or are
+// sent as followups. They go through the BeforeSend hook of the Client like
+// other messages.
+//
+// The slash commands are registered with SyncSlashCommands, or when the bot
+// starts if SyncSlash is true. The returned bool is true when the method is
+// found.
+func (sub *Subcommand) SetSlash(methodName string, optionNames ...string) bool {
+	c := sub.findCommand(methodName)
+	if c == nil {
+		return false
+	}
+
+	c.Slash = true
+	c.OptionNames = optionNames
+	return true
+}
+
+// SlashCommands returns the slash commands of the commands set with SetSlash.
+func (ctx *Context) SlashCommands() []api.CreateCommandData {
+	var cmds = []api.CreateCommandData{}
+
+	for _, cmd := range ctx.Commands {
+		// Plumbed commands have no name.
+		if !cmd.Slash || cmd.Command == "" {
+			continue
+		}
+
+		cmds = append(cmds, api.CreateCommandData{
+			Name:        slashName(cmd.Command),
+			Description: slashDescription(cmd.Description, cmd.Command),
+			Options:     cmd.slashOptions(),
+		})
+	}
+
+	for _, sub := range ctx.subcommands {
+		var data = api.CreateCommandData{
+			Name:        slashName(sub.Command),
+			Description: slashDescription(sub.Description, sub.Command),
+		}
+		var found bool
+
+		for _, cmd := range sub.Commands {
+			if !cmd.Slash {
+				continue
+			}
+
+			found = true
+
+			// Plumbed subcommands only have the one command.
+			if sub.plumb {
+				data.Options = cmd.slashOptions()
+				break
+			}
+
+			data.Options = append(data.Options, discord.CommandOption{
+				Type:        discord.SubcommandOption,
+				Name:        slashName(cmd.Command),
+				Description: slashDescription(cmd.Description, cmd.Command),
+				Options:     cmd.slashOptions(),
+			})
+		}
+
+		if found {
+			cmds = append(cmds, data)
+		}
+	}
+
+	return cmds
+}
+
+// SyncSlashCommands overwrites the slash commands of the application with
+// SlashCommands, in the guild if guildID is valid, where they show up
+// instantly, or globally otherwise. The application ID is the one of the
+// Ready event, which is also the ID of the bot for most bots.
+func (ctx *Context) SyncSlashCommands(guildID discord.Snowflake) error {
+	return ctx.syncSlash(readyAppID(&ctx.Ready), guildID)
+}
+
+func (ctx *Context) syncSlash(appID, guildID discord.Snowflake) error {
+	if !appID.Valid() {
+		return errors.New("Unknown application ID, the bot isn't ready yet")
+	}
+
+	var err error
+	if guildID.Valid() {
+		_, err = ctx.BulkOverwriteGuildCommands(
+			appID, guildID, ctx.SlashCommands())
+	} else {
+		_, err = ctx.BulkOverwriteCommands(appID, ctx.SlashCommands())
+	}
+
+	return errors.Wrap(err, "Failed to overwrite slash commands")
+}
+
+// readyAppID returns the ID of the application of the bot.
+func readyAppID(r *gateway.ReadyEvent) discord.Snowflake {
+	if r.Application != nil {
+		return r.Application.ID
+	}
+	return r.User.ID
+}
+
+// callInteraction runs the command of a slash command interaction.
+func (ctx *Context) callInteraction(ev *gateway.InteractionCreateEvent) error {
+	if ev.Type != discord.CommandInteraction || ev.Data == nil {
+		return nil
+	}
+
+	content, ok, usageErr := ctx.interactionContent(ev.Data)
+	if !ok {
+		return nil
+	}
+
+	var mc = &gateway.MessageCreateEvent{
+		ID:        ev.ID,
+		ChannelID: ev.ChannelID,
+		GuildID:   ev.GuildID,
+		Content:   content,
+		Member:    ev.Member,
+	}
+
+	switch {
+	case ev.Member != nil:
+		mc.Author = ev.Member.User
+	case ev.User != nil:
+		mc.Author = *ev.User
+	}
+
+	var inv = &slashInvocation{ev: ev}
+
+	ctx.interactions.Store(ev.ID, inv)
+	defer ctx.interactions.Delete(ev.ID)
+
+	err := ctx.RespondInteraction(ev.ID, ev.Token, discord.InteractionResponse{
+		Type: discord.DeferredMessageInteractionWithSource,
+	})
+	if err != nil {
+		return errors.Wrap(err, "Failed to defer interaction response")
+	}
+
+	if err = usageErr; err == nil {
+		err = ctx.callMessageCreate(mc)
+	}
+
+	if err == nil {
+		if !inv.isResponded() {
+			err = ctx.ReplyEphemeral(mc, InteractionDoneString, nil)
+		}
+
+		return err
+	}
+
+	// Start only replies errors to messages, so they're replied here.
+	if ctx.ReplyError {
+		if str := ctx.FormatError(err); str != "" {
+			if err := ctx.ReplyEphemeral(mc, str, nil); err != nil {
+				ctx.ErrorLogger(err)
+			}
+		}
+	}
+
+	// The deferred response would otherwise be loading until it expires.
+	if !inv.isResponded() {
+		err := ctx.DeleteInteractionResponse(ev.AppID, ev.Token)
+		if err != nil {
+			ctx.ErrorLogger(
+				errors.Wrap(err, "Failed to delete deferred response"))
+		}
+	}
+
+	return err
+}
+
+func (inv *slashInvocation) isResponded() bool {
+	inv.mutex.Lock()
+	defer inv.mutex.Unlock()
+
+	return inv.responded
+}
+
+// interactionContent returns the content of the message command that's
+// equivalent to the interaction, or false if the interaction isn't one of the
+// slash commands. The error is an ErrInvalidUsage if an option is missing.
+func (ctx *Context) interactionContent(
+	data *discord.InteractionData) (string, bool, error) {
+
+	var cmd *CommandContext
+	var args []string
+	var options = data.Options
+
+	for _, c := range ctx.Commands {
+		if c.Command != "" && slashName(c.Command) == data.Name {
+			cmd = c
+			args = []string{c.Command}
+			break
+		}
+	}
+
+	if cmd == nil {
+		for _, sub := range ctx.subcommands {
+			if slashName(sub.Command) != data.Name {
+				continue
+			}
+
+			if sub.plumb {
+				cmd = sub.Commands[0]
+				args = []string{sub.Command}
+				break
+			}
+
+			var isSub = len(options) == 1 &&
+				options[0].Type == discord.SubcommandOption
+			if !isSub {
+				break
+			}
+
+			for _, c := range sub.Commands {
+				if slashName(c.Command) == options[0].Name {
+					cmd = c
+					args = []string{sub.Command, c.Command}
+					break
+				}
+			}
+
+			options = options[0].Options
+			break
+		}
+	}
+
+	if cmd == nil || !cmd.Slash {
+		return "", false, nil
+	}
+
+	// The arguments are positional, while options are given by name, in any
+	// order, and optional ones may be missing.
+	for i, arg := range cmd.Arguments {
+		var name = cmd.optionName(i)
+
+		opt, ok := findOption(options, name)
+		switch {
+		case ok && arg.fn == nil:
+			// Manual and custom parsers take the content as it is.
+			args = append(args, optionValue(opt))
+		case ok:
+			args = append(args, `"`+argQuoter.Replace(optionValue(opt))+`"`)
+		case arg.fn == nil:
+			// Optional, and it takes the rest of the content, which is
+			// then empty.
+		default:
+			return "", true, &ErrInvalidUsage{
+				Args:   args,
+				Prefix: ctx.Prefix,
+				Err:    "Missing option " + name,
+				Ctx:    cmd,
+			}
+		}
+	}
+
+	return ctx.Prefix + strings.Join(args, " "), true, nil
+}
+
+func findOption(options []discord.InteractionOption,
+	name string) (discord.InteractionOption, bool) {
+
+	for _, opt := range options {
+		if opt.Name == name {
+			return opt, true
+		}
+	}
+	return discord.InteractionOption{}, false
+}
+
+// optionValue returns the value of the option as it would be in a message.
+func optionValue(opt discord.InteractionOption) string {
+	switch opt.Type {
+	case discord.UserOption:
+		return "<@" + opt.String() + ">"
+	case discord.ChannelOption:
+		return "<#" + opt.String() + ">"
+	case discord.RoleOption:
+		return "<@&" + opt.String() + ">"
+	default:
+		return opt.String()
+	}
+}
+
+// respondInteraction replaces the deferred response of the interaction with
+// the message, or sends it as a followup if it was already replaced. The
+// message goes through the BeforeSend hook of the Client first, with the
+// channel of the interaction.
+func (ctx *Context) respondInteraction(inv *slashInvocation,
+	data api.SendMessageData, ephemeral bool) error {
+
+	resp, err := ctx.interactionData(inv.ev.ChannelID, data, ephemeral)
+	if err != nil {
+		return err
+	}
+
+	inv.mutex.Lock()
+	defer inv.mutex.Unlock()
+
+	var appID, token = inv.ev.AppID, inv.ev.Token
+
+	// The deferred response is visible to everyone, so it can only be
+	// replaced by a message that isn't ephemeral. Otherwise, it's deleted,
+	// and the message is sent as a followup.
+	if !inv.responded && !ephemeral {
+		var edit = api.EditInteractionResponseData{
+			Content:         resp.Content,
+			Embeds:          resp.Embeds,
+			AllowedMentions: resp.AllowedMentions,
+		}
+		if len(resp.Components) > 0 {
+			edit.Components = &resp.Components
+		}
+
+		_, err := ctx.EditInteractionResponse(appID, token, edit)
+		if err != nil {
+			return errors.Wrap(err, "Failed to respond to interaction")
+		}
+
+		inv.responded = true
+		return nil
+	}
+
+	if !inv.responded {
+		if err := ctx.DeleteInteractionResponse(appID, token); err != nil {
+			return errors.Wrap(err, "Failed to delete deferred response")
+		}
+
+		inv.responded = true
+	}
+
+	_, err = ctx.CreateFollowupMessage(appID, token, resp)
+	return errors.Wrap(err, "Failed to send followup message")
+}
+
+// interactionData turns the message into the data of an interaction response,
+// after passing it to the BeforeSend hook of the Client.
+func (ctx *Context) interactionData(channelID discord.Snowflake,
+	data api.SendMessageData,
+	ephemeral bool) (discord.InteractionResponseData, error) {
+
+	if ctx.BeforeSend != nil {
+		if err := ctx.BeforeSend(channelID, &data); err != nil {
+			return discord.InteractionResponseData{},
+				errors.Wrap(err, "Message rejected")
+		}
+	}
+
+	var resp = discord.InteractionResponseData{
+		TTS:             data.TTS,
+		Content:         data.Content,
+		Components:      data.Components,
+		AllowedMentions: data.AllowedMentions,
+	}
+	if data.Embed != nil {
+		resp.Embeds = []discord.Embed{*data.Embed}
+	}
+	if ephemeral {
+		resp.Flags = discord.EphemeralMessage
+	}
+
+	return resp, nil
+}
+
+// interactionOf returns the interaction that the message was made from, if
+// it's a slash command served as a message command.
+func (ctx *Context) interactionOf(
+	mc *gateway.MessageCreateEvent) (*slashInvocation, bool) {
+
+	v, ok := ctx.interactions.Load(mc.ID)
+	if !ok {
+		return nil, false
+	}
+	return v.(*slashInvocation), true
+}
+
+func (cmd *CommandContext) optionName(i int) string {
+	if i < len(cmd.OptionNames) {
+		return cmd.OptionNames[i]
+	}
+	return "arg" + strconv.Itoa(i+1)
+}
+
+func (cmd *CommandContext) slashOptions() []discord.CommandOption {
+	var options = make([]discord.CommandOption, len(cmd.Arguments))
+
+	for i, arg := range cmd.Arguments {
+		options[i] = discord.CommandOption{
+			Type:        slashOptionType(arg),
+			Name:        cmd.optionName(i),
+			Description: slashDescription("", arg.String),
+			Required:    true,
+		}
+
+		// Manual and custom parsers take the rest of the content, which may
+		// be empty.
+		if arg.fn == nil {
+			options[i].Type = discord.StringOption
+			options[i].Required = false
+		}
+	}
+
+	return options
+}
+
+// slashOptionType returns the option type of the argument. Mentions, such as
+// the ones of the arguments package, are recognized by their Usage.
+func slashOptionType(arg Argument) discord.CommandOptionType {
+	var t = arg.Type
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	usager, ok := reflect.New(t).Interface().(interface{ Usage() string })
+	if ok {
+		switch usager.Usage() {
+		case "@user":
+			return discord.UserOption
+		case "#channel":
+			return discord.ChannelOption
+		case "@role":
+			return discord.RoleOption
+		}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return discord.BooleanOption
+	case reflect.Float32, reflect.Float64:
+		return discord.NumberOption
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32,
+		reflect.Uint64:
+
+		// Snowflakes are too large for integer options.
+		if t != typeSnowflake {
+			return discord.IntegerOption
+		}
+	}
+
+	return discord.StringOption
+}
+
+// slashName returns the name of a command as a slash command, which must be
+// lowercase.
+func slashName(command string) string {
+	return strings.ToLower(command)
+}
+
+// slashDescription returns the description, or the fallback if it's empty,
+// cut to the 100 characters that slash commands can have.
+func slashDescription(desc, fallback string) string {
+	if desc == "" {
+		desc = fallback
+	}
+
+	if runes := []rune(desc); len(runes) > 100 {
+		desc = string(runes[:99]) + "…"
+	}
+
+	return desc
+}
@@ -0,0 +1,205 @@
+// +build unit
+
+package bot
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/diamondburned/arikawa/api"
+	"github.com/diamondburned/arikawa/bot/extras/arguments"
+	"github.com/diamondburned/arikawa/discord"
+	"github.com/diamondburned/arikawa/gateway"
+	"github.com/diamondburned/arikawa/internal/json"
+	"github.com/pkg/errors"
+)
+
+type testSlashCommands struct {
+	Ctx    *Context
+	Kicked discord.Snowflake
+}
+
+func (t *testSlashCommands) Setup(sub *Subcommand) {
+	sub.Description = "Moderation"
+	sub.SetSlash("Kick", "user", "reason")
+	sub.ChangeCommandInfo("Kick", "", "Kicks a user.")
+}
+
+func (t *testSlashCommands) Kick(m *gateway.MessageCreateEvent,
+	user *arguments.UserMention, reason string) (string, error) {
+
+	t.Kicked = user.ID()
+	return "Kicked " + user.Mention() + ": " + reason, nil
+}
+
+func TestSlashCommands(t *testing.T) {
+	ctx, _ := newReplyContext(t, false)
+	ctx.MustRegisterSubcommand(&testSlashCommands{})
+
+	if !ctx.SetSlash("Send", "text") {
+		t.Fatal("Send command not found")
+	}
+
+	var expect = []api.CreateCommandData{{
+		Name:        "send",
+		Description: "send",
+		Options: []discord.CommandOption{{
+			Type:        discord.StringOption,
+			Name:        "text",
+			Description: "string",
+			Required:    true,
+		}},
+	}, {
+		Name:        "testslashcommands",
+		Description: "Moderation",
+		Options: []discord.CommandOption{{
+			Type:        discord.SubcommandOption,
+			Name:        "kick",
+			Description: "Kicks a user.",
+			Options: []discord.CommandOption{{
+				Type:        discord.UserOption,
+				Name:        "user",
+				Description: "*arguments.UserMention",
+				Required:    true,
+			}, {
+				Type:        discord.StringOption,
+				Name:        "reason",
+				Description: "string",
+				Required:    true,
+			}},
+		}},
+	}}
+
+	if cmds := ctx.SlashCommands(); !reflect.DeepEqual(cmds, expect) {
+		t.Fatalf("Unexpected slash commands:\n%+v\nexpected:\n%+v",
+			cmds, expect)
+	}
+}
+
+func slashOption(name string, typ discord.CommandOptionType,
+	value string) discord.InteractionOption {
+
+	b, _ := (json.Default{}).Marshal(value)
+	return discord.InteractionOption{Name: name, Type: typ, Value: b}
+}
+
+func kickInteraction(options ...discord.InteractionOption) interface{} {
+	return &gateway.InteractionCreateEvent{
+		ID:    5,
+		AppID: 7,
+		Type:  discord.CommandInteraction,
+		Token: "token",
+		User:  &discord.User{ID: 4},
+		Data: &discord.InteractionData{
+			Name: "testslashcommands",
+			Options: []discord.InteractionOption{{
+				Name:    "kick",
+				Type:    discord.SubcommandOption,
+				Options: options,
+			}},
+		},
+	}
+}
+
+func assertRequests(t *testing.T, requests *[]string, expect []string) {
+	t.Helper()
+
+	for i := range *requests {
+		(*requests)[i] = strings.TrimSpace((*requests)[i])
+	}
+
+	if !reflect.DeepEqual(*requests, expect) {
+		t.Fatalf("Unexpected requests:\n%q\nexpected:\n%q", *requests, expect)
+	}
+}
+
+func TestSlashInteraction(t *testing.T) {
+	ctx, requests := newReplyContext(t, false)
+	ctx.Prefix = "!"
+	ctx.AllowedMentions = &discord.AllowedMentions{}
+	ctx.BeforeSend = func(_ discord.Snowflake, d *api.SendMessageData) error {
+		d.Content = strings.ReplaceAll(d.Content, "spam", "****")
+		return nil
+	}
+
+	var cmds = &testSlashCommands{}
+	ctx.MustRegisterSubcommand(cmds)
+
+	// The options are out of order.
+	err := ctx.Call(kickInteraction(
+		slashOption("reason", discord.StringOption, `spam "links"`),
+		slashOption("user", discord.UserOption, "2"),
+	))
+	if err != nil {
+		t.Fatal("Failed to call interaction:", err)
+	}
+
+	if cmds.Kicked != 2 {
+		t.Fatal("Unexpected user kicked:", cmds.Kicked)
+	}
+
+	// The interaction is deferred, and then the deferred response is
+	// replaced, with the default allowed mentions.
+	assertRequests(t, requests, []string{
+		`POST /interactions/5/token/callback {"type":5}`,
+		`PATCH /webhooks/7/token/messages/@original ` +
+			`{"content":"Kicked \u003c@2\u003e: **** \"links\"",` +
+			`"allowed_mentions":{"parse":[]}}`,
+	})
+
+	if _, ok := ctx.interactions.Load(discord.Snowflake(5)); ok {
+		t.Fatal("Interaction wasn't forgotten")
+	}
+}
+
+func TestSlashInteractionMissingOption(t *testing.T) {
+	ctx, requests := newReplyContext(t, false)
+	ctx.ReplyError = true
+
+	var cmds = &testSlashCommands{}
+	ctx.MustRegisterSubcommand(cmds)
+
+	// The reason isn't given as the user.
+	err := ctx.Call(kickInteraction(
+		slashOption("reason", discord.StringOption, "2"),
+	))
+	if _, ok := errors.Cause(err).(*ErrInvalidUsage); !ok {
+		t.Fatal("Expected an invalid usage error, got", err)
+	}
+
+	if cmds.Kicked.Valid() {
+		t.Fatal("Command was called without the user:", cmds.Kicked)
+	}
+
+	assertRequests(t, requests, []string{
+		`POST /interactions/5/token/callback {"type":5}`,
+		`DELETE /webhooks/7/token/messages/@original`,
+		`POST /webhooks/7/token ` +
+			`{"content":"Invalid usage, error: Missing option user",` +
+			`"flags":64}`,
+	})
+}
+
+func TestSlashInteractionNoReply(t *testing.T) {
+	ctx, requests := newReplyContext(t, false)
+	ctx.SetSlash("Noop")
+
+	err := ctx.Call(&gateway.InteractionCreateEvent{
+		ID:    5,
+		AppID: 7,
+		Type:  discord.CommandInteraction,
+		Token: "token",
+		Data:  &discord.InteractionData{Name: "noop"},
+	})
+	if err != nil {
+		t.Fatal("Failed to call interaction:", err)
+	}
+
+	// The deferred response is replaced by an ephemeral one.
+	assertRequests(t, requests, []string{
+		`POST /interactions/5/token/callback {"type":5}`,
+		`DELETE /webhooks/7/token/messages/@original`,
+		`POST /webhooks/7/token {"content":"Done.","flags":64}`,
+	})
+}
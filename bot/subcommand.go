@@ -90,6 +90,21 @@ type CommandContext struct {
 	retType reflect.Type
 
 	Arguments []Argument
+
+	// Concurrency, if not nil, limits the instances of the command that run
+	// at once. See SetConcurrency.
+	Concurrency *Concurrency
+	// Cooldown, if not nil, limits how often each user can invoke the
+	// command. See SetCooldown.
+	Cooldown *Cooldown
+	// Permissions are the permissions the user needs in the channel to invoke
+	// the command. See SetPermissions.
+	Permissions discord.Permissions
+
+	// Slash, if true, also serves the command as a slash command, whose
+	// options are named after OptionNames. See SetSlash.
+	Slash       bool
+	OptionNames []string
 }
 
 // CanSetup is used for subcommands to change variables, such as Description.
@@ -168,6 +183,17 @@ func (sub *Subcommand) ChangeCommandInfo(methodName, cmd, desc string) bool {
 	return false
 }
 
+// findCommand returns the command of the matched methodName, or nil.
+func (sub *Subcommand) findCommand(methodName string) *CommandContext {
+	for _, c := range sub.Commands {
+		if c.MethodName == methodName {
+			return c
+		}
+	}
+
+	return nil
+}
+
 func (sub *Subcommand) Help(prefix, indent string, hideAdmin bool) string {
 	if sub.Flag.Is(AdminOnly) && hideAdmin {
 		return ""
@@ -291,156 +317,213 @@ func (sub *Subcommand) parseCommands() error {
 			continue
 		}
 
-		methodT := method.Type()
-		numArgs := methodT.NumIn()
-
-		if numArgs == 0 {
-			// Doesn't meet the requirement for an event, continue.
-			continue
-		}
-
-		if methodT == typeSetupFn {
+		if method.Type() == typeSetupFn {
 			// Method is a setup method, continue.
 			continue
 		}
 
-		// Check number of returns:
-		numOut := methodT.NumOut()
-		if numOut == 0 || numOut > 2 {
-			continue
-		}
+		var reflected = sub.ptrType.Method(i)
 
-		// Check the last return's type:
-		if i := methodT.Out(numOut - 1); i == nil || !i.Implements(typeIError) {
-			// Invalid, skip.
-			continue
+		command, err := sub.parseCommand(reflected.Name, method)
+		if err != nil {
+			return err
 		}
-
-		var command = CommandContext{
-			method: sub.ptrType.Method(i),
-			value:  method,
-			event:  methodT.In(0), // parse event
+		if command != nil {
+			command.method = reflected
 		}
+	}
 
-		// Parse the method name
-		flag, name := ParseFlag(command.method.Name)
+	return nil
+}
 
-		// Set the method name, command, and flag:
-		command.MethodName = name
-		command.Command = name
-		command.Flag = flag
+// AddFunc registers a function as a command, such as for commands that are
+// made at runtime. The name is parsed like the name of a method, so it can
+// have flags, and fn must have the signature of a command method:
+//
+//	sub.AddFunc("Roll", func(
+//		m *gateway.MessageCreateEvent, sides int) (string, error) {
+//
+//		return strconv.Itoa(rand.Intn(sides) + 1), nil
+//	})
+//
+// Functions that take another event than MessageCreateEvent are added as event
+// handlers. The returned CommandContext can be given a Description for the
+// help. AddFunc should be called before the Context is started.
+func (sub *Subcommand) AddFunc(
+	name string, fn interface{}) (*CommandContext, error) {
+
+	if name == "" {
+		return nil, errors.New("Missing command name")
+	}
 
-		// Check if Raw is enabled for command:
-		if !flag.Is(Raw) {
-			command.Command = lowerFirstLetter(name)
-		}
+	var v = reflect.ValueOf(fn)
+	if v.Kind() != reflect.Func {
+		return nil, errors.New("Command " + name + " is not a function")
+	}
 
-		// Middlewares shouldn't even have arguments.
-		if flag.Is(Middleware) {
-			sub.mwMethods = append(sub.mwMethods, &command)
-			continue
-		}
+	if sub.plumb {
+		return nil, errors.New("Subcommand is plumbed, so it has no commands")
+	}
 
-		// TODO: allow more flexibility
-		if command.event != typeMessageCreate || flag.Is(Hidden) {
-			sub.Events = append(sub.Events, &command)
-			continue
-		}
+	command, err := sub.parseCommand(name, v)
+	if err != nil {
+		return nil, err
+	}
+	if command == nil {
+		return nil, errors.New("Command " + name + " has an invalid signature")
+	}
 
-		// See if we know the first return type, if error's return is the
-		// second:
-		if numOut > 1 {
-			switch t := methodT.Out(0); t {
-			case typeString, typeEmbed, typeSend:
-				// noop, passes
-			default:
-				continue
-			}
-		}
+	return command, nil
+}
 
-		// If a plumb method has been found:
-		if sub.plumb {
-			continue
-		}
+// parseCommand adds the function as a command, an event handler or a
+// middleware, depending on its name and signature. Nil is returned if the
+// function isn't any of them.
+func (sub *Subcommand) parseCommand(
+	name string, method reflect.Value) (*CommandContext, error) {
 
-		// If the method only takes an event:
-		if numArgs == 1 {
-			sub.Commands = append(sub.Commands, &command)
-			continue
-		}
+	methodT := method.Type()
+	numArgs := methodT.NumIn()
 
-		// The argument's second argument (the first is the event).
-		var inT = methodT.In(1)
-		var ptr bool
+	if numArgs == 0 {
+		// Doesn't meet the requirement for an event, continue.
+		return nil, nil
+	}
 
-		if inT.Kind() != reflect.Ptr {
-			inT = reflect.PtrTo(inT)
-			ptr = true
-		}
+	// Check number of returns:
+	numOut := methodT.NumOut()
+	if numOut == 0 || numOut > 2 {
+		return nil, nil
+	}
 
-		// If the second argument implements CustomParse()
-		if t := inT; t.Implements(typeICusP) {
-			mt, _ := inT.MethodByName("CustomParse")
+	// Check the last return's type:
+	if i := methodT.Out(numOut - 1); i == nil || !i.Implements(typeIError) {
+		// Invalid, skip.
+		return nil, nil
+	}
+
+	var command = CommandContext{
+		value: method,
+		event: methodT.In(0), // parse event
+	}
 
-			if t.Kind() == reflect.Ptr {
-				t = t.Elem()
-			}
+	// Parse the method name
+	flag, name := ParseFlag(name)
 
-			command.Arguments = []Argument{{
-				String:  t.String(),
-				Type:    t,
-				pointer: ptr,
-				custom:  &mt,
-			}}
+	// Set the method name, command, and flag:
+	command.MethodName = name
+	command.Command = name
+	command.Flag = flag
 
-			goto Done
+	// Check if Raw is enabled for command:
+	if !flag.Is(Raw) {
+		command.Command = lowerFirstLetter(name)
+	}
+
+	// Middlewares shouldn't even have arguments.
+	if flag.Is(Middleware) {
+		sub.mwMethods = append(sub.mwMethods, &command)
+		return &command, nil
+	}
+
+	// TODO: allow more flexibility
+	if command.event != typeMessageCreate || flag.Is(Hidden) {
+		sub.Events = append(sub.Events, &command)
+		return &command, nil
+	}
+
+	// See if we know the first return type, if error's return is the
+	// second:
+	if numOut > 1 {
+		switch t := methodT.Out(0); t {
+		case typeString, typeEmbed, typeSend:
+			// noop, passes
+		default:
+			return nil, nil
 		}
+	}
 
-		// If the second argument implements ParseContent()
-		if t := inT; t.Implements(typeIManP) {
-			mt, _ := inT.MethodByName("ParseContent")
+	// If a plumb method has been found:
+	if sub.plumb {
+		return nil, nil
+	}
 
-			if t.Kind() == reflect.Ptr {
-				t = t.Elem()
-			}
+	// If the method only takes an event:
+	if numArgs == 1 {
+		sub.Commands = append(sub.Commands, &command)
+		return &command, nil
+	}
+
+	// The argument's second argument (the first is the event).
+	var inT = methodT.In(1)
+	var ptr bool
+
+	if inT.Kind() != reflect.Ptr {
+		inT = reflect.PtrTo(inT)
+		ptr = true
+	}
 
-			command.Arguments = []Argument{{
-				String:  t.String(),
-				Type:    t,
-				pointer: ptr,
-				manual:  &mt,
-			}}
+	// If the second argument implements CustomParse()
+	if t := inT; t.Implements(typeICusP) {
+		mt, _ := inT.MethodByName("CustomParse")
 
-			goto Done
+		if t.Kind() == reflect.Ptr {
+			t = t.Elem()
 		}
 
-		command.Arguments = make([]Argument, 0, numArgs)
+		command.Arguments = []Argument{{
+			String:  t.String(),
+			Type:    t,
+			pointer: ptr,
+			custom:  &mt,
+		}}
+
+		goto Done
+	}
 
-		// Fill up arguments
-		for i := 1; i < numArgs; i++ {
-			t := methodT.In(i)
-			a, err := getArgumentValueFn(t)
-			if err != nil {
-				return errors.Wrap(err, "Error parsing argument "+t.String())
-			}
+	// If the second argument implements ParseContent()
+	if t := inT; t.Implements(typeIManP) {
+		mt, _ := inT.MethodByName("ParseContent")
 
-			command.Arguments = append(command.Arguments, *a)
+		if t.Kind() == reflect.Ptr {
+			t = t.Elem()
 		}
 
-	Done:
-		// If the current event is a plumb event:
-		if flag.Is(Plumb) {
-			command.Command = "" // plumbers don't have names
-			sub.Commands = []*CommandContext{&command}
-			sub.plumb = true
-			continue
+		command.Arguments = []Argument{{
+			String:  t.String(),
+			Type:    t,
+			pointer: ptr,
+			manual:  &mt,
+		}}
+
+		goto Done
+	}
+
+	command.Arguments = make([]Argument, 0, numArgs)
+
+	// Fill up arguments
+	for i := 1; i < numArgs; i++ {
+		t := methodT.In(i)
+		a, err := getArgumentValueFn(t)
+		if err != nil {
+			return nil, errors.Wrap(err, "Error parsing argument "+t.String())
 		}
 
-		// Append
-		sub.Commands = append(sub.Commands, &command)
+		command.Arguments = append(command.Arguments, *a)
 	}
 
-	return nil
+Done:
+	// If the current event is a plumb event:
+	if flag.Is(Plumb) {
+		command.Command = "" // plumbers don't have names
+		sub.Commands = []*CommandContext{&command}
+		sub.plumb = true
+		return &command, nil
+	}
+
+	// Append
+	sub.Commands = append(sub.Commands, &command)
+	return &command, nil
 }
 
 func lowerFirstLetter(name string) string {
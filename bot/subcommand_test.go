@@ -2,7 +2,13 @@
 
 package bot
 
-import "testing"
+import (
+	"strings"
+	"testing"
+
+	"github.com/diamondburned/arikawa/gateway"
+	"github.com/diamondburned/arikawa/state"
+)
 
 func TestNewSubcommand(t *testing.T) {
 	_, err := NewSubcommand(&testCommands{})
@@ -90,3 +96,55 @@ func BenchmarkSubcommandConstructor(b *testing.B) {
 		NewSubcommand(&testCommands{})
 	}
 }
+
+func TestAddFunc(t *testing.T) {
+	s, err := NewSubcommand(&testCommands{})
+	if err != nil {
+		t.Fatal("Failed to create subcommand:", err)
+	}
+
+	var ctx = &Context{
+		Subcommand: s,
+		State:      &state.State{Store: state.NewDefaultStore(nil)},
+		Prefix:     "!",
+	}
+
+	if err := ctx.Subcommand.InitCommands(ctx); err != nil {
+		t.Fatal("Failed to init commands:", err)
+	}
+
+	var rolled int
+
+	cmd, err := ctx.AddFunc("Roll",
+		func(m *gateway.MessageCreateEvent, sides int) error {
+			rolled = sides
+			return nil
+		},
+	)
+	if err != nil {
+		t.Fatal("Failed to add function:", err)
+	}
+
+	cmd.Description = "Rolls a die."
+
+	err = ctx.callCmd(&gateway.MessageCreateEvent{Content: "!roll 6"})
+	if err != nil {
+		t.Fatal("Failed to call function:", err)
+	}
+
+	if rolled != 6 {
+		t.Fatal("Unexpected argument:", rolled)
+	}
+
+	if help := ctx.Help(); !strings.Contains(help, "!roll int") {
+		t.Fatal("Function missing from help:", help)
+	}
+
+	if _, err := ctx.AddFunc("Bad", func() {}); err == nil {
+		t.Fatal("Expected an error for an invalid signature")
+	}
+
+	if _, err := ctx.AddFunc("NotFunc", 1); err == nil {
+		t.Fatal("Expected an error for a non-function")
+	}
+}
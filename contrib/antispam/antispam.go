@@ -0,0 +1,325 @@
+// Package antispam detects message spam and join raids.
+//
+// The Detector watches MessageCreate and GuildMemberAdd events and dispatches
+// SpamDetected and RaidDetected events through the State's handler when the
+// configured limits are exceeded. It never takes action by itself; that is
+// left to the bot, which can handle the events like any other:
+//
+//	s.AddHandler(func(ev *antispam.SpamDetected) {
+//	    s.Kick(ev.GuildID, ev.UserID)
+//	})
+package antispam
+
+import (
+	"sync"
+	"time"
+
+	"github.com/diamondburned/arikawa/discord"
+	"github.com/diamondburned/arikawa/gateway"
+	"github.com/diamondburned/arikawa/state"
+)
+
+// Config contains the limits of the Detector. A zero limit disables the check.
+type Config struct {
+	// MaxMessages is the number of messages a user can send in a guild within
+	// MessageWindow.
+	MaxMessages int
+	// MaxDuplicates is the number of identical messages a user can send in a
+	// guild within MessageWindow.
+	MaxDuplicates int
+	// MessageWindow is the duration messages are remembered for.
+	MessageWindow time.Duration
+
+	// MaxMentions is the number of users and roles a single message can
+	// mention.
+	MaxMentions int
+
+	// MaxJoins is the number of members that can join a guild within
+	// JoinWindow.
+	MaxJoins   int
+	JoinWindow time.Duration
+
+	// Cooldown is the duration after a detection during which the same user
+	// (for spam) or guild (for raids) is not reported again.
+	Cooldown time.Duration
+}
+
+// DefaultConfig is used by New if no Config is given.
+var DefaultConfig = Config{
+	MaxMessages:   6,
+	MaxDuplicates: 3,
+	MessageWindow: 5 * time.Second,
+	MaxMentions:   10,
+	MaxJoins:      10,
+	JoinWindow:    10 * time.Second,
+	Cooldown:      time.Minute,
+}
+
+type SpamReason uint8
+
+const (
+	TooManyMessages SpamReason = iota
+	DuplicateMessages
+	MassMention
+)
+
+func (r SpamReason) String() string {
+	switch r {
+	case TooManyMessages:
+		return "too many messages"
+	case DuplicateMessages:
+		return "duplicate messages"
+	case MassMention:
+		return "mass mention"
+	default:
+		return "unknown"
+	}
+}
+
+// SpamDetected is dispatched when a user exceeds a message limit.
+type SpamDetected struct {
+	GuildID discord.Snowflake
+	UserID  discord.Snowflake
+	Reason  SpamReason
+
+	// Messages are the messages that triggered the detection, oldest first.
+	Messages []discord.Message
+}
+
+// RaidDetected is dispatched when too many members join a guild at once.
+type RaidDetected struct {
+	GuildID discord.Snowflake
+
+	// Members are the members that joined within the window, oldest first.
+	Members []discord.Member
+}
+
+type userKey struct {
+	GuildID discord.Snowflake
+	UserID  discord.Snowflake
+}
+
+type message struct {
+	time time.Time
+	msg  discord.Message
+}
+
+type join struct {
+	time   time.Time
+	member discord.Member
+}
+
+// Detector watches the events of a State for spam and raids.
+type Detector struct {
+	*state.State
+	Config Config
+
+	mutex    sync.Mutex
+	messages map[userKey][]message
+	joins    map[discord.Snowflake][]join
+	lastSpam map[userKey]time.Time
+	lastRaid map[discord.Snowflake]time.Time
+	lastGC   time.Time
+	unhook   []func()
+
+	now func() time.Time
+}
+
+// New creates a new Detector and adds its handlers to the State. If cfg is nil,
+// DefaultConfig is used.
+func New(s *state.State, cfg *Config) *Detector {
+	d := newDetector(cfg)
+	d.State = s
+
+	d.unhook = []func(){
+		s.AddHandler(func(m *gateway.MessageCreateEvent) {
+			if ev := d.checkMessage(discord.Message(*m)); ev != nil {
+				s.Call(ev)
+			}
+		}),
+		s.AddHandler(func(m *gateway.GuildMemberAddEvent) {
+			if ev := d.checkJoin(m.GuildID, m.Member); ev != nil {
+				s.Call(ev)
+			}
+		}),
+	}
+
+	return d
+}
+
+func newDetector(cfg *Config) *Detector {
+	if cfg == nil {
+		var def = DefaultConfig
+		cfg = &def
+	}
+
+	return &Detector{
+		Config:   *cfg,
+		messages: map[userKey][]message{},
+		joins:    map[discord.Snowflake][]join{},
+		lastSpam: map[userKey]time.Time{},
+		lastRaid: map[discord.Snowflake]time.Time{},
+		now:      time.Now,
+	}
+}
+
+// Close removes the Detector's handlers from the State.
+func (d *Detector) Close() {
+	for _, rm := range d.unhook {
+		rm()
+	}
+}
+
+func (d *Detector) checkMessage(m discord.Message) *SpamDetected {
+	// Only check guild messages from users.
+	if !m.GuildID.Valid() || m.Author.Bot || m.WebhookID.Valid() {
+		return nil
+	}
+
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	var now = d.now()
+	var key = userKey{m.GuildID, m.Author.ID}
+
+	d.gc(now)
+
+	// Drop the messages that are out of the window.
+	var msgs = d.messages[key]
+	var i = 0
+	for i < len(msgs) && now.Sub(msgs[i].time) > d.Config.MessageWindow {
+		i++
+	}
+	msgs = append(msgs[i:], message{now, m})
+	d.messages[key] = msgs
+
+	var ev *SpamDetected
+
+	switch {
+	case d.Config.MaxMentions > 0 &&
+		len(m.Mentions)+len(m.MentionRoleIDs) > d.Config.MaxMentions:
+
+		ev = &SpamDetected{Reason: MassMention, Messages: []discord.Message{m}}
+
+	case d.Config.MaxMessages > 0 && len(msgs) > d.Config.MaxMessages:
+		ev = &SpamDetected{Reason: TooManyMessages, Messages: collect(msgs)}
+
+	case d.Config.MaxDuplicates > 0:
+		var dups []message
+		for _, msg := range msgs {
+			if msg.msg.Content == m.Content {
+				dups = append(dups, msg)
+			}
+		}
+
+		if len(dups) > d.Config.MaxDuplicates {
+			ev = &SpamDetected{
+				Reason:   DuplicateMessages,
+				Messages: collect(dups),
+			}
+		}
+	}
+
+	if ev == nil {
+		return nil
+	}
+
+	if last, ok := d.lastSpam[key]; ok && now.Sub(last) < d.Config.Cooldown {
+		return nil
+	}
+	d.lastSpam[key] = now
+
+	// Start over, so the same messages aren't reported again.
+	delete(d.messages, key)
+
+	ev.GuildID = m.GuildID
+	ev.UserID = m.Author.ID
+	return ev
+}
+
+// gc removes the history of users and guilds that have been quiet for longer
+// than the windows and the cooldown. It only runs once every Cooldown.
+func (d *Detector) gc(now time.Time) {
+	var expiry = d.Config.Cooldown
+	if d.Config.MessageWindow > expiry {
+		expiry = d.Config.MessageWindow
+	}
+	if d.Config.JoinWindow > expiry {
+		expiry = d.Config.JoinWindow
+	}
+
+	if now.Sub(d.lastGC) < expiry {
+		return
+	}
+	d.lastGC = now
+
+	for key, msgs := range d.messages {
+		if now.Sub(msgs[len(msgs)-1].time) > d.Config.MessageWindow {
+			delete(d.messages, key)
+		}
+	}
+	for guildID, joins := range d.joins {
+		if now.Sub(joins[len(joins)-1].time) > d.Config.JoinWindow {
+			delete(d.joins, guildID)
+		}
+	}
+	for key, last := range d.lastSpam {
+		if now.Sub(last) > d.Config.Cooldown {
+			delete(d.lastSpam, key)
+		}
+	}
+	for guildID, last := range d.lastRaid {
+		if now.Sub(last) > d.Config.Cooldown {
+			delete(d.lastRaid, guildID)
+		}
+	}
+}
+
+func collect(msgs []message) []discord.Message {
+	var evidence = make([]discord.Message, len(msgs))
+	for i, msg := range msgs {
+		evidence[i] = msg.msg
+	}
+	return evidence
+}
+
+func (d *Detector) checkJoin(
+	guildID discord.Snowflake, member discord.Member) *RaidDetected {
+
+	if d.Config.MaxJoins <= 0 {
+		return nil
+	}
+
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	var now = d.now()
+	d.gc(now)
+
+	var joins = d.joins[guildID]
+	var i = 0
+	for i < len(joins) && now.Sub(joins[i].time) > d.Config.JoinWindow {
+		i++
+	}
+	joins = append(joins[i:], join{now, member})
+	d.joins[guildID] = joins
+
+	if len(joins) <= d.Config.MaxJoins {
+		return nil
+	}
+
+	if last, ok := d.lastRaid[guildID]; ok && now.Sub(last) < d.Config.Cooldown {
+		return nil
+	}
+	d.lastRaid[guildID] = now
+
+	var members = make([]discord.Member, len(joins))
+	for i, j := range joins {
+		members[i] = j.member
+	}
+
+	return &RaidDetected{
+		GuildID: guildID,
+		Members: members,
+	}
+}
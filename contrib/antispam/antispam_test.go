@@ -0,0 +1,81 @@
+// +build unit
+
+package antispam
+
+import (
+	"testing"
+	"time"
+
+	"github.com/diamondburned/arikawa/discord"
+)
+
+func newTestDetector(cfg Config) (*Detector, *time.Time) {
+	var now = time.Unix(0, 0)
+
+	d := newDetector(&cfg)
+	d.now = func() time.Time { return now }
+
+	return d, &now
+}
+
+func TestMessageSpam(t *testing.T) {
+	d, now := newTestDetector(Config{
+		MaxMessages:   5,
+		MaxDuplicates: 2,
+		MessageWindow: 5 * time.Second,
+		Cooldown:      time.Minute,
+	})
+
+	var msg = func(content string) discord.Message {
+		return discord.Message{
+			GuildID: 1,
+			Author:  discord.User{ID: 2},
+			Content: content,
+		}
+	}
+
+	// Messages spread out over time are fine.
+	for i := 0; i < 5; i++ {
+		if ev := d.checkMessage(msg(string(rune('a' + i)))); ev != nil {
+			t.Fatal("Unexpected detection:", ev.Reason)
+		}
+		*now = now.Add(3 * time.Second)
+	}
+
+	d.checkMessage(msg("spam"))
+	d.checkMessage(msg("spam"))
+
+	ev := d.checkMessage(msg("spam"))
+	if ev == nil || ev.Reason != DuplicateMessages || len(ev.Messages) != 3 {
+		t.Fatal("Expected duplicate messages, got", ev)
+	}
+
+	// Cooldown.
+	for i := 0; i < 5; i++ {
+		if ev := d.checkMessage(msg("spam")); ev != nil {
+			t.Fatal("Unexpected detection during cooldown:", ev.Reason)
+		}
+	}
+}
+
+func TestRaid(t *testing.T) {
+	d, now := newTestDetector(Config{
+		MaxJoins:   2,
+		JoinWindow: 10 * time.Second,
+		Cooldown:   time.Minute,
+	})
+
+	d.checkJoin(1, discord.Member{})
+	*now = now.Add(11 * time.Second)
+	d.checkJoin(1, discord.Member{})
+	d.checkJoin(1, discord.Member{})
+
+	if ev := d.checkJoin(2, discord.Member{}); ev != nil {
+		t.Fatal("Unexpected raid in another guild")
+	}
+
+	ev := d.checkJoin(1, discord.Member{})
+	if ev == nil || ev.GuildID != 1 || len(ev.Members) != 3 {
+		t.Fatal("Expected raid, got", ev)
+	}
+}
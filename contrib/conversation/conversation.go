@@ -0,0 +1,241 @@
+// Package conversation routes the messages of a user in a channel to the steps
+// of a conversation, such as a setup wizard that asks a few questions in a row.
+//
+// Each step handles an answer and returns the next step, or nil once the
+// conversation is over:
+//
+//	var askName, askAge conversation.Step
+//
+//	askName = func(c *conversation.Conversation,
+//		m *gateway.MessageCreateEvent) (conversation.Step, error) {
+//
+//		c.Data = m.Content
+//		_, err := s.SendMessage(m.ChannelID, "How old are you?", nil)
+//		return askAge, err
+//	}
+//
+//	s.SendMessage(channelID, "What's your name?", nil)
+//	m.Start(userID, channelID, askName)
+//
+// A step can return itself to ask again, such as after an invalid answer.
+package conversation
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/diamondburned/arikawa/discord"
+	"github.com/diamondburned/arikawa/gateway"
+	"github.com/diamondburned/arikawa/handler"
+	"github.com/pkg/errors"
+)
+
+// DefaultTimeout is the default time a user has to answer each step.
+var DefaultTimeout = 5 * time.Minute
+
+var (
+	// ErrTimeout ends a conversation whose user didn't answer in time.
+	ErrTimeout = errors.New("Conversation timed out")
+	// ErrCanceled ends a conversation that was canceled or replaced.
+	ErrCanceled = errors.New("Conversation canceled")
+)
+
+// Step handles a message of the conversation, and returns the next step. The
+// conversation ends if the step is nil or if there's an error.
+type Step func(c *Conversation, m *gateway.MessageCreateEvent) (Step, error)
+
+// Conversation is a conversation with a user in a channel.
+type Conversation struct {
+	UserID    discord.Snowflake
+	ChannelID discord.Snowflake
+
+	// Data is free for the steps to keep their state in. It's only used by
+	// one step at a time.
+	Data interface{}
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	done   chan struct{}
+	err    error
+}
+
+// Cancel ends the conversation with ErrCanceled. The step running, if any, is
+// not interrupted.
+func (c *Conversation) Cancel() {
+	c.cancel()
+}
+
+// Done returns a channel that's closed once the conversation is over.
+func (c *Conversation) Done() <-chan struct{} {
+	return c.done
+}
+
+// Err returns why the conversation ended: nil if it ended with a nil step,
+// ErrTimeout, ErrCanceled or the error of a step. It's only set once Done is
+// closed.
+func (c *Conversation) Err() error {
+	select {
+	case <-c.done:
+		return c.err
+	default:
+		return nil
+	}
+}
+
+type key struct {
+	user    discord.Snowflake
+	channel discord.Snowflake
+}
+
+// Manager keeps track of the conversations. A user can have one conversation
+// per channel.
+type Manager struct {
+	Handler *handler.Handler
+
+	// Timeout is the time a user has to answer each step. It defaults to
+	// DefaultTimeout if 0.
+	Timeout time.Duration
+
+	// OnTimeout, if not nil, is called when a conversation times out, such as
+	// to tell the user.
+	OnTimeout func(c *Conversation)
+	// ErrorLog is called with the errors returned by steps.
+	ErrorLog func(err error)
+
+	mutex         sync.Mutex
+	conversations map[key]*Conversation
+}
+
+// New creates a Manager that listens for messages on the handler, such as the
+// one of a State.
+func New(h *handler.Handler) *Manager {
+	return &Manager{
+		Handler:       h,
+		ErrorLog:      func(err error) {},
+		conversations: map[key]*Conversation{},
+	}
+}
+
+// Start starts a conversation with the user in the channel, whose next message
+// is given to first. A conversation the user already has in the channel is
+// canceled. The steps run in the background.
+func (m *Manager) Start(
+	userID, channelID discord.Snowflake, first Step) *Conversation {
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	c := &Conversation{
+		UserID:    userID,
+		ChannelID: channelID,
+		ctx:       ctx,
+		cancel:    cancel,
+		done:      make(chan struct{}),
+	}
+
+	// Messages are listened to before returning, so the next message isn't
+	// missed.
+	msgs := m.Handler.ChanFor(ctx, func(ev *gateway.MessageCreateEvent) bool {
+		return ev.Author.ID == userID && ev.ChannelID == channelID
+	})
+
+	m.mutex.Lock()
+	if old, ok := m.conversations[key{userID, channelID}]; ok {
+		old.Cancel()
+	}
+	m.conversations[key{userID, channelID}] = c
+	m.mutex.Unlock()
+
+	go m.run(c, first, msgs)
+
+	return c
+}
+
+// Conversation returns the conversation with the user in the channel, or nil
+// if there's none.
+func (m *Manager) Conversation(
+	userID, channelID discord.Snowflake) *Conversation {
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	return m.conversations[key{userID, channelID}]
+}
+
+// Cancel cancels the conversation with the user in the channel. False is
+// returned if there's none.
+func (m *Manager) Cancel(userID, channelID discord.Snowflake) bool {
+	c := m.Conversation(userID, channelID)
+	if c == nil {
+		return false
+	}
+
+	c.Cancel()
+	return true
+}
+
+func (m *Manager) run(
+	c *Conversation, step Step, msgs <-chan interface{}) {
+
+	var err = m.steps(c, step, msgs)
+
+	m.mutex.Lock()
+	// The conversation could have been replaced already.
+	if m.conversations[key{c.UserID, c.ChannelID}] == c {
+		delete(m.conversations, key{c.UserID, c.ChannelID})
+	}
+	m.mutex.Unlock()
+
+	c.err = err
+	c.cancel()
+	close(c.done)
+
+	switch err {
+	case nil, ErrCanceled:
+	case ErrTimeout:
+		if m.OnTimeout != nil {
+			m.OnTimeout(c)
+		}
+	default:
+		m.ErrorLog(errors.Wrap(err, "Conversation step failed"))
+	}
+}
+
+func (m *Manager) steps(
+	c *Conversation, step Step, msgs <-chan interface{}) error {
+
+	var timeout = m.Timeout
+	if timeout == 0 {
+		timeout = DefaultTimeout
+	}
+
+	var timer = time.NewTimer(timeout)
+	defer timer.Stop()
+
+	for step != nil {
+		select {
+		case <-c.ctx.Done():
+			return ErrCanceled
+		case <-timer.C:
+			return ErrTimeout
+		case ev, ok := <-msgs:
+			if !ok {
+				return ErrCanceled
+			}
+
+			var err error
+			step, err = step(c, ev.(*gateway.MessageCreateEvent))
+			if err != nil {
+				return err
+			}
+		}
+
+		// The user has the whole timeout for each step.
+		if !timer.Stop() {
+			<-timer.C
+		}
+		timer.Reset(timeout)
+	}
+
+	return nil
+}
@@ -0,0 +1,122 @@
+// +build unit
+
+package conversation
+
+import (
+	"testing"
+	"time"
+
+	"github.com/diamondburned/arikawa/discord"
+	"github.com/diamondburned/arikawa/gateway"
+	"github.com/diamondburned/arikawa/handler"
+)
+
+func message(
+	userID discord.Snowflake, content string) *gateway.MessageCreateEvent {
+
+	return &gateway.MessageCreateEvent{
+		ChannelID: 1,
+		Author:    discord.User{ID: userID},
+		Content:   content,
+	}
+}
+
+func wait(t *testing.T, c *Conversation) {
+	select {
+	case <-c.Done():
+	case <-time.After(time.Second):
+		t.Fatal("Conversation didn't end")
+	}
+}
+
+func TestConversation(t *testing.T) {
+	h := handler.New()
+	h.Synchronous = true
+	m := New(h)
+
+	var answers []string
+	var ask Step
+	ask = func(c *Conversation, m *gateway.MessageCreateEvent) (Step, error) {
+		answers = append(answers, m.Content)
+		if len(answers) == 2 {
+			return nil, nil
+		}
+		return ask, nil
+	}
+
+	c := m.Start(2, 1, ask)
+
+	if m.Conversation(2, 1) != c {
+		t.Fatal("Conversation wasn't stored")
+	}
+
+	h.Call(message(3, "someone else"))
+	h.Call(message(2, "first"))
+	h.Call(message(2, "second"))
+
+	wait(t, c)
+
+	if c.Err() != nil {
+		t.Fatal("Unexpected error:", c.Err())
+	}
+
+	if len(answers) != 2 || answers[0] != "first" || answers[1] != "second" {
+		t.Fatalf("Unexpected answers: %q", answers)
+	}
+
+	if m.Conversation(2, 1) != nil {
+		t.Fatal("Conversation wasn't removed")
+	}
+}
+
+func TestTimeout(t *testing.T) {
+	m := New(handler.New())
+	m.Timeout = 10 * time.Millisecond
+
+	var timedOut = make(chan *Conversation, 1)
+	m.OnTimeout = func(c *Conversation) { timedOut <- c }
+
+	c := m.Start(2, 1, func(*Conversation, *gateway.MessageCreateEvent) (
+		Step, error) {
+
+		t.Fatal("Step was called without a message")
+		return nil, nil
+	})
+
+	wait(t, c)
+
+	if c.Err() != ErrTimeout {
+		t.Fatal("Unexpected error:", c.Err())
+	}
+
+	if <-timedOut != c {
+		t.Fatal("OnTimeout wasn't called with the conversation")
+	}
+}
+
+func TestReplace(t *testing.T) {
+	m := New(handler.New())
+
+	var step = func(*Conversation, *gateway.MessageCreateEvent) (Step, error) {
+		return nil, nil
+	}
+
+	first := m.Start(2, 1, step)
+	second := m.Start(2, 1, step)
+
+	wait(t, first)
+
+	if first.Err() != ErrCanceled {
+		t.Fatal("Unexpected error:", first.Err())
+	}
+
+	if m.Conversation(2, 1) != second {
+		t.Fatal("Replacing conversation was removed")
+	}
+
+	if !m.Cancel(2, 1) {
+		t.Fatal("Conversation wasn't found")
+	}
+
+	wait(t, second)
+}
@@ -0,0 +1,91 @@
+// Package invitetracker tracks which invite new members joined with.
+//
+// The Tracker makes the State track the invites of every guild, and guesses
+// the invite each member joined with using State.JoinedVia. A
+// MemberJoinedViaInvite event is then dispatched through the State's handler.
+//
+// Fetching invites requires MANAGE_GUILD.
+package invitetracker
+
+import (
+	"github.com/diamondburned/arikawa/discord"
+	"github.com/diamondburned/arikawa/gateway"
+	"github.com/diamondburned/arikawa/state"
+	"github.com/pkg/errors"
+)
+
+// MemberJoinedViaInvite is dispatched after a member joins. It can be handled
+// like any other event, with a func(*MemberJoinedViaInvite) handler.
+type MemberJoinedViaInvite struct {
+	GuildID discord.Snowflake
+	Member  discord.Member
+
+	// Code is empty if the invite couldn't be determined, for example if the
+	// member joined with a vanity URL or if multiple members joined at once.
+	Code string
+	// Inviter is nil if Code is empty or if the invite has no inviter.
+	Inviter *discord.User
+	// Uses is the use count of the invite, including this member.
+	Uses uint
+}
+
+type Tracker struct {
+	*state.State
+
+	// ErrorLog is called when invites can't be fetched.
+	ErrorLog func(err error)
+
+	unhook func()
+}
+
+// New creates a new Tracker, which enables TrackInvites on the State and adds
+// its handler to it. It should be called before the State is opened.
+func New(s *state.State) *Tracker {
+	s.TrackInvites = true
+
+	t := &Tracker{
+		State:    s,
+		ErrorLog: func(err error) { s.ErrorLog(err) },
+	}
+
+	t.unhook = s.AddHandler(t.onMemberAdd)
+
+	return t
+}
+
+// Close removes the Tracker's handler from the State.
+func (t *Tracker) Close() {
+	t.unhook()
+}
+
+// Invites returns the tracked invites of the guild.
+func (t *Tracker) Invites(guildID discord.Snowflake) []discord.InviteMeta {
+	invites, _ := t.TrackedInvites(guildID)
+	return invites
+}
+
+// Snapshot fetches the guild's invites and tracks them.
+func (t *Tracker) Snapshot(guildID discord.Snowflake) error {
+	return t.RefreshInvites(guildID)
+}
+
+func (t *Tracker) onMemberAdd(m *gateway.GuildMemberAddEvent) {
+	inv, err := t.JoinedVia(m.GuildID)
+	if err != nil {
+		t.ErrorLog(errors.Wrapf(err, "Failed to snapshot guild %d", m.GuildID))
+		return
+	}
+
+	var ev = &MemberJoinedViaInvite{
+		GuildID: m.GuildID,
+		Member:  m.Member,
+	}
+
+	if inv != nil {
+		ev.Code = inv.Code
+		ev.Inviter = inv.Inviter
+		ev.Uses = inv.Uses
+	}
+
+	t.Call(ev)
+}
@@ -0,0 +1,266 @@
+// Package starboard reposts messages that get enough star reactions into a
+// starboard channel.
+//
+// Stars are counted by fetching the users that reacted with the configured
+// emoji, so that bots and, unless SelfStar is set, the author of the message
+// aren't counted. The starboard message is edited as the count changes, and
+// deleted when the count drops below the threshold or when the original message
+// is deleted.
+//
+// Each guild has its own Config, which is kept in the Store along with the
+// starboard messages:
+//
+//	b := starboard.New(s, nil)
+//	b.Store.SetConfig(guildID, &starboard.Config{ChannelID: channelID})
+package starboard
+
+import (
+	"strconv"
+	"sync"
+
+	"github.com/diamondburned/arikawa/discord"
+	"github.com/diamondburned/arikawa/gateway"
+	"github.com/diamondburned/arikawa/state"
+	"github.com/pkg/errors"
+)
+
+var (
+	DefaultEmoji     = discord.Emoji{Name: "⭐"}
+	DefaultThreshold = 3
+)
+
+// Config is the starboard config of a guild.
+type Config struct {
+	// ChannelID is the starboard channel. Messages in it can't be starred.
+	ChannelID discord.Snowflake
+
+	// Emoji is the reaction counted as a star. It defaults to DefaultEmoji if
+	// its Name is empty.
+	Emoji discord.Emoji
+	// Threshold is the number of stars needed for a message to be posted. It
+	// defaults to DefaultThreshold if 0.
+	Threshold int
+
+	// SelfStar, if true, counts the author's own star.
+	SelfStar bool
+}
+
+func (cfg Config) emoji() discord.Emoji {
+	if cfg.Emoji.Name == "" {
+		return DefaultEmoji
+	}
+	return cfg.Emoji
+}
+
+func (cfg Config) threshold() int {
+	if cfg.Threshold == 0 {
+		return DefaultThreshold
+	}
+	return cfg.Threshold
+}
+
+// isStar returns true if the reaction is the star emoji.
+func (cfg Config) isStar(e discord.Emoji) bool {
+	var star = cfg.emoji()
+	if star.ID.Valid() {
+		return e.ID == star.ID
+	}
+	return e.Name == star.Name
+}
+
+type Starboard struct {
+	*state.State
+	Store Store
+
+	// ErrorLog is called when a message can't be starred.
+	ErrorLog func(err error)
+
+	// mutex serializes updates, so the same message isn't posted twice.
+	mutex  sync.Mutex
+	unhook []func()
+}
+
+// New creates a new Starboard and adds its handlers to the State. If store is
+// nil, a MemoryStore is used.
+func New(s *state.State, store Store) *Starboard {
+	if store == nil {
+		store = NewMemoryStore()
+	}
+
+	b := &Starboard{
+		State:    s,
+		Store:    store,
+		ErrorLog: func(err error) { s.ErrorLog(err) },
+	}
+
+	b.unhook = []func(){
+		s.AddHandler(func(r *gateway.MessageReactionAddEvent) {
+			b.onReaction(r.GuildID, r.ChannelID, r.MessageID, r.Emoji)
+		}),
+		s.AddHandler(func(r *gateway.MessageReactionRemoveEvent) {
+			b.onReaction(r.GuildID, r.ChannelID, r.MessageID, r.Emoji)
+		}),
+		s.AddHandler(func(r *gateway.MessageReactionRemoveAllEvent) {
+			b.update(r.GuildID, r.ChannelID, r.MessageID)
+		}),
+		s.AddHandler(func(m *gateway.MessageDeleteEvent) {
+			b.remove(m.GuildID, m.ID)
+		}),
+	}
+
+	return b
+}
+
+// Close removes the Starboard's handlers from the State.
+func (b *Starboard) Close() {
+	for _, rm := range b.unhook {
+		rm()
+	}
+}
+
+func (b *Starboard) onReaction(
+	guildID, channelID, messageID discord.Snowflake, emoji discord.Emoji) {
+
+	cfg, err := b.config(guildID)
+	if err != nil || cfg == nil || !cfg.isStar(emoji) {
+		return
+	}
+
+	b.update(guildID, channelID, messageID)
+}
+
+// config returns the guild's config, or nil if the guild has no starboard.
+func (b *Starboard) config(guildID discord.Snowflake) (*Config, error) {
+	if !guildID.Valid() {
+		return nil, nil
+	}
+
+	cfg, err := b.Store.Config(guildID)
+	if err != nil {
+		b.ErrorLog(errors.Wrap(err, "Failed to get starboard config"))
+		return nil, err
+	}
+
+	return cfg, nil
+}
+
+// update counts the stars of the message, then posts, edits or deletes its
+// starboard message.
+func (b *Starboard) update(guildID, channelID, messageID discord.Snowflake) {
+	cfg, err := b.config(guildID)
+	if err != nil || cfg == nil || channelID == cfg.ChannelID {
+		return
+	}
+
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if err := b.star(guildID, *cfg, channelID, messageID); err != nil {
+		b.ErrorLog(errors.Wrapf(err, "Failed to star message %d", messageID))
+	}
+}
+
+func (b *Starboard) star(guildID discord.Snowflake,
+	cfg Config, channelID, messageID discord.Snowflake) error {
+
+	m, err := b.Message(channelID, messageID)
+	if err != nil {
+		return errors.Wrap(err, "Failed to get message")
+	}
+
+	// Messages from the API don't have a guild ID.
+	m.GuildID = guildID
+
+	users, err := b.Reactions(channelID, messageID, 0, cfg.emoji().APIString())
+	if err != nil {
+		return errors.Wrap(err, "Failed to get reactions")
+	}
+
+	var stars = countStars(users, m.Author.ID, cfg.SelfStar)
+
+	entryID, err := b.Store.Entry(messageID)
+	if err != nil {
+		return errors.Wrap(err, "Failed to get starboard entry")
+	}
+
+	switch {
+	case stars >= cfg.threshold() && !entryID.Valid():
+		entry, err := b.SendMessage(
+			cfg.ChannelID, Content(cfg.emoji(), stars, *m), Embed(*m))
+		if err != nil {
+			return errors.Wrap(err, "Failed to post starboard message")
+		}
+
+		return b.Store.SetEntry(messageID, entry.ID)
+
+	case stars >= cfg.threshold():
+		// The embed is left as is.
+		_, err := b.EditMessage(cfg.ChannelID, entryID,
+			Content(cfg.emoji(), stars, *m), nil, false)
+		if err != nil {
+			return errors.Wrap(err, "Failed to edit starboard message")
+		}
+
+	case entryID.Valid():
+		if err := b.DeleteMessage(cfg.ChannelID, entryID); err != nil {
+			return errors.Wrap(err, "Failed to delete starboard message")
+		}
+
+		return b.Store.RemoveEntry(messageID)
+	}
+
+	return nil
+}
+
+// remove deletes the starboard message of a deleted message.
+func (b *Starboard) remove(guildID, messageID discord.Snowflake) {
+	cfg, err := b.config(guildID)
+	if err != nil || cfg == nil {
+		return
+	}
+
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	entryID, err := b.Store.Entry(messageID)
+	if err != nil || !entryID.Valid() {
+		return
+	}
+
+	if err := b.DeleteMessage(cfg.ChannelID, entryID); err != nil {
+		b.ErrorLog(errors.Wrap(err, "Failed to delete starboard message"))
+	}
+
+	if err := b.Store.RemoveEntry(messageID); err != nil {
+		b.ErrorLog(errors.Wrap(err, "Failed to remove starboard entry"))
+	}
+}
+
+// countStars counts the users that starred a message, excluding bots and, if
+// selfStar is false, the author.
+func countStars(
+	users []discord.User, authorID discord.Snowflake, selfStar bool) int {
+
+	var stars int
+	for _, u := range users {
+		if u.Bot || (u.ID == authorID && !selfStar) {
+			continue
+		}
+		stars++
+	}
+	return stars
+}
+
+// Content formats the content of a starboard message, which has the star count
+// and the channel of the message.
+func Content(emoji discord.Emoji, stars int, m discord.Message) string {
+	return emoji.String() + " **" + strconv.Itoa(stars) + "** " +
+		discord.Channel{ID: m.ChannelID}.Mention()
+}
+
+// Embed formats the embed of a starboard message, which quotes the message.
+func Embed(m discord.Message) *discord.Embed {
+	var embed = m.QuoteEmbed()
+	embed.Color = 0xFFAC33
+	return embed
+}
@@ -0,0 +1,49 @@
+// +build unit
+
+package starboard
+
+import (
+	"testing"
+
+	"github.com/diamondburned/arikawa/discord"
+)
+
+func TestCountStars(t *testing.T) {
+	var users = []discord.User{
+		{ID: 1},
+		{ID: 2},
+		{ID: 3, Bot: true},
+		{ID: 4},
+	}
+
+	if n := countStars(users, 1, false); n != 2 {
+		t.Fatal("Expected 2 stars without self-star, got", n)
+	}
+
+	if n := countStars(users, 1, true); n != 3 {
+		t.Fatal("Expected 3 stars with self-star, got", n)
+	}
+}
+
+func TestIsStar(t *testing.T) {
+	var cfg Config
+	if !cfg.isStar(discord.Emoji{Name: "⭐"}) {
+		t.Fatal("Default emoji is not a star")
+	}
+
+	cfg.Emoji = discord.Emoji{ID: 42, Name: "star"}
+	if cfg.isStar(discord.Emoji{Name: "⭐"}) {
+		t.Fatal("Unicode star matched a custom emoji")
+	}
+	if !cfg.isStar(discord.Emoji{ID: 42, Name: "renamed"}) {
+		t.Fatal("Custom emoji wasn't matched by ID")
+	}
+}
+
+func TestContent(t *testing.T) {
+	var m = discord.Message{ChannelID: 123}
+
+	if s := Content(DefaultEmoji, 5, m); s != "⭐ **5** <#123>" {
+		t.Fatal("Unexpected content:", s)
+	}
+}
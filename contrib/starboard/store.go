@@ -0,0 +1,90 @@
+package starboard
+
+import (
+	"sync"
+
+	"github.com/diamondburned/arikawa/discord"
+)
+
+// Store persists the starboard configs and the starboard messages. Implement
+// it to keep the starboard across restarts; MemoryStore only lives as long as
+// the process.
+type Store interface {
+	// Config returns the guild's config, or nil if the guild has no
+	// starboard.
+	Config(guildID discord.Snowflake) (*Config, error)
+	// SetConfig sets the guild's config. A nil config removes the starboard.
+	SetConfig(guildID discord.Snowflake, cfg *Config) error
+
+	// Entry returns the ID of the starboard message posted for the message, or
+	// 0 if there isn't one.
+	Entry(messageID discord.Snowflake) (discord.Snowflake, error)
+	SetEntry(messageID, entryID discord.Snowflake) error
+	RemoveEntry(messageID discord.Snowflake) error
+}
+
+// MemoryStore is a Store that keeps everything in memory.
+type MemoryStore struct {
+	mutex   sync.RWMutex
+	configs map[discord.Snowflake]Config
+	entries map[discord.Snowflake]discord.Snowflake
+}
+
+var _ Store = (*MemoryStore)(nil)
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		configs: map[discord.Snowflake]Config{},
+		entries: map[discord.Snowflake]discord.Snowflake{},
+	}
+}
+
+func (s *MemoryStore) Config(guildID discord.Snowflake) (*Config, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	cfg, ok := s.configs[guildID]
+	if !ok {
+		return nil, nil
+	}
+
+	return &cfg, nil
+}
+
+func (s *MemoryStore) SetConfig(guildID discord.Snowflake, cfg *Config) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if cfg == nil {
+		delete(s.configs, guildID)
+	} else {
+		s.configs[guildID] = *cfg
+	}
+
+	return nil
+}
+
+func (s *MemoryStore) Entry(
+	messageID discord.Snowflake) (discord.Snowflake, error) {
+
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	return s.entries[messageID], nil
+}
+
+func (s *MemoryStore) SetEntry(messageID, entryID discord.Snowflake) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.entries[messageID] = entryID
+	return nil
+}
+
+func (s *MemoryStore) RemoveEntry(messageID discord.Snowflake) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	delete(s.entries, messageID)
+	return nil
+}
@@ -0,0 +1,264 @@
+package discord
+
+import "github.com/diamondburned/arikawa/internal/json"
+
+// https://discordapp.com/developers/docs/resources/audit-log#audit-log-object
+type AuditLog struct {
+	// List of webhooks found in the audit log.
+	Webhooks []Webhook `json:"webhooks"`
+	// List of users found in the audit log.
+	Users []User `json:"users"`
+	// List of audit log entries.
+	Entries []AuditLogEntry `json:"audit_log_entries"`
+	// List of partial integration objects, only ID, Name, Type, and Account.
+	Integrations []Integration `json:"integrations"`
+}
+
+// AuditLogEntry is a single entry in the audit log.
+//
+// https://discordapp.com/developers/docs/resources/audit-log#audit-log-entry-object
+type AuditLogEntry struct {
+	ID       Snowflake `json:"id"`
+	UserID   Snowflake `json:"user_id"`
+	TargetID Snowflake `json:"target_id,omitempty"`
+
+	ActionType AuditLogEvent `json:"action_type"`
+
+	Changes []AuditLogChange `json:"changes,omitempty"`
+	Options AuditEntryInfo   `json:"options,omitempty"`
+	Reason  string           `json:"reason,omitempty"`
+}
+
+type AuditLogEvent uint8
+
+const (
+	GuildUpdate AuditLogEvent = 1
+)
+
+const (
+	ChannelCreate AuditLogEvent = iota + 10
+	ChannelUpdate
+	ChannelDelete
+	ChannelOverwriteCreate
+	ChannelOverwriteUpdate
+	ChannelOverwriteDelete
+)
+
+const (
+	MemberKick AuditLogEvent = iota + 20
+	MemberPrune
+	MemberBanAdd
+	MemberBanRemove
+	MemberUpdate
+	MemberRoleUpdate
+	MemberMove
+	MemberDisconnect
+	BotAdd
+)
+
+const (
+	RoleCreate AuditLogEvent = iota + 30
+	RoleUpdate
+	RoleDelete
+)
+
+const (
+	InviteCreate AuditLogEvent = iota + 40
+	InviteUpdate
+	InviteDelete
+)
+
+const (
+	WebhookCreate AuditLogEvent = iota + 50
+	WebhookUpdate
+	WebhookDelete
+)
+
+const (
+	EmojiCreate AuditLogEvent = iota + 60
+	EmojiUpdate
+	EmojiDelete
+)
+
+const (
+	MessageDelete AuditLogEvent = iota + 72
+	MessageBulkDelete
+	MessagePin
+	MessageUnpin
+)
+
+const (
+	IntegrationCreate AuditLogEvent = iota + 80
+	IntegrationUpdate
+	IntegrationDelete
+)
+
+// AuditEntryInfo contains optional information for some action types.
+type AuditEntryInfo struct {
+	// MEMBER_PRUNE
+	DeleteMemberDays string `json:"delete_member_days,omitempty"`
+	MembersRemoved   string `json:"members_removed,omitempty"`
+
+	// MEMBER_MOVE & MESSAGE_DELETE & MESSAGE_PIN & MESSAGE_UNPIN
+	ChannelID Snowflake `json:"channel_id,omitempty"`
+	// MESSAGE_PIN & MESSAGE_UNPIN
+	MessageID Snowflake `json:"message_id,omitempty"`
+
+	// MESSAGE_DELETE & MESSAGE_BULK_DELETE & MEMBER_DISCONNECT & MEMBER_MOVE
+	Count string `json:"count,omitempty"`
+
+	// CHANNEL_OVERWRITE_CREATE & CHANNEL_OVERWRITE_UPDATE &
+	// CHANNEL_OVERWRITE_DELETE
+	ID   Snowflake     `json:"id,omitempty"`
+	Type OverwriteType `json:"type,omitempty"`
+	// Only if Type is OverwriteRole.
+	RoleName string `json:"role_name,omitempty"`
+}
+
+// AuditLogChange is a single change in an audit log entry. The type of the
+// values depends on the key; refer to the documentation of each
+// AuditLogChangeKey, and use UnmarshalValues to decode them.
+//
+// https://discordapp.com/developers/docs/resources/audit-log#audit-log-change-object
+type AuditLogChange struct {
+	Key      AuditLogChangeKey `json:"key"`
+	NewValue json.Raw          `json:"new_value,omitempty"`
+	OldValue json.Raw          `json:"old_value,omitempty"`
+}
+
+// UnmarshalValues unmarshals the old and new values into the given pointers.
+// Either pointer can be nil, and values that don't exist are skipped.
+func (a AuditLogChange) UnmarshalValues(old, new interface{}) error {
+	if old != nil && len(a.OldValue) > 0 {
+		if err := (json.Default{}).Unmarshal(a.OldValue, old); err != nil {
+			return err
+		}
+	}
+
+	if new != nil && len(a.NewValue) > 0 {
+		if err := (json.Default{}).Unmarshal(a.NewValue, new); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+type AuditLogChangeKey string
+
+const (
+	// Guild keys:
+
+	// string
+	AuditGuildName AuditLogChangeKey = "name"
+	// Hash
+	AuditGuildIconHash AuditLogChangeKey = "icon_hash"
+	// Hash
+	AuditGuildSplashHash AuditLogChangeKey = "splash_hash"
+	// Snowflake
+	AuditGuildOwnerID AuditLogChangeKey = "owner_id"
+	// string
+	AuditGuildRegion AuditLogChangeKey = "region"
+	// Snowflake
+	AuditGuildAFKChannelID AuditLogChangeKey = "afk_channel_id"
+	// Seconds
+	AuditGuildAFKTimeout AuditLogChangeKey = "afk_timeout"
+	// MFALevel
+	AuditGuildMFA AuditLogChangeKey = "mfa_level"
+	// Verification
+	AuditGuildVerification AuditLogChangeKey = "verification_level"
+	// ExplicitFilter
+	AuditGuildExplicitFilter AuditLogChangeKey = "explicit_content_filter"
+	// Notification
+	AuditGuildNotification AuditLogChangeKey = "default_message_notifications"
+	// string
+	AuditGuildVanityURLCode AuditLogChangeKey = "vanity_url_code"
+	// []Role, partial with only ID and Name
+	AuditGuildRoleAdd AuditLogChangeKey = "$add"
+	// []Role, partial with only ID and Name
+	AuditGuildRoleRemove AuditLogChangeKey = "$remove"
+	// int
+	AuditGuildPruneDeleteDays AuditLogChangeKey = "prune_delete_days"
+	// bool
+	AuditGuildWidgetEnabled AuditLogChangeKey = "widget_enabled"
+	// Snowflake
+	AuditGuildWidgetChannelID AuditLogChangeKey = "widget_channel_id"
+	// Snowflake
+	AuditGuildSystemChannelID AuditLogChangeKey = "system_channel_id"
+
+	// Channel keys:
+
+	// int
+	AuditChannelPosition AuditLogChangeKey = "position"
+	// string
+	AuditChannelTopic AuditLogChangeKey = "topic"
+	// uint
+	AuditChannelBitrate AuditLogChangeKey = "bitrate"
+	// []Overwrite
+	AuditChannelPermissionOverwrites AuditLogChangeKey = "permission_overwrites"
+	// bool
+	AuditChannelNSFW AuditLogChangeKey = "nsfw"
+	// Snowflake
+	AuditChannelApplicationID AuditLogChangeKey = "application_id"
+	// Seconds
+	AuditChannelRateLimitPerUser AuditLogChangeKey = "rate_limit_per_user"
+
+	// Role keys:
+
+	// Permissions
+	AuditRolePermissions AuditLogChangeKey = "permissions"
+	// Color
+	AuditRoleColor AuditLogChangeKey = "color"
+	// bool
+	AuditRoleHoist AuditLogChangeKey = "hoist"
+	// bool
+	AuditRoleMentionable AuditLogChangeKey = "mentionable"
+	// Permissions
+	AuditRoleAllow AuditLogChangeKey = "allow"
+	// Permissions
+	AuditRoleDeny AuditLogChangeKey = "deny"
+
+	// Invite keys:
+
+	// string
+	AuditInviteCode AuditLogChangeKey = "code"
+	// Snowflake
+	AuditInviteChannelID AuditLogChangeKey = "channel_id"
+	// Snowflake
+	AuditInviteInviterID AuditLogChangeKey = "inviter_id"
+	// int
+	AuditInviteMaxUses AuditLogChangeKey = "max_uses"
+	// int
+	AuditInviteUses AuditLogChangeKey = "uses"
+	// Seconds
+	AuditInviteMaxAge AuditLogChangeKey = "max_age"
+	// bool
+	AuditInviteTemporary AuditLogChangeKey = "temporary"
+
+	// User keys:
+
+	// bool
+	AuditUserDeaf AuditLogChangeKey = "deaf"
+	// bool
+	AuditUserMute AuditLogChangeKey = "mute"
+	// string
+	AuditUserNick AuditLogChangeKey = "nick"
+	// Hash
+	AuditUserAvatarHash AuditLogChangeKey = "avatar_hash"
+
+	// Any keys:
+
+	// Snowflake
+	AuditAnyID AuditLogChangeKey = "id"
+	// ChannelType or string
+	AuditAnyType AuditLogChangeKey = "type"
+
+	// Integration keys:
+
+	// bool
+	AuditIntegrationEnableEmoticons AuditLogChangeKey = "enable_emoticons"
+	// int
+	AuditIntegrationExpireBehavior AuditLogChangeKey = "expire_behavior"
+	// int
+	AuditIntegrationExpireGracePeriod AuditLogChangeKey = "expire_grace_period"
+)
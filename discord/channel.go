@@ -15,14 +15,15 @@ type Channel struct {
 
 	Icon Hash `json:"icon,omitempty"`
 
-	// Direct Messaging fields
+	// Direct Messaging fields. DMOwnerID is also the creator of a thread.
 	DMOwnerID    Snowflake `json:"owner_id,string,omitempty"`
 	DMRecipients []User    `json:"recipients,omitempty"`
 
 	// AppID of the group DM creator if it's bot-created
 	AppID Snowflake `json:"application_id,string,omitempty"`
 
-	// ID of the category the channel is in, if any.
+	// ID of the category the channel is in, if any. For threads, this is the
+	// ID of the channel the thread was created in.
 	CategoryID Snowflake `json:"parent_id,string,omitempty"`
 
 	LastPinTime Timestamp `json:"last_pin_timestamp,omitempty"`
@@ -36,9 +37,20 @@ type Channel struct {
 	// "manage_channel" permissions are unaffected.
 	UserRateLimit Seconds `json:"rate_limit_per_user,omitempty"`
 
-	// Voice, so GuildVoice only
+	// Voice, so GuildVoice and GuildStageVoice only
 	VoiceBitrate   uint `json:"bitrate,omitempty"`
 	VoiceUserLimit uint `json:"user_limit,omitempty"`
+
+	// Thread fields, so threads only. MessageCount and MemberCount stop
+	// counting at 50.
+	MessageCount   int             `json:"message_count,omitempty"`
+	MemberCount    int             `json:"member_count,omitempty"`
+	ThreadMetadata *ThreadMetadata `json:"thread_metadata,omitempty"`
+	// ThreadMember is the current user, if they have joined the thread.
+	ThreadMember *ThreadMember `json:"member,omitempty"`
+
+	// Default duration of the threads created in the channel.
+	DefaultAutoArchiveDuration ArchiveDuration `json:"default_auto_archive_duration,omitempty"`
 }
 
 func (ch Channel) Mention() string {
@@ -55,8 +67,20 @@ const (
 	GuildCategory
 	GuildNews
 	GuildStore
+	_
+	_
+	_
+	GuildNewsThread
+	GuildPublicThread
+	GuildPrivateThread
+	GuildStageVoice
 )
 
+// IsThread returns true if the channel type is a thread.
+func (t ChannelType) IsThread() bool {
+	return t >= GuildNewsThread && t <= GuildPrivateThread
+}
+
 type Overwrite struct {
 	ID    Snowflake     `json:"id,string,omitempty"`
 	Type  OverwriteType `json:"type"`
@@ -70,3 +94,76 @@ const (
 	OverwriteRole   OverwriteType = "role"
 	OverwriteMember OverwriteType = "member"
 )
+
+// RoleOverwrite returns an empty overwrite for the role, to be built with
+// Allowing, Denying and Inheriting:
+//
+//	ow := discord.RoleOverwrite(roleID).
+//		Allowing(discord.PermissionSendMessages).
+//		Denying(discord.PermissionAttachFiles)
+func RoleOverwrite(roleID Snowflake) Overwrite {
+	return Overwrite{ID: roleID, Type: OverwriteRole}
+}
+
+// MemberOverwrite returns an empty overwrite for the member, like
+// RoleOverwrite.
+func MemberOverwrite(userID Snowflake) Overwrite {
+	return Overwrite{ID: userID, Type: OverwriteMember}
+}
+
+// Allowing returns a copy of the overwrite that allows the permissions.
+func (o Overwrite) Allowing(perm Permissions) Overwrite {
+	o.Allow |= perm
+	o.Deny &^= perm
+	return o
+}
+
+// Denying returns a copy of the overwrite that denies the permissions.
+func (o Overwrite) Denying(perm Permissions) Overwrite {
+	o.Deny |= perm
+	o.Allow &^= perm
+	return o
+}
+
+// Inheriting returns a copy of the overwrite that neither allows nor denies
+// the permissions, so they're inherited from the roles.
+func (o Overwrite) Inheriting(perm Permissions) Overwrite {
+	o.Allow &^= perm
+	o.Deny &^= perm
+	return o
+}
+
+// ThreadMetadata is the state of a thread.
+type ThreadMetadata struct {
+	Archived bool `json:"archived"`
+	// Duration of inactivity after which the thread is archived.
+	AutoArchiveDuration ArchiveDuration `json:"auto_archive_duration"`
+	// Time the thread was last archived or unarchived.
+	ArchiveTimestamp Timestamp `json:"archive_timestamp"`
+	// Locked threads can only be unarchived by members with the
+	// "manage_threads" permission.
+	Locked bool `json:"locked,omitempty"`
+}
+
+// ArchiveDuration is the inactivity duration in minutes after which a thread
+// is archived.
+type ArchiveDuration int
+
+const (
+	OneHourArchive   ArchiveDuration = 60
+	OneDayArchive    ArchiveDuration = 24 * 60
+	ThreeDaysArchive ArchiveDuration = 3 * 24 * 60
+	SevenDaysArchive ArchiveDuration = 7 * 24 * 60
+)
+
+// ThreadMember is a user that has joined a thread.
+type ThreadMember struct {
+	// ID of the thread, omitted in the member of a Channel.
+	ID Snowflake `json:"id,string,omitempty"`
+	// UserID is omitted in the member of a Channel.
+	UserID Snowflake `json:"user_id,string,omitempty"`
+
+	JoinTimestamp Timestamp `json:"join_timestamp"`
+	// Flags are only used for notifications.
+	Flags uint64 `json:"flags"`
+}
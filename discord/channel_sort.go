@@ -0,0 +1,145 @@
+package discord
+
+import "sort"
+
+// ChannelLess reports whether channel a is shown before channel b by clients,
+// if they are in the same category, or both outside of one. Channels come
+// before categories, text channels before voice and stage channels, then
+// channels are sorted by position, then by ID.
+func ChannelLess(a, b Channel) bool {
+	var ca, cb = a.Type == GuildCategory, b.Type == GuildCategory
+	if ca != cb {
+		return cb
+	}
+
+	var va, vb = a.Type.isVoice(), b.Type.isVoice()
+	if va != vb {
+		return vb
+	}
+
+	if a.Position != b.Position {
+		return a.Position < b.Position
+	}
+
+	return a.ID < b.ID
+}
+
+func (t ChannelType) isVoice() bool {
+	return t == GuildVoice || t == GuildStageVoice
+}
+
+// SortChannels sorts the guild channels in the order clients show them: the
+// channels without a category, then each category followed by its channels,
+// sorted with ChannelLess. Threads follow the channel they were created in,
+// oldest first.
+func SortChannels(channels []Channel) {
+	var threads = map[Snowflake][]Channel{}
+	var others = make([]Channel, 0, len(channels))
+
+	for _, ch := range channels {
+		if ch.Type.IsThread() {
+			threads[ch.CategoryID] = append(threads[ch.CategoryID], ch)
+		} else {
+			others = append(others, ch)
+		}
+	}
+
+	var sorted = make([]Channel, 0, len(channels))
+
+	var add = func(ch Channel) {
+		sorted = append(sorted, ch)
+
+		if ts, ok := threads[ch.ID]; ok {
+			sortByID(ts)
+			sorted = append(sorted, ts...)
+			delete(threads, ch.ID)
+		}
+	}
+
+	for _, c := range ChannelsByCategory(others) {
+		if c.Category != nil {
+			add(*c.Category)
+		}
+		for _, ch := range c.Channels {
+			add(ch)
+		}
+	}
+
+	// Threads whose channel is missing go last.
+	var orphans []Channel
+	for _, ts := range threads {
+		orphans = append(orphans, ts...)
+	}
+	sortByID(orphans)
+
+	copy(channels, append(sorted, orphans...))
+}
+
+// CategoryChannels is a category and the channels in it.
+type CategoryChannels struct {
+	// Category is nil for the channels that aren't in a category.
+	Category *Channel
+	Channels []Channel
+}
+
+// ChannelsByCategory groups the guild channels by category, in the order
+// clients show them: the channels without a category come first, then the
+// categories, with the channels sorted with ChannelLess. Channels in a
+// category that's missing are treated as without one. Threads are left out.
+func ChannelsByCategory(channels []Channel) []CategoryChannels {
+	var categories []Channel
+	var children = map[Snowflake][]Channel{}
+
+	for _, ch := range channels {
+		if ch.Type == GuildCategory {
+			categories = append(categories, ch)
+			children[ch.ID] = nil
+		}
+	}
+
+	for _, ch := range channels {
+		if ch.Type == GuildCategory || ch.Type.IsThread() {
+			continue
+		}
+
+		var parent = ch.CategoryID
+		if _, ok := children[parent]; !ok {
+			parent = 0
+		}
+
+		children[parent] = append(children[parent], ch)
+	}
+
+	sortChannels(categories)
+
+	var sorted = make([]CategoryChannels, 0, len(categories)+1)
+
+	if chs := children[0]; len(chs) > 0 {
+		sortChannels(chs)
+		sorted = append(sorted, CategoryChannels{Channels: chs})
+	}
+
+	for i := range categories {
+		var chs = children[categories[i].ID]
+		sortChannels(chs)
+
+		sorted = append(sorted, CategoryChannels{
+			Category: &categories[i],
+			Channels: chs,
+		})
+	}
+
+	return sorted
+}
+
+func sortChannels(chs []Channel) {
+	sort.SliceStable(chs, func(i, j int) bool {
+		return ChannelLess(chs[i], chs[j])
+	})
+}
+
+func sortByID(chs []Channel) {
+	sort.Slice(chs, func(i, j int) bool {
+		return chs[i].ID < chs[j].ID
+	})
+}
@@ -0,0 +1,67 @@
+// +build unit
+
+package discord
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestChannelsByCategory(t *testing.T) {
+	var channels = []Channel{
+		{ID: 1, Type: GuildCategory, Position: 1},
+		{ID: 2, Type: GuildCategory, Position: 0},
+		{ID: 3, Type: GuildVoice, Position: 0, CategoryID: 1},
+		{ID: 4, Type: GuildText, Position: 1, CategoryID: 1},
+		{ID: 5, Type: GuildText, Position: 1, CategoryID: 1},
+		{ID: 6, Type: GuildText, Position: 0},
+		{ID: 7, Type: GuildPublicThread, CategoryID: 4},
+		{ID: 8, Type: GuildNews, Position: 0, CategoryID: 2},
+	}
+
+	var sorted = ChannelsByCategory(channels)
+
+	var ids [][]Snowflake
+	for _, c := range sorted {
+		var group []Snowflake
+		if c.Category != nil {
+			group = append(group, c.Category.ID)
+		} else {
+			group = append(group, 0)
+		}
+		for _, ch := range c.Channels {
+			group = append(group, ch.ID)
+		}
+		ids = append(ids, group)
+	}
+
+	// The first ID of each group is the category.
+	var expect = [][]Snowflake{{0, 6}, {2, 8}, {1, 4, 5, 3}}
+	if !reflect.DeepEqual(ids, expect) {
+		t.Fatalf("Unexpected order %v, expected %v", ids, expect)
+	}
+}
+
+func TestSortChannels(t *testing.T) {
+	var channels = []Channel{
+		{ID: 1, Type: GuildCategory, Position: 0},
+		{ID: 2, Type: GuildVoice, Position: 0, CategoryID: 1},
+		{ID: 3, Type: GuildText, Position: 5, CategoryID: 1},
+		{ID: 4, Type: GuildText, Position: 0},
+		{ID: 5, Type: GuildPublicThread, CategoryID: 3},
+		{ID: 6, Type: GuildPrivateThread, CategoryID: 9},
+		{ID: 7, Type: GuildText, Position: 0, CategoryID: 8},
+	}
+
+	SortChannels(channels)
+
+	var ids []Snowflake
+	for _, ch := range channels {
+		ids = append(ids, ch.ID)
+	}
+
+	var expect = []Snowflake{4, 7, 1, 3, 5, 2, 6}
+	if !reflect.DeepEqual(ids, expect) {
+		t.Fatalf("Unexpected order %v, expected %v", ids, expect)
+	}
+}
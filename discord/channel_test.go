@@ -0,0 +1,23 @@
+// +build unit
+
+package discord
+
+import "testing"
+
+func TestOverwriteBuilder(t *testing.T) {
+	ow := RoleOverwrite(1).
+		Allowing(PermissionSendMessages | PermissionAttachFiles).
+		Denying(PermissionAttachFiles | PermissionEmbedLinks).
+		Inheriting(PermissionEmbedLinks)
+
+	var expected = Overwrite{
+		ID:    1,
+		Type:  OverwriteRole,
+		Allow: PermissionSendMessages,
+		Deny:  PermissionAttachFiles,
+	}
+
+	if ow != expected {
+		t.Fatalf("Unexpected overwrite %+v, expected %+v", ow, expected)
+	}
+}
@@ -0,0 +1,54 @@
+package discord
+
+// Command is an application command, also known as a slash command.
+//
+// https://discord.com/developers/docs/interactions/slash-commands#applicationcommand
+type Command struct {
+	ID    Snowflake `json:"id,string,omitempty"`
+	AppID Snowflake `json:"application_id,string,omitempty"`
+	// GuildID is only set for guild commands.
+	GuildID Snowflake `json:"guild_id,string,omitempty"`
+
+	Name        string          `json:"name"`        // 1-32 chars
+	Description string          `json:"description"` // 1-100 chars
+	Options     []CommandOption `json:"options,omitempty"`
+}
+
+type CommandOptionType uint8
+
+const (
+	SubcommandOption CommandOptionType = iota + 1
+	SubcommandGroupOption
+	StringOption
+	IntegerOption
+	BooleanOption
+	UserOption
+	ChannelOption
+	RoleOption
+	MentionableOption
+	NumberOption
+)
+
+// CommandOption is an option of a command. Subcommands and subcommand groups
+// are also options, with their own Options.
+//
+// https://discord.com/developers/docs/interactions/slash-commands#applicationcommandoption
+type CommandOption struct {
+	Type        CommandOptionType `json:"type"`
+	Name        string            `json:"name"`
+	Description string            `json:"description"`
+	Required    bool              `json:"required,omitempty"`
+
+	// Choices are only valid for StringOption, IntegerOption and
+	// NumberOption.
+	Choices []CommandOptionChoice `json:"choices,omitempty"`
+	// Options are only valid for SubcommandOption and SubcommandGroupOption.
+	Options []CommandOption `json:"options,omitempty"`
+}
+
+// CommandOptionChoice is a predefined value the user can pick. Value is either
+// a string or a number, depending on the option type.
+type CommandOptionChoice struct {
+	Name  string      `json:"name"`
+	Value interface{} `json:"value"`
+}
@@ -0,0 +1,294 @@
+package discord
+
+import (
+	"strconv"
+
+	"github.com/diamondburned/arikawa/internal/json"
+	"github.com/pkg/errors"
+)
+
+// ComponentType is the type of a message component.
+type ComponentType uint8
+
+const (
+	ActionRowComponent ComponentType = iota + 1
+	ButtonComponent
+	SelectMenuComponent
+)
+
+// Component is a message component: an ActionRow, a Button or a SelectMenu.
+//
+// https://discord.com/developers/docs/interactions/message-components
+type Component interface {
+	Type() ComponentType
+	Validate() error
+}
+
+var (
+	// ErrComponentNotInRow is returned if a Button or SelectMenu is not inside
+	// an ActionRow.
+	ErrComponentNotInRow = errors.New("Components must be inside action rows")
+	// ErrNestedActionRow is returned if an ActionRow is inside another one.
+	ErrNestedActionRow = errors.New("Action rows can't be nested")
+	// ErrMixedActionRow is returned if an ActionRow has a SelectMenu along
+	// with other components.
+	ErrMixedActionRow = errors.New(
+		"Action rows with a select menu can't have other components")
+)
+
+// Components is a list of message components. It is used instead of
+// []Component, as it can be unmarshaled.
+type Components []Component
+
+// Validate checks the nesting rules and the limits of the components. The
+// top-level components must all be ActionRows, of which there can be up to 5.
+func (c Components) Validate() error {
+	if len(c) > 5 {
+		return &ErrOverbound{len(c), 5, "Action rows"}
+	}
+
+	for i, component := range c {
+		if _, ok := component.(*ActionRow); !ok {
+			return ErrComponentNotInRow
+		}
+
+		if err := component.Validate(); err != nil {
+			return errors.Wrap(err, "Action row "+strconv.Itoa(i))
+		}
+	}
+
+	return nil
+}
+
+func (c *Components) UnmarshalJSON(v []byte) error {
+	var raws []json.Raw
+	if err := (json.Default{}).Unmarshal(v, &raws); err != nil {
+		return err
+	}
+
+	*c = make(Components, len(raws))
+
+	for i, raw := range raws {
+		var head struct {
+			Type ComponentType `json:"type"`
+		}
+
+		if err := (json.Default{}).Unmarshal(raw, &head); err != nil {
+			return err
+		}
+
+		var component Component
+
+		switch head.Type {
+		case ActionRowComponent:
+			component = &ActionRow{}
+		case ButtonComponent:
+			component = &Button{}
+		case SelectMenuComponent:
+			component = &SelectMenu{}
+		default:
+			return errors.New(
+				"Unknown component type " + strconv.Itoa(int(head.Type)))
+		}
+
+		if err := (json.Default{}).Unmarshal(raw, component); err != nil {
+			return err
+		}
+
+		(*c)[i] = component
+	}
+
+	return nil
+}
+
+// ActionRow is a container for up to 5 Buttons or a single SelectMenu.
+type ActionRow struct {
+	Components Components `json:"components"`
+}
+
+func (*ActionRow) Type() ComponentType {
+	return ActionRowComponent
+}
+
+func (r *ActionRow) Validate() error {
+	if len(r.Components) > 5 {
+		return &ErrOverbound{len(r.Components), 5, "Components"}
+	}
+
+	for i, component := range r.Components {
+		switch component.(type) {
+		case *ActionRow:
+			return ErrNestedActionRow
+		case *SelectMenu:
+			if len(r.Components) > 1 {
+				return ErrMixedActionRow
+			}
+		}
+
+		if err := component.Validate(); err != nil {
+			return errors.Wrap(err, "Component "+strconv.Itoa(i))
+		}
+	}
+
+	return nil
+}
+
+func (r ActionRow) MarshalJSON() ([]byte, error) {
+	type raw ActionRow
+
+	return (json.Default{}).Marshal(struct {
+		Type ComponentType `json:"type"`
+		raw
+	}{ActionRowComponent, raw(r)})
+}
+
+type ButtonStyle uint8
+
+const (
+	PrimaryButton ButtonStyle = iota + 1
+	SecondaryButton
+	SuccessButton
+	DangerButton
+	// LinkButton opens the URL instead of sending an interaction. It has no
+	// CustomID.
+	LinkButton
+)
+
+// ComponentEmoji is the partial emoji used in Buttons and SelectOptions. ID is
+// 0 for Unicode emojis.
+type ComponentEmoji struct {
+	ID       Snowflake `json:"id,string,omitempty"`
+	Name     string    `json:"name,omitempty"`
+	Animated bool      `json:"animated,omitempty"`
+}
+
+// Button is a clickable component. Clicking on it sends a ComponentInteraction
+// with its CustomID, unless it is a LinkButton.
+type Button struct {
+	Style    ButtonStyle     `json:"style"`
+	Label    string          `json:"label,omitempty"`
+	Emoji    *ComponentEmoji `json:"emoji,omitempty"`
+	CustomID string          `json:"custom_id,omitempty"`
+	URL      URL             `json:"url,omitempty"`
+	Disabled bool            `json:"disabled,omitempty"`
+}
+
+func (*Button) Type() ComponentType {
+	return ButtonComponent
+}
+
+func (b *Button) Validate() error {
+	if len(b.Label) > 80 {
+		return &ErrOverbound{len(b.Label), 80, "Label"}
+	}
+
+	if len(b.CustomID) > 100 {
+		return &ErrOverbound{len(b.CustomID), 100, "Custom ID"}
+	}
+
+	if b.Style == LinkButton {
+		if b.URL == "" || b.CustomID != "" {
+			return errors.New("Link buttons must have a URL and no custom ID")
+		}
+		return nil
+	}
+
+	if b.CustomID == "" || b.URL != "" {
+		return errors.New("Buttons must have a custom ID and no URL")
+	}
+
+	return nil
+}
+
+func (b Button) MarshalJSON() ([]byte, error) {
+	type raw Button
+
+	return (json.Default{}).Marshal(struct {
+		Type ComponentType `json:"type"`
+		raw
+	}{ButtonComponent, raw(b)})
+}
+
+// SelectMenu is a dropdown of options. Choosing options sends a
+// ComponentInteraction with its CustomID and the values of the chosen options.
+type SelectMenu struct {
+	CustomID    string         `json:"custom_id"`
+	Options     []SelectOption `json:"options"`
+	Placeholder string         `json:"placeholder,omitempty"`
+
+	// MinValues and MaxValues default to 1 if nil.
+	MinValues json.OptionInt `json:"min_values,omitempty"`
+	MaxValues json.OptionInt `json:"max_values,omitempty"`
+
+	Disabled bool `json:"disabled,omitempty"`
+}
+
+type SelectOption struct {
+	Label       string          `json:"label"`
+	Value       string          `json:"value"`
+	Description string          `json:"description,omitempty"`
+	Emoji       *ComponentEmoji `json:"emoji,omitempty"`
+	Default     bool            `json:"default,omitempty"`
+}
+
+func (*SelectMenu) Type() ComponentType {
+	return SelectMenuComponent
+}
+
+func (s *SelectMenu) Validate() error {
+	if s.CustomID == "" {
+		return errors.New("Select menus must have a custom ID")
+	}
+
+	if len(s.CustomID) > 100 {
+		return &ErrOverbound{len(s.CustomID), 100, "Custom ID"}
+	}
+
+	if len(s.Placeholder) > 100 {
+		return &ErrOverbound{len(s.Placeholder), 100, "Placeholder"}
+	}
+
+	if len(s.Options) == 0 {
+		return errors.New("Select menus must have at least one option")
+	}
+
+	if len(s.Options) > 25 {
+		return &ErrOverbound{len(s.Options), 25, "Options"}
+	}
+
+	if s.MinValues != nil && *s.MinValues > 25 {
+		return &ErrOverbound{*s.MinValues, 25, "Min values"}
+	}
+
+	if s.MaxValues != nil && *s.MaxValues > 25 {
+		return &ErrOverbound{*s.MaxValues, 25, "Max values"}
+	}
+
+	for i, opt := range s.Options {
+		if len(opt.Label) > 100 {
+			return &ErrOverbound{len(opt.Label), 100,
+				"Label of option " + strconv.Itoa(i)}
+		}
+
+		if len(opt.Value) > 100 {
+			return &ErrOverbound{len(opt.Value), 100,
+				"Value of option " + strconv.Itoa(i)}
+		}
+
+		if len(opt.Description) > 100 {
+			return &ErrOverbound{len(opt.Description), 100,
+				"Description of option " + strconv.Itoa(i)}
+		}
+	}
+
+	return nil
+}
+
+func (s SelectMenu) MarshalJSON() ([]byte, error) {
+	type raw SelectMenu
+
+	return (json.Default{}).Marshal(struct {
+		Type ComponentType `json:"type"`
+		raw
+	}{SelectMenuComponent, raw(s)})
+}
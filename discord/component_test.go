@@ -0,0 +1,83 @@
+// +build unit
+
+package discord
+
+import (
+	"testing"
+
+	"github.com/diamondburned/arikawa/internal/json"
+	"github.com/pkg/errors"
+)
+
+func TestComponentsJSON(t *testing.T) {
+	var components = Components{
+		&ActionRow{Components: Components{
+			&Button{Style: PrimaryButton, Label: "Yes", CustomID: "yes"},
+			&Button{Style: LinkButton, Label: "Docs", URL: "https://a.b"},
+		}},
+		&ActionRow{Components: Components{
+			&SelectMenu{CustomID: "pick", Options: []SelectOption{
+				{Label: "A", Value: "a"},
+			}},
+		}},
+	}
+
+	b, err := (json.Default{}).Marshal(components)
+	if err != nil {
+		t.Fatal("Failed to marshal:", err)
+	}
+
+	var got Components
+	if err := (json.Default{}).Unmarshal(b, &got); err != nil {
+		t.Fatal("Failed to unmarshal:", err)
+	}
+
+	if len(got) != 2 {
+		t.Fatal("Unexpected number of rows:", len(got))
+	}
+
+	row, ok := got[0].(*ActionRow)
+	if !ok || len(row.Components) != 2 {
+		t.Fatalf("Unexpected first row: %#v", got[0])
+	}
+
+	button, ok := row.Components[1].(*Button)
+	if !ok || button.Style != LinkButton || button.URL != "https://a.b" {
+		t.Fatalf("Unexpected button: %#v", row.Components[1])
+	}
+
+	row = got[1].(*ActionRow)
+	menu, ok := row.Components[0].(*SelectMenu)
+	if !ok || menu.CustomID != "pick" || len(menu.Options) != 1 {
+		t.Fatalf("Unexpected select menu: %#v", row.Components[0])
+	}
+}
+
+func TestComponentsValidate(t *testing.T) {
+	var button = &Button{Style: PrimaryButton, CustomID: "a"}
+	var menu = &SelectMenu{CustomID: "b", Options: []SelectOption{{}}}
+
+	var tests = []struct {
+		name       string
+		components Components
+		err        error
+	}{
+		{"valid", Components{&ActionRow{Components{button, button}}}, nil},
+		{"not in row", Components{button}, ErrComponentNotInRow},
+		{"nested", Components{&ActionRow{Components{&ActionRow{}}}},
+			ErrNestedActionRow},
+		{"mixed", Components{&ActionRow{Components{menu, button}}},
+			ErrMixedActionRow},
+	}
+
+	for _, test := range tests {
+		if err := test.components.Validate(); errors.Cause(err) != test.err {
+			t.Errorf("%s: expected %v, got %v", test.name, test.err, err)
+		}
+	}
+
+	var link = &Button{Style: LinkButton, CustomID: "a"}
+	if err := (&ActionRow{Components{link}}).Validate(); err == nil {
+		t.Error("Expected error for link button without URL")
+	}
+}
@@ -1,5 +1,7 @@
 package discord
 
+import "github.com/diamondburned/arikawa/internal/json"
+
 type Guild struct {
 	ID     Snowflake `json:"id,string"`
 	Name   string    `json:"name"`
@@ -49,8 +51,71 @@ type Guild struct {
 
 	// Defaults to en-US, only set if guild has DISCOVERABLE
 	PreferredLocale string `json:"preferred_locale"`
+
+	// Only filled by GuildWithCount.
+	ApproximateMembers   uint64 `json:"approximate_member_count,omitempty"`
+	ApproximatePresences uint64 `json:"approximate_presence_count,omitempty"`
+}
+
+// GuildPreview is the public information of a guild, which is available for
+// discoverable guilds even without being in them.
+type GuildPreview struct {
+	ID   Snowflake `json:"id,string"`
+	Name string    `json:"name"`
+
+	Icon            Hash `json:"icon"`
+	Splash          Hash `json:"splash"`
+	DiscoverySplash Hash `json:"discovery_splash"`
+
+	Emojis   []Emoji        `json:"emojis"`
+	Features []GuildFeature `json:"features"`
+
+	ApproximateMembers   uint64 `json:"approximate_member_count"`
+	ApproximatePresences uint64 `json:"approximate_presence_count"`
+
+	Description string `json:"description"`
+}
+
+// WelcomeScreen is shown to new members of community guilds.
+type WelcomeScreen struct {
+	Description string           `json:"description,omitempty"`
+	Channels    []WelcomeChannel `json:"welcome_channels"` // max 5
+}
+
+// WelcomeChannel is a channel suggested in the welcome screen.
+type WelcomeChannel struct {
+	ChannelID   Snowflake `json:"channel_id,string"`
+	Description string    `json:"description"`
+
+	// The emoji is a custom one if EmojiID is valid, or a Unicode one
+	// otherwise.
+	EmojiID   Snowflake `json:"emoji_id,string,omitempty"`
+	EmojiName string    `json:"emoji_name,omitempty"`
+}
+
+// MembershipScreening is the form new members of a community guild must
+// complete before they can talk.
+type MembershipScreening struct {
+	Version     Timestamp                  `json:"version"`
+	FormFields  []MembershipScreeningField `json:"form_fields"`
+	Description string                     `json:"description,omitempty"`
 }
 
+// MembershipScreeningField is a field of the membership screening form.
+type MembershipScreeningField struct {
+	Type     MembershipScreeningFieldType `json:"field_type"`
+	Label    string                       `json:"label"`
+	Values   []string                     `json:"values,omitempty"`
+	Required bool                         `json:"required"`
+}
+
+type MembershipScreeningFieldType string
+
+const (
+	// TermsField asks the member to agree to the rules in Values.
+	TermsField MembershipScreeningFieldType = "TERMS"
+)
+
 type Role struct {
 	ID   Snowflake `json:"id,string"`
 	Name string    `json:"name"`
@@ -63,12 +128,70 @@ type Role struct {
 
 	Managed     bool `json:"managed"`
 	Mentionable bool `json:"mentionable"`
+
+	// Tags tell what manages the role, if anything.
+	Tags *RoleTags `json:"tags,omitempty"`
 }
 
 func (r Role) Mention() string {
 	return "<&" + r.ID.String() + ">"
 }
 
+// IsBotManaged returns true if the role belongs to a bot. It's given to the
+// bot only, and can't be given to anyone else.
+func (r Role) IsBotManaged() bool {
+	return r.Tags != nil && r.Tags.BotID.Valid()
+}
+
+// IsBoosterRole returns true if the role is the one of the guild's boosters,
+// which is only given by Discord.
+func (r Role) IsBoosterRole() bool {
+	return r.Tags != nil && r.Tags.PremiumSubscriber
+}
+
+// RoleTags tell what manages a role. Such roles can't be given to members.
+type RoleTags struct {
+	// BotID is the ID of the bot the role belongs to.
+	BotID Snowflake `json:"bot_id,string,omitempty"`
+	// IntegrationID is the ID of the integration the role belongs to.
+	IntegrationID Snowflake `json:"integration_id,string,omitempty"`
+	// PremiumSubscriber is true for the role of the guild's boosters.
+	PremiumSubscriber bool `json:"-"`
+}
+
+// UnmarshalJSON sets PremiumSubscriber if premium_subscriber is there, as it's
+// always null.
+func (t *RoleTags) UnmarshalJSON(b []byte) error {
+	type raw RoleTags
+
+	if err := (json.Default{}).Unmarshal(b, (*raw)(t)); err != nil {
+		return err
+	}
+
+	var fields map[string]json.Raw
+	if err := (json.Default{}).Unmarshal(b, &fields); err != nil {
+		return err
+	}
+
+	_, t.PremiumSubscriber = fields["premium_subscriber"]
+	return nil
+}
+
+// MarshalJSON sends premium_subscriber as null if PremiumSubscriber is true,
+// like Discord does.
+func (t RoleTags) MarshalJSON() ([]byte, error) {
+	type raw RoleTags
+
+	if !t.PremiumSubscriber {
+		return (json.Default{}).Marshal(raw(t))
+	}
+
+	return (json.Default{}).Marshal(struct {
+		raw
+		PremiumSubscriber json.Raw `json:"premium_subscriber"`
+	}{raw(t), json.Raw("null")})
+}
+
 type Presence struct {
 	User    User        `json:"user"`
 	RoleIDs []Snowflake `json:"roles"`
@@ -97,17 +220,49 @@ type Member struct {
 	Nick    string      `json:"nick,omitempty"`
 	RoleIDs []Snowflake `json:"roles"`
 
+	// Avatar and Banner are the ones of the member in the guild, which
+	// override the ones of the user if set.
+	Avatar Hash `json:"avatar,omitempty"`
+	Banner Hash `json:"banner,omitempty"`
+
 	Joined       Timestamp `json:"joined_at"`
 	BoostedSince Timestamp `json:"premium_since,omitempty"`
 
 	Deaf bool `json:"deaf"`
 	Mute bool `json:"mute"`
+
+	// Pending is true if the member hasn't passed the membership screening
+	// of the guild yet, in which case they can't talk.
+	Pending bool `json:"pending,omitempty"`
 }
 
 func (m Member) Mention() string {
 	return "<@!" + m.User.ID.String() + ">"
 }
 
+// AvatarURL returns the URL of the avatar of the member in the guild, or of
+// the avatar of the user if they have none.
+func (m Member) AvatarURL(guildID Snowflake) string {
+	if m.Avatar == "" {
+		return m.User.AvatarURL()
+	}
+	return imageURL(m.guildPath(guildID)+"avatars/", m.Avatar)
+}
+
+// BannerURL returns the URL of the banner of the member in the guild, or of
+// the banner of the user if they have none. It's an empty string if neither
+// has one.
+func (m Member) BannerURL(guildID Snowflake) string {
+	if m.Banner == "" {
+		return m.User.BannerURL()
+	}
+	return imageURL(m.guildPath(guildID)+"banners/", m.Banner)
+}
+
+func (m Member) guildPath(guildID Snowflake) string {
+	return "guilds/" + guildID.String() + "/users/" + m.User.ID.String() + "/"
+}
+
 type Ban struct {
 	Reason string `json:"reason,omitempty"`
 	User   User   `json:"user"`
@@ -141,6 +296,18 @@ type GuildEmbed struct {
 	ChannelID Snowflake `json:"channel_id,omitempty"`
 }
 
+// BoosterRole returns the role of the guild's boosters, or nil if the guild has
+// none.
+func (g Guild) BoosterRole() *Role {
+	for i := range g.Roles {
+		if g.Roles[i].IsBoosterRole() {
+			return &g.Roles[i]
+		}
+	}
+
+	return nil
+}
+
 // DefaultMemberColor is the color used for members without colored roles.
 var DefaultMemberColor Color = 0x0
 
@@ -0,0 +1,52 @@
+// +build unit
+
+package discord
+
+import (
+	"testing"
+
+	"github.com/diamondburned/arikawa/internal/json"
+)
+
+func TestRoleTags(t *testing.T) {
+	var tests = []struct {
+		json    string
+		booster bool
+		bot     bool
+	}{
+		{`{"id":"1","tags":{"premium_subscriber":null}}`, true, false},
+		{`{"id":"1","tags":{"bot_id":"2"}}`, false, true},
+		{`{"id":"1","tags":{}}`, false, false},
+		{`{"id":"1"}`, false, false},
+	}
+
+	for _, test := range tests {
+		var r Role
+		var err = (json.Default{}).Unmarshal([]byte(test.json), &r)
+		if err != nil {
+			t.Fatal("Failed to unmarshal role:", err)
+		}
+
+		if r.IsBoosterRole() != test.booster || r.IsBotManaged() != test.bot {
+			t.Fatalf("Unexpected tags %+v for %s", r.Tags, test.json)
+		}
+
+		if r.Tags == nil {
+			continue
+		}
+
+		b, err := (json.Default{}).Marshal(r.Tags)
+		if err != nil {
+			t.Fatal("Failed to marshal tags:", err)
+		}
+
+		var tags RoleTags
+		if err := (json.Default{}).Unmarshal(b, &tags); err != nil {
+			t.Fatal("Failed to unmarshal tags:", err)
+		}
+
+		if tags != *r.Tags {
+			t.Fatalf("Tags %+v changed to %+v through %s", *r.Tags, tags, b)
+		}
+	}
+}
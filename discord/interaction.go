@@ -0,0 +1,141 @@
+package discord
+
+import (
+	"strconv"
+
+	"github.com/diamondburned/arikawa/internal/json"
+)
+
+// Interaction is sent when a user uses a slash command or a message component,
+// such as a button.
+//
+// https://discord.com/developers/docs/interactions/slash-commands#interaction
+type Interaction struct {
+	ID    Snowflake        `json:"id,string"`
+	AppID Snowflake        `json:"application_id,string"`
+	Type  InteractionType  `json:"type"`
+	Data  *InteractionData `json:"data,omitempty"`
+
+	GuildID   Snowflake `json:"guild_id,string,omitempty"`
+	ChannelID Snowflake `json:"channel_id,string,omitempty"`
+
+	// Member is only sent in guilds, and User is only sent in DMs.
+	Member *Member `json:"member,omitempty"`
+	User   *User   `json:"user,omitempty"`
+
+	// Token is used to respond to the interaction. It is valid for 15 minutes.
+	Token   string `json:"token"`
+	Version int    `json:"version"`
+
+	// Message is the message the component is attached to, for component
+	// interactions only.
+	Message *Message `json:"message,omitempty"`
+}
+
+// UserID returns the ID of the user that invoked the interaction, regardless
+// of whether it's in a guild or a DM.
+func (i Interaction) UserID() Snowflake {
+	switch {
+	case i.Member != nil:
+		return i.Member.User.ID
+	case i.User != nil:
+		return i.User.ID
+	default:
+		return 0
+	}
+}
+
+type InteractionType uint8
+
+const (
+	PingInteraction InteractionType = iota + 1
+	CommandInteraction
+	ComponentInteraction
+)
+
+// InteractionData is the data of an interaction. Command fields are only set
+// for command interactions, and component fields for component interactions.
+type InteractionData struct {
+	// Command interactions
+	ID      Snowflake           `json:"id,string,omitempty"`
+	Name    string              `json:"name,omitempty"`
+	Options []InteractionOption `json:"options,omitempty"`
+
+	// Component interactions
+	CustomID      string        `json:"custom_id,omitempty"`
+	ComponentType ComponentType `json:"component_type,omitempty"`
+	Values        []string      `json:"values,omitempty"`
+}
+
+// InteractionOption is an option the user filled in. Subcommands and
+// subcommand groups have Options instead of a Value.
+type InteractionOption struct {
+	Name    string              `json:"name"`
+	Type    CommandOptionType   `json:"type"`
+	Value   json.Raw            `json:"value,omitempty"`
+	Options []InteractionOption `json:"options,omitempty"`
+}
+
+// String returns the value as a string. Non-string values are returned as
+// their JSON representation.
+func (o InteractionOption) String() string {
+	var s string
+	if err := (json.Default{}).Unmarshal(o.Value, &s); err != nil {
+		return string(o.Value)
+	}
+	return s
+}
+
+// Int parses the value as an integer.
+func (o InteractionOption) Int() (int64, error) {
+	return strconv.ParseInt(o.String(), 10, 64)
+}
+
+// Bool parses the value as a boolean.
+func (o InteractionOption) Bool() (bool, error) {
+	return strconv.ParseBool(o.String())
+}
+
+// Snowflake parses the value as a Snowflake, which is used for user, channel,
+// role and mentionable options.
+func (o InteractionOption) Snowflake() (Snowflake, error) {
+	return ParseSnowflake(o.String())
+}
+
+// https://discord.com/developers/docs/interactions/slash-commands#interaction-response
+type InteractionResponse struct {
+	Type InteractionResponseType  `json:"type"`
+	Data *InteractionResponseData `json:"data,omitempty"`
+}
+
+type InteractionResponseType uint8
+
+const (
+	// PongInteraction acknowledges a Ping.
+	PongInteraction InteractionResponseType = 1
+	// MessageInteractionWithSource responds with a message.
+	MessageInteractionWithSource InteractionResponseType = 4
+	// DeferredMessageInteractionWithSource acknowledges the interaction and
+	// shows a loading state. The message is sent later by editing the
+	// response.
+	DeferredMessageInteractionWithSource InteractionResponseType = 5
+	// DeferredMessageUpdate acknowledges a component interaction without
+	// changing the message, for editing it later.
+	DeferredMessageUpdate InteractionResponseType = 6
+	// UpdateMessage edits the message the component is attached to.
+	UpdateMessage InteractionResponseType = 7
+)
+
+// InteractionResponseData is the message sent as a response. Set Flags to
+// EphemeralMessage to make the response only visible to the user.
+type InteractionResponseData struct {
+	TTS        bool         `json:"tts,omitempty"`
+	Content    string       `json:"content,omitempty"`
+	Embeds     []Embed      `json:"embeds,omitempty"`
+	Components Components   `json:"components,omitempty"`
+	Flags      MessageFlags `json:"flags,omitempty"`
+
+	// AllowedMentions controls who is pinged. The api package defaults it to
+	// the AllowedMentions of the Client if nil.
+	AllowedMentions *AllowedMentions `json:"allowed_mentions,omitempty"`
+}
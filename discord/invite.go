@@ -5,13 +5,29 @@ type Invite struct {
 	Channel Channel `json:"channel"`         // partial
 	Guild   *Guild  `json:"guild,omitempty"` // partial
 
-	ApproxMembers uint `json:"approximate_members_count,omitempty"`
-
 	Target     *User          `json:"target_user,omitempty"` // partial
 	TargetType InviteUserType `json:"target_user_type,omitempty"`
 
-	// Only available if Target is
+	// Only available if the invite is fetched with counts
+	ApproxMembers   uint `json:"approximate_member_count,omitempty"`
 	ApproxPresences uint `json:"approximate_presence_count,omitempty"`
+
+	// ExpiresAt is only available if the invite is fetched with its
+	// expiration, and is invalid if the invite never expires.
+	ExpiresAt Timestamp `json:"expires_at,omitempty"`
+}
+
+// InviteMeta is an Invite with extra metadata, which is only returned when
+// listing the invites of a guild or channel.
+type InviteMeta struct {
+	Invite
+
+	Inviter   *User     `json:"inviter,omitempty"`
+	Uses      uint      `json:"uses"`
+	MaxUses   uint      `json:"max_uses"`  // 0 for unlimited
+	MaxAge    Seconds   `json:"max_age"`   // 0 for never
+	Temporary bool      `json:"temporary"` // kicked after disconnecting
+	CreatedAt Timestamp `json:"created_at"`
 }
 
 type InviteUserType uint8
@@ -0,0 +1,110 @@
+package discord
+
+import (
+	"sort"
+	"strings"
+)
+
+// MemberGroup is a group of members in the member list.
+type MemberGroup struct {
+	// Role is the hoisted role of the group. It's nil for the group of online
+	// members without a hoisted role, and for the group of offline members.
+	Role *Role
+	// Online is false for the group of offline members only.
+	Online  bool
+	Members []Member
+}
+
+// MemberList groups the members of the guild like the member list of clients:
+// the online members are grouped by their highest hoisted role, then come the
+// online members without one, then all offline members. The groups of
+// hoisted roles are ordered by role position, and members are ordered by
+// display name, then by ID. Empty groups are left out.
+//
+// Members are online if their presence has a status other than offline or
+// invisible, so members without a presence are offline.
+func MemberList(
+	guild Guild, members []Member, presences []Presence) []MemberGroup {
+
+	var online = make(map[Snowflake]bool, len(presences))
+	for _, p := range presences {
+		switch p.Status {
+		case UnknownStatus, OfflineStatus, InvisibleStatus:
+		default:
+			online[p.User.ID] = true
+		}
+	}
+
+	var hoisted []Role
+	for _, r := range guild.Roles {
+		if r.Hoist {
+			hoisted = append(hoisted, r)
+		}
+	}
+
+	// Highest role first, like in clients.
+	sort.SliceStable(hoisted, func(i, j int) bool {
+		if hoisted[i].Position != hoisted[j].Position {
+			return hoisted[i].Position > hoisted[j].Position
+		}
+		return hoisted[i].ID < hoisted[j].ID
+	})
+
+	// One group per hoisted role, then the online and offline groups.
+	var groups = make([]MemberGroup, len(hoisted)+2)
+	for i := range hoisted {
+		groups[i] = MemberGroup{Role: &hoisted[i], Online: true}
+	}
+	groups[len(hoisted)].Online = true
+
+	for _, m := range members {
+		var g = len(groups) - 1
+
+		if online[m.User.ID] {
+			g = hoistedGroup(hoisted, m)
+		}
+
+		groups[g].Members = append(groups[g].Members, m)
+	}
+
+	var list = groups[:0]
+	for _, g := range groups {
+		if len(g.Members) > 0 {
+			sortMembers(g.Members)
+			list = append(list, g)
+		}
+	}
+
+	return list
+}
+
+// hoistedGroup returns the index of the highest hoisted role of the member,
+// or the one after the roles if the member has none.
+func hoistedGroup(hoisted []Role, m Member) int {
+	for i, r := range hoisted {
+		for _, id := range m.RoleIDs {
+			if id == r.ID {
+				return i
+			}
+		}
+	}
+
+	return len(hoisted)
+}
+
+func sortMembers(members []Member) {
+	sort.SliceStable(members, func(i, j int) bool {
+		a, b := memberName(members[i]), memberName(members[j])
+		if a != b {
+			return a < b
+		}
+		return members[i].User.ID < members[j].User.ID
+	})
+}
+
+func memberName(m Member) string {
+	if m.Nick != "" {
+		return strings.ToLower(m.Nick)
+	}
+	return strings.ToLower(m.User.DisplayName())
+}
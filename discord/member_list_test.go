@@ -0,0 +1,71 @@
+// +build unit
+
+package discord
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMemberList(t *testing.T) {
+	var guild = Guild{
+		Roles: []Role{
+			{ID: 1, Position: 0},
+			{ID: 2, Position: 1, Hoist: true},
+			{ID: 3, Position: 2, Hoist: true},
+			{ID: 4, Position: 3},
+		},
+	}
+
+	var member = func(id Snowflake, name string, roles ...Snowflake) Member {
+		return Member{User: User{ID: id, Username: name}, RoleIDs: roles}
+	}
+
+	var members = []Member{
+		member(10, "bob", 2),
+		member(11, "Alice", 2, 3),
+		member(12, "carol", 4),
+		member(13, "dave", 2),
+		member(14, "eve", 3),
+		member(15, "Bob"),
+	}
+
+	var presences = []Presence{
+		{User: User{ID: 10}, Status: OnlineStatus},
+		{User: User{ID: 11}, Status: IdleStatus},
+		{User: User{ID: 12}, Status: DoNotDisturbStatus},
+		{User: User{ID: 13}, Status: InvisibleStatus},
+		{User: User{ID: 15}, Status: OnlineStatus},
+	}
+
+	var groups [][]Snowflake
+	for _, g := range MemberList(guild, members, presences) {
+		var ids []Snowflake
+		if g.Role != nil {
+			ids = append(ids, g.Role.ID)
+		} else if g.Online {
+			ids = append(ids, 0)
+		} else {
+			ids = append(ids, 1<<63-1)
+		}
+
+		for _, m := range g.Members {
+			ids = append(ids, m.User.ID)
+		}
+
+		groups = append(groups, ids)
+	}
+
+	// The first ID of each group is the role, 0 for online members and the
+	// maximum for offline members.
+	var expect = [][]Snowflake{
+		{3, 11},
+		{2, 10},
+		{0, 15, 12},
+		{1<<63 - 1, 13, 14},
+	}
+
+	if !reflect.DeepEqual(groups, expect) {
+		t.Fatalf("Unexpected groups %v, expected %v", groups, expect)
+	}
+}
@@ -0,0 +1,213 @@
+package discord
+
+import (
+	"regexp"
+
+	"github.com/diamondburned/arikawa/internal/json"
+	"github.com/pkg/errors"
+)
+
+var (
+	UserMentionRegex    = regexp.MustCompile(`<@!?(\d+)>`)
+	RoleMentionRegex    = regexp.MustCompile(`<@&(\d+)>`)
+	ChannelMentionRegex = regexp.MustCompile(`<#(\d+)>`)
+)
+
+// ParseUserMentions parses all user mentions in the content, including
+// nickname mentions. Duplicate IDs are removed.
+func ParseUserMentions(content string) []Snowflake {
+	return parseMentions(UserMentionRegex, content)
+}
+
+// ParseRoleMentions parses all role mentions in the content. Duplicate IDs are
+// removed.
+func ParseRoleMentions(content string) []Snowflake {
+	return parseMentions(RoleMentionRegex, content)
+}
+
+// ParseChannelMentions parses all channel mentions in the content. Duplicate
+// IDs are removed.
+func ParseChannelMentions(content string) []Snowflake {
+	return parseMentions(ChannelMentionRegex, content)
+}
+
+func parseMentions(reg *regexp.Regexp, content string) []Snowflake {
+	var matches = reg.FindAllStringSubmatch(content, -1)
+	if len(matches) == 0 {
+		return nil
+	}
+
+	var ids = make([]Snowflake, 0, len(matches))
+	var seen SnowflakeSet
+
+	for _, match := range matches {
+		id, err := ParseSnowflake(match[1])
+		if err != nil {
+			continue
+		}
+
+		ids = appendUniqueID(ids, &seen, id)
+	}
+
+	return ids
+}
+
+// appendUniqueID appends the ID to ids if it's not in seen yet, which keeps
+// the order of the IDs.
+func appendUniqueID(
+	ids []Snowflake, seen *SnowflakeSet, id Snowflake) []Snowflake {
+
+	if seen.Add(id) {
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+// MentionedUserIDs returns the IDs of all mentioned users. This includes both
+// the Mentions array and the mentions parsed from the content, which is useful
+// for messages that don't have Mentions filled.
+func (m Message) MentionedUserIDs() []Snowflake {
+	var ids = make([]Snowflake, 0, len(m.Mentions))
+	var seen SnowflakeSet
+
+	for _, u := range m.Mentions {
+		ids = appendUniqueID(ids, &seen, u.ID)
+	}
+
+	for _, id := range ParseUserMentions(m.Content) {
+		ids = appendUniqueID(ids, &seen, id)
+	}
+
+	return ids
+}
+
+// MentionedRoleIDs returns the IDs of all mentioned roles. This includes both
+// MentionRoleIDs and the mentions parsed from the content.
+func (m Message) MentionedRoleIDs() []Snowflake {
+	var ids = make([]Snowflake, 0, len(m.MentionRoleIDs))
+	var seen SnowflakeSet
+
+	for _, id := range m.MentionRoleIDs {
+		ids = appendUniqueID(ids, &seen, id)
+	}
+
+	for _, id := range ParseRoleMentions(m.Content) {
+		ids = appendUniqueID(ids, &seen, id)
+	}
+
+	return ids
+}
+
+// MentionedChannelIDs returns the IDs of all mentioned channels. This includes
+// both MentionChannels and the mentions parsed from the content, as not all
+// channel mentions will appear in MentionChannels.
+func (m Message) MentionedChannelIDs() []Snowflake {
+	var ids = make([]Snowflake, 0, len(m.MentionChannels))
+	var seen SnowflakeSet
+
+	for _, ch := range m.MentionChannels {
+		ids = appendUniqueID(ids, &seen, ch.ChannelID)
+	}
+
+	for _, id := range ParseChannelMentions(m.Content) {
+		ids = appendUniqueID(ids, &seen, id)
+	}
+
+	return ids
+}
+
+// IsMentioning returns true if the message mentions the given user, either in
+// the Mentions array or in the content. It does not check for @everyone or role
+// mentions.
+func (m Message) IsMentioning(userID Snowflake) bool {
+	for _, u := range m.Mentions {
+		if u.ID == userID {
+			return true
+		}
+	}
+
+	for _, id := range ParseUserMentions(m.Content) {
+		if id == userID {
+			return true
+		}
+	}
+
+	return false
+}
+
+// IsMentionPrefixed returns true if the message content starts with a mention
+// to the given user. It is useful for commands that use the bot's mention as a
+// prefix.
+func (m Message) IsMentionPrefixed(userID Snowflake) bool {
+	var loc = UserMentionRegex.FindStringSubmatchIndex(m.Content)
+	if loc == nil || loc[0] != 0 {
+		return false
+	}
+
+	id, err := ParseSnowflake(m.Content[loc[2]:loc[3]])
+	return err == nil && id == userID
+}
+
+// AllowedMentionType is a type of mention that's parsed from the content of a
+// message.
+type AllowedMentionType string
+
+const (
+	AllowRoleMentions     AllowedMentionType = "roles"
+	AllowUserMentions     AllowedMentionType = "users"
+	AllowEveryoneMentions AllowedMentionType = "everyone"
+)
+
+// ErrMentionConflict is returned if the same type of mention is both parsed
+// and listed.
+var ErrMentionConflict = errors.New(
+	"Mentions can't be both parsed and listed for the same type")
+
+// AllowedMentions controls who is pinged by a message. The mentions in the
+// content are still shown, but only the allowed ones ping. The zero value
+// pings no one, which is safe for echoing user content.
+//
+// https://discord.com/developers/docs/resources/channel#allowed-mentions-object
+type AllowedMentions struct {
+	// Parse are the types of mentions in the content that ping.
+	Parse []AllowedMentionType `json:"parse"`
+	// Roles and Users are the roles and users that are pinged if they're
+	// mentioned. They can't be set with the same type in Parse.
+	Roles []Snowflake `json:"roles,omitempty"`
+	Users []Snowflake `json:"users,omitempty"`
+
+	// RepliedUser, if true, pings the author of the replied message.
+	RepliedUser bool `json:"replied_user,omitempty"`
+}
+
+// Validate returns an error if the mentions are listed for a parsed type, or
+// if more than 100 roles or users are listed.
+func (am AllowedMentions) Validate() error {
+	if len(am.Roles) > 100 {
+		return &ErrOverbound{len(am.Roles), 100, "Roles"}
+	}
+	if len(am.Users) > 100 {
+		return &ErrOverbound{len(am.Users), 100, "Users"}
+	}
+
+	for _, t := range am.Parse {
+		if (t == AllowRoleMentions && len(am.Roles) > 0) ||
+			(t == AllowUserMentions && len(am.Users) > 0) {
+			return ErrMentionConflict
+		}
+	}
+
+	return nil
+}
+
+// MarshalJSON always sends Parse, as Discord parses every mention if it's
+// missing.
+func (am AllowedMentions) MarshalJSON() ([]byte, error) {
+	type raw AllowedMentions
+
+	if am.Parse == nil {
+		am.Parse = []AllowedMentionType{}
+	}
+
+	return (json.Default{}).Marshal(raw(am))
+}
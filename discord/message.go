@@ -1,5 +1,11 @@
 package discord
 
+import (
+	"errors"
+	"net/url"
+	"strings"
+)
+
 type Message struct {
 	ID        Snowflake   `json:"id,string"`
 	Type      MessageType `json:"type"`
@@ -39,6 +45,7 @@ type Message struct {
 
 	Attachments []Attachment `json:"attachments"`
 	Embeds      []Embed      `json:"embeds"`
+	Components  Components   `json:"components,omitempty"`
 
 	Reactions []Reaction `json:"reaction,omitempty"`
 
@@ -55,14 +62,66 @@ type Message struct {
 // URL generates a Discord client URL to the message. If the message doesn't
 // have a GuildID, it will generate a URL with the guild "@me".
 func (m Message) URL() string {
+	return MessageURL(m.GuildID, m.ChannelID, m.ID)
+}
+
+// MessageURL generates a Discord client URL (or jump URL) to the message. If
+// guildID is invalid, it will generate a URL with the guild "@me".
+func MessageURL(guildID, channelID, messageID Snowflake) string {
 	var head = "https://discordapp.com/channels/"
-	var tail = "/" + m.ChannelID.String() + "/" + m.ID.String()
+	var tail = "/" + channelID.String() + "/" + messageID.String()
 
-	if !m.GuildID.Valid() {
+	if !guildID.Valid() {
 		return head + "@me" + tail
 	}
 
-	return head + m.GuildID.String() + tail
+	return head + guildID.String() + tail
+}
+
+// ErrInvalidMessageURL is returned by ParseMessageURL if the URL is not a
+// Discord message URL.
+var ErrInvalidMessageURL = errors.New("invalid message URL")
+
+// ParseMessageURL parses a Discord client URL to a message, such as one made by
+// MessageURL or copied with "Copy Message Link". The returned guildID is 0 if
+// the message is in a direct message channel.
+func ParseMessageURL(
+	messageURL string) (guildID, channelID, messageID Snowflake, err error) {
+
+	u, err := url.Parse(messageURL)
+	if err != nil {
+		return 0, 0, 0, ErrInvalidMessageURL
+	}
+
+	switch u.Host {
+	case "discordapp.com", "discord.com",
+		"canary.discordapp.com", "canary.discord.com",
+		"ptb.discordapp.com", "ptb.discord.com":
+	default:
+		return 0, 0, 0, ErrInvalidMessageURL
+	}
+
+	// The path looks like /channels/guildID/channelID/messageID.
+	parts := strings.Split(strings.Trim(u.Path, "/"), "/")
+	if len(parts) != 4 || parts[0] != "channels" {
+		return 0, 0, 0, ErrInvalidMessageURL
+	}
+
+	if parts[1] != "@me" {
+		if guildID, err = ParseSnowflake(parts[1]); err != nil {
+			return 0, 0, 0, ErrInvalidMessageURL
+		}
+	}
+
+	if channelID, err = ParseSnowflake(parts[2]); err != nil {
+		return 0, 0, 0, ErrInvalidMessageURL
+	}
+
+	if messageID, err = ParseSnowflake(parts[3]); err != nil {
+		return 0, 0, 0, ErrInvalidMessageURL
+	}
+
+	return guildID, channelID, messageID, nil
 }
 
 type MessageType uint8
@@ -91,6 +150,10 @@ const (
 	SuppressEmbeds
 	SourceMessageDeleted
 	UrgentMessage
+	_
+	// EphemeralMessage is only visible to the user who invoked the
+	// interaction.
+	EphemeralMessage
 )
 
 type ChannelMention struct {
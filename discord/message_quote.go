@@ -0,0 +1,97 @@
+package discord
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// QuoteEmbed renders the message into an embed that quotes it, with the
+// author's name and avatar, the timestamp, a jump link and a preview of the
+// first image. System messages are rendered with SystemContent.
+func (m Message) QuoteEmbed() *Embed {
+	var embed = NewEmbed()
+	embed.Timestamp = m.Timestamp
+	embed.Author = &EmbedAuthor{
+		Name: m.authorName(),
+		URL:  m.URL(),
+		Icon: m.Author.AvatarURL(),
+	}
+
+	var content = m.Content
+	if m.IsSystem() {
+		content = m.SystemContent("")
+	}
+
+	// Quote the first embed if there's no content, which is the case for most
+	// bot and webhook messages.
+	if content == "" && len(m.Embeds) > 0 {
+		content = m.Embeds[0].Description
+		if content == "" {
+			content = m.Embeds[0].Title
+		}
+	}
+
+	embed.Description = truncate(content, 2048)
+	embed.Image = m.previewImage()
+
+	// List the attachments that aren't previewed, so they can still be
+	// reached.
+	var files []string
+	for _, a := range m.Attachments {
+		if embed.Image == nil || a.URL != embed.Image.URL {
+			files = append(files, "["+a.Filename+"]("+a.URL+")")
+		}
+	}
+	if len(files) > 0 {
+		embed.Fields = append(embed.Fields, EmbedField{
+			Name:  "Attachments",
+			Value: truncate(strings.Join(files, "\n"), 1024),
+		})
+	}
+
+	embed.Fields = append(embed.Fields, EmbedField{
+		Name:  "Source",
+		Value: "[Jump to message](" + m.URL() + ")",
+	})
+
+	return embed
+}
+
+// previewImage returns the first image attachment, or the image of the first
+// embed that has one. Nil is returned if there's none.
+func (m Message) previewImage() *EmbedImage {
+	for _, a := range m.Attachments {
+		// Only images have dimensions.
+		if a.Height > 0 && a.Width > 0 {
+			return &EmbedImage{URL: a.URL}
+		}
+	}
+
+	for _, e := range m.Embeds {
+		switch {
+		case e.Image != nil:
+			return &EmbedImage{URL: e.Image.URL}
+		case e.Thumbnail != nil && e.Type == ImageEmbed:
+			return &EmbedImage{URL: e.Thumbnail.URL}
+		}
+	}
+
+	return nil
+}
+
+// truncate cuts s to at most max bytes without splitting a character, adding
+// an ellipsis if it's cut.
+func truncate(s string, max int) string {
+	if len(s) <= max {
+		return s
+	}
+
+	const ellipsis = "…"
+
+	var cut = max - len(ellipsis)
+	for cut > 0 && !utf8.RuneStart(s[cut]) {
+		cut--
+	}
+
+	return s[:cut] + ellipsis
+}
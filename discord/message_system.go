@@ -0,0 +1,83 @@
+package discord
+
+// IsSystem returns true if the message is a system message, such as a member
+// join or a pin notification, rather than a regular message.
+func (m Message) IsSystem() bool {
+	return m.Type != DefaultMessage
+}
+
+// SystemContent renders a system message into a human-readable string, similar
+// to what the official client displays. guildName is used for the Nitro tier
+// messages; "this server" is used if it's empty. Regular messages and unknown
+// types return the message content as-is.
+func (m Message) SystemContent(guildName string) string {
+	var author = m.authorName()
+
+	if guildName == "" {
+		guildName = "this server"
+	}
+
+	switch m.Type {
+	case RecipientAddMessage:
+		return author + " added " + m.mentionedName() + " to the group."
+	case RecipientRemoveMessage:
+		if len(m.Mentions) > 0 && m.Mentions[0].ID != m.Author.ID {
+			return author + " removed " + m.mentionedName() + " from the group."
+		}
+		return author + " left the group."
+	case CallMessage:
+		return author + " started a call."
+	case ChannelNameChangeMessage:
+		return author + " changed the channel name: " + m.Content
+	case ChannelIconChangeMessage:
+		return author + " changed the channel icon."
+	case ChannelPinnedMessage:
+		return author + " pinned a message to this channel."
+	case GuildMemberJoinMessage:
+		return author + " joined the server."
+	case NitroBoostMessage:
+		// The content contains the number of boosts, if there's more than one.
+		if m.Content != "" && m.Content != "1" {
+			return author + " just boosted the server **" + m.Content +
+				"** times!"
+		}
+		return author + " just boosted the server!"
+	case NitroTier1Message:
+		return author + " just boosted the server! " + guildName +
+			" has achieved **Level 1!**"
+	case NitroTier2Message:
+		return author + " just boosted the server! " + guildName +
+			" has achieved **Level 2!**"
+	case NitroTier3Message:
+		return author + " just boosted the server! " + guildName +
+			" has achieved **Level 3!**"
+	case ChannelFollowAddMessage:
+		return author + " has added " + m.Content + " to this channel. " +
+			"Its most important updates will show up here."
+	default:
+		return m.Content
+	}
+}
+
+// authorName returns the nickname of the author if available, or their display
+// name otherwise.
+func (m Message) authorName() string {
+	if m.Member != nil && m.Member.Nick != "" {
+		return m.Member.Nick
+	}
+	return m.Author.DisplayName()
+}
+
+// mentionedName returns the name of the first mentioned user, which is the
+// target user of recipient messages.
+func (m Message) mentionedName() string {
+	if len(m.Mentions) == 0 {
+		return "someone"
+	}
+
+	var u = m.Mentions[0]
+	if u.Member != nil && u.Member.Nick != "" {
+		return u.Member.Nick
+	}
+	return u.DisplayName()
+}
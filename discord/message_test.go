@@ -0,0 +1,132 @@
+// +build unit
+
+package discord
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestParseMessageURL(t *testing.T) {
+	var tests = []struct {
+		url     string
+		guild   Snowflake
+		channel Snowflake
+		message Snowflake
+		err     bool
+	}{
+		{"https://discordapp.com/channels/1/2/3", 1, 2, 3, false},
+		{"https://discord.com/channels/@me/2/3", 0, 2, 3, false},
+		{"https://canary.discordapp.com/channels/1/2/3/", 1, 2, 3, false},
+		{"https://example.com/channels/1/2/3", 0, 0, 0, true},
+		{"https://discordapp.com/channels/1/2", 0, 0, 0, true},
+		{"https://discordapp.com/channels/1/a/3", 0, 0, 0, true},
+	}
+
+	for _, test := range tests {
+		g, c, m, err := ParseMessageURL(test.url)
+		if test.err {
+			if err == nil {
+				t.Fatal("Expected error for", test.url)
+			}
+			continue
+		}
+
+		if err != nil {
+			t.Fatal("Unexpected error for", test.url, err)
+		}
+
+		if g != test.guild || c != test.channel || m != test.message {
+			t.Fatalf("Unexpected IDs for %s: %d %d %d", test.url, g, c, m)
+		}
+	}
+
+	const url = "https://discordapp.com/channels/@me/2/3"
+	if u := MessageURL(0, 2, 3); u != url {
+		t.Fatal("Unexpected MessageURL:", u)
+	}
+}
+
+func TestSystemContent(t *testing.T) {
+	var author = User{ID: 1, Username: "alice"}
+	var bob = GuildUser{User: User{ID: 2, Username: "bob"}}
+
+	var tests = []struct {
+		msg    Message
+		expect string
+	}{
+		{
+			Message{Type: DefaultMessage, Author: author, Content: "hi"},
+			"hi",
+		},
+		{
+			Message{Type: RecipientAddMessage, Author: author,
+				Mentions: []GuildUser{bob}},
+			"alice added bob to the group.",
+		},
+		{
+			Message{Type: RecipientRemoveMessage, Author: author,
+				Mentions: []GuildUser{{User: author}}},
+			"alice left the group.",
+		},
+		{
+			Message{Type: GuildMemberJoinMessage, Author: author,
+				Member: &Member{Nick: "Alice"}},
+			"Alice joined the server.",
+		},
+		{
+			Message{Type: NitroBoostMessage, Author: author, Content: "3"},
+			"alice just boosted the server **3** times!",
+		},
+		{
+			Message{Type: NitroTier2Message, Author: author},
+			"alice just boosted the server! this server has achieved " +
+				"**Level 2!**",
+		},
+	}
+
+	for _, test := range tests {
+		if s := test.msg.SystemContent(""); s != test.expect {
+			t.Fatalf("Unexpected content %q, expected %q", s, test.expect)
+		}
+	}
+}
+
+func TestQuoteEmbed(t *testing.T) {
+	var m = Message{
+		ID:        3,
+		ChannelID: 2,
+		GuildID:   1,
+		Author:    User{ID: 4, Username: "user", Discriminator: "0"},
+		Member:    &Member{Nick: "nick"},
+		Content:   strings.Repeat("é", 1100),
+		Attachments: []Attachment{
+			{Filename: "a.txt", URL: "https://a/a.txt"},
+			{Filename: "b.png", URL: "https://a/b.png", Width: 1, Height: 1},
+		},
+	}
+
+	var embed = m.QuoteEmbed()
+
+	if embed.Author.Name != "nick" {
+		t.Fatal("Unexpected author name:", embed.Author.Name)
+	}
+
+	if embed.Image == nil || embed.Image.URL != "https://a/b.png" {
+		t.Fatal("Unexpected image:", embed.Image)
+	}
+
+	if len(embed.Description) > 2048 || !utf8.ValidString(embed.Description) {
+		t.Fatal("Description not truncated properly")
+	}
+
+	var files = "[a.txt](https://a/a.txt)"
+	if len(embed.Fields) != 2 || embed.Fields[0].Value != files {
+		t.Fatalf("Unexpected fields: %#v", embed.Fields)
+	}
+
+	if err := embed.Validate(); err != nil {
+		t.Fatal("Invalid embed:", err)
+	}
+}
@@ -0,0 +1,72 @@
+package discord
+
+// GuildScheduledEvent is an event scheduled in a guild, which takes place in
+// a stage channel, a voice channel or somewhere else.
+type GuildScheduledEvent struct {
+	ID      Snowflake `json:"id,string"`
+	GuildID Snowflake `json:"guild_id,string"`
+	// ChannelID is 0 for external events.
+	ChannelID Snowflake `json:"channel_id,string,omitempty"`
+	// CreatorID is 0 for events created before October 25th, 2021.
+	CreatorID Snowflake `json:"creator_id,string,omitempty"`
+
+	Name        string `json:"name"` // 1-100 chars
+	Description string `json:"description,omitempty"`
+	Image       Hash   `json:"image,omitempty"`
+
+	StartTime Timestamp `json:"scheduled_start_time"`
+	// EndTime is required for external events only.
+	EndTime Timestamp `json:"scheduled_end_time,omitempty"`
+
+	PrivacyLevel ScheduledEventPrivacyLevel `json:"privacy_level"`
+	Status       ScheduledEventStatus       `json:"status"`
+
+	EntityType ScheduledEventEntityType `json:"entity_type"`
+	// EntityID is the ID of the stage instance of the event, if any.
+	EntityID Snowflake `json:"entity_id,string,omitempty"`
+	// EntityMetadata is only used by external events.
+	EntityMetadata *ScheduledEventEntityMetadata `json:"entity_metadata,omitempty"`
+
+	Creator *User `json:"creator,omitempty"`
+	// UserCount is the number of users subscribed to the event. It's only
+	// returned if asked for.
+	UserCount int `json:"user_count,omitempty"`
+}
+
+type ScheduledEventPrivacyLevel uint8
+
+// GuildOnlyEvent is only visible to guild members, and is the only privacy
+// level.
+const GuildOnlyEvent ScheduledEventPrivacyLevel = 2
+
+type ScheduledEventStatus uint8
+
+const (
+	_ ScheduledEventStatus = iota
+	EventScheduled
+	EventActive
+	EventCompleted
+	EventCanceled
+)
+
+type ScheduledEventEntityType uint8
+
+const (
+	_ ScheduledEventEntityType = iota
+	StageInstanceEntity
+	VoiceEntity
+	ExternalEntity
+)
+
+type ScheduledEventEntityMetadata struct {
+	// Location of the external event, 1-100 chars.
+	Location string `json:"location,omitempty"`
+}
+
+// GuildScheduledEventUser is a user subscribed to a scheduled event.
+type GuildScheduledEventUser struct {
+	EventID Snowflake `json:"guild_scheduled_event_id,string"`
+	User    User      `json:"user"`
+	// Member is only returned if asked for, and if the user is in the guild.
+	Member *Member `json:"member,omitempty"`
+}
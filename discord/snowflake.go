@@ -78,5 +78,5 @@ func (s Snowflake) Increment() uint16 {
 }
 
 func TimeToDiscordEpoch(t time.Time) int64 {
-	return t.UnixNano()/int64(time.Millisecond) - DiscordEpoch
+	return (t.UnixNano() - DiscordEpoch) / int64(time.Millisecond)
 }
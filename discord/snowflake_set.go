@@ -0,0 +1,111 @@
+package discord
+
+import "sort"
+
+// SnowflakeSet is a set of snowflakes kept as a sorted slice. It takes 8 bytes
+// per ID, a fraction of what a map takes, and lookups are binary searches,
+// which makes it fit for large collections such as the IDs of the messages a
+// bot already processed. Since snowflakes are ordered by time, old IDs can be
+// dropped with RemoveBefore.
+//
+// The zero value is an empty set. A SnowflakeSet isn't safe for concurrent
+// use.
+type SnowflakeSet struct {
+	ids []Snowflake
+}
+
+// NewSnowflakeSet creates a set with the given IDs.
+func NewSnowflakeSet(ids ...Snowflake) *SnowflakeSet {
+	var set SnowflakeSet
+	set.AddAll(ids...)
+	return &set
+}
+
+// Len returns the number of IDs in the set.
+func (s *SnowflakeSet) Len() int {
+	return len(s.ids)
+}
+
+// search returns the index the ID is at, or would be inserted at.
+func (s *SnowflakeSet) search(id Snowflake) int {
+	return sort.Search(len(s.ids), func(i int) bool { return s.ids[i] >= id })
+}
+
+// Contains returns true if the ID is in the set.
+func (s *SnowflakeSet) Contains(id Snowflake) bool {
+	i := s.search(id)
+	return i < len(s.ids) && s.ids[i] == id
+}
+
+// Add adds the ID to the set, and returns false if it was already there.
+// Adding IDs in increasing order, as they're created, is the fastest.
+func (s *SnowflakeSet) Add(id Snowflake) bool {
+	// Fast path for new IDs.
+	if n := len(s.ids); n == 0 || s.ids[n-1] < id {
+		s.ids = append(s.ids, id)
+		return true
+	}
+
+	i := s.search(id)
+	if s.ids[i] == id {
+		return false
+	}
+
+	s.ids = append(s.ids, 0)
+	copy(s.ids[i+1:], s.ids[i:])
+	s.ids[i] = id
+
+	return true
+}
+
+// AddAll adds all the IDs to the set. It's faster than Add for many IDs in no
+// particular order.
+func (s *SnowflakeSet) AddAll(ids ...Snowflake) {
+	if len(ids) == 0 {
+		return
+	}
+
+	s.ids = append(s.ids, ids...)
+	sort.Slice(s.ids, func(i, j int) bool { return s.ids[i] < s.ids[j] })
+
+	// Remove the duplicates in place.
+	var n = 1
+	for _, id := range s.ids[1:] {
+		if id != s.ids[n-1] {
+			s.ids[n] = id
+			n++
+		}
+	}
+
+	s.ids = s.ids[:n]
+}
+
+// Remove removes the ID from the set, and returns false if it wasn't there.
+func (s *SnowflakeSet) Remove(id Snowflake) bool {
+	i := s.search(id)
+	if i == len(s.ids) || s.ids[i] != id {
+		return false
+	}
+
+	s.ids = append(s.ids[:i], s.ids[i+1:]...)
+	return true
+}
+
+// RemoveBefore removes the IDs lower than the given one, which are the ones
+// created before it, and returns how many were removed. NewSnowflake makes the
+// ID of a time.
+func (s *SnowflakeSet) RemoveBefore(id Snowflake) int {
+	i := s.search(id)
+	s.ids = append(s.ids[:0], s.ids[i:]...)
+	return i
+}
+
+// Clear removes all the IDs from the set.
+func (s *SnowflakeSet) Clear() {
+	s.ids = s.ids[:0]
+}
+
+// Slice returns a copy of the IDs in increasing order.
+func (s *SnowflakeSet) Slice() []Snowflake {
+	return append([]Snowflake(nil), s.ids...)
+}
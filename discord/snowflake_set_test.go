@@ -0,0 +1,60 @@
+// +build unit
+
+package discord
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSnowflakeSet(t *testing.T) {
+	var set = NewSnowflakeSet(5, 3, 5, 1)
+
+	if !reflect.DeepEqual(set.Slice(), []Snowflake{1, 3, 5}) {
+		t.Fatal("Unexpected IDs:", set.Slice())
+	}
+
+	if !set.Add(4) || !set.Add(6) || !set.Add(0) || set.Add(3) {
+		t.Fatal("Unexpected results of Add")
+	}
+
+	if !set.Remove(1) || set.Remove(2) || set.Remove(7) {
+		t.Fatal("Unexpected results of Remove")
+	}
+
+	if !reflect.DeepEqual(set.Slice(), []Snowflake{0, 3, 4, 5, 6}) {
+		t.Fatal("Unexpected IDs:", set.Slice())
+	}
+
+	if !set.Contains(4) || set.Contains(1) || set.Contains(7) {
+		t.Fatal("Unexpected results of Contains")
+	}
+
+	if n := set.RemoveBefore(4); n != 2 {
+		t.Fatal("Unexpected number of IDs removed:", n)
+	}
+
+	if !reflect.DeepEqual(set.Slice(), []Snowflake{4, 5, 6}) {
+		t.Fatal("Unexpected IDs:", set.Slice())
+	}
+
+	set.Clear()
+
+	if set.Len() != 0 || set.Contains(4) {
+		t.Fatal("Set isn't empty after Clear")
+	}
+}
+
+func TestSnowflakeSetZero(t *testing.T) {
+	var set SnowflakeSet
+
+	if set.Contains(1) || set.Remove(1) || set.RemoveBefore(1) != 0 {
+		t.Fatal("Unexpected results on an empty set")
+	}
+
+	set.AddAll(2, 2, 1)
+
+	if set.Len() != 2 || !set.Contains(1) || !set.Contains(2) {
+		t.Fatal("Unexpected IDs:", set.Slice())
+	}
+}
@@ -0,0 +1,16 @@
+// +build unit
+
+package discord
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNewSnowflake(t *testing.T) {
+	var now = time.Now().Truncate(time.Millisecond)
+
+	if sf := NewSnowflake(now); !sf.Time().Equal(now) {
+		t.Fatal("Unexpected snowflake time:", sf.Time(), "expected", now)
+	}
+}
@@ -1,12 +1,24 @@
 package discord
 
-import "strings"
+import (
+	"strconv"
+	"strings"
+)
 
 type User struct {
-	ID            Snowflake `json:"id,string"`
-	Username      string    `json:"username"`
-	Discriminator string    `json:"discriminator"`
-	Avatar        Hash      `json:"avatar"`
+	ID       Snowflake `json:"id,string"`
+	Username string    `json:"username"`
+	// Discriminator is "0" for users that moved to unique usernames.
+	Discriminator string `json:"discriminator"`
+	Avatar        Hash   `json:"avatar"`
+
+	// Banner is only filled when the user is fetched on its own.
+	Banner Hash `json:"banner,omitempty"`
+	// AvatarDecoration is nil if the user has no decoration.
+	AvatarDecoration *AvatarDecoration `json:"avatar_decoration_data,omitempty"`
+
+	// GlobalName is the display name of the user, if they set one.
+	GlobalName string `json:"global_name,omitempty"`
 
 	// These fields may be omitted
 
@@ -27,16 +39,91 @@ func (u User) Mention() string {
 	return "<@" + u.ID.String() + ">"
 }
 
-func (u User) AvatarURL() string {
-	base := "https://cdn.discordapp.com"
+// DisplayName returns the global name of the user, or their username if they
+// have none. Nicknames of members are not taken into account.
+func (u User) DisplayName() string {
+	if u.GlobalName != "" {
+		return u.GlobalName
+	}
+	return u.Username
+}
+
+// Tag returns the username with the discriminator, such as "user#1234", or
+// only the username for users that moved to unique usernames.
+func (u User) Tag() string {
+	if !u.hasDiscriminator() {
+		return u.Username
+	}
+	return u.Username + "#" + u.Discriminator
+}
+
+func (u User) hasDiscriminator() bool {
+	return u.Discriminator != "" && u.Discriminator != "0"
+}
+
+// DefaultAvatarIndex returns the index of the default avatar of the user, which
+// is used if they have no avatar. It depends on the ID for users that moved to
+// unique usernames, and on the discriminator for the others.
+func (u User) DefaultAvatarIndex() int {
+	if !u.hasDiscriminator() {
+		return int((u.ID >> 22) % 6)
+	}
+
+	d, err := strconv.Atoi(u.Discriminator)
+	if err != nil {
+		return 0
+	}
 
+	return d % 5
+}
+
+// DefaultAvatarURL returns the URL of the default avatar of the user.
+func (u User) DefaultAvatarURL() string {
+	return "https://cdn.discordapp.com/embed/avatars/" +
+		strconv.Itoa(u.DefaultAvatarIndex()) + ".png"
+}
+
+// AvatarURL returns the URL of the avatar of the user, or of their default
+// avatar if they have none.
+func (u User) AvatarURL() string {
 	if u.Avatar == "" {
-		return base + "/embed/avatars/" + u.Discriminator + ".png"
+		return u.DefaultAvatarURL()
 	}
 
-	base += "/avatars/" + u.ID.String() + "/" + u.Avatar
+	return imageURL("avatars/"+u.ID.String()+"/", u.Avatar)
+}
+
+// BannerURL returns the URL of the banner of the user, or an empty string if
+// they have none.
+func (u User) BannerURL() string {
+	if u.Banner == "" {
+		return ""
+	}
+	return imageURL("banners/"+u.ID.String()+"/", u.Banner)
+}
+
+// AvatarDecorationURL returns the URL of the avatar decoration of the user, or
+// an empty string if they have none.
+func (u User) AvatarDecorationURL() string {
+	if u.AvatarDecoration == nil {
+		return ""
+	}
+	return "https://cdn.discordapp.com/avatar-decoration-presets/" +
+		u.AvatarDecoration.Asset + ".png"
+}
+
+// AvatarDecoration is the decoration shown around the avatar of a user.
+type AvatarDecoration struct {
+	Asset Hash      `json:"asset"`
+	SKUID Snowflake `json:"sku_id,string"`
+}
+
+// imageURL returns the CDN URL of the image with the hash under the path,
+// which is a GIF if the image is animated.
+func imageURL(path string, hash Hash) string {
+	base := "https://cdn.discordapp.com/" + path + hash
 
-	if strings.HasPrefix(u.Avatar, "a_") {
+	if strings.HasPrefix(hash, "a_") {
 		return base + ".gif"
 	} else {
 		return base + ".png"
@@ -151,8 +238,12 @@ const (
 	StreamingActivity
 	// Listening to $name
 	ListeningActivity
-	// $emoji $name
+	// Watching $name
+	WatchingActivity
+	// $emoji $state
 	CustomActivity
+	// Competing in $name
+	CompetingActivity
 )
 
 type ActivityFlags uint8
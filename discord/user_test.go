@@ -0,0 +1,77 @@
+// +build unit
+
+package discord
+
+import "testing"
+
+func TestUserNames(t *testing.T) {
+	var tests = []struct {
+		user    User
+		tag     string
+		display string
+		avatar  string
+	}{{
+		user:    User{ID: 5 << 22, Username: "user", Discriminator: "0"},
+		tag:     "user",
+		display: "user",
+		avatar:  "https://cdn.discordapp.com/embed/avatars/5.png",
+	}, {
+		user: User{
+			ID:            1,
+			Username:      "user",
+			Discriminator: "1234",
+			GlobalName:    "User",
+		},
+		tag:     "user#1234",
+		display: "User",
+		avatar:  "https://cdn.discordapp.com/embed/avatars/4.png",
+	}, {
+		user:    User{ID: 1, Username: "user", Avatar: "a_hash"},
+		tag:     "user",
+		display: "user",
+		avatar:  "https://cdn.discordapp.com/avatars/1/a_hash.gif",
+	}}
+
+	for _, test := range tests {
+		if tag := test.user.Tag(); tag != test.tag {
+			t.Errorf("Unexpected tag %q, expected %q", tag, test.tag)
+		}
+		if name := test.user.DisplayName(); name != test.display {
+			t.Errorf("Unexpected name %q, expected %q", name, test.display)
+		}
+		if url := test.user.AvatarURL(); url != test.avatar {
+			t.Errorf("Unexpected avatar %q, expected %q", url, test.avatar)
+		}
+	}
+}
+
+func TestMemberImageURLs(t *testing.T) {
+	var user = User{ID: 1, Avatar: "user", Banner: "a_user"}
+
+	var tests = []struct {
+		member Member
+		avatar string
+		banner string
+	}{{
+		member: Member{User: User{ID: 1}},
+		avatar: "https://cdn.discordapp.com/embed/avatars/0.png",
+		banner: "",
+	}, {
+		member: Member{User: user},
+		avatar: "https://cdn.discordapp.com/avatars/1/user.png",
+		banner: "https://cdn.discordapp.com/banners/1/a_user.gif",
+	}, {
+		member: Member{User: user, Avatar: "a_member", Banner: "member"},
+		avatar: "https://cdn.discordapp.com/guilds/2/users/1/avatars/a_member.gif",
+		banner: "https://cdn.discordapp.com/guilds/2/users/1/banners/member.png",
+	}}
+
+	for _, test := range tests {
+		if url := test.member.AvatarURL(2); url != test.avatar {
+			t.Errorf("Unexpected avatar %q, expected %q", url, test.avatar)
+		}
+		if url := test.member.BannerURL(2); url != test.banner {
+			t.Errorf("Unexpected banner %q, expected %q", url, test.banner)
+		}
+	}
+}
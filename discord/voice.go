@@ -26,3 +26,26 @@ type VoiceRegion struct {
 	Deprecated bool   `json:"deprecated"`
 	Custom     bool   `json:"custom"` // used for events
 }
+
+// StageInstance is a live stage in a stage channel.
+type StageInstance struct {
+	ID        Snowflake `json:"id,string"`
+	GuildID   Snowflake `json:"guild_id,string"`
+	ChannelID Snowflake `json:"channel_id,string"`
+
+	Topic        string            `json:"topic"` // 1-120 chars
+	PrivacyLevel StagePrivacyLevel `json:"privacy_level"`
+	// DiscoverableDisabled is true if the stage isn't shown in Stage
+	// Discovery.
+	DiscoverableDisabled bool `json:"discoverable_disabled"`
+}
+
+type StagePrivacyLevel uint8
+
+const (
+	_ StagePrivacyLevel = iota
+	// PublicStage is visible to everyone.
+	PublicStage
+	// GuildOnlyStage is only visible to guild members.
+	GuildOnlyStage
+)
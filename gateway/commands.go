@@ -2,6 +2,7 @@ package gateway
 
 import (
 	"context"
+	"strings"
 
 	"github.com/diamondburned/arikawa/discord"
 	"github.com/pkg/errors"
@@ -20,6 +21,10 @@ type IdentifyData struct {
 	Shard *Shard `json:"shard,omitempty"` // [ shard_id, num_shards ]
 
 	Presence *UpdateStatusData `json:"presence,omitempty"`
+
+	// Intents is a bitmask of the events to receive. Leaving this 0 omits the
+	// field, which makes Discord send all events.
+	Intents Intents `json:"intents,omitempty"`
 }
 
 func (i *IdentifyData) SetShard(id, num int) {
@@ -29,6 +34,55 @@ func (i *IdentifyData) SetShard(id, num int) {
 	i.Shard[0], i.Shard[1] = id, num
 }
 
+// AddIntent adds a Gateway Intent to the identify data.
+func (i *IdentifyData) AddIntent(intent Intents) {
+	i.Intents |= intent
+}
+
+// The range of LargeThreshold accepted by Discord. 0 is also accepted, as it's
+// omitted.
+const (
+	MinLargeThreshold = 50
+	MaxLargeThreshold = 250
+)
+
+var (
+	ErrInvalidLargeThreshold = errors.New(
+		"LargeThreshold must be between 50 and 250")
+	ErrInvalidShard = errors.New(
+		"Shard ID must be between 0 and the number of shards")
+	ErrInvalidStatus = errors.New("Invalid presence status")
+	// ErrInvalidActivity is returned for activities without a name, or
+	// without a state for custom statuses.
+	ErrInvalidActivity  = errors.New("Invalid presence activity")
+	ErrInvalidStreamURL = errors.New(
+		"Streaming activities need a Twitch or YouTube URL")
+)
+
+// Validate checks the identify data against the ranges accepted by Discord.
+func (i *IdentifyData) Validate() error {
+	if i.LargeThreshold != 0 &&
+		(i.LargeThreshold < MinLargeThreshold ||
+			i.LargeThreshold > MaxLargeThreshold) {
+
+		return ErrInvalidLargeThreshold
+	}
+
+	if i.Shard != nil {
+		if id, num := i.Shard.ShardID(), i.Shard.NumShards(); id < 0 ||
+			num < 1 || id >= num {
+
+			return ErrInvalidShard
+		}
+	}
+
+	if i.Presence != nil {
+		return i.Presence.Validate()
+	}
+
+	return nil
+}
+
 type IdentifyProperties struct {
 	// Required
 	OS      string `json:"os"`      // GOOS
@@ -51,7 +105,7 @@ func (g *Gateway) Identify() error {
 		return errors.Wrap(err, "Can't wait for identify()")
 	}
 
-	return g.Send(IdentifyOP, g.Identifier)
+	return g.Send(IdentifyOP, g.identifyData())
 }
 
 type ResumeData struct {
@@ -83,6 +137,7 @@ func (g *Gateway) Resume() error {
 type HeartbeatData int
 
 func (g *Gateway) Heartbeat() error {
+	g.status.heartbeat()
 	return g.Send(HeartbeatOP, g.Sequence.Get())
 }
 
@@ -93,6 +148,10 @@ type RequestGuildMembersData struct {
 	Query     string `json:"query,omitempty"`
 	Limit     uint   `json:"limit"`
 	Presences bool   `json:"presences,omitempty"`
+
+	// Nonce is sent back in the GuildMembersChunkEvents, to tell which
+	// request they're for. It can be up to 32 bytes.
+	Nonce string `json:"nonce,omitempty"`
 }
 
 func (g *Gateway) RequestGuildMembers(data RequestGuildMembersData) error {
@@ -106,8 +165,11 @@ type UpdateVoiceStateData struct {
 	SelfDeaf  bool              `json:"self_deaf"`
 }
 
+// UpdateVoiceState joins, moves or leaves a voice channel. A zero ChannelID
+// means leaving the current channel.
 func (g *Gateway) UpdateVoiceState(data UpdateVoiceStateData) error {
-	return g.Send(VoiceStateUpdateOP, data)
+	// Send a pointer, so that a zero ChannelID is marshaled as null.
+	return g.Send(VoiceStateUpdateOP, &data)
 }
 
 type UpdateStatusData struct {
@@ -118,6 +180,58 @@ type UpdateStatusData struct {
 	AFK    bool           `json:"afk"`
 }
 
+// Validate checks that the status is valid, and that the activity, if any,
+// can be set by a bot.
+func (d UpdateStatusData) Validate() error {
+	switch d.Status {
+	case discord.OnlineStatus, discord.DoNotDisturbStatus,
+		discord.IdleStatus, discord.InvisibleStatus,
+		discord.OfflineStatus:
+	default:
+		return ErrInvalidStatus
+	}
+
+	if d.Game == nil {
+		return nil
+	}
+
+	switch d.Game.Type {
+	case discord.CustomActivity:
+		if d.Game.State == "" {
+			return ErrInvalidActivity
+		}
+	case discord.StreamingActivity:
+		if !streamingURL(d.Game.URL) {
+			return ErrInvalidStreamURL
+		}
+		fallthrough
+	case discord.GameActivity, discord.ListeningActivity,
+		discord.WatchingActivity, discord.CompetingActivity:
+
+		if d.Game.Name == "" {
+			return ErrInvalidActivity
+		}
+	default:
+		return ErrInvalidActivity
+	}
+
+	return nil
+}
+
+// streamingURL returns true if the URL is one of the streaming sites that
+// Discord accepts.
+func streamingURL(url string) bool {
+	for _, prefix := range []string{
+		"https://twitch.tv/", "https://www.twitch.tv/",
+		"https://youtube.com/", "https://www.youtube.com/",
+	} {
+		if strings.HasPrefix(url, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
 func (g *Gateway) UpdateStatus(data UpdateStatusData) error {
 	return g.Send(StatusUpdateOP, data)
 }
@@ -0,0 +1,99 @@
+// +build unit
+
+package gateway
+
+import (
+	"testing"
+
+	"github.com/diamondburned/arikawa/discord"
+)
+
+func TestIdentifyDataValidate(t *testing.T) {
+	var tests = []struct {
+		name string
+		data IdentifyData
+		err  error
+	}{
+		{"default", DefaultIdentifier("").IdentifyData, nil},
+		{"omitted threshold", IdentifyData{}, nil},
+		{"low threshold", IdentifyData{LargeThreshold: 49},
+			ErrInvalidLargeThreshold},
+		{"high threshold", IdentifyData{LargeThreshold: 251},
+			ErrInvalidLargeThreshold},
+		{"shard out of range", IdentifyData{Shard: &Shard{2, 2}},
+			ErrInvalidShard},
+		{"no shards", IdentifyData{Shard: &Shard{0, 0}}, ErrInvalidShard},
+		{"empty status", IdentifyData{Presence: &UpdateStatusData{}},
+			ErrInvalidStatus},
+		{"valid status", IdentifyData{Presence: &UpdateStatusData{
+			Status: discord.IdleStatus,
+		}}, nil},
+	}
+
+	for _, test := range tests {
+		if err := test.data.Validate(); err != test.err {
+			t.Errorf("%s: expected %v, got %v", test.name, test.err, err)
+		}
+	}
+}
+
+func TestUpdateStatusDataValidate(t *testing.T) {
+	var activity = func(
+		typ discord.ActivityType, name, url string) *discord.Activity {
+
+		return &discord.Activity{Name: name, Type: typ, URL: url}
+	}
+
+	var tests = []struct {
+		name string
+		game *discord.Activity
+		err  error
+	}{
+		{"no activity", nil, nil},
+		{"playing", activity(discord.GameActivity, "chess", ""), nil},
+		{"no name", activity(discord.WatchingActivity, "", ""),
+			ErrInvalidActivity},
+		{"custom without state", &discord.Activity{
+			Name: "Custom Status", Type: discord.CustomActivity,
+		}, ErrInvalidActivity},
+		{"streaming", activity(discord.StreamingActivity, "live",
+			"https://www.twitch.tv/discord"), nil},
+		{"streaming elsewhere", activity(discord.StreamingActivity, "live",
+			"https://example.com"), ErrInvalidStreamURL},
+		{"unknown type", activity(42, "chess", ""), ErrInvalidActivity},
+	}
+
+	for _, test := range tests {
+		var data = UpdateStatusData{
+			Status: discord.OnlineStatus,
+			Game:   test.game,
+		}
+		if err := data.Validate(); err != test.err {
+			t.Errorf("%s: expected %v, got %v", test.name, test.err, err)
+		}
+	}
+}
+
+func TestPresenceBuilder(t *testing.T) {
+	data, err := NewPresence().Idle().Custom("Busy").Build()
+	if err != nil {
+		t.Fatal("Failed to build presence:", err)
+	}
+
+	if data.Status != discord.IdleStatus || data.Since == 0 {
+		t.Fatal("Unexpected status:", data.Status, data.Since)
+	}
+
+	if data.Game.Type != discord.CustomActivity || data.Game.State != "Busy" {
+		t.Fatalf("Unexpected activity: %+v", data.Game)
+	}
+
+	data, err = NewPresence().Idle().DoNotDisturb().NoActivity().Build()
+	if err != nil || data.Since != 0 || data.Game != nil {
+		t.Fatalf("Unexpected presence: %+v %v", data, err)
+	}
+
+	if _, err := NewPresence().Streaming("live", "").Build(); err == nil {
+		t.Fatal("Expected an error for a stream without a URL")
+	}
+}
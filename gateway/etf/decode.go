@@ -0,0 +1,734 @@
+package etf
+
+import (
+	"bytes"
+	"encoding/binary"
+	stdjson "encoding/json"
+	"math"
+	"math/big"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/diamondburned/arikawa/internal/json"
+	"github.com/pkg/errors"
+)
+
+var (
+	rawType         = reflect.TypeOf(json.Raw(nil))
+	unmarshalerType = reflect.TypeOf((*stdjson.Unmarshaler)(nil)).Elem()
+)
+
+// Unmarshal decodes the ETF data into v, which must be a non-nil pointer.
+func Unmarshal(data []byte, v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return errors.Errorf("Cannot decode into non-pointer %T", v)
+	}
+
+	if len(data) == 0 || data[0] != Version {
+		return errors.New("Missing ETF version byte")
+	}
+
+	d := decoder{b: data, i: 1}
+
+	if err := d.decode(rv.Elem()); err != nil {
+		return err
+	}
+
+	if d.i != len(d.b) {
+		return errors.New("Trailing data after the ETF value")
+	}
+
+	return nil
+}
+
+type decoder struct {
+	b []byte
+	i int
+}
+
+var errUnexpectedEnd = errors.New("Unexpected end of ETF data")
+
+func (d *decoder) read(n int) ([]byte, error) {
+	if n < 0 || d.i+n > len(d.b) {
+		return nil, errUnexpectedEnd
+	}
+
+	b := d.b[d.i : d.i+n]
+	d.i += n
+	return b, nil
+}
+
+func (d *decoder) uint8() (int, error) {
+	b, err := d.read(1)
+	if err != nil {
+		return 0, err
+	}
+	return int(b[0]), nil
+}
+
+func (d *decoder) uint16() (int, error) {
+	b, err := d.read(2)
+	if err != nil {
+		return 0, err
+	}
+	return int(binary.BigEndian.Uint16(b)), nil
+}
+
+func (d *decoder) uint32() (int, error) {
+	b, err := d.read(4)
+	if err != nil {
+		return 0, err
+	}
+	return int(binary.BigEndian.Uint32(b)), nil
+}
+
+// fits returns an error if n terms, each of at least size bytes, can't fit in
+// the rest of the data. Lengths are checked before allocating, so a malformed
+// frame can't make the decoder allocate more than the frame itself.
+func (d *decoder) fits(n, size int) error {
+	if n < 0 || n > (len(d.b)-d.i)/size {
+		return errors.Errorf("ETF length %d exceeds the data left", n)
+	}
+	return nil
+}
+
+// peek returns the tag of the next term.
+func (d *decoder) peek() (int, error) {
+	if d.i >= len(d.b) {
+		return 0, errUnexpectedEnd
+	}
+	return int(d.b[d.i]), nil
+}
+
+// isNil returns true if the next term is the nil atom.
+func (d *decoder) isNil() bool {
+	var rest = d.b[d.i:]
+	return bytes.HasPrefix(rest, []byte{smallAtomExt, 3, 'n', 'i', 'l'}) ||
+		bytes.HasPrefix(rest, []byte{atomExt, 0, 3, 'n', 'i', 'l'}) ||
+		bytes.HasPrefix(rest, []byte{smallAtomUTF8Ext, 3, 'n', 'i', 'l'}) ||
+		bytes.HasPrefix(rest, []byte{atomUTF8Ext, 0, 3, 'n', 'i', 'l'})
+}
+
+// skip skips the next term, and returns its bytes.
+func (d *decoder) skip() ([]byte, error) {
+	var start = d.i
+
+	tag, err := d.uint8()
+	if err != nil {
+		return nil, err
+	}
+
+	var n, count int
+
+	switch tag {
+	case smallIntegerExt:
+		n = 1
+	case integerExt:
+		n = 4
+	case newFloatExt:
+		n = 8
+	case floatExt:
+		n = 31
+	case nilExt:
+	case smallAtomExt, smallAtomUTF8Ext:
+		n, err = d.uint8()
+	case atomExt, atomUTF8Ext, stringExt:
+		n, err = d.uint16()
+	case binaryExt:
+		n, err = d.uint32()
+	case smallBigExt:
+		n, err = d.uint8()
+		n++ // sign
+	case largeBigExt:
+		n, err = d.uint32()
+		n++ // sign
+	case smallTupleExt:
+		count, err = d.uint8()
+	case largeTupleExt:
+		count, err = d.uint32()
+	case listExt:
+		count, err = d.uint32()
+		count++ // tail
+	case mapExt:
+		count, err = d.uint32()
+		count *= 2
+	default:
+		return nil, errors.Errorf("Unknown ETF tag %d", tag)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := d.read(n); err != nil {
+		return nil, err
+	}
+
+	for i := 0; i < count; i++ {
+		if _, err := d.skip(); err != nil {
+			return nil, err
+		}
+	}
+
+	return d.b[start:d.i], nil
+}
+
+// decode decodes the next term into v.
+func (d *decoder) decode(v reflect.Value) error {
+	if v.Kind() == reflect.Ptr {
+		if d.isNil() {
+			d.skip()
+			v.Set(reflect.Zero(v.Type()))
+			return nil
+		}
+
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+
+		return d.decode(v.Elem())
+	}
+
+	// Raw values keep the ETF, so they're decoded with the same driver.
+	if v.Type() == rawType {
+		b, err := d.skip()
+		if err != nil {
+			return err
+		}
+
+		v.SetBytes(append([]byte{Version}, b...))
+		return nil
+	}
+
+	if v.CanAddr() && v.Addr().Type().Implements(unmarshalerType) {
+		return d.unmarshalJSON(v.Addr().Interface().(stdjson.Unmarshaler))
+	}
+
+	if v.Kind() == reflect.Interface && v.NumMethod() == 0 {
+		var i interface{}
+		if err := d.unmarshalJSON(jsonValue{&i}); err != nil {
+			return err
+		}
+
+		if i == nil {
+			v.Set(reflect.Zero(v.Type()))
+		} else {
+			v.Set(reflect.ValueOf(i))
+		}
+
+		return nil
+	}
+
+	tag, err := d.peek()
+	if err != nil {
+		return err
+	}
+
+	switch tag {
+	case smallAtomExt, smallAtomUTF8Ext, atomExt, atomUTF8Ext:
+		return d.decodeAtom(v)
+	case smallIntegerExt, integerExt, smallBigExt, largeBigExt:
+		return d.decodeInteger(v)
+	case newFloatExt, floatExt:
+		return d.decodeFloat(v)
+	case binaryExt:
+		return d.decodeBinary(v)
+	case stringExt:
+		if v.Kind() == reflect.String {
+			return d.decodeBinary(v)
+		}
+		return d.decodeList(v)
+	case nilExt, listExt, smallTupleExt, largeTupleExt:
+		return d.decodeList(v)
+	case mapExt:
+		return d.decodeMap(v)
+	default:
+		return errors.Errorf("Unknown ETF tag %d", tag)
+	}
+}
+
+// unmarshalJSON decodes the next term through its JSON form.
+func (d *decoder) unmarshalJSON(u stdjson.Unmarshaler) error {
+	var buf bytes.Buffer
+	if err := d.json(&buf); err != nil {
+		return err
+	}
+
+	return u.UnmarshalJSON(buf.Bytes())
+}
+
+// jsonValue decodes JSON into any value, for types that aren't Unmarshalers.
+type jsonValue struct {
+	v interface{}
+}
+
+func (j jsonValue) UnmarshalJSON(b []byte) error {
+	return stdjson.Unmarshal(b, j.v)
+}
+
+func (d *decoder) typeError(tag int, v reflect.Value) error {
+	return errors.Errorf("Cannot decode ETF tag %d into %s", tag, v.Type())
+}
+
+func (d *decoder) atom() (string, error) {
+	tag, err := d.uint8()
+	if err != nil {
+		return "", err
+	}
+
+	var n int
+	if tag == smallAtomExt || tag == smallAtomUTF8Ext {
+		n, err = d.uint8()
+	} else {
+		n, err = d.uint16()
+	}
+	if err != nil {
+		return "", err
+	}
+
+	b, err := d.read(n)
+	return string(b), err
+}
+
+func (d *decoder) decodeAtom(v reflect.Value) error {
+	atom, err := d.atom()
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case atom == "nil":
+		v.Set(reflect.Zero(v.Type()))
+	case (atom == "true" || atom == "false") && v.Kind() == reflect.Bool:
+		v.SetBool(atom == "true")
+	case v.Kind() == reflect.String:
+		v.SetString(atom)
+	default:
+		return errors.Errorf("Cannot decode atom %q into %s", atom, v.Type())
+	}
+
+	return nil
+}
+
+// integer reads an integer term, which could be too big for an int64.
+func (d *decoder) integer() (*big.Int, error) {
+	tag, err := d.uint8()
+	if err != nil {
+		return nil, err
+	}
+
+	switch tag {
+	case smallIntegerExt:
+		i, err := d.uint8()
+		return big.NewInt(int64(i)), err
+	case integerExt:
+		b, err := d.read(4)
+		if err != nil {
+			return nil, err
+		}
+		return big.NewInt(int64(int32(binary.BigEndian.Uint32(b)))), nil
+	}
+
+	var n int
+	if tag == smallBigExt {
+		n, err = d.uint8()
+	} else {
+		n, err = d.uint32()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	sign, err := d.uint8()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := d.fits(n, 1); err != nil {
+		return nil, err
+	}
+
+	digits, err := d.read(n)
+	if err != nil {
+		return nil, err
+	}
+
+	// The digits are little-endian, while big.Int wants big-endian.
+	var be = make([]byte, n)
+	for i, digit := range digits {
+		be[n-1-i] = digit
+	}
+
+	var i = new(big.Int).SetBytes(be)
+	if sign != 0 {
+		i.Neg(i)
+	}
+
+	return i, nil
+}
+
+func (d *decoder) decodeInteger(v reflect.Value) error {
+	tag, _ := d.peek()
+
+	i, err := d.integer()
+	if err != nil {
+		return err
+	}
+
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if !i.IsInt64() || v.OverflowInt(i.Int64()) {
+			return errors.Errorf("Integer %s overflows %s", i, v.Type())
+		}
+		v.SetInt(i.Int64())
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32,
+		reflect.Uint64, reflect.Uintptr:
+
+		if !i.IsUint64() || v.OverflowUint(i.Uint64()) {
+			return errors.Errorf("Integer %s overflows %s", i, v.Type())
+		}
+		v.SetUint(i.Uint64())
+
+	case reflect.Float32, reflect.Float64:
+		f, _ := new(big.Float).SetInt(i).Float64()
+		v.SetFloat(f)
+
+	default:
+		return d.typeError(tag, v)
+	}
+
+	return nil
+}
+
+func (d *decoder) float() (float64, error) {
+	tag, err := d.uint8()
+	if err != nil {
+		return 0, err
+	}
+
+	if tag == newFloatExt {
+		b, err := d.read(8)
+		if err != nil {
+			return 0, err
+		}
+		return math.Float64frombits(binary.BigEndian.Uint64(b)), nil
+	}
+
+	// The old format is a string, padded with zeros.
+	b, err := d.read(31)
+	if err != nil {
+		return 0, err
+	}
+
+	return strconv.ParseFloat(strings.TrimRight(string(b), "\x00"), 64)
+}
+
+func (d *decoder) decodeFloat(v reflect.Value) error {
+	tag, _ := d.peek()
+
+	f, err := d.float()
+	if err != nil {
+		return err
+	}
+
+	switch v.Kind() {
+	case reflect.Float32, reflect.Float64:
+		v.SetFloat(f)
+	default:
+		return d.typeError(tag, v)
+	}
+
+	return nil
+}
+
+// binary reads a binary or a string term.
+func (d *decoder) binary() ([]byte, error) {
+	tag, err := d.uint8()
+	if err != nil {
+		return nil, err
+	}
+
+	var n int
+	if tag == stringExt {
+		n, err = d.uint16()
+	} else {
+		n, err = d.uint32()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return d.read(n)
+}
+
+func (d *decoder) decodeBinary(v reflect.Value) error {
+	tag, _ := d.peek()
+
+	b, err := d.binary()
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case v.Kind() == reflect.String:
+		v.SetString(string(b))
+	case v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.Uint8:
+		v.SetBytes(append([]byte(nil), b...))
+	default:
+		return d.typeError(tag, v)
+	}
+
+	return nil
+}
+
+func (d *decoder) decodeList(v reflect.Value) error {
+	tag, err := d.uint8()
+	if err != nil {
+		return err
+	}
+
+	var n int
+	switch tag {
+	case nilExt:
+	case stringExt:
+		// A list of small integers, such as bytes.
+		n, err = d.uint16()
+	case smallTupleExt:
+		n, err = d.uint8()
+	case largeTupleExt, listExt:
+		n, err = d.uint32()
+	}
+	if err != nil {
+		return err
+	}
+
+	// Each element takes at least a byte.
+	if err := d.fits(n, 1); err != nil {
+		return err
+	}
+
+	switch v.Kind() {
+	case reflect.Slice:
+		v.Set(reflect.MakeSlice(v.Type(), n, n))
+	case reflect.Array:
+		if n > v.Len() {
+			return errors.Errorf("List of %d is too long for %s", n, v.Type())
+		}
+		v.Set(reflect.Zero(v.Type()))
+	case reflect.String:
+		if tag == nilExt {
+			v.SetString("")
+			return nil
+		}
+		fallthrough
+	default:
+		return d.typeError(tag, v)
+	}
+
+	for i := 0; i < n; i++ {
+		if tag == stringExt {
+			b, _ := d.read(1)
+			if err := setUint(v.Index(i), uint64(b[0])); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if err := d.decode(v.Index(i)); err != nil {
+			return err
+		}
+	}
+
+	if tag == listExt {
+		// Proper lists end with an empty list.
+		if _, err := d.skip(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func setUint(v reflect.Value, u uint64) error {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		v.SetInt(int64(u))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32,
+		reflect.Uint64, reflect.Uintptr:
+		v.SetUint(u)
+	case reflect.Float32, reflect.Float64:
+		v.SetFloat(float64(u))
+	case reflect.Interface:
+		v.Set(reflect.ValueOf(float64(u)))
+	default:
+		return errors.Errorf("Cannot decode integer into %s", v.Type())
+	}
+	return nil
+}
+
+// key reads a map key, which could be a binary, an atom or an integer.
+func (d *decoder) key() (string, error) {
+	tag, err := d.peek()
+	if err != nil {
+		return "", err
+	}
+
+	switch tag {
+	case binaryExt, stringExt:
+		b, err := d.binary()
+		return string(b), err
+	case smallAtomExt, smallAtomUTF8Ext, atomExt, atomUTF8Ext:
+		return d.atom()
+	case smallIntegerExt, integerExt, smallBigExt, largeBigExt:
+		i, err := d.integer()
+		if err != nil {
+			return "", err
+		}
+		return i.String(), nil
+	default:
+		return "", errors.Errorf("Invalid ETF map key tag %d", tag)
+	}
+}
+
+func (d *decoder) decodeMap(v reflect.Value) error {
+	if _, err := d.uint8(); err != nil {
+		return err
+	}
+
+	n, err := d.uint32()
+	if err != nil {
+		return err
+	}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		return d.decodeStruct(v, n)
+	case reflect.Map:
+	default:
+		return d.typeError(mapExt, v)
+	}
+
+	// Each pair takes at least two bytes.
+	if err := d.fits(n, 2); err != nil {
+		return err
+	}
+
+	var t = v.Type()
+	if v.IsNil() {
+		v.Set(reflect.MakeMapWithSize(t, n))
+	}
+
+	for i := 0; i < n; i++ {
+		k, err := d.key()
+		if err != nil {
+			return err
+		}
+
+		key := reflect.New(t.Key()).Elem()
+
+		switch t.Key().Kind() {
+		case reflect.String:
+			key.SetString(k)
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32,
+			reflect.Int64:
+
+			i, err := strconv.ParseInt(k, 10, 64)
+			if err != nil || key.OverflowInt(i) {
+				return errors.Errorf("Invalid map key %q for %s", k, t)
+			}
+			key.SetInt(i)
+
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32,
+			reflect.Uint64, reflect.Uintptr:
+
+			u, err := strconv.ParseUint(k, 10, 64)
+			if err != nil || key.OverflowUint(u) {
+				return errors.Errorf("Invalid map key %q for %s", k, t)
+			}
+			key.SetUint(u)
+
+		default:
+			return errors.Errorf("Unsupported map key type %s", t.Key())
+		}
+
+		elem := reflect.New(t.Elem()).Elem()
+		if err := d.decode(elem); err != nil {
+			return err
+		}
+
+		v.SetMapIndex(key, elem)
+	}
+
+	return nil
+}
+
+func (d *decoder) decodeStruct(v reflect.Value, n int) error {
+	var fields = cachedFields(v.Type())
+
+	for i := 0; i < n; i++ {
+		k, err := d.key()
+		if err != nil {
+			return err
+		}
+
+		f := fields.lookup(k)
+		if f == nil {
+			if _, err := d.skip(); err != nil {
+				return err
+			}
+			continue
+		}
+
+		fv := fieldByIndex(v, f.index)
+
+		if f.quoted {
+			if err := d.decodeQuoted(fv); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if err := d.decode(fv); err != nil {
+			return errors.Wrap(err, "Failed to decode "+k)
+		}
+	}
+
+	return nil
+}
+
+// decodeQuoted decodes a field with the string option, which has its value in
+// a string.
+func (d *decoder) decodeQuoted(v reflect.Value) error {
+	tag, err := d.peek()
+	if err != nil {
+		return err
+	}
+
+	if tag != binaryExt || v.Kind() == reflect.String {
+		return d.decode(v)
+	}
+
+	b, err := d.binary()
+	if err != nil {
+		return err
+	}
+
+	return stdjson.Unmarshal(b, v.Addr().Interface())
+}
+
+// fieldByIndex returns the field, allocating the embedded pointers on the way.
+func fieldByIndex(v reflect.Value, index []int) reflect.Value {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v
+}
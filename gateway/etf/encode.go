@@ -0,0 +1,369 @@
+package etf
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	stdjson "encoding/json"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+
+	"github.com/pkg/errors"
+)
+
+var marshalerType = reflect.TypeOf((*stdjson.Marshaler)(nil)).Elem()
+
+// Marshal encodes v in ETF.
+func Marshal(v interface{}) ([]byte, error) {
+	e := encoder{b: []byte{Version}}
+
+	if err := e.encode(reflect.ValueOf(v)); err != nil {
+		return nil, err
+	}
+
+	return e.b, nil
+}
+
+type encoder struct {
+	b []byte
+}
+
+func (e *encoder) atom(atom string) {
+	e.b = append(e.b, smallAtomUTF8Ext, byte(len(atom)))
+	e.b = append(e.b, atom...)
+}
+
+func (e *encoder) bool(b bool) {
+	if b {
+		e.atom("true")
+	} else {
+		e.atom("false")
+	}
+}
+
+func (e *encoder) int(i int64) {
+	switch {
+	case i >= 0 && i <= math.MaxUint8:
+		e.b = append(e.b, smallIntegerExt, byte(i))
+	case i >= math.MinInt32 && i <= math.MaxInt32:
+		e.b = append(e.b, integerExt, 0, 0, 0, 0)
+		binary.BigEndian.PutUint32(e.b[len(e.b)-4:], uint32(i))
+	case i < 0:
+		e.big(uint64(-i), true)
+	default:
+		e.big(uint64(i), false)
+	}
+}
+
+func (e *encoder) uint(u uint64) {
+	if u <= math.MaxInt32 {
+		e.int(int64(u))
+	} else {
+		e.big(u, false)
+	}
+}
+
+// big writes the integer as a small big, which has little-endian digits.
+func (e *encoder) big(u uint64, negative bool) {
+	var at = len(e.b)
+	e.b = append(e.b, smallBigExt, 0, 0)
+
+	if negative {
+		e.b[at+2] = 1
+	}
+
+	for ; u > 0; u >>= 8 {
+		e.b = append(e.b, byte(u))
+		e.b[at+1]++
+	}
+}
+
+func (e *encoder) float(f float64) {
+	e.b = append(e.b, newFloatExt, 0, 0, 0, 0, 0, 0, 0, 0)
+	binary.BigEndian.PutUint64(e.b[len(e.b)-8:], math.Float64bits(f))
+}
+
+func (e *encoder) binary(s string) {
+	e.b = append(e.b, binaryExt, 0, 0, 0, 0)
+	binary.BigEndian.PutUint32(e.b[len(e.b)-4:], uint32(len(s)))
+	e.b = append(e.b, s...)
+}
+
+// begin starts a map or a list, and returns the position of its header, which
+// end fills in.
+func (e *encoder) begin(isMap bool) int {
+	var start = len(e.b)
+
+	if isMap {
+		e.b = append(e.b, mapExt, 0, 0, 0, 0)
+	} else {
+		e.b = append(e.b, listExt, 0, 0, 0, 0)
+	}
+
+	return start
+}
+
+// end finishes the map or the list with n elements.
+func (e *encoder) end(start, n int, isMap bool) {
+	if !isMap && n == 0 {
+		// An empty list is its own term.
+		e.b = append(e.b[:start], nilExt)
+		return
+	}
+
+	binary.BigEndian.PutUint32(e.b[start+1:], uint32(n))
+
+	if !isMap {
+		// Proper lists end with an empty list.
+		e.b = append(e.b, nilExt)
+	}
+}
+
+func (e *encoder) encode(v reflect.Value) error {
+	if !v.IsValid() {
+		e.atom("nil")
+		return nil
+	}
+
+	var t = v.Type()
+
+	if t == rawType {
+		return e.raw(v.Bytes())
+	}
+
+	// Pointer receivers such as Snowflake's need an addressable value.
+	if t.Kind() != reflect.Ptr && !v.CanAddr() &&
+		reflect.PtrTo(t).Implements(marshalerType) {
+
+		p := reflect.New(t)
+		p.Elem().Set(v)
+		v = p.Elem()
+	}
+
+	if v.CanAddr() && v.Addr().Type().Implements(marshalerType) {
+		v = v.Addr()
+	}
+
+	if v.Type().Implements(marshalerType) {
+		if v.Kind() == reflect.Ptr && v.IsNil() {
+			e.atom("nil")
+			return nil
+		}
+
+		b, err := v.Interface().(stdjson.Marshaler).MarshalJSON()
+		if err != nil {
+			return errors.Wrapf(err, "Failed to marshal %s", v.Type())
+		}
+
+		return e.json(b)
+	}
+
+	switch v.Kind() {
+	case reflect.Bool:
+		e.bool(v.Bool())
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		e.int(v.Int())
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32,
+		reflect.Uint64, reflect.Uintptr:
+		e.uint(v.Uint())
+
+	case reflect.Float32, reflect.Float64:
+		f := v.Float()
+		if math.IsInf(f, 0) || math.IsNaN(f) {
+			return errors.Errorf("Unsupported float %v", f)
+		}
+		e.float(f)
+
+	case reflect.String:
+		e.binary(v.String())
+
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			e.atom("nil")
+			return nil
+		}
+		return e.encode(v.Elem())
+
+	case reflect.Slice:
+		if v.IsNil() {
+			e.atom("nil")
+			return nil
+		}
+
+		// Bytes are base64 strings, like in JSON.
+		if t.Elem().Kind() == reflect.Uint8 {
+			e.binary(base64.StdEncoding.EncodeToString(v.Bytes()))
+			return nil
+		}
+
+		return e.list(v)
+
+	case reflect.Array:
+		return e.list(v)
+
+	case reflect.Map:
+		if v.IsNil() {
+			e.atom("nil")
+			return nil
+		}
+		return e.encodeMap(v)
+
+	case reflect.Struct:
+		return e.encodeStruct(v)
+
+	default:
+		return errors.Errorf("Unsupported type %s", t)
+	}
+
+	return nil
+}
+
+// raw writes a Raw value, which is ETF if it came from this package, or JSON
+// otherwise.
+func (e *encoder) raw(b []byte) error {
+	switch {
+	case len(b) == 0:
+		e.atom("nil")
+	case b[0] == Version:
+		e.b = append(e.b, b[1:]...)
+	default:
+		return e.json(b)
+	}
+
+	return nil
+}
+
+func (e *encoder) list(v reflect.Value) error {
+	var start = e.begin(false)
+
+	for i := 0; i < v.Len(); i++ {
+		if err := e.encode(v.Index(i)); err != nil {
+			return err
+		}
+	}
+
+	e.end(start, v.Len(), false)
+	return nil
+}
+
+func (e *encoder) encodeMap(v reflect.Value) error {
+	var keys = make([]string, 0, v.Len())
+	var values = make(map[string]reflect.Value, v.Len())
+
+	for _, k := range v.MapKeys() {
+		var key string
+
+		switch k.Kind() {
+		case reflect.String:
+			key = k.String()
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32,
+			reflect.Int64:
+			key = strconv.FormatInt(k.Int(), 10)
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32,
+			reflect.Uint64, reflect.Uintptr:
+			key = strconv.FormatUint(k.Uint(), 10)
+		default:
+			return errors.Errorf("Unsupported map key type %s", k.Type())
+		}
+
+		keys = append(keys, key)
+		values[key] = v.MapIndex(k)
+	}
+
+	// Sorted like encoding/json, so the output is stable.
+	sort.Strings(keys)
+
+	var start = e.begin(true)
+
+	for _, k := range keys {
+		e.binary(k)
+		if err := e.encode(values[k]); err != nil {
+			return err
+		}
+	}
+
+	e.end(start, len(keys), true)
+	return nil
+}
+
+func (e *encoder) encodeStruct(v reflect.Value) error {
+	var start = e.begin(true)
+	var n int
+
+	for _, f := range cachedFields(v.Type()).list {
+		fv, ok := fieldValue(v, f.index)
+		if !ok || (f.omitEmpty && isEmptyValue(fv)) {
+			continue
+		}
+
+		e.binary(f.name)
+		n++
+
+		if f.quoted && isQuotable(fv) {
+			b, err := stdjson.Marshal(fv.Interface())
+			if err != nil {
+				return err
+			}
+			e.binary(string(b))
+			continue
+		}
+
+		if err := e.encode(fv); err != nil {
+			return errors.Wrap(err, "Failed to encode "+f.name)
+		}
+	}
+
+	e.end(start, n, true)
+	return nil
+}
+
+// fieldValue returns the field, or false if it's in a nil embedded pointer.
+func fieldValue(v reflect.Value, index []int) (reflect.Value, bool) {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				return reflect.Value{}, false
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v, true
+}
+
+// isQuotable returns true if the string option applies to the value.
+func isQuotable(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32,
+		reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		return true
+	default:
+		return false
+	}
+}
+
+// isEmptyValue is taken from encoding/json, for the omitempty option.
+func isEmptyValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Bool:
+		return !v.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int() == 0
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32,
+		reflect.Uint64, reflect.Uintptr:
+		return v.Uint() == 0
+	case reflect.Float32, reflect.Float64:
+		return v.Float() == 0
+	case reflect.Interface, reflect.Ptr:
+		return v.IsNil()
+	}
+	return false
+}
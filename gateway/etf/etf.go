@@ -0,0 +1,78 @@
+// Package etf implements the Erlang External Term Format as a driver for the
+// Gateway, which is smaller and cheaper to decode than JSON for large guilds:
+//
+//	g, err := gateway.NewGatewayWithDriver(token, etf.Driver{})
+//
+// Values are mapped the way encoding/json maps them, using the same struct
+// tags. Types that implement json.Marshaler or json.Unmarshaler, such as
+// discord.Snowflake, are converted through their JSON form. Raw fields, such
+// as the data of an OP, hold the raw ETF of the value instead of JSON.
+//
+// ETF can't be used with zlib-stream transport compression, so
+// gateway.WSCompress is ignored with this driver.
+package etf
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+)
+
+// Version is the version byte that every ETF value starts with.
+const Version = 131
+
+// The term tags, as documented in the Erlang External Term Format.
+const (
+	newFloatExt      = 70
+	smallIntegerExt  = 97
+	integerExt       = 98
+	floatExt         = 99
+	atomExt          = 100
+	smallTupleExt    = 104
+	largeTupleExt    = 105
+	nilExt           = 106
+	stringExt        = 107
+	listExt          = 108
+	binaryExt        = 109
+	smallBigExt      = 110
+	largeBigExt      = 111
+	smallAtomExt     = 115
+	mapExt           = 116
+	atomUTF8Ext      = 118
+	smallAtomUTF8Ext = 119
+)
+
+// Driver is a json.Driver that encodes in ETF.
+type Driver struct{}
+
+// Encoding returns "etf", which is the encoding given to the Gateway.
+func (Driver) Encoding() string {
+	return "etf"
+}
+
+func (Driver) Marshal(v interface{}) ([]byte, error) {
+	return Marshal(v)
+}
+
+func (Driver) Unmarshal(data []byte, v interface{}) error {
+	return Unmarshal(data, v)
+}
+
+func (Driver) DecodeStream(r io.Reader, v interface{}) error {
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		return err
+	}
+
+	return Unmarshal(b, v)
+}
+
+func (Driver) EncodeStream(w io.Writer, v interface{}) error {
+	b, err := Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	_, err = io.Copy(w, bytes.NewReader(b))
+	return err
+}
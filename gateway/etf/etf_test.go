@@ -0,0 +1,192 @@
+// +build unit
+
+package etf
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/diamondburned/arikawa/discord"
+	"github.com/diamondburned/arikawa/internal/json"
+)
+
+type testOP struct {
+	Code      int      `json:"op"`
+	Data      json.Raw `json:"d,omitempty"`
+	Sequence  int64    `json:"s,omitempty"`
+	EventName string   `json:"t,omitempty"`
+}
+
+type testBase struct {
+	ID   discord.Snowflake `json:"id"`
+	Name string            `json:"name"`
+}
+
+type testEvent struct {
+	testBase
+	Count   uint64            `json:"count,string"`
+	Tags    []string          `json:"tags"`
+	Extra   map[string]int    `json:"extra,omitempty"`
+	Parent  *testBase         `json:"parent"`
+	Ratio   float64           `json:"ratio"`
+	Flags   []int             `json:"flags"`
+	Anyway  interface{}       `json:"any"`
+	GuildID discord.Snowflake `json:"guild_id,omitempty"`
+	Ignored string            `json:"-"`
+}
+
+func TestRoundTrip(t *testing.T) {
+	var ev = testEvent{
+		testBase: testBase{ID: 175928847299117063, Name: "arikawa"},
+		Count:    1 << 40,
+		Tags:     []string{"a", "b"},
+		Extra:    map[string]int{"x": -1, "y": 70000},
+		Ratio:    0.5,
+		Flags:    []int{},
+		Anyway:   map[string]interface{}{"k": "v"},
+		Ignored:  "ignored",
+	}
+
+	data, err := Marshal(ev)
+	if err != nil {
+		t.Fatal("Failed to marshal event:", err)
+	}
+
+	b, err := Marshal(testOP{Code: 0, Data: data, Sequence: 1, EventName: "X"})
+	if err != nil {
+		t.Fatal("Failed to marshal OP:", err)
+	}
+
+	var op testOP
+	if err := Unmarshal(b, &op); err != nil {
+		t.Fatal("Failed to unmarshal OP:", err)
+	}
+
+	if op.Sequence != 1 || op.EventName != "X" {
+		t.Fatalf("Unexpected OP: %+v", op)
+	}
+
+	var got testEvent
+	if err := Unmarshal(op.Data, &got); err != nil {
+		t.Fatal("Failed to unmarshal event:", err)
+	}
+
+	ev.Ignored = ""
+	if !reflect.DeepEqual(got, ev) {
+		t.Fatalf("Unexpected event:\n%+v\nexpected:\n%+v", got, ev)
+	}
+}
+
+// TestUnmarshalDiscord decodes a term the way Discord encodes them, with atom
+// keys, nil atoms and big integers.
+func TestUnmarshalDiscord(t *testing.T) {
+	var b = []byte{Version, mapExt, 0, 0, 0, 3}
+
+	// id: 175928847299117063
+	b = append(b, smallAtomUTF8Ext, 2, 'i', 'd')
+	b = append(b, smallBigExt, 8, 0, 7, 0, 2, 0xc1, 0x5a, 6, 0x71, 2)
+	// name: "arikawa"
+	b = append(b, atomExt, 0, 4, 'n', 'a', 'm', 'e')
+	b = append(b, binaryExt, 0, 0, 0, 7, 'a', 'r', 'i', 'k', 'a', 'w', 'a')
+	// parent: nil
+	b = append(b, smallAtomExt, 6, 'p', 'a', 'r', 'e', 'n', 't')
+	b = append(b, smallAtomExt, 3, 'n', 'i', 'l')
+
+	var ev = testEvent{Parent: &testBase{}}
+	if err := Unmarshal(b, &ev); err != nil {
+		t.Fatal("Failed to unmarshal:", err)
+	}
+
+	if ev.ID != 175928847299117063 || ev.Name != "arikawa" || ev.Parent != nil {
+		t.Fatalf("Unexpected event: %+v", ev)
+	}
+
+	j, err := JSON(b)
+	if err != nil {
+		t.Fatal("Failed to convert to JSON:", err)
+	}
+
+	const expected = `{"id":175928847299117063,"name":"arikawa","parent":null}`
+	if string(j) != expected {
+		t.Fatalf("Unexpected JSON %s", j)
+	}
+}
+
+func TestUnmarshalErrors(t *testing.T) {
+	var v testBase
+
+	if err := Unmarshal([]byte(`{}`), &v); err == nil {
+		t.Fatal("JSON was decoded as ETF")
+	}
+
+	if err := Unmarshal([]byte{Version, mapExt, 0, 0, 0, 1}, &v); err == nil {
+		t.Fatal("A truncated map was decoded")
+	}
+
+	// name: 1
+	b := []byte{Version, mapExt, 0, 0, 0, 1,
+		binaryExt, 0, 0, 0, 4, 'n', 'a', 'm', 'e', smallIntegerExt, 1}
+	if err := Unmarshal(b, &v); err == nil {
+		t.Fatal("An integer was decoded into a string")
+	}
+}
+
+func TestUnmarshalMalformed(t *testing.T) {
+	data, err := Marshal(testEvent{
+		testBase: testBase{ID: 1, Name: "arikawa"},
+		Tags:     []string{"a", "b"},
+		Extra:    map[string]int{"x": 1},
+		Flags:    []int{1 << 40},
+	})
+	if err != nil {
+		t.Fatal("Failed to marshal event:", err)
+	}
+
+	for i := 1; i < len(data); i++ {
+		var ev testEvent
+		if err := Unmarshal(data[:i], &ev); err == nil {
+			t.Fatalf("Frame truncated to %d bytes was decoded", i)
+		}
+	}
+
+	// Corrupted frames must fail or decode, but never panic.
+	for i := 1; i < len(data); i++ {
+		for _, c := range []byte{0x00, 0x7f, 0xff} {
+			var b = append([]byte(nil), data...)
+			b[i] = c
+
+			var ev testEvent
+			Unmarshal(b, &ev)
+			JSON(b)
+		}
+	}
+
+	var huge = map[string][]byte{
+		"list":   {Version, listExt, 0xff, 0xff, 0xff, 0xff, nilExt},
+		"string": {Version, stringExt, 0xff, 0xff, 1},
+		"tuple":  {Version, largeTupleExt, 0x7f, 0xff, 0xff, 0xff},
+		"map":    {Version, mapExt, 0xff, 0xff, 0xff, 0xff},
+		"bignum": {Version, largeBigExt, 0xff, 0xff, 0xff, 0xff, 0, 1},
+	}
+
+	for name, b := range huge {
+		var v struct {
+			List []int          `json:"list"`
+			Map  map[string]int `json:"map"`
+			Any  interface{}    `json:"any"`
+		}
+
+		if err := Unmarshal(b, &v.List); err == nil {
+			t.Errorf("Huge %s was decoded into a list", name)
+		}
+		if err := Unmarshal(b, &v.Map); err == nil {
+			t.Errorf("Huge %s was decoded into a map", name)
+		}
+		if err := Unmarshal(b, &v.Any); err == nil {
+			t.Errorf("Huge %s was decoded into an interface", name)
+		}
+		if _, err := JSON(b); err == nil {
+			t.Errorf("Huge %s was converted to JSON", name)
+		}
+	}
+}
@@ -0,0 +1,148 @@
+package etf
+
+import (
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// field is a struct field, named by its json tag.
+type field struct {
+	name      string
+	index     []int
+	omitEmpty bool
+	// quoted is true for the string option, which puts numbers and bools in
+	// strings.
+	quoted bool
+}
+
+type structFields struct {
+	list   []field
+	byName map[string]*field
+}
+
+// lookup returns the field with the name, or the first one that matches it
+// case-insensitively, like encoding/json.
+func (s *structFields) lookup(name string) *field {
+	if f, ok := s.byName[name]; ok {
+		return f
+	}
+
+	for i := range s.list {
+		if strings.EqualFold(s.list[i].name, name) {
+			return &s.list[i]
+		}
+	}
+
+	return nil
+}
+
+var fieldCache sync.Map // reflect.Type -> *structFields
+
+func cachedFields(t reflect.Type) *structFields {
+	if f, ok := fieldCache.Load(t); ok {
+		return f.(*structFields)
+	}
+
+	f, _ := fieldCache.LoadOrStore(t, typeFields(t))
+	return f.(*structFields)
+}
+
+// typeFields returns the fields of the struct, including the ones promoted from
+// embedded structs. Like encoding/json, shallower fields hide deeper ones.
+func typeFields(t reflect.Type) *structFields {
+	type embedded struct {
+		typ   reflect.Type
+		index []int
+	}
+
+	var fields = &structFields{byName: map[string]*field{}}
+	var visited = map[reflect.Type]bool{}
+	var current = []embedded{{t, nil}}
+
+	for len(current) > 0 {
+		var next []embedded
+
+		for _, e := range current {
+			if visited[e.typ] {
+				continue
+			}
+			visited[e.typ] = true
+
+			for i := 0; i < e.typ.NumField(); i++ {
+				sf := e.typ.Field(i)
+
+				tag := sf.Tag.Get("json")
+				if tag == "-" {
+					continue
+				}
+
+				name, opts := parseTag(tag)
+				index := append(append([]int(nil), e.index...), i)
+
+				if sf.Anonymous && name == "" {
+					ft := sf.Type
+					if ft.Kind() == reflect.Ptr {
+						// Unexported pointers can't be allocated.
+						if sf.PkgPath != "" {
+							continue
+						}
+						ft = ft.Elem()
+					}
+
+					if ft.Kind() == reflect.Struct {
+						next = append(next, embedded{ft, index})
+						continue
+					}
+				}
+
+				if sf.PkgPath != "" {
+					continue
+				}
+
+				if name == "" {
+					name = sf.Name
+				}
+
+				if _, ok := fields.byName[name]; ok {
+					continue
+				}
+
+				fields.list = append(fields.list, field{
+					name:      name,
+					index:     index,
+					omitEmpty: hasOption(opts, "omitempty"),
+					quoted:    hasOption(opts, "string"),
+				})
+				fields.byName[name] = nil
+			}
+		}
+
+		current = next
+	}
+
+	// The list doesn't grow anymore, so pointers into it are stable.
+	for i := range fields.list {
+		fields.byName[fields.list[i].name] = &fields.list[i]
+	}
+
+	return fields
+}
+
+func parseTag(tag string) (name, opts string) {
+	if i := strings.Index(tag, ","); i > -1 {
+		return tag[:i], tag[i+1:]
+	}
+	return tag, ""
+}
+
+func hasOption(opts, option string) bool {
+	for opts != "" {
+		var opt string
+		opt, opts = parseTag(opts)
+		if opt == option {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,286 @@
+package etf
+
+import (
+	"bytes"
+	stdjson "encoding/json"
+	"math"
+	"strconv"
+	"unicode/utf8"
+
+	"github.com/pkg/errors"
+)
+
+// JSON converts an ETF value to JSON, such as for logging frames.
+func JSON(data []byte) ([]byte, error) {
+	if len(data) == 0 || data[0] != Version {
+		return nil, errors.New("Missing ETF version byte")
+	}
+
+	var buf bytes.Buffer
+	var d = decoder{b: data, i: 1}
+
+	if err := d.json(&buf); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// json writes the next term as JSON. Atoms other than nil, true and false are
+// written as strings, and so are map keys.
+func (d *decoder) json(buf *bytes.Buffer) error {
+	tag, err := d.peek()
+	if err != nil {
+		return err
+	}
+
+	switch tag {
+	case smallAtomExt, smallAtomUTF8Ext, atomExt, atomUTF8Ext:
+		atom, err := d.atom()
+		if err != nil {
+			return err
+		}
+
+		switch atom {
+		case "nil":
+			buf.WriteString("null")
+		case "true", "false":
+			buf.WriteString(atom)
+		default:
+			writeString(buf, atom)
+		}
+
+	case smallIntegerExt, integerExt, smallBigExt, largeBigExt:
+		i, err := d.integer()
+		if err != nil {
+			return err
+		}
+		buf.WriteString(i.String())
+
+	case newFloatExt, floatExt:
+		f, err := d.float()
+		if err != nil {
+			return err
+		}
+		if math.IsInf(f, 0) || math.IsNaN(f) {
+			return errors.Errorf("Unsupported float %v", f)
+		}
+		buf.WriteString(strconv.FormatFloat(f, 'g', -1, 64))
+
+	case binaryExt:
+		b, err := d.binary()
+		if err != nil {
+			return err
+		}
+		writeString(buf, string(b))
+
+	case stringExt:
+		// Lists of small integers are sent as strings.
+		b, err := d.binary()
+		if err != nil {
+			return err
+		}
+
+		buf.WriteByte('[')
+		for i, c := range b {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			buf.WriteString(strconv.Itoa(int(c)))
+		}
+		buf.WriteByte(']')
+
+	case nilExt, listExt, smallTupleExt, largeTupleExt:
+		return d.jsonList(buf)
+
+	case mapExt:
+		return d.jsonMap(buf)
+
+	default:
+		return errors.Errorf("Unknown ETF tag %d", tag)
+	}
+
+	return nil
+}
+
+func (d *decoder) jsonList(buf *bytes.Buffer) error {
+	tag, err := d.uint8()
+	if err != nil {
+		return err
+	}
+
+	var n int
+	switch tag {
+	case smallTupleExt:
+		n, err = d.uint8()
+	case largeTupleExt, listExt:
+		n, err = d.uint32()
+	}
+	if err != nil {
+		return err
+	}
+
+	buf.WriteByte('[')
+	for i := 0; i < n; i++ {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		if err := d.json(buf); err != nil {
+			return err
+		}
+	}
+	buf.WriteByte(']')
+
+	if tag == listExt {
+		if _, err := d.skip(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (d *decoder) jsonMap(buf *bytes.Buffer) error {
+	if _, err := d.uint8(); err != nil {
+		return err
+	}
+
+	n, err := d.uint32()
+	if err != nil {
+		return err
+	}
+
+	buf.WriteByte('{')
+	for i := 0; i < n; i++ {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+
+		k, err := d.key()
+		if err != nil {
+			return err
+		}
+
+		writeString(buf, k)
+		buf.WriteByte(':')
+
+		if err := d.json(buf); err != nil {
+			return err
+		}
+	}
+	buf.WriteByte('}')
+
+	return nil
+}
+
+const hex = "0123456789abcdef"
+
+// writeString writes s as a JSON string. Invalid UTF-8 is replaced, like
+// encoding/json does.
+func writeString(buf *bytes.Buffer, s string) {
+	buf.WriteByte('"')
+
+	for i := 0; i < len(s); {
+		c := s[i]
+
+		if c < utf8.RuneSelf {
+			switch {
+			case c == '"' || c == '\\':
+				buf.WriteByte('\\')
+				buf.WriteByte(c)
+			case c == '\n':
+				buf.WriteString(`\n`)
+			case c == '\r':
+				buf.WriteString(`\r`)
+			case c == '\t':
+				buf.WriteString(`\t`)
+			case c < 0x20:
+				buf.WriteString(`\u00`)
+				buf.WriteByte(hex[c>>4])
+				buf.WriteByte(hex[c&0xF])
+			default:
+				buf.WriteByte(c)
+			}
+			i++
+			continue
+		}
+
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if r == utf8.RuneError && size == 1 {
+			buf.WriteString("\ufffd")
+		} else {
+			buf.WriteString(s[i : i+size])
+		}
+		i += size
+	}
+
+	buf.WriteByte('"')
+}
+
+// json writes the JSON value as ETF, for types that implement json.Marshaler.
+func (e *encoder) json(b []byte) error {
+	dec := stdjson.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+
+	return e.jsonValue(dec)
+}
+
+func (e *encoder) jsonValue(dec *stdjson.Decoder) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return errors.Wrap(err, "Invalid JSON")
+	}
+
+	switch tok := tok.(type) {
+	case nil:
+		e.atom("nil")
+	case bool:
+		e.bool(tok)
+	case string:
+		e.binary(tok)
+	case stdjson.Number:
+		if i, err := tok.Int64(); err == nil {
+			e.int(i)
+			break
+		}
+
+		if u, err := strconv.ParseUint(tok.String(), 10, 64); err == nil {
+			e.uint(u)
+			break
+		}
+
+		f, err := tok.Float64()
+		if err != nil {
+			return err
+		}
+		e.float(f)
+
+	case stdjson.Delim:
+		var isMap = tok == '{'
+		var start = e.begin(isMap)
+		var n int
+
+		for ; dec.More(); n++ {
+			if isMap {
+				key, err := dec.Token()
+				if err != nil {
+					return errors.Wrap(err, "Invalid JSON")
+				}
+				e.binary(key.(string))
+			}
+
+			if err := e.jsonValue(dec); err != nil {
+				return err
+			}
+		}
+
+		// Read the closing delimiter.
+		if _, err := dec.Token(); err != nil {
+			return errors.Wrap(err, "Invalid JSON")
+		}
+
+		e.end(start, n, isMap)
+	}
+
+	return nil
+}
@@ -1,6 +1,9 @@
 package gateway
 
-import "github.com/diamondburned/arikawa/discord"
+import (
+	"github.com/diamondburned/arikawa/discord"
+	"github.com/diamondburned/arikawa/internal/json"
+)
 
 // Rules: VOICE_STATE_UPDATE -> VoiceStateUpdateEvent
 
@@ -30,6 +33,68 @@ type (
 	}
 )
 
+// https://discord.com/developers/docs/topics/gateway#threads
+type (
+	ThreadCreateEvent discord.Channel
+	ThreadUpdateEvent discord.Channel
+	ThreadDeleteEvent struct {
+		ID       discord.Snowflake   `json:"id"`
+		GuildID  discord.Snowflake   `json:"guild_id"`
+		ParentID discord.Snowflake   `json:"parent_id"`
+		Type     discord.ChannelType `json:"type"`
+	}
+
+	// ThreadListSyncEvent is sent when the current user gains access to a
+	// channel, with the active threads in it. ChannelIDs is empty if the
+	// threads of the whole guild are synced.
+	ThreadListSyncEvent struct {
+		GuildID    discord.Snowflake      `json:"guild_id"`
+		ChannelIDs []discord.Snowflake    `json:"channel_ids,omitempty"`
+		Threads    []discord.Channel      `json:"threads"`
+		Members    []discord.ThreadMember `json:"members"`
+	}
+
+	// ThreadMemberUpdateEvent is sent when the thread member of the current
+	// user is updated.
+	ThreadMemberUpdateEvent discord.ThreadMember
+
+	// ThreadMembersUpdateEvent is sent when users are added to or removed
+	// from a thread.
+	ThreadMembersUpdateEvent struct {
+		ID      discord.Snowflake `json:"id"`
+		GuildID discord.Snowflake `json:"guild_id"`
+		// MemberCount stops counting at 50.
+		MemberCount      int                    `json:"member_count"`
+		AddedMembers     []discord.ThreadMember `json:"added_members,omitempty"`
+		RemovedMemberIDs []discord.Snowflake    `json:"removed_member_ids,omitempty"`
+	}
+)
+
+// https://discord.com/developers/docs/topics/gateway#guild-scheduled-event-create
+type (
+	GuildScheduledEventCreateEvent discord.GuildScheduledEvent
+	GuildScheduledEventUpdateEvent discord.GuildScheduledEvent
+	GuildScheduledEventDeleteEvent discord.GuildScheduledEvent
+
+	GuildScheduledEventUserAddEvent struct {
+		EventID discord.Snowflake `json:"guild_scheduled_event_id"`
+		UserID  discord.Snowflake `json:"user_id"`
+		GuildID discord.Snowflake `json:"guild_id"`
+	}
+	GuildScheduledEventUserRemoveEvent struct {
+		EventID discord.Snowflake `json:"guild_scheduled_event_id"`
+		UserID  discord.Snowflake `json:"user_id"`
+		GuildID discord.Snowflake `json:"guild_id"`
+	}
+)
+
+// https://discord.com/developers/docs/topics/gateway#stage-instances
+type (
+	StageInstanceCreateEvent discord.StageInstance
+	StageInstanceUpdateEvent discord.StageInstance
+	StageInstanceDeleteEvent discord.StageInstance
+)
+
 // https://discordapp.com/developers/docs/topics/gateway#guilds
 type (
 	GuildCreateEvent struct {
@@ -40,10 +105,13 @@ type (
 		Unavailable bool              `json:"unavailable,omitempty"`
 		MemberCount uint64            `json:"member_count,omitempty"`
 
-		VoiceStates []discord.VoiceState `json:"voice_state,omitempty"`
+		VoiceStates []discord.VoiceState `json:"voice_states,omitempty"`
 		Members     []discord.Member     `json:"members,omitempty"`
 		Channels    []discord.Channel    `json:"channel,omitempty"`
 		Presences   []discord.Presence   `json:"presences,omitempty"`
+		// Threads are the active threads the current user can see. They are
+		// only sent since API v9.
+		Threads []discord.Channel `json:"threads,omitempty"`
 	}
 	GuildUpdateEvent discord.Guild
 	GuildDeleteEvent struct {
@@ -83,6 +151,12 @@ type (
 		RoleIDs []discord.Snowflake `json:"roles"`
 		User    discord.User        `json:"user"`
 		Nick    string              `json:"nick"`
+		Avatar  discord.Hash        `json:"avatar"`
+		Banner  discord.Hash        `json:"banner"`
+		Pending bool                `json:"pending,omitempty"`
+
+		Joined       discord.Timestamp `json:"joined_at"`
+		BoostedSince discord.Timestamp `json:"premium_since,omitempty"`
 	}
 
 	// GuildMembersChunkEvent is sent when Guild Request Members is called.
@@ -95,6 +169,13 @@ type (
 
 		// Only filled if requested
 		Presences []discord.Presence `json:"presences,omitempty"`
+
+		// ChunkIndex is the index of the chunk, out of ChunkCount.
+		ChunkIndex int `json:"chunk_index"`
+		ChunkCount int `json:"chunk_count"`
+
+		// Nonce is the nonce of the request.
+		Nonce string `json:"nonce,omitempty"`
 	}
 
 	GuildRoleCreateEvent struct {
@@ -115,8 +196,38 @@ func (u GuildMemberUpdateEvent) Update(m *discord.Member) {
 	m.RoleIDs = u.RoleIDs
 	m.User = u.User
 	m.Nick = u.Nick
+	m.Avatar = u.Avatar
+	m.Banner = u.Banner
+	m.Pending = u.Pending
+	m.Joined = u.Joined
+	m.BoostedSince = u.BoostedSince
 }
 
+// https://discordapp.com/developers/docs/topics/gateway#invites
+type (
+	InviteCreateEvent struct {
+		Code      string            `json:"code"`
+		CreatedAt discord.Timestamp `json:"created_at"`
+		ChannelID discord.Snowflake `json:"channel_id"`
+		GuildID   discord.Snowflake `json:"guild_id,omitempty"`
+
+		// Similar to discord.Invite
+		Inviter    *discord.User          `json:"inviter,omitempty"`
+		Target     *discord.User          `json:"target_user,omitempty"`
+		TargetType discord.InviteUserType `json:"target_user_type,omitempty"`
+
+		MaxAge    discord.Seconds `json:"max_age"`
+		MaxUses   uint            `json:"max_uses"`
+		Temporary bool            `json:"temporary"`
+		Uses      uint            `json:"uses"`
+	}
+	InviteDeleteEvent struct {
+		Code      string            `json:"code"`
+		ChannelID discord.Snowflake `json:"channel_id"`
+		GuildID   discord.Snowflake `json:"guild_id,omitempty"`
+	}
+)
+
 // https://discordapp.com/developers/docs/topics/gateway#messages
 type (
 	MessageCreateEvent discord.Message
@@ -132,6 +243,13 @@ type (
 		GuildID   discord.Snowflake   `json:"guild_id,omitempty"`
 	}
 
+	// MessageAckEvent is sent to user accounts when a channel is read up to
+	// the message, such as from another client.
+	MessageAckEvent struct {
+		MessageID discord.Snowflake `json:"message_id"`
+		ChannelID discord.Snowflake `json:"channel_id"`
+	}
+
 	MessageReactionAddEvent struct {
 		UserID    discord.Snowflake `json:"user_id"`
 		ChannelID discord.Snowflake `json:"channel_id"`
@@ -153,6 +271,8 @@ type (
 	}
 	MessageReactionRemoveAllEvent struct {
 		ChannelID discord.Snowflake `json:"channel_id"`
+		MessageID discord.Snowflake `json:"message_id"`
+		GuildID   discord.Snowflake `json:"guild_id,omitempty"`
 	}
 )
 
@@ -181,6 +301,11 @@ type (
 	}
 )
 
+// https://discord.com/developers/docs/topics/gateway#interactions
+type (
+	InteractionCreateEvent discord.Interaction
+)
+
 // https://discordapp.com/developers/docs/topics/gateway#webhooks
 type (
 	WebhooksUpdateEvent struct {
@@ -194,3 +319,31 @@ type (
 	UserGuildSettingsUpdateEvent UserGuildSettings
 	UserSettingsUpdateEvent      UserSettings
 )
+
+// UnknownEvent is sent for dispatch events that aren't known yet, so they can
+// still be handled. Data is the raw event, in the encoding of the Gateway's
+// Driver.
+type UnknownEvent struct {
+	Name string
+	Data json.Raw
+}
+
+// RawEvent is sent after every dispatch event if the Gateway's RawEvents is
+// true. It has the raw data of the event, in the encoding of the Gateway's
+// Driver, for fields the event types don't have yet:
+//
+//	s.AddHandler(func(raw *gateway.RawEvent) {
+//		if _, ok := raw.Event.(*gateway.MessageCreateEvent); ok {
+//			var m struct {
+//				NewField string `json:"new_field"`
+//			}
+//			s.Gateway.Driver.Unmarshal(raw.Data, &m)
+//		}
+//	})
+type RawEvent struct {
+	Name string
+	Data json.Raw
+	// Event is the event that was sent before, which is an UnknownEvent if
+	// the event isn't known.
+	Event Event
+}
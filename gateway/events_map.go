@@ -14,6 +14,17 @@ var EventCreator = map[string]func() Event{
 	"CHANNEL_DELETE":      func() Event { return new(ChannelDeleteEvent) },
 	"CHANNEL_PINS_UPDATE": func() Event { return new(ChannelPinsUpdateEvent) },
 
+	"THREAD_CREATE":    func() Event { return new(ThreadCreateEvent) },
+	"THREAD_UPDATE":    func() Event { return new(ThreadUpdateEvent) },
+	"THREAD_DELETE":    func() Event { return new(ThreadDeleteEvent) },
+	"THREAD_LIST_SYNC": func() Event { return new(ThreadListSyncEvent) },
+	"THREAD_MEMBER_UPDATE": func() Event {
+		return new(ThreadMemberUpdateEvent)
+	},
+	"THREAD_MEMBERS_UPDATE": func() Event {
+		return new(ThreadMembersUpdateEvent)
+	},
+
 	"GUILD_CREATE": func() Event { return new(GuildCreateEvent) },
 	"GUILD_UPDATE": func() Event { return new(GuildUpdateEvent) },
 	"GUILD_DELETE": func() Event { return new(GuildDeleteEvent) },
@@ -35,10 +46,30 @@ var EventCreator = map[string]func() Event{
 	"GUILD_ROLE_UPDATE": func() Event { return new(GuildRoleUpdateEvent) },
 	"GUILD_ROLE_DELETE": func() Event { return new(GuildRoleDeleteEvent) },
 
+	"GUILD_SCHEDULED_EVENT_CREATE": func() Event {
+		return new(GuildScheduledEventCreateEvent)
+	},
+	"GUILD_SCHEDULED_EVENT_UPDATE": func() Event {
+		return new(GuildScheduledEventUpdateEvent)
+	},
+	"GUILD_SCHEDULED_EVENT_DELETE": func() Event {
+		return new(GuildScheduledEventDeleteEvent)
+	},
+	"GUILD_SCHEDULED_EVENT_USER_ADD": func() Event {
+		return new(GuildScheduledEventUserAddEvent)
+	},
+	"GUILD_SCHEDULED_EVENT_USER_REMOVE": func() Event {
+		return new(GuildScheduledEventUserRemoveEvent)
+	},
+
+	"INVITE_CREATE": func() Event { return new(InviteCreateEvent) },
+	"INVITE_DELETE": func() Event { return new(InviteDeleteEvent) },
+
 	"MESSAGE_CREATE":      func() Event { return new(MessageCreateEvent) },
 	"MESSAGE_UPDATE":      func() Event { return new(MessageUpdateEvent) },
 	"MESSAGE_DELETE":      func() Event { return new(MessageDeleteEvent) },
 	"MESSAGE_DELETE_BULK": func() Event { return new(MessageDeleteBulkEvent) },
+	"MESSAGE_ACK":         func() Event { return new(MessageAckEvent) },
 
 	"MESSAGE_REACTION_ADD": func() Event {
 		return new(MessageReactionAddEvent)
@@ -54,8 +85,20 @@ var EventCreator = map[string]func() Event{
 	"TYPING_START":    func() Event { return new(TypingStartEvent) },
 	"USER_UPDATE":     func() Event { return new(UserUpdateEvent) },
 
+	"STAGE_INSTANCE_CREATE": func() Event {
+		return new(StageInstanceCreateEvent)
+	},
+	"STAGE_INSTANCE_UPDATE": func() Event {
+		return new(StageInstanceUpdateEvent)
+	},
+	"STAGE_INSTANCE_DELETE": func() Event {
+		return new(StageInstanceDeleteEvent)
+	},
+
 	"VOICE_STATE_UPDATE":  func() Event { return new(VoiceStateUpdateEvent) },
 	"VOICE_SERVER_UPDATE": func() Event { return new(VoiceServerUpdateEvent) },
 
+	"INTERACTION_CREATE": func() Event { return new(InteractionCreateEvent) },
+
 	"WEBHOOKS_UPDATE": func() Event { return new(WebhooksUpdateEvent) },
 }
@@ -0,0 +1,129 @@
+package fixture
+
+import (
+	"bytes"
+	stdjson "encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// RedactedKeys are the keys whose values are replaced with "[REDACTED]".
+var RedactedKeys = []string{
+	"token", "password", "email", "phone", "ip", "session_id",
+}
+
+// NameKeys are the keys of user names, whose values are replaced with names
+// such as "user1". The same name is always replaced with the same one.
+var NameKeys = []string{"username", "global_name", "nick"}
+
+// HashKeys are the keys of image hashes, whose values are replaced with fake
+// hashes, keeping the "a_" prefix of animated images.
+var HashKeys = []string{"avatar", "banner"}
+
+// Anonymizer scrubs tokens and user data from JSON events. Names and hashes
+// are replaced consistently across the events it anonymizes, so a user keeps
+// the same name in all of them. IDs are kept, as events refer to each other
+// with them.
+type Anonymizer struct {
+	// KeepContent keeps the content of messages, which is replaced with
+	// "[REDACTED]" otherwise. Regression tests of commands need it, but the
+	// content should then be checked by hand.
+	KeepContent bool
+
+	mutex  sync.Mutex
+	names  map[string]string
+	hashes map[string]string
+}
+
+// NewAnonymizer creates an Anonymizer.
+func NewAnonymizer() *Anonymizer {
+	return &Anonymizer{
+		names:  map[string]string{},
+		hashes: map[string]string{},
+	}
+}
+
+// Anonymize returns the anonymized copy of the JSON data.
+func (a *Anonymizer) Anonymize(data []byte) ([]byte, error) {
+	var v interface{}
+
+	// Numbers are kept as they are, as they could be too large for floats.
+	d := stdjson.NewDecoder(bytes.NewReader(data))
+	d.UseNumber()
+
+	if err := d.Decode(&v); err != nil {
+		return nil, err
+	}
+
+	a.mutex.Lock()
+	v = a.value("", v)
+	a.mutex.Unlock()
+
+	return stdjson.Marshal(v)
+}
+
+func (a *Anonymizer) value(key string, v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for k, value := range v {
+			v[k] = a.value(k, value)
+		}
+		return v
+
+	case []interface{}:
+		for i, value := range v {
+			v[i] = a.value(key, value)
+		}
+		return v
+
+	case string:
+		return a.string(key, v)
+
+	default:
+		return v
+	}
+}
+
+func (a *Anonymizer) string(key, v string) string {
+	switch {
+	case v == "":
+		return v
+
+	case has(RedactedKeys, key), key == "content" && !a.KeepContent:
+		return "[REDACTED]"
+
+	case has(NameKeys, key):
+		name, ok := a.names[v]
+		if !ok {
+			name = fmt.Sprintf("user%d", len(a.names)+1)
+			a.names[v] = name
+		}
+		return name
+
+	case has(HashKeys, key):
+		var prefix string
+		if strings.HasPrefix(v, "a_") {
+			prefix = "a_"
+		}
+
+		hash, ok := a.hashes[v]
+		if !ok {
+			hash = prefix + fmt.Sprintf("%032x", len(a.hashes)+1)
+			a.hashes[v] = hash
+		}
+		return hash
+
+	default:
+		return v
+	}
+}
+
+func has(keys []string, key string) bool {
+	for _, k := range keys {
+		if k == key {
+			return true
+		}
+	}
+	return false
+}
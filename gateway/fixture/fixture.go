@@ -0,0 +1,181 @@
+// Package fixture records gateway events into anonymized JSON fixtures, and
+// loads them back for regression tests of event handling.
+//
+// Events are recorded from the RawEvents of a Gateway:
+//
+//	s.Gateway.RawEvents = true
+//
+//	r := fixture.NewRecorder()
+//	s.AddHandler(r.Record)
+//
+//	// Later, once the events to reproduce happened:
+//	r.SaveFile("testdata/guild_create.json")
+//
+// A test can then replay the fixture on the handler of a State:
+//
+//	ops, err := fixture.LoadFile("testdata/guild_create.json")
+//	if err != nil {
+//		t.Fatal(err)
+//	}
+//	if err := fixture.Replay(s.Handler, ops); err != nil {
+//		t.Fatal(err)
+//	}
+package fixture
+
+import (
+	"io"
+	"os"
+	"sync"
+
+	"github.com/diamondburned/arikawa/gateway"
+	"github.com/diamondburned/arikawa/gateway/etf"
+	"github.com/diamondburned/arikawa/handler"
+	"github.com/diamondburned/arikawa/internal/json"
+	"github.com/pkg/errors"
+)
+
+// Recorder records dispatch events, which are anonymized when saved.
+type Recorder struct {
+	// Anonymizer anonymizes the events when they're saved.
+	Anonymizer *Anonymizer
+
+	mutex  sync.Mutex
+	events []gateway.RawEvent
+}
+
+// NewRecorder creates a Recorder with a new Anonymizer.
+func NewRecorder() *Recorder {
+	return &Recorder{Anonymizer: NewAnonymizer()}
+}
+
+// Record records the event. It's meant to be added as a handler, and requires
+// the RawEvents of the Gateway to be true.
+func (r *Recorder) Record(ev *gateway.RawEvent) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.events = append(r.events, *ev)
+}
+
+// Reset forgets the events recorded so far.
+func (r *Recorder) Reset() {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.events = nil
+}
+
+// Save writes the events recorded so far as a JSON array of dispatch OPs. The
+// events are numbered from 1, in the order they were recorded.
+func (r *Recorder) Save(w io.Writer) error {
+	r.mutex.Lock()
+	var events = append([]gateway.RawEvent(nil), r.events...)
+	r.mutex.Unlock()
+
+	var ops = make([]gateway.OP, 0, len(events))
+
+	for i, ev := range events {
+		var data = []byte(ev.Data)
+
+		// Fixtures are always JSON, even if the Gateway used ETF.
+		if len(data) > 0 && data[0] == etf.Version {
+			j, err := etf.JSON(data)
+			if err != nil {
+				return errors.Wrap(err, "Failed to convert "+ev.Name)
+			}
+			data = j
+		}
+
+		data, err := r.Anonymizer.Anonymize(data)
+		if err != nil {
+			return errors.Wrap(err, "Failed to anonymize "+ev.Name)
+		}
+
+		ops = append(ops, gateway.OP{
+			Code:      gateway.DispatchOP,
+			Data:      data,
+			Sequence:  int64(i + 1),
+			EventName: ev.Name,
+		})
+	}
+
+	return (json.Default{}).EncodeStream(w, ops)
+}
+
+// SaveFile saves the events to the file, which is overwritten.
+func (r *Recorder) SaveFile(path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return errors.Wrap(err, "Failed to create fixture")
+	}
+
+	if err := r.Save(f); err != nil {
+		f.Close()
+		return err
+	}
+
+	return f.Close()
+}
+
+// Load reads a fixture written by Save.
+func Load(r io.Reader) ([]gateway.OP, error) {
+	var ops []gateway.OP
+	if err := (json.Default{}).DecodeStream(r, &ops); err != nil {
+		return nil, errors.Wrap(err, "Failed to decode fixture")
+	}
+
+	return ops, nil
+}
+
+// LoadFile reads a fixture file written by SaveFile.
+func LoadFile(path string) ([]gateway.OP, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to open fixture")
+	}
+	defer f.Close()
+
+	return Load(f)
+}
+
+// Events decodes the OPs into events, the same way a Gateway does.
+func Events(ops []gateway.OP) ([]gateway.Event, error) {
+	g := &gateway.Gateway{
+		Driver:   json.Default{},
+		Events:   make(chan gateway.Event, 1),
+		Sequence: gateway.NewSequence(),
+	}
+
+	var events = make([]gateway.Event, 0, len(ops))
+
+	for i := range ops {
+		if ops[i].Code != gateway.DispatchOP {
+			return nil, errors.Errorf(
+				"OP %d is %d, not a dispatch", i, ops[i].Code)
+		}
+
+		if err := gateway.HandleOP(g, &ops[i]); err != nil {
+			return nil, err
+		}
+
+		events = append(events, <-g.Events)
+	}
+
+	return events, nil
+}
+
+// Replay decodes the OPs and calls the handler with each event, in order,
+// like a Session does. The handlers run in the background unless the Handler
+// is Synchronous, so they might not be done when Replay returns.
+func Replay(h *handler.Handler, ops []gateway.OP) error {
+	events, err := Events(ops)
+	if err != nil {
+		return err
+	}
+
+	for _, ev := range events {
+		h.Call(ev)
+	}
+
+	return nil
+}
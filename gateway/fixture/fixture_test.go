@@ -0,0 +1,69 @@
+// +build unit
+
+package fixture
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/diamondburned/arikawa/gateway"
+	"github.com/diamondburned/arikawa/handler"
+)
+
+func TestRecordReplay(t *testing.T) {
+	r := NewRecorder()
+	r.Record(&gateway.RawEvent{
+		Name: "READY",
+		Data: []byte(`{"v":6,"session_id":"secret","user":` +
+			`{"id":"1","username":"alice","email":"alice@example.com"}}`),
+	})
+	r.Record(&gateway.RawEvent{
+		Name: "MESSAGE_CREATE",
+		Data: []byte(`{"id":"2","channel_id":"3","content":"hi bob",` +
+			`"author":{"id":"1","username":"alice","avatar":"a_1234"}}`),
+	})
+
+	var b bytes.Buffer
+	if err := r.Save(&b); err != nil {
+		t.Fatal("Failed to save:", err)
+	}
+
+	for _, secret := range []string{"secret", "alice", "bob", "a_1234"} {
+		if strings.Contains(b.String(), secret) {
+			t.Fatalf("%q wasn't scrubbed: %s", secret, b.String())
+		}
+	}
+
+	ops, err := Load(&b)
+	if err != nil {
+		t.Fatal("Failed to load:", err)
+	}
+
+	h := handler.New()
+	h.Synchronous = true
+
+	var ready *gateway.ReadyEvent
+	var msg *gateway.MessageCreateEvent
+
+	h.AddHandler(func(ev *gateway.ReadyEvent) { ready = ev })
+	h.AddHandler(func(ev *gateway.MessageCreateEvent) { msg = ev })
+
+	if err := Replay(h, ops); err != nil {
+		t.Fatal("Failed to replay:", err)
+	}
+
+	if ready == nil || msg == nil {
+		t.Fatal("Events weren't replayed:", ready, msg)
+	}
+
+	// The same user keeps the same name.
+	if ready.User.Username != "user1" || msg.Author.Username != "user1" {
+		t.Fatal("Unexpected usernames:",
+			ready.User.Username, msg.Author.Username)
+	}
+
+	if msg.ID != 2 || !strings.HasPrefix(msg.Author.Avatar, "a_") {
+		t.Fatalf("Unexpected message: %+v", msg)
+	}
+}
@@ -0,0 +1,134 @@
+package gateway
+
+import (
+	"bytes"
+	"regexp"
+	"strconv"
+	"sync/atomic"
+
+	"github.com/diamondburned/arikawa/gateway/etf"
+	"github.com/diamondburned/arikawa/logger"
+)
+
+// FrameLogSize is the default maximum size of a frame logged by FrameLog.
+var FrameLogSize = 2048
+
+// redactedKeys matches the values of the JSON keys that are never logged.
+var redactedKeys = regexp.MustCompile(
+	`("(?:token|password)"\s*:\s*)"(?:[^"\\]|\\.)*"`)
+
+// FrameLog logs every frame sent and received by a Gateway at the debug
+// level, for diagnosing problems such as the order of events:
+//
+//	g.FrameLog = &gateway.FrameLog{
+//		Logger: logger.NewStd(nil, logger.DebugLevel),
+//		Sample: 10,
+//	}
+//
+// Tokens are redacted from the frames, so the logs can be shared.
+type FrameLog struct {
+	Logger logger.Logger
+
+	// MaxSize is the maximum size of a logged frame. Longer frames are cut
+	// off. It defaults to FrameLogSize if 0.
+	MaxSize int
+
+	// Sample logs 1 of every Sample dispatch events. Other frames are
+	// always logged. 0 logs every event.
+	Sample uint64
+	// Events, if not empty, are the names of the only dispatch events
+	// logged, such as "MESSAGE_CREATE".
+	Events []string
+
+	counter uint64 // atomic
+}
+
+// logFrame logs the frame to FrameLog, if there's one.
+func (g *Gateway) logFrame(sent bool, frame []byte) {
+	var l = g.FrameLog
+	if l == nil {
+		return
+	}
+
+	// Invalid frames are still logged, as they're worth seeing.
+	var op OP
+	g.Driver.Unmarshal(frame, &op)
+
+	if l.skip(op) {
+		return
+	}
+
+	var direction = "received"
+	if sent {
+		direction = "sent"
+	}
+
+	var fields = []logger.Field{
+		logger.F("direction", direction),
+		logger.F("shard", g.Status().ShardID),
+		logger.F("op", int(op.Code)),
+		logger.F("size", len(frame)),
+	}
+
+	if op.Code == DispatchOP {
+		fields = append(fields,
+			logger.F("t", op.EventName), logger.F("s", op.Sequence))
+	}
+
+	// Binary frames are logged as JSON, so they can be read and redacted.
+	if len(frame) > 0 && frame[0] == etf.Version {
+		if j, err := etf.JSON(frame); err == nil {
+			frame = j
+		}
+	}
+
+	l.Logger.Debug(l.format(g.Identifier.Token, frame), fields...)
+}
+
+// skip returns true if the frame isn't sampled or isn't one of Events.
+func (l *FrameLog) skip(op OP) bool {
+	if op.Code != DispatchOP {
+		return false
+	}
+
+	if len(l.Events) > 0 {
+		var found bool
+		for _, name := range l.Events {
+			if name == op.EventName {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return true
+		}
+	}
+
+	if l.Sample > 1 {
+		return atomic.AddUint64(&l.counter, 1)%l.Sample != 1
+	}
+
+	return false
+}
+
+// format redacts and truncates the frame.
+func (l *FrameLog) format(token string, frame []byte) string {
+	frame = redactedKeys.ReplaceAll(frame, []byte(`$1"[REDACTED]"`))
+
+	// The token could show up outside of a token key.
+	if token != "" {
+		frame = bytes.Replace(frame, []byte(token), []byte("[REDACTED]"), -1)
+	}
+
+	var max = l.MaxSize
+	if max == 0 {
+		max = FrameLogSize
+	}
+
+	if len(frame) > max {
+		return string(frame[:max]) + "... (" +
+			strconv.Itoa(len(frame)-max) + " more bytes)"
+	}
+
+	return string(frame)
+}
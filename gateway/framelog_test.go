@@ -0,0 +1,93 @@
+// +build unit
+
+package gateway
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/diamondburned/arikawa/internal/json"
+	"github.com/diamondburned/arikawa/logger"
+)
+
+func newFrameLogGateway(l *FrameLog, logged *[]string) *Gateway {
+	l.Logger = logger.Func(
+		func(lvl logger.Level, msg string, fields ...logger.Field) {
+			*logged = append(*logged, msg)
+		},
+	)
+
+	return &Gateway{
+		Driver:     json.Default{},
+		Identifier: DefaultIdentifier("Bot secret"),
+		FrameLog:   l,
+	}
+}
+
+func TestFrameLogRedact(t *testing.T) {
+	var logged []string
+	g := newFrameLogGateway(&FrameLog{}, &logged)
+
+	d, err := g.Marshal(g.Identifier)
+	if err != nil {
+		t.Fatal("Failed to marshal:", err)
+	}
+	b, err := g.Marshal(OP{Code: IdentifyOP, Data: d})
+	if err != nil {
+		t.Fatal("Failed to marshal:", err)
+	}
+
+	g.logFrame(true, b)
+	g.logFrame(false, []byte(`{"op":0,"d":{"content":"Bot secret"}}`))
+
+	if len(logged) != 2 {
+		t.Fatal("Unexpected number of frames logged:", len(logged))
+	}
+
+	for _, frame := range logged {
+		if strings.Contains(frame, "secret") {
+			t.Fatal("Token wasn't redacted:", frame)
+		}
+	}
+
+	if !strings.Contains(logged[0], `"token":"[REDACTED]"`) {
+		t.Fatal("Token key wasn't redacted:", logged[0])
+	}
+}
+
+func TestFrameLogTruncate(t *testing.T) {
+	var logged []string
+	g := newFrameLogGateway(&FrameLog{MaxSize: 8}, &logged)
+
+	g.logFrame(false, []byte(`{"op":11,"d":null}`))
+
+	if expect := `{"op":11... (10 more bytes)`; logged[0] != expect {
+		t.Fatalf("Unexpected frame %q, expected %q", logged[0], expect)
+	}
+}
+
+func TestFrameLogSample(t *testing.T) {
+	var logged []string
+	g := newFrameLogGateway(&FrameLog{
+		Sample: 2,
+		Events: []string{"MESSAGE_CREATE"},
+	}, &logged)
+
+	var frames = []string{
+		`{"op":0,"t":"MESSAGE_CREATE","s":1}`,
+		`{"op":0,"t":"MESSAGE_CREATE","s":2}`,
+		`{"op":0,"t":"TYPING_START","s":3}`,
+		`{"op":11}`,
+		`{"op":0,"t":"MESSAGE_CREATE","s":4}`,
+	}
+
+	for _, frame := range frames {
+		g.logFrame(false, []byte(frame))
+	}
+
+	var expect = []string{frames[0], frames[3], frames[4]}
+
+	if strings.Join(logged, "\n") != strings.Join(expect, "\n") {
+		t.Fatal("Unexpected frames logged:", logged)
+	}
+}
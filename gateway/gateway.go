@@ -10,14 +10,19 @@ package gateway
 import (
 	"context"
 	"log"
+	"net/http"
 	"net/url"
 	"runtime"
+	"sync"
 	"time"
 
 	"github.com/diamondburned/arikawa/api"
+	"github.com/diamondburned/arikawa/discord"
 	"github.com/diamondburned/arikawa/internal/httputil"
 	"github.com/diamondburned/arikawa/internal/json"
 	"github.com/diamondburned/arikawa/internal/wsutil"
+	"github.com/diamondburned/arikawa/logger"
+	"github.com/diamondburned/arikawa/metrics"
 	"github.com/pkg/errors"
 )
 
@@ -34,10 +39,11 @@ var (
 	// before Gateway cancels and fails.
 	WSTimeout = wsutil.DefaultTimeout
 	// WSBuffer is the size of the Event channel. This has to be at least 1 to
-	// make space for the first Event: Ready or Resumed.
+	// make space for the first Event: Ready or Resumed, or 2 with RawEvents.
 	WSBuffer = 10
 	// WSRetries is the times Gateway would try and connect or reconnect to the
-	// gateway.
+	// gateway. It's read when a Gateway is created, and used by the Gateways
+	// whose ReconnectPolicy has a MaxRetries of 0.
 	WSRetries = uint(5)
 	// WSError is the default error handler
 	WSError = func(err error) {}
@@ -47,12 +53,35 @@ var (
 	// WSExtraReadTimeout is the duration to be added to Hello, as a read
 	// timeout for the websocket.
 	WSExtraReadTimeout = time.Second
+	// WSReadLimit is the maximum size of a decompressed message, or 0 for no
+	// limit. Larger messages, such as the GuildCreate of a huge guild, are
+	// skipped and given to ErrorLog, instead of closing the connection.
+	WSReadLimit = wsutil.WSReadLimit
+	// WSCompress enables zlib-stream transport compression, which greatly
+	// reduces the bandwidth used by large bots. It shouldn't be used with the
+	// Compress field of IdentifyData, and it's ignored with encodings other
+	// than JSON.
+	WSCompress = false
+	// WSDialOptions are the options used to dial the Gateway, such as the TLS
+	// config or the TCP keep-alive period. Voice gateways use them too.
+	WSDialOptions DialOptions
+	// WSRawEvents is the default RawEvents of new Gateways, including shards.
+	WSRawEvents = false
 )
 
+// DialOptions are the options for dialing the websocket. See WSDialOptions.
+type DialOptions = wsutil.DialOptions
+
 var (
 	ErrMissingForResume = errors.New(
 		"missing session ID or sequence for resuming")
 	ErrWSMaxTries = errors.New("max tries reached")
+
+	// errReconnect closes the Websocket with an error, which doesn't
+	// invalidate the session like a normal closure does.
+	errReconnect = errors.New("Reconnecting")
+	// errDetach closes the Websocket for CloseResumable.
+	errDetach = errors.New("Closing to resume later")
 )
 
 func GatewayURL() (string, error) {
@@ -64,6 +93,35 @@ func GatewayURL() (string, error) {
 		&Gateway, "GET", EndpointGateway)
 }
 
+// BotData is the data returned from the Bot Gateway endpoint. It contains the
+// recommended number of shards.
+type BotData struct {
+	URL        string             `json:"url"`
+	Shards     int                `json:"shards"`
+	StartLimit *SessionStartLimit `json:"session_start_limit"`
+}
+
+// SessionStartLimit is the remaining number of identifies allowed, which
+// resets after ResetAfter.
+type SessionStartLimit struct {
+	Total      int                  `json:"total"`
+	Remaining  int                  `json:"remaining"`
+	ResetAfter discord.Milliseconds `json:"reset_after"`
+}
+
+// BotURL fetches the Gateway URL along with the recommended number of shards.
+// The token must be a bot token, including the "Bot " prefix.
+func BotURL(token string) (*BotData, error) {
+	var headers = http.Header{}
+	headers.Set("Authorization", token)
+
+	var d *BotData
+	return d, httputil.DefaultClient.RequestJSON(
+		&d, "GET", EndpointGatewayBot,
+		httputil.WithHeaders(headers),
+	)
+}
+
 // Identity is used as the default identity when initializing a new Gateway.
 var Identity = IdentifyProperties{
 	OS:      runtime.GOOS,
@@ -78,8 +136,14 @@ type Gateway struct {
 	// Timeout for connecting and writing to the Websocket, uses default
 	// WSTimeout (global).
 	WSTimeout time.Duration
-	// Retries on connect and reconnect.
-	WSRetries uint // 3
+	// ReconnectPolicy controls the retries on connect and reconnect. It
+	// defaults to DefaultReconnectPolicy.
+	ReconnectPolicy ReconnectPolicy
+	// Retries on connect and reconnect. If not 0, it overrides the
+	// MaxRetries of the ReconnectPolicy.
+	//
+	// Deprecated: Use ReconnectPolicy.MaxRetries instead.
+	WSRetries uint
 
 	// All events sent over are pointers to Event structs (structs suffixed with
 	// "Event"). This shouldn't be accessed if the Gateway is created with a
@@ -95,19 +159,35 @@ type Gateway struct {
 	ErrorLog func(err error) // default to log.Println
 	FatalLog func(err error) // called when the WS can't reconnect and resume
 
+	// Metrics, if not nil, counts the dispatched events and reconnects.
+	Metrics metrics.Recorder
+
+	// RawEvents, if true, sends a RawEvent after every dispatch event, so
+	// that fields the event types don't have yet can still be read.
+	RawEvents bool
+
 	// Only use for debugging
 
 	// If this channel is non-nil, all incoming OP packets will also be sent
 	// here. This should be buffered, so to not block the main loop.
 	OP chan Event
 
+	// FrameLog, if not nil, logs all frames sent and received.
+	FrameLog *FrameLog
+
 	// Filled by methods, internal use
 	done      chan struct{}
 	paceDeath chan error
+	status    gatewayStatus
+
+	// presence guards the Presence of the Identifier, which is read when the
+	// Gateway identifies again.
+	presence sync.Mutex
 }
 
-// NewGateway starts a new Gateway with the default stdlib JSON driver. For more
-// information, refer to NewGatewayWithDriver.
+// NewGateway starts a new Gateway with the default JSON driver, which is set
+// with the utils/json package. For more information, refer to
+// NewGatewayWithDriver.
 func NewGateway(token string) (*Gateway, error) {
 	return NewGatewayWithDriver(token, json.Default{})
 }
@@ -119,21 +199,40 @@ func NewGatewayWithDriver(token string, driver json.Driver) (*Gateway, error) {
 		return nil, errors.Wrap(err, "Failed to get gateway endpoint")
 	}
 
+	return NewCustomGateway(URL, token, driver)
+}
+
+// NewCustomGateway creates a new Gateway with the given Gateway URL, without
+// fetching it. The URL must not have any parameters.
+func NewCustomGateway(
+	URL, token string, driver json.Driver) (*Gateway, error) {
+
+	var encoding = DriverEncoding(driver)
+
+	var policy = DefaultReconnectPolicy
+	if policy.MaxRetries == 0 {
+		policy.MaxRetries = WSRetries
+	}
+
 	g := &Gateway{
-		Driver:     driver,
-		WSTimeout:  WSTimeout,
-		WSRetries:  WSRetries,
-		Events:     make(chan Event, WSBuffer),
-		Identifier: DefaultIdentifier(token),
-		Sequence:   NewSequence(),
-		ErrorLog:   WSError,
-		FatalLog:   WSFatal,
+		Driver:          driver,
+		WSTimeout:       WSTimeout,
+		ReconnectPolicy: policy,
+		Events:          make(chan Event, WSBuffer),
+		Identifier:      DefaultIdentifier(token),
+		Sequence:        NewSequence(),
+		ErrorLog:        WSError,
+		FatalLog:        WSFatal,
+		RawEvents:       WSRawEvents,
 	}
 
 	// Parameters for the gateway
 	param := url.Values{}
 	param.Set("v", Version)
-	param.Set("encoding", Encoding)
+	param.Set("encoding", encoding)
+	if WSCompress && encoding == Encoding {
+		param.Set("compress", "zlib-stream")
+	}
 	// Append the form to the URL
 	URL += "?" + param.Encode()
 
@@ -141,7 +240,11 @@ func NewGatewayWithDriver(token string, driver json.Driver) (*Gateway, error) {
 	defer cancel()
 
 	// Create a new undialed Websocket.
-	ws, err := wsutil.NewCustom(ctx, wsutil.NewConn(driver), URL)
+	conn := wsutil.NewConn(driver)
+	conn.ReadLimit = WSReadLimit
+	conn.DialOptions = WSDialOptions
+
+	ws, err := wsutil.NewCustom(ctx, conn, URL)
 	if err != nil {
 		return nil, errors.Wrap(err, "Failed to connect to Gateway "+URL)
 	}
@@ -151,8 +254,101 @@ func NewGatewayWithDriver(token string, driver json.Driver) (*Gateway, error) {
 	return g, nil
 }
 
-// Close closes the underlying Websocket connection.
+// EncodingDriver is a driver for an encoding other than JSON, such as
+// etf.Driver.
+type EncodingDriver interface {
+	json.Driver
+	// Encoding returns the name of the encoding, which is given to Discord.
+	Encoding() string
+}
+
+// DriverEncoding returns the encoding of the driver, which is JSON unless the
+// driver is an EncodingDriver.
+func DriverEncoding(driver json.Driver) string {
+	if d, ok := driver.(EncodingDriver); ok {
+		return d.Encoding()
+	}
+	return Encoding
+}
+
+// SetLogger makes ErrorLog and FatalLog log to l, with the shard ID as a
+// field. FatalLog no longer exits the program; errors from it are logged with
+// the "fatal" field set to true.
+func (g *Gateway) SetLogger(l logger.Logger) {
+	var shard = logger.F("shard", g.Status().ShardID)
+
+	g.ErrorLog = logger.ErrorFunc(l, logger.ErrorLevel,
+		logger.F("component", "gateway"), shard)
+	g.FatalLog = logger.ErrorFunc(l, logger.ErrorLevel,
+		logger.F("component", "gateway"), shard, logger.F("fatal", true))
+}
+
+// SetPresence sets the presence sent when the Gateway identifies, such as after
+// a reconnect. Unlike setting the Presence of the Identifier, it's safe to call
+// while the Gateway is connected.
+func (g *Gateway) SetPresence(data *UpdateStatusData) {
+	g.presence.Lock()
+	defer g.presence.Unlock()
+
+	g.Identifier.Presence = data
+}
+
+// identifyData returns a copy of the identify data, for the presence to not
+// change while it's read.
+func (g *Gateway) identifyData() IdentifyData {
+	g.presence.Lock()
+	defer g.presence.Unlock()
+
+	return g.Identifier.IdentifyData
+}
+
+// AddIntent adds a Gateway Intent before connecting to the Gateway. As such,
+// this function will only work before Open() is called.
+func (g *Gateway) AddIntent(i Intents) {
+	g.Identifier.AddIntent(i)
+}
+
+// Close closes the underlying Websocket connection. The session is invalidated,
+// so it can't be resumed.
 func (g *Gateway) Close() error {
+	return g.close(nil)
+}
+
+// CloseResumable closes the Websocket without invalidating the session, so
+// that it can be resumed later with ResumeState, such as by the next process
+// during a rolling deploy.
+func (g *Gateway) CloseResumable() error {
+	return g.close(errDetach)
+}
+
+// ResumeState is the data needed to resume the session of a Gateway.
+type ResumeState struct {
+	ShardID   int    `json:"shard_id"`
+	SessionID string `json:"session_id"`
+	Sequence  int64  `json:"seq"`
+}
+
+// ResumeState returns the data needed to resume the session of the Gateway.
+// The SessionID is empty if there's no session.
+func (g *Gateway) ResumeState() ResumeState {
+	return ResumeState{
+		ShardID:   g.Status().ShardID,
+		SessionID: g.SessionID,
+		Sequence:  g.Sequence.Get(),
+	}
+}
+
+// SetResumeState makes the Gateway resume the session on Open instead of
+// identifying. The events missed since the session was closed are replayed by
+// Discord. It should be called before Open.
+func (g *Gateway) SetResumeState(state ResumeState) {
+	g.SessionID = state.SessionID
+	g.Sequence.Set(state.Sequence)
+}
+
+// close closes the Websocket with the error. If it's not nil, the session can
+// still be resumed.
+func (g *Gateway) close(err error) error {
 	// If the pacemaker is running:
 	// Stop the pacemaker and the event handler
 	g.Pacemaker.Stop()
@@ -165,73 +361,128 @@ func (g *Gateway) Close() error {
 		g.done = nil
 	}
 
+	g.status.setConnected(false)
+
 	// Stop the Websocket
-	return g.WS.Close(nil)
+	return g.WS.Close(err)
 }
 
-// Reconnects and resumes.
+// Reconnect reconnects and resumes the session, with the ReconnectPolicy. The
+// events missed in between are replayed by Discord.
 func (g *Gateway) Reconnect() error {
+	if g.Metrics != nil {
+		g.Metrics.Reconnect(g.Status().ShardID)
+	}
+
 	// Close, but we don't care about the error (I think)
-	g.Close()
+	g.close(errReconnect)
 	// Actually a reconnect at this point.
+	return g.open(true)
+}
+
+// UpdateToken changes the token of the Gateway. If the Gateway is connected,
+// it's reconnected with a new session, since a session can't be resumed with
+// another token.
+func (g *Gateway) UpdateToken(token string) error {
+	if token == g.Identifier.Token {
+		return nil
+	}
+
+	if !g.Status().Connected {
+		g.Identifier.Token = token
+		return nil
+	}
+
+	// A normal closure ends the old session.
+	g.Close()
+
+	g.Identifier.Token = token
+	g.SessionID = ""
+	g.Sequence.Set(0)
+
 	return g.Open()
 }
 
+// Open connects to the Gateway and identifies, or resumes if the Gateway was
+// connected before. It's retried with the ReconnectPolicy.
 func (g *Gateway) Open() error {
-	// Reconnect timeout
-	ctx, cancel := context.WithTimeout(context.Background(), g.WSTimeout)
-	defer cancel()
+	return g.open(false)
+}
 
-	var Lerr error
+func (g *Gateway) open(reconnecting bool) error {
+	var policy = g.ReconnectPolicy
+	policy.MaxRetries = g.maxRetries()
 
-	for i := uint(0); i < g.WSRetries; i++ {
-		// Check if context is expired
-		if err := ctx.Err(); err != nil {
-			// Close the connection
-			g.Close()
+	var err error
 
-			// Don't bother if it's expired
-			return err
-		}
+	// There's a session to resume if the Gateway was connected before.
+	g.status.setResuming(g.SessionID != "")
+	defer g.status.setResuming(false)
+
+	for i := uint(1); i <= policy.MaxRetries; i++ {
+		time.Sleep(policy.delay(i))
+
+		err = g.connect()
 
-		// Reconnect to the Gateway
-		if err := g.WS.Dial(ctx); err != nil {
-			// Save the error, retry again
-			Lerr = errors.Wrap(err, "Failed to reconnect")
-			continue
+		if reconnecting && policy.OnReconnect != nil {
+			policy.OnReconnect(i, err)
 		}
 
-		// Try to resume the connection
-		if err := g.Start(); err != nil {
-			// If the connection is rate limited (documented behavior):
-			// https://discordapp.com/developers/docs/topics/gateway#rate-limiting
-			if err == ErrInvalidSession {
-				continue
-			}
+		if err == nil {
+			g.status.setConnected(true)
+			return nil
+		}
 
-			// Else, keep retrying
-			g.ErrorLog(errors.Wrap(err, "Failed to start gateway"))
-			continue
+		// The last error is returned instead.
+		if i < policy.MaxRetries {
+			g.ErrorLog(err)
 		}
+	}
 
-		// Started successfully, return
-		return nil
+	if err == nil {
+		// We tried.
+		return ErrWSMaxTries
 	}
 
-	// Check if any earlier errors are fatal
-	if Lerr != nil {
-		return Lerr
+	return err
+}
+
+// maxRetries returns the number of attempts to connect, which is the deprecated
+// WSRetries field if set, or the MaxRetries of the ReconnectPolicy, or the
+// global WSRetries if it's 0.
+func (g *Gateway) maxRetries() uint {
+	switch {
+	case g.WSRetries > 0:
+		return g.WSRetries
+	case g.ReconnectPolicy.MaxRetries > 0:
+		return g.ReconnectPolicy.MaxRetries
+	default:
+		return WSRetries
 	}
+}
 
-	// We tried.
-	return ErrWSMaxTries
+// connect dials and starts the Gateway once.
+func (g *Gateway) connect() error {
+	ctx, cancel := context.WithTimeout(context.Background(), g.WSTimeout)
+	defer cancel()
+
+	if err := g.WS.Dial(ctx); err != nil {
+		return errors.Wrap(err, "Failed to reconnect")
+	}
+
+	if err := g.Start(); err != nil {
+		return errors.Wrap(err, "Failed to start gateway")
+	}
+
+	return nil
 }
 
 // Start authenticates with the websocket, or resume from a dead Websocket
 // connection. This function doesn't block.
 func (g *Gateway) Start() error {
 	if err := g.start(); err != nil {
-		g.Close()
+		// Keep the session, so the next attempt could resume it.
+		g.close(errReconnect)
 		return err
 	}
 	return nil
@@ -243,7 +494,10 @@ func (g *Gateway) start() error {
 
 	// Wait for an OP 10 Hello
 	var hello HelloEvent
-	if _, err := AssertEvent(g, <-ch, HelloOP, &hello); err != nil {
+	var ev = <-ch
+	g.logFrame(false, ev.Data)
+
+	if _, err := AssertEvent(g, ev, HelloOP, &hello); err != nil {
 		return errors.Wrap(err, "Error at Hello")
 	}
 
@@ -258,7 +512,7 @@ func (g *Gateway) start() error {
 
 	// Send Discord either the Identify packet (if it's a fresh connection), or
 	// a Resume packet (if it's a dead connection).
-	if g.SessionID == "" {
+	if g.SessionID == "" || g.Sequence.Get() == 0 {
 		// SessionID is empty, so this is a completely new session.
 		if err := g.Identify(); err != nil {
 			return errors.Wrap(err, "Failed to identify")
@@ -270,7 +524,7 @@ func (g *Gateway) start() error {
 	}
 
 	// Expect at least one event
-	ev := <-ch
+	ev = <-ch
 
 	// Check for error
 	if ev.Error != nil {
@@ -348,6 +602,8 @@ func (g *Gateway) Send(code OPCode, v interface{}) error {
 		return errors.Wrap(err, "Failed to encode payload")
 	}
 
+	g.logFrame(true, b)
+
 	ctx, cancel := context.WithTimeout(context.Background(), g.WSTimeout)
 	defer cancel()
 
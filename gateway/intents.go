@@ -0,0 +1,40 @@
+package gateway
+
+// Intents for the new Discord API feature, documented at
+// https://discordapp.com/developers/docs/topics/gateway#gateway-intents.
+type Intents uint32
+
+const (
+	IntentGuilds Intents = 1 << iota
+	IntentGuildMembers
+	IntentGuildBans
+	IntentGuildEmojis
+	IntentGuildIntegrations
+	IntentGuildWebhooks
+	IntentGuildInvites
+	IntentGuildVoiceStates
+	IntentGuildPresences
+	IntentGuildMessages
+	IntentGuildMessageReactions
+	IntentGuildMessageTyping
+	IntentDirectMessages
+	IntentDirectMessageReactions
+	IntentDirectMessageTyping
+
+	IntentGuildScheduledEvents Intents = 1 << 16
+)
+
+// PrivilegedIntents contains the intents that have to be whitelisted in the
+// developer portal before they can be used.
+const PrivilegedIntents = IntentGuildMembers | IntentGuildPresences
+
+// Has returns true if all the given intents are set.
+func (i Intents) Has(intents Intents) bool {
+	return i&intents == intents
+}
+
+// IsPrivileged returns whether the privileged presence and member intents are
+// set, respectively.
+func (i Intents) IsPrivileged() (presences, member bool) {
+	return i.Has(IntentGuildPresences), i.Has(IntentGuildMembers)
+}
@@ -96,6 +96,8 @@ func AssertEvent(driver json.Driver,
 }
 
 func HandleEvent(g *Gateway, data []byte) error {
+	g.logFrame(false, data)
+
 	// Parse the raw data into an OP struct
 	var op *OP
 	if err := g.Driver.Unmarshal(data, &op); err != nil {
@@ -114,6 +116,7 @@ func HandleOP(g *Gateway, op *OP) error {
 	case HeartbeatAckOP:
 		// Heartbeat from the server?
 		g.Pacemaker.Echo()
+		g.status.heartbeatAck()
 
 	case HeartbeatOP:
 		// Server requesting a heartbeat.
@@ -124,10 +127,20 @@ func HandleOP(g *Gateway, op *OP) error {
 		return g.Reconnect()
 
 	case InvalidSessionOP:
+		// The data is true if the session can be resumed.
+		var resumable bool
+		g.Driver.Unmarshal(op.Data, &resumable)
+
 		// Discord expects us to sleep for no reason
 		time.Sleep(time.Duration(rand.Intn(5)+1) * time.Second)
 
-		// Invalid session, respond with Identify.
+		if resumable {
+			return g.Resume()
+		}
+
+		// Invalid session, start a new one with Identify.
+		g.SessionID = ""
+		g.Sequence.Set(0)
 		return g.Identify()
 
 	case HelloOP:
@@ -140,18 +153,26 @@ func HandleOP(g *Gateway, op *OP) error {
 			g.Sequence.Set(op.Sequence)
 		}
 
-		// Check if we know the event
-		fn, ok := EventCreator[op.EventName]
-		if !ok {
-			return errors.New("Unknown event: " + op.EventName)
+		if g.Metrics != nil {
+			g.Metrics.Event(g.Status().ShardID, op.EventName)
 		}
 
-		// Make a new pointer to the event
-		var ev = fn()
+		var ev Event
 
-		// Try and parse the event
-		if err := g.Driver.Unmarshal(op.Data, ev); err != nil {
-			return errors.Wrap(err, "Failed to parse event "+op.EventName)
+		// Check if we know the event
+		if fn, ok := EventCreator[op.EventName]; ok {
+			// Make a new pointer to the event
+			ev = fn()
+
+			// Try and parse the event
+			if err := g.Driver.Unmarshal(op.Data, ev); err != nil {
+				return errors.Wrap(err, "Failed to parse event "+op.EventName)
+			}
+		} else {
+			ev = &UnknownEvent{
+				Name: op.EventName,
+				Data: op.Data,
+			}
 		}
 
 		// If the event is a ready, we'll want its sessionID
@@ -159,8 +180,19 @@ func HandleOP(g *Gateway, op *OP) error {
 			g.SessionID = ev.SessionID
 		}
 
+		g.status.event(ev)
+
 		// Throw the event into a channel, it's valid now.
 		g.Events <- ev
+
+		if g.RawEvents {
+			g.Events <- &RawEvent{
+				Name:  op.EventName,
+				Data:  op.Data,
+				Event: ev,
+			}
+		}
+
 		return nil
 
 	default:
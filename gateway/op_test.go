@@ -0,0 +1,87 @@
+// +build unit
+
+package gateway
+
+import (
+	"testing"
+
+	"github.com/diamondburned/arikawa/internal/json"
+)
+
+func TestHandleUnknownEvent(t *testing.T) {
+	g := &Gateway{
+		Driver:   json.Default{},
+		Events:   make(chan Event, 1),
+		Sequence: NewSequence(),
+	}
+
+	err := HandleOP(g, &OP{
+		Code:      DispatchOP,
+		Sequence:  2,
+		EventName: "SOMETHING_NEW",
+		Data:      json.Raw(`{"id":"1"}`),
+	})
+	if err != nil {
+		t.Fatal("Failed to handle unknown event:", err)
+	}
+
+	ev, ok := (<-g.Events).(*UnknownEvent)
+	if !ok {
+		t.Fatal("Event isn't an UnknownEvent")
+	}
+
+	if ev.Name != "SOMETHING_NEW" || ev.Data.String() != `{"id":"1"}` {
+		t.Fatalf("Unexpected event: %s %s", ev.Name, ev.Data)
+	}
+
+	if g.Sequence.Get() != 2 {
+		t.Fatal("Sequence wasn't set")
+	}
+}
+
+func TestHandleRawEvent(t *testing.T) {
+	g := &Gateway{
+		Driver:    json.Default{},
+		Events:    make(chan Event, 2),
+		Sequence:  NewSequence(),
+		RawEvents: true,
+	}
+
+	var data = json.Raw(`{"channel_id":"1","new_field":true}`)
+
+	err := HandleOP(g, &OP{
+		Code:      DispatchOP,
+		Sequence:  1,
+		EventName: "TYPING_START",
+		Data:      data,
+	})
+	if err != nil {
+		t.Fatal("Failed to handle event:", err)
+	}
+
+	typing, ok := (<-g.Events).(*TypingStartEvent)
+	if !ok || typing.ChannelID != 1 {
+		t.Fatal("Unexpected typed event:", typing)
+	}
+
+	raw, ok := (<-g.Events).(*RawEvent)
+	if !ok {
+		t.Fatal("Event isn't a RawEvent")
+	}
+
+	if raw.Name != "TYPING_START" || raw.Event != typing {
+		t.Fatalf("Unexpected raw event: %s %v", raw.Name, raw.Event)
+	}
+
+	var fields struct {
+		NewField bool `json:"new_field"`
+	}
+
+	if err := g.Driver.Unmarshal(raw.Data, &fields); err != nil {
+		t.Fatal("Failed to decode raw data:", err)
+	}
+
+	if !fields.NewField {
+		t.Fatal("New field wasn't in the raw data")
+	}
+}
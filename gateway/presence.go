@@ -0,0 +1,129 @@
+package gateway
+
+import (
+	"time"
+
+	"github.com/diamondburned/arikawa/discord"
+)
+
+// PresenceBuilder builds the data of a presence update, which is validated by
+// Build:
+//
+//	data, err := gateway.NewPresence().Idle().Playing("chess").Build()
+//	if err != nil {
+//		return err
+//	}
+//	s.UpdateStatus(data)
+type PresenceBuilder struct {
+	data UpdateStatusData
+}
+
+// NewPresence creates a PresenceBuilder with the online status and no
+// activity.
+func NewPresence() *PresenceBuilder {
+	return &PresenceBuilder{
+		data: UpdateStatusData{Status: discord.OnlineStatus},
+	}
+}
+
+// Online sets the status to online.
+func (b *PresenceBuilder) Online() *PresenceBuilder {
+	return b.status(discord.OnlineStatus)
+}
+
+// Idle sets the status to idle, since now.
+func (b *PresenceBuilder) Idle() *PresenceBuilder {
+	b.status(discord.IdleStatus)
+	b.data.Since = discord.Milliseconds(
+		time.Now().UnixNano() / int64(time.Millisecond))
+
+	return b
+}
+
+// DoNotDisturb sets the status to do not disturb.
+func (b *PresenceBuilder) DoNotDisturb() *PresenceBuilder {
+	return b.status(discord.DoNotDisturbStatus)
+}
+
+// Invisible sets the status to invisible, which shows the user as offline.
+func (b *PresenceBuilder) Invisible() *PresenceBuilder {
+	return b.status(discord.InvisibleStatus)
+}
+
+// AFK sets whether the user is AFK, which makes mobile notifications go to
+// the other clients of user accounts.
+func (b *PresenceBuilder) AFK(afk bool) *PresenceBuilder {
+	b.data.AFK = afk
+	return b
+}
+
+func (b *PresenceBuilder) status(status discord.Status) *PresenceBuilder {
+	b.data.Status = status
+	b.data.Since = 0
+	return b
+}
+
+// Playing sets the activity to "Playing name".
+func (b *PresenceBuilder) Playing(name string) *PresenceBuilder {
+	return b.activity(discord.Activity{Name: name, Type: discord.GameActivity})
+}
+
+// ListeningTo sets the activity to "Listening to name".
+func (b *PresenceBuilder) ListeningTo(name string) *PresenceBuilder {
+	return b.activity(discord.Activity{
+		Name: name,
+		Type: discord.ListeningActivity,
+	})
+}
+
+// Watching sets the activity to "Watching name".
+func (b *PresenceBuilder) Watching(name string) *PresenceBuilder {
+	return b.activity(discord.Activity{
+		Name: name,
+		Type: discord.WatchingActivity,
+	})
+}
+
+// CompetingIn sets the activity to "Competing in name".
+func (b *PresenceBuilder) CompetingIn(name string) *PresenceBuilder {
+	return b.activity(discord.Activity{
+		Name: name,
+		Type: discord.CompetingActivity,
+	})
+}
+
+// Streaming sets the activity to "Streaming name", linking to the stream,
+// which must be on Twitch or YouTube.
+func (b *PresenceBuilder) Streaming(name, url string) *PresenceBuilder {
+	return b.activity(discord.Activity{
+		Name: name,
+		Type: discord.StreamingActivity,
+		URL:  url,
+	})
+}
+
+// Custom sets the activity to a custom status with only the text, as bots
+// can't set the emoji.
+func (b *PresenceBuilder) Custom(text string) *PresenceBuilder {
+	return b.activity(discord.Activity{
+		Name:  "Custom Status",
+		Type:  discord.CustomActivity,
+		State: text,
+	})
+}
+
+// NoActivity removes the activity.
+func (b *PresenceBuilder) NoActivity() *PresenceBuilder {
+	b.data.Game = nil
+	return b
+}
+
+func (b *PresenceBuilder) activity(a discord.Activity) *PresenceBuilder {
+	b.data.Game = &a
+	return b
+}
+
+// Build returns the data, or an error if it's invalid.
+func (b *PresenceBuilder) Build() (UpdateStatusData, error) {
+	return b.data, b.data.Validate()
+}
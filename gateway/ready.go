@@ -13,12 +13,32 @@ type ReadyEvent struct {
 
 	Shard *Shard `json:"shard"`
 
+	// Application is only sent to bots.
+	Application *ReadyApplication `json:"application,omitempty"`
+
 	// Undocumented fields
 	Settings          *UserSettings                `json:"user_settings"`
 	UserGuildSettings []UserGuildSettings          `json:"user_guild_settings"`
 	Relationships     []Relationship               `json:"relationships"`
 	Presences         []discord.Presence           `json:"presences,omitempty"`
 	Notes             map[discord.Snowflake]string `json:"notes,omitempty"`
+	// ReadState is only sent to user accounts.
+	ReadState []ReadState `json:"read_state,omitempty"`
+}
+
+// ReadyApplication is the partial application of a bot, whose ID is used for
+// its application commands.
+type ReadyApplication struct {
+	ID    discord.Snowflake `json:"id,string"`
+	Flags uint64            `json:"flags"`
+}
+
+// ReadState is the last message read in a channel, and the number of unread
+// mentions since then.
+type ReadState struct {
+	ChannelID     discord.Snowflake `json:"id"`
+	LastMessageID discord.Snowflake `json:"last_message_id"`
+	MentionCount  int               `json:"mention_count"`
 }
 
 type UserSettings struct {
@@ -0,0 +1,56 @@
+package gateway
+
+import (
+	"math/rand"
+	"time"
+)
+
+// ReconnectPolicy controls how a Gateway retries connecting.
+type ReconnectPolicy struct {
+	// MaxRetries is the number of attempts before giving up. 0 uses
+	// WSRetries.
+	MaxRetries uint
+
+	// Backoff is the delay before the second attempt. It's doubled after
+	// each attempt, up to MaxBackoff.
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+	// Jitter is the fraction of the delay, from 0 to 1, that's randomly taken
+	// off, so that shards don't all reconnect at once.
+	Jitter float64
+
+	// OnReconnect, if not nil, is called after every attempt to reconnect,
+	// with the attempt number starting from 1, and the error of the attempt
+	// or nil if it succeeded. It's not called for the first Open.
+	OnReconnect func(attempt uint, err error)
+}
+
+// DefaultReconnectPolicy is the ReconnectPolicy of new Gateways. Its
+// MaxRetries is 0, so that WSRetries is read when the Gateway is created.
+var DefaultReconnectPolicy = ReconnectPolicy{
+	Backoff:    time.Second,
+	MaxBackoff: time.Minute,
+	Jitter:     0.5,
+}
+
+// delay returns how long to wait before the attempt, which starts from 1.
+func (p ReconnectPolicy) delay(attempt uint) time.Duration {
+	if attempt < 2 {
+		return 0
+	}
+
+	var d = p.Backoff
+	for i := uint(2); i < attempt && d < p.MaxBackoff; i++ {
+		d *= 2
+	}
+
+	if d > p.MaxBackoff {
+		d = p.MaxBackoff
+	}
+
+	if p.Jitter > 0 {
+		d -= time.Duration(rand.Float64() * p.Jitter * float64(d))
+	}
+
+	return d
+}
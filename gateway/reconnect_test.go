@@ -0,0 +1,52 @@
+// +build unit
+
+package gateway
+
+import (
+	"testing"
+	"time"
+)
+
+func TestReconnectPolicyDelay(t *testing.T) {
+	var p = ReconnectPolicy{
+		Backoff:    time.Second,
+		MaxBackoff: 5 * time.Second,
+	}
+
+	var expect = []time.Duration{
+		0, time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second,
+	}
+
+	for i, d := range expect {
+		if delay := p.delay(uint(i + 1)); delay != d {
+			t.Errorf("Attempt %d waits %v, expected %v", i+1, delay, d)
+		}
+	}
+
+	p.Jitter = 0.5
+
+	for i := 0; i < 100; i++ {
+		if d := p.delay(2); d <= 500*time.Millisecond || d > time.Second {
+			t.Fatal("Jittered delay out of range:", d)
+		}
+	}
+}
+
+func TestMaxRetries(t *testing.T) {
+	var g Gateway
+
+	// A zero ReconnectPolicy still tries to connect.
+	if n := g.maxRetries(); n != WSRetries {
+		t.Fatal("Unexpected retries for a zero policy:", n)
+	}
+
+	g.ReconnectPolicy.MaxRetries = 2
+	if n := g.maxRetries(); n != 2 {
+		t.Fatal("Unexpected retries for the policy:", n)
+	}
+
+	g.WSRetries = 3
+	if n := g.maxRetries(); n != 3 {
+		t.Fatal("Unexpected retries for the deprecated field:", n)
+	}
+}
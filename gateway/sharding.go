@@ -0,0 +1,539 @@
+package gateway
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/diamondburned/arikawa/discord"
+	"github.com/diamondburned/arikawa/internal/json"
+	"github.com/diamondburned/arikawa/logger"
+	"github.com/diamondburned/arikawa/metrics"
+	"github.com/pkg/errors"
+	"golang.org/x/time/rate"
+)
+
+// ShardManager spawns and supervises multiple Gateway shards. All shards share
+// the same identify rate limiters, so only one shard can identify every 5
+// seconds, and all events are sent into a single Events channel.
+type ShardManager struct {
+	// Shards contains all the shards, with the shard ID as the index. It's
+	// replaced by Rescale, so the methods should be used instead while the
+	// shards could be rescaled.
+	Shards []*Gateway
+
+	// Events is the channel that all shards send their events to.
+	Events chan Event
+
+	// ErrorLog is called on any error from any of the shards.
+	ErrorLog func(err error)
+	// FatalLog is called when a shard can't recover, even after trying to
+	// reconnect it once.
+	FatalLog func(err error)
+
+	// The limiters shared by all shards.
+	IdentifyShortLimit  *rate.Limiter
+	IdentifyGlobalLimit *rate.Limiter
+
+	// Used to create shards on Rescale.
+	url    string
+	token  string
+	driver json.Driver
+
+	// metrics is given to new shards. See SetMetrics.
+	metrics metrics.Recorder
+
+	// mutex guards Shards and metrics. rescale is held during a Rescale.
+	mutex   sync.RWMutex
+	rescale sync.Mutex
+	// stopForward stops forwarding the events of the current shards, if
+	// they're forwarded, and drainForward stops once the events left are
+	// forwarded. See Rescale.
+	stopForward  func()
+	drainForward func()
+}
+
+// NewShardManager creates a new ShardManager with the number of shards
+// recommended by Discord. The token must be a bot token, including the "Bot "
+// prefix.
+func NewShardManager(token string) (*ShardManager, error) {
+	return NewShardManagerWithDriver(token, 0, json.Default{})
+}
+
+// NewShardManagerWithDriver creates a new ShardManager. If numShards is 0, the
+// recommended number of shards will be used.
+func NewShardManagerWithDriver(
+	token string, numShards int, driver json.Driver) (*ShardManager, error) {
+
+	d, err := BotURL(token)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to get bot gateway endpoint")
+	}
+
+	if numShards < 1 {
+		numShards = d.Shards
+	}
+	if numShards < 1 {
+		numShards = 1
+	}
+
+	m := &ShardManager{
+		Shards:   make([]*Gateway, numShards),
+		Events:   make(chan Event, WSBuffer*numShards),
+		ErrorLog: WSError,
+		FatalLog: WSFatal,
+
+		IdentifyShortLimit:  rate.NewLimiter(rate.Every(5*time.Second), 1),
+		IdentifyGlobalLimit: newGlobalLimit(d.StartLimit),
+
+		url:    d.URL,
+		token:  token,
+		driver: driver,
+	}
+
+	if err := m.createShards(m.Shards, m.Events, nil); err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
+
+// newGlobalLimit creates the limiter of the identifies of all shards, from the
+// session start limit if it's not nil.
+func newGlobalLimit(limit *SessionStartLimit) *rate.Limiter {
+	if limit == nil {
+		return rate.NewLimiter(rate.Every(24*time.Hour), 1000)
+	}
+
+	// A burst of 0 would make every Wait fail instead of waiting.
+	var burst = limit.Remaining
+	if burst < 1 {
+		burst = 1
+	}
+
+	l := rate.NewLimiter(rate.Every(24*time.Hour), burst)
+
+	if limit.Remaining < 1 {
+		// There are no identifies left until the limit resets, so the only
+		// token is taken as if it was a day before the reset, which makes
+		// the next one available after ResetAfter.
+		var reset = limit.ResetAfter.Duration()
+		l.AllowN(time.Now().Add(reset-24*time.Hour), 1)
+	}
+
+	return l
+}
+
+// createShards fills shards with new shards, which all send their events into
+// the same channel. If from isn't nil, the new shards get its identify data and
+// settings.
+func (m *ShardManager) createShards(
+	shards []*Gateway, events chan Event, from *Gateway) error {
+
+	for id := range shards {
+		g, err := NewCustomGateway(m.url, m.token, m.driver)
+		if err != nil {
+			return errors.Wrapf(err, "Failed to create shard %d", id)
+		}
+
+		if from != nil {
+			inheritShard(g, from)
+		}
+
+		m.setupShard(g, id, len(shards))
+		g.Events = events
+		shards[id] = g
+	}
+
+	return nil
+}
+
+// inheritShard copies the identify data, such as the intents and the presence,
+// and the settings of from into g, so that they're kept after a Rescale.
+func inheritShard(g, from *Gateway) {
+	var data = from.identifyData()
+	data.Shard = nil
+
+	// Each shard gets its own copy.
+	if data.Presence != nil {
+		var presence = *data.Presence
+		data.Presence = &presence
+	}
+
+	g.Identifier.IdentifyData = data
+
+	g.WSTimeout = from.WSTimeout
+	g.ReconnectPolicy = from.ReconnectPolicy
+	g.WSRetries = from.WSRetries
+	g.RawEvents = from.RawEvents
+	g.FrameLog = from.FrameLog
+}
+
+func (m *ShardManager) setupShard(g *Gateway, id, numShards int) {
+	g.Identifier.SetShard(id, numShards)
+	g.Identifier.IdentifyShortLimit = m.IdentifyShortLimit
+	g.Identifier.IdentifyGlobalLimit = m.IdentifyGlobalLimit
+
+	m.mutex.RLock()
+	g.Metrics = m.metrics
+	m.mutex.RUnlock()
+
+	g.ErrorLog = func(err error) {
+		m.ErrorLog(errors.Wrapf(err, "Shard %d", id))
+	}
+
+	g.FatalLog = func(err error) {
+		m.ErrorLog(errors.Wrapf(err, "Shard %d died, reconnecting", id))
+
+		// The event loop exits right after FatalLog, so the reconnect has
+		// to happen in the background.
+		go func() {
+			if err := g.Reconnect(); err != nil {
+				m.FatalLog(errors.Wrapf(err, "Shard %d failed to reconnect", id))
+			}
+		}()
+	}
+}
+
+// SetLogger makes ErrorLog and FatalLog log to l. The errors already mention
+// the shard they come from.
+func (m *ShardManager) SetLogger(l logger.Logger) {
+	m.ErrorLog = logger.ErrorFunc(l, logger.ErrorLevel,
+		logger.F("component", "gateway"))
+	m.FatalLog = logger.ErrorFunc(l, logger.ErrorLevel,
+		logger.F("component", "gateway"), logger.F("fatal", true))
+}
+
+// SetMetrics sets the Metrics of all shards, including the ones created by
+// Rescale. It should be called before Open.
+func (m *ShardManager) SetMetrics(r metrics.Recorder) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.metrics = r
+
+	for _, g := range m.Shards {
+		g.Metrics = r
+	}
+}
+
+// UpdateToken changes the token of all shards, including the ones created by
+// Rescale, and reconnects the connected ones with a new session.
+func (m *ShardManager) UpdateToken(token string) error {
+	m.rescale.Lock()
+	defer m.rescale.Unlock()
+
+	m.token = token
+
+	return m.ForEach(func(_ int, g *Gateway) error {
+		return g.UpdateToken(token)
+	})
+}
+
+// Open opens all shards, one after another. The identify rate limit is
+// respected, so this could take a while for large bots.
+func (m *ShardManager) Open() error {
+	return openShards(m.shards())
+}
+
+func openShards(shards []*Gateway) error {
+	for id, g := range shards {
+		if err := g.Open(); err != nil {
+			return errors.Wrapf(err, "Failed to open shard %d", id)
+		}
+	}
+
+	return nil
+}
+
+// Close closes all shards. The first error is returned, but all shards are
+// closed regardless.
+func (m *ShardManager) Close() error {
+	m.rescale.Lock()
+	defer m.rescale.Unlock()
+
+	err := closeShards(m.shards())
+
+	if m.stopForward != nil {
+		m.stopForward()
+		m.stopForward = nil
+		m.drainForward = nil
+	}
+
+	return err
+}
+
+// CloseResumable closes all shards at once without invalidating their
+// sessions, so that they can be resumed later with ResumeState.
+func (m *ShardManager) CloseResumable() error {
+	m.rescale.Lock()
+	defer m.rescale.Unlock()
+
+	var shards = m.shards()
+	var errs = make([]error, len(shards))
+
+	var wg sync.WaitGroup
+	wg.Add(len(shards))
+
+	for id, g := range shards {
+		go func(id int, g *Gateway) {
+			defer wg.Done()
+
+			if err := g.CloseResumable(); err != nil {
+				errs[id] = errors.Wrapf(err, "Failed to close shard %d", id)
+			}
+		}(id, g)
+	}
+
+	wg.Wait()
+
+	if m.stopForward != nil {
+		m.stopForward()
+		m.stopForward = nil
+		m.drainForward = nil
+	}
+
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ResumeState returns the data needed to resume the session of every shard, in
+// order of the shard ID.
+func (m *ShardManager) ResumeState() []ResumeState {
+	var shards = m.shards()
+	var data = make([]ResumeState, len(shards))
+
+	for id, g := range shards {
+		data[id] = g.ResumeState()
+	}
+
+	return data
+}
+
+// SetResumeState makes the shards resume the sessions on Open instead of
+// identifying. The number of shards must be the same as when the data was
+// taken, and data for shards that don't exist is ignored. It should be called
+// before Open.
+func (m *ShardManager) SetResumeState(data []ResumeState) {
+	for _, d := range data {
+		if g := m.Shard(d.ShardID); g != nil {
+			g.SetResumeState(d)
+		}
+	}
+}
+
+func closeShards(shards []*Gateway) error {
+	var firstErr error
+
+	for id, g := range shards {
+		if err := g.Close(); err != nil && firstErr == nil {
+			firstErr = errors.Wrapf(err, "Failed to close shard %d", id)
+		}
+	}
+
+	return firstErr
+}
+
+// Rescale changes the number of shards without a full restart. If numShards is
+// 0, the number of shards recommended by Discord is used.
+//
+// The new shards are opened while the old ones keep running, and they keep the
+// identify data and the settings of the old shards. Until all of them are
+// ready, only the guilds from the new shards are sent into Events, so the Store
+// gets the guilds without getting every other event twice. Once the new shards
+// are ready, the old shards are closed, and the events they've already received
+// are still sent. Events received by both the old and the new shards in the
+// meantime are sent twice.
+//
+// If any of the new shards fails to open, they're all closed, and the old
+// shards are kept.
+func (m *ShardManager) Rescale(numShards int) error {
+	m.rescale.Lock()
+	defer m.rescale.Unlock()
+
+	if numShards < 1 {
+		d, err := BotURL(m.token)
+		if err != nil {
+			return errors.Wrap(err, "Failed to get the recommended shards")
+		}
+
+		numShards = d.Shards
+	}
+	if numShards < 1 {
+		numShards = 1
+	}
+
+	var shards = make([]*Gateway, numShards)
+	var events = make(chan Event, WSBuffer*numShards)
+
+	var from *Gateway
+	if old := m.shards(); len(old) > 0 {
+		from = old[0]
+	}
+
+	if err := m.createShards(shards, events, from); err != nil {
+		return err
+	}
+
+	var warming = int32(1)
+	var drain = make(chan struct{})
+	var stop = make(chan struct{})
+	go m.forward(events, &warming, drain, stop)
+
+	if err := openShards(shards); err != nil {
+		closeShards(shards)
+		close(stop)
+		return errors.Wrap(err, "Failed to open new shards")
+	}
+
+	// Events are sent twice until the old shards are closed, which is better
+	// than losing them.
+	atomic.StoreInt32(&warming, 0)
+
+	m.mutex.Lock()
+	old := m.Shards
+	m.Shards = shards
+	m.mutex.Unlock()
+
+	if err := closeShards(old); err != nil {
+		m.ErrorLog(errors.Wrap(err, "Failed to close old shards"))
+	}
+
+	// The old shards are closed, so their events left are the last ones.
+	if m.drainForward != nil {
+		m.drainForward()
+	}
+	m.stopForward = func() { close(stop) }
+	m.drainForward = func() { close(drain) }
+
+	return nil
+}
+
+// forward sends the events of rescaled shards into Events. While warming is 1,
+// only GuildCreateEvents are sent. Once drain is closed, the events left in the
+// channel are sent before returning, unless stop is closed.
+func (m *ShardManager) forward(events <-chan Event, warming *int32,
+	drain, stop <-chan struct{}) {
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-drain:
+			for {
+				select {
+				case ev := <-events:
+					if !m.send(ev, stop) {
+						return
+					}
+				default:
+					return
+				}
+			}
+		case ev := <-events:
+			if _, ok := ev.(*GuildCreateEvent); !ok &&
+				atomic.LoadInt32(warming) == 1 {
+
+				continue
+			}
+
+			if !m.send(ev, stop) {
+				return
+			}
+		}
+	}
+}
+
+// send sends the event into Events, and returns false if stop is closed first.
+func (m *ShardManager) send(ev Event, stop <-chan struct{}) bool {
+	select {
+	case m.Events <- ev:
+		return true
+	case <-stop:
+		return false
+	}
+}
+
+func (m *ShardManager) shards() []*Gateway {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	return m.Shards
+}
+
+// ForEach calls fn on every shard, in order of the shard ID. fn is called on
+// all shards even if it fails on some, and the first error is returned.
+func (m *ShardManager) ForEach(fn func(shardID int, g *Gateway) error) error {
+	var firstErr error
+
+	for id, g := range m.shards() {
+		if err := fn(id, g); err != nil && firstErr == nil {
+			firstErr = errors.Wrapf(err, "Shard %d", id)
+		}
+	}
+
+	return firstErr
+}
+
+// UpdateStatusAll updates the status on all shards, as each shard has its own
+// presence. The status is also used when a shard identifies again, so it's kept
+// after a reconnect.
+func (m *ShardManager) UpdateStatusAll(data UpdateStatusData) error {
+	return m.ForEach(func(_ int, g *Gateway) error {
+		// Each shard gets its own copy.
+		var presence = data
+		g.SetPresence(&presence)
+
+		return g.UpdateStatus(data)
+	})
+}
+
+// Status returns the status of every shard, in order of the shard ID.
+func (m *ShardManager) Status() []ShardStatus {
+	var shards = m.shards()
+	var statuses = make([]ShardStatus, len(shards))
+
+	for id, g := range shards {
+		statuses[id] = g.Status()
+	}
+
+	return statuses
+}
+
+// NumShards returns the number of shards.
+func (m *ShardManager) NumShards() int {
+	return len(m.shards())
+}
+
+// Shard returns the shard with the given ID, or nil if there's none.
+func (m *ShardManager) Shard(id int) *Gateway {
+	var shards = m.shards()
+
+	if id < 0 || id >= len(shards) {
+		return nil
+	}
+
+	return shards[id]
+}
+
+// ShardForGuild returns the shard that handles the given guild.
+func (m *ShardManager) ShardForGuild(guildID discord.Snowflake) *Gateway {
+	var shards = m.shards()
+	return shards[ShardIDForGuild(guildID, len(shards))]
+}
+
+// ShardIDForGuild calculates the ID of the shard that handles the given guild,
+// as documented at
+// https://discordapp.com/developers/docs/topics/gateway#sharding.
+func ShardIDForGuild(guildID discord.Snowflake, numShards int) int {
+	if numShards < 1 {
+		return 0
+	}
+
+	return int((uint64(guildID) >> 22) % uint64(numShards))
+}
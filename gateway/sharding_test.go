@@ -0,0 +1,259 @@
+// +build unit
+
+package gateway
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/diamondburned/arikawa/discord"
+	"github.com/diamondburned/arikawa/internal/json"
+	"github.com/diamondburned/arikawa/internal/wsutil"
+	"golang.org/x/time/rate"
+)
+
+func TestShardManagerForEach(t *testing.T) {
+	m := &ShardManager{
+		Shards: []*Gateway{{}, {}, {}},
+	}
+
+	var called []int
+
+	err := m.ForEach(func(id int, g *Gateway) error {
+		if g != m.Shards[id] {
+			t.Fatal("Wrong shard for ID", id)
+		}
+
+		called = append(called, id)
+
+		if id > 0 {
+			return errors.New("oops")
+		}
+		return nil
+	})
+
+	if len(called) != 3 {
+		t.Fatal("Not all shards were called:", called)
+	}
+
+	if err == nil || err.Error() != "Shard 1: oops" {
+		t.Fatal("Unexpected error:", err)
+	}
+}
+
+func TestShardManagerForward(t *testing.T) {
+	m := &ShardManager{Events: make(chan Event, 10)}
+
+	var events = make(chan Event)
+	var warming = int32(1)
+	var stop = make(chan struct{})
+	defer close(stop)
+
+	go m.forward(events, &warming, make(chan struct{}), stop)
+
+	// Only guilds are sent while warming up.
+	events <- &ReadyEvent{}
+	events <- &MessageCreateEvent{}
+	events <- &GuildCreateEvent{}
+
+	atomic.StoreInt32(&warming, 0)
+	events <- &MessageCreateEvent{}
+
+	for _, expect := range []string{"guild", "message"} {
+		select {
+		case ev := <-m.Events:
+			var got string
+			switch ev.(type) {
+			case *GuildCreateEvent:
+				got = "guild"
+			case *MessageCreateEvent:
+				got = "message"
+			}
+
+			if got != expect {
+				t.Fatalf("Expected %s event, got %T", expect, ev)
+			}
+
+		case <-time.After(time.Second):
+			t.Fatal("Timed out waiting for", expect)
+		}
+	}
+}
+
+func TestNewGlobalLimit(t *testing.T) {
+	var now = time.Now()
+
+	l := newGlobalLimit(&SessionStartLimit{Remaining: 2})
+	if !l.AllowN(now, 2) || l.Allow() {
+		t.Fatal("Unexpected identifies allowed with 2 remaining")
+	}
+
+	// With no identifies left, they have to wait until the reset instead of
+	// failing.
+	l = newGlobalLimit(&SessionStartLimit{
+		Remaining:  0,
+		ResetAfter: discord.DurationToMilliseconds(time.Hour),
+	})
+
+	if l.Burst() != 1 || l.AllowN(now, 1) {
+		t.Fatal("An identify was allowed before the reset")
+	}
+
+	d := l.ReserveN(now, 1).DelayFrom(now)
+	if d < 59*time.Minute || d > 61*time.Minute {
+		t.Fatal("Unexpected delay until the reset:", d)
+	}
+}
+
+func TestShardManagerForwardDrain(t *testing.T) {
+	m := &ShardManager{Events: make(chan Event, 10)}
+
+	var events = make(chan Event, 10)
+	var warming = int32(0)
+	var drain = make(chan struct{})
+	var stop = make(chan struct{})
+	defer close(stop)
+
+	// The events are still in the channel when the old shards are closed.
+	events <- &MessageCreateEvent{}
+	events <- &MessageCreateEvent{}
+	close(drain)
+
+	var done = make(chan struct{})
+	go func() {
+		m.forward(events, &warming, drain, stop)
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Timed out waiting for the forwarder to stop")
+	}
+
+	if len(m.Events) != 2 {
+		t.Fatal("Unexpected number of events forwarded:", len(m.Events))
+	}
+}
+
+func TestShardManagerRescaleInherit(t *testing.T) {
+	m := &ShardManager{
+		Shards: make([]*Gateway, 1),
+		Events: make(chan Event),
+		url:    "wss://gateway.discord.gg",
+		token:  "Bot token",
+		driver: json.Default{},
+	}
+
+	if err := m.createShards(m.Shards, m.Events, nil); err != nil {
+		t.Fatal("Failed to create shards:", err)
+	}
+
+	var old = m.Shards[0]
+	old.AddIntent(IntentGuildMembers | IntentGuildMessages)
+	old.Identifier.Presence = &UpdateStatusData{Status: discord.IdleStatus}
+	old.Identifier.LargeThreshold = 250
+	old.Identifier.Compress = false
+	old.RawEvents = true
+	old.ReconnectPolicy.MaxRetries = 3
+	old.FrameLog = &FrameLog{}
+
+	// The new shards are created like Rescale does.
+	var shards = make([]*Gateway, 3)
+	if err := m.createShards(shards, make(chan Event), old); err != nil {
+		t.Fatal("Failed to create shards:", err)
+	}
+
+	for id, g := range shards {
+		var data = g.Identifier.IdentifyData
+
+		if data.Intents != IntentGuildMembers|IntentGuildMessages {
+			t.Fatal("Intents lost on shard", id, data.Intents)
+		}
+		if data.Presence == nil || data.Presence == old.Identifier.Presence ||
+			data.Presence.Status != discord.IdleStatus {
+
+			t.Fatal("Presence not copied to shard", id, data.Presence)
+		}
+		if data.LargeThreshold != 250 || data.Compress {
+			t.Fatal("Identify data not copied to shard", id)
+		}
+		if *data.Shard != (Shard{id, 3}) {
+			t.Fatal("Unexpected shard:", *data.Shard)
+		}
+		if !g.RawEvents || g.ReconnectPolicy.MaxRetries != 3 ||
+			g.FrameLog != old.FrameLog {
+
+			t.Fatal("Settings not copied to shard", id)
+		}
+	}
+
+	if *old.Identifier.Shard != (Shard{0, 1}) {
+		t.Fatal("Old shard changed:", *old.Identifier.Shard)
+	}
+}
+
+// discardConn is a connection that discards everything sent.
+type discardConn struct {
+	wsutil.Connection
+}
+
+func (discardConn) Send(context.Context, []byte) error { return nil }
+
+func TestShardManagerUpdateStatusAll(t *testing.T) {
+	var unlimited = rate.NewLimiter(rate.Inf, 0)
+
+	m := &ShardManager{Shards: make([]*Gateway, 2)}
+	for id := range m.Shards {
+		g := &Gateway{
+			WS: &wsutil.Websocket{
+				Conn:        discardConn{},
+				SendLimiter: unlimited,
+			},
+			Driver:     json.Default{},
+			WSTimeout:  time.Second,
+			Identifier: NewIdentifier(IdentifyData{Token: "Bot token"}),
+		}
+		g.Identifier.IdentifyShortLimit = unlimited
+		g.Identifier.IdentifyGlobalLimit = unlimited
+
+		m.Shards[id] = g
+	}
+
+	// A shard that reconnects identifies again with the presence.
+	var done = make(chan struct{})
+	go func() {
+		defer close(done)
+
+		for i := 0; i < 100; i++ {
+			if err := m.Shards[1].Identify(); err != nil {
+				t.Error("Failed to identify:", err)
+				return
+			}
+		}
+	}()
+
+	for i := 0; i < 100; i++ {
+		err := m.UpdateStatusAll(UpdateStatusData{Status: discord.IdleStatus})
+		if err != nil {
+			t.Fatal("Failed to update the status:", err)
+		}
+	}
+
+	<-done
+
+	for id, g := range m.Shards {
+		var presence = g.identifyData().Presence
+		if presence == nil || presence.Status != discord.IdleStatus {
+			t.Fatal("Presence not set on shard", id)
+		}
+	}
+
+	var first, second = m.Shards[0], m.Shards[1]
+	if first.identifyData().Presence == second.identifyData().Presence {
+		t.Fatal("Shards share the same presence")
+	}
+}
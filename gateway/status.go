@@ -0,0 +1,118 @@
+package gateway
+
+import (
+	"sync"
+	"time"
+
+	"github.com/diamondburned/arikawa/discord"
+)
+
+// ShardStatus is the status of a Gateway, for health checks and dashboards.
+type ShardStatus struct {
+	ShardID int
+
+	// Connected is true if the Gateway is connected and has identified or
+	// resumed.
+	Connected bool
+	// Resuming is true while the Gateway is reconnecting to resume its
+	// session.
+	Resuming bool
+
+	// Latency is the time between the last heartbeat and its acknowledgement,
+	// or 0 if no heartbeat has been acknowledged yet.
+	Latency time.Duration
+	// LastEvent is when the last event was received, or zero if none was.
+	LastEvent time.Time
+
+	// Guilds is the number of guilds on the Gateway, including unavailable
+	// ones.
+	Guilds int
+}
+
+// gatewayStatus keeps track of the status of a Gateway.
+type gatewayStatus struct {
+	mutex sync.Mutex
+
+	connected bool
+	resuming  bool
+
+	beat      time.Time
+	latency   time.Duration
+	lastEvent time.Time
+
+	guilds discord.SnowflakeSet
+}
+
+// Status returns the status of the Gateway.
+func (g *Gateway) Status() ShardStatus {
+	g.status.mutex.Lock()
+	defer g.status.mutex.Unlock()
+
+	var status = ShardStatus{
+		Connected: g.status.connected,
+		Resuming:  g.status.resuming,
+		Latency:   g.status.latency,
+		LastEvent: g.status.lastEvent,
+		Guilds:    g.status.guilds.Len(),
+	}
+
+	if g.Identifier != nil && g.Identifier.Shard != nil {
+		status.ShardID = g.Identifier.Shard.ShardID()
+	}
+
+	return status
+}
+
+func (s *gatewayStatus) setConnected(connected bool) {
+	s.mutex.Lock()
+	s.connected = connected
+	s.mutex.Unlock()
+}
+
+func (s *gatewayStatus) setResuming(resuming bool) {
+	s.mutex.Lock()
+	s.resuming = resuming
+	s.mutex.Unlock()
+}
+
+func (s *gatewayStatus) heartbeat() {
+	s.mutex.Lock()
+	s.beat = time.Now()
+	s.mutex.Unlock()
+}
+
+func (s *gatewayStatus) heartbeatAck() {
+	s.mutex.Lock()
+	if !s.beat.IsZero() {
+		s.latency = time.Since(s.beat)
+	}
+	s.mutex.Unlock()
+}
+
+// event updates the last event time, and the guilds if ev changes them.
+func (s *gatewayStatus) event(ev Event) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.lastEvent = time.Now()
+
+	switch ev := ev.(type) {
+	case *ReadyEvent:
+		var ids = make([]discord.Snowflake, len(ev.Guilds))
+		for i, guild := range ev.Guilds {
+			ids[i] = guild.ID
+		}
+
+		s.guilds.Clear()
+		s.guilds.AddAll(ids...)
+
+	case *GuildCreateEvent:
+		s.guilds.Add(ev.ID)
+
+	case *GuildDeleteEvent:
+		// Unavailable guilds are still on the shard.
+		if !ev.Unavailable {
+			s.guilds.Remove(ev.ID)
+		}
+	}
+}
@@ -0,0 +1,45 @@
+// +build unit
+
+package gateway
+
+import (
+	"testing"
+	"time"
+
+	"github.com/diamondburned/arikawa/discord"
+)
+
+func TestGatewayStatus(t *testing.T) {
+	g := &Gateway{Identifier: DefaultIdentifier("")}
+	g.Identifier.SetShard(2, 4)
+
+	g.status.event(&ReadyEvent{
+		Guilds: []discord.Guild{{ID: 1}, {ID: 2}, {ID: 3}},
+	})
+	g.status.event(&GuildCreateEvent{Guild: discord.Guild{ID: 4}})
+	g.status.event(&GuildDeleteEvent{ID: 1, Unavailable: true})
+	g.status.event(&GuildDeleteEvent{ID: 2})
+
+	g.status.heartbeat()
+	g.status.beat = g.status.beat.Add(-time.Millisecond)
+	g.status.heartbeatAck()
+	g.status.setConnected(true)
+
+	status := g.Status()
+
+	if status.ShardID != 2 {
+		t.Fatal("Unexpected shard ID", status.ShardID)
+	}
+	if !status.Connected || status.Resuming {
+		t.Fatal("Unexpected connection status:", status)
+	}
+	if status.Guilds != 3 {
+		t.Fatal("Expected 3 guilds, got", status.Guilds)
+	}
+	if status.LastEvent.IsZero() {
+		t.Fatal("Last event wasn't set")
+	}
+	if status.Latency < time.Millisecond {
+		t.Fatal("Latency wasn't set")
+	}
+}
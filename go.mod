@@ -6,6 +6,8 @@ require (
 	github.com/gorilla/schema v1.1.0
 	github.com/pkg/errors v0.8.1
 	github.com/sasha-s/go-csync v0.0.0-20160729053059-3bc6c8bdb3fa
+	go.etcd.io/bbolt v1.3.5
+	golang.org/x/crypto v0.0.0-20190308221718-c2843e01d9a2
 	golang.org/x/net v0.0.0-20200114155413-6afb5195e5aa // indirect
 	golang.org/x/time v0.0.0-20191024005414-555d28b269f0
 	nhooyr.io/websocket v1.7.4
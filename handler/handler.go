@@ -41,6 +41,17 @@ type Handler struct {
 	horders  []uint64
 	hserial  uint64
 	hmutex   sync.Mutex
+
+	// running counts the handlers being called, for Drain. Once draining is
+	// set, handlers aren't called anymore.
+	running  sync.WaitGroup
+	draining bool // guarded by hmutex
+
+	// caller is the chain built from the middlewares, or nil if there are
+	// none.
+	middlewares []Middleware
+	caller      Caller
+	mmutex      sync.RWMutex
 }
 
 func New() *Handler {
@@ -49,13 +60,33 @@ func New() *Handler {
 	}
 }
 
+// Call dispatches the event to the handlers that accept it, through the
+// middlewares if there are any.
 func (h *Handler) Call(ev interface{}) {
+	h.mmutex.RLock()
+	var caller = h.caller
+	h.mmutex.RUnlock()
+
+	if caller != nil {
+		caller.Call(ev)
+		return
+	}
+
+	h.dispatch(ev)
+}
+
+// dispatch calls the handlers, skipping the middlewares.
+func (h *Handler) dispatch(ev interface{}) {
 	var evV = reflect.ValueOf(ev)
 	var evT = evV.Type()
 
 	h.hmutex.Lock()
 	defer h.hmutex.Unlock()
 
+	if h.draining {
+		return
+	}
+
 	for _, order := range h.horders {
 		handler, ok := h.handlers[order]
 		if !ok {
@@ -67,31 +98,121 @@ func (h *Handler) Call(ev interface{}) {
 			continue
 		}
 
+		h.running.Add(1)
+
 		if h.Synchronous {
-			handler.call(evV)
+			h.call(handler, evV)
 		} else {
-			go handler.call(evV)
+			go h.call(handler, evV)
 		}
 	}
 }
 
-func (h *Handler) WaitFor(ctx context.Context, fn func(interface{}) bool) interface{} {
-	var result = make(chan interface{})
+// call calls the handler, keeping track of it for Drain.
+func (h *Handler) call(handler handler, evV reflect.Value) {
+	defer h.running.Done()
+	handler.call(evV)
+}
 
-	cancel := h.AddHandler(func(v interface{}) {
-		if fn(v) {
-			result <- v
-		}
-	})
+// Drain stops the handlers from being called, and waits for the ones being
+// called to return. Events are dropped from then on. The context's error is
+// returned if it's done before the handlers return, which could be because
+// of handlers blocked on a channel from ChanFor that isn't read.
+func (h *Handler) Drain(ctx context.Context) error {
+	h.hmutex.Lock()
+	h.draining = true
+	h.hmutex.Unlock()
 
-	defer cancel()
+	var done = make(chan struct{})
+	go func() {
+		h.running.Wait()
+		close(done)
+	}()
 
 	select {
-	case r := <-result:
-		return r
-	case <-ctx.Done():
+	case <-done:
 		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// WaitFor blocks until there's an event that matches the filter, then returns
+// it. Nil is returned if the context is canceled before that.
+//
+// The filter must be a function that takes an event, the same way a handler
+// would, and returns a bool:
+//
+//    ev := h.WaitFor(ctx, func(r *gateway.MessageReactionAddEvent) bool {
+//        return r.UserID == userID
+//    })
+//
+// WaitFor panics if the filter is invalid.
+func (h *Handler) WaitFor(
+	ctx context.Context, filter interface{}) interface{} {
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	return <-h.ChanFor(ctx, filter)
+}
+
+// ChanFor returns a channel that receives all events that match the filter,
+// which takes the same form as the one given to WaitFor. The filter is removed
+// and the channel is closed once the context is canceled.
+//
+// Events are not buffered: if the handler is Synchronous, Call blocks until
+// each event is received or the context is canceled.
+//
+// ChanFor panics if the filter is invalid.
+func (h *Handler) ChanFor(
+	ctx context.Context, filter interface{}) <-chan interface{} {
+
+	f, err := reflectFilter(filter)
+	if err != nil {
+		panic(err)
 	}
+
+	var out = make(chan interface{})
+
+	// closing is write-locked to close the channel once all the senders that
+	// got in before the cancellation are done.
+	var closing sync.RWMutex
+	var closed bool
+
+	r, _ := reflectFn(func(v interface{}) {
+		if !f.match(v) {
+			return
+		}
+
+		closing.RLock()
+		defer closing.RUnlock()
+
+		if closed {
+			return
+		}
+
+		select {
+		case out <- v:
+		case <-ctx.Done():
+		}
+	})
+
+	// The channel keeps receiving events if the handlers are replaced.
+	r.internal = true
+	rm := h.add(*r)
+
+	go func() {
+		<-ctx.Done()
+		rm()
+
+		closing.Lock()
+		closed = true
+		close(out)
+		closing.Unlock()
+	}()
+
+	return out
 }
 
 func (h *Handler) AddHandler(handler interface{}) (rm func()) {
@@ -127,18 +248,14 @@ func (h *Handler) addHandler(handler interface{}) (rm func(), err error) {
 		return nil, errors.Wrap(err, "Handler reflect failed")
 	}
 
+	return h.add(*r), nil
+}
+
+func (h *Handler) add(r handler) (rm func()) {
 	h.hmutex.Lock()
 	defer h.hmutex.Unlock()
 
-	// Get the current counter value and increment the counter:
-	serial := h.hserial
-	h.hserial++
-
-	// Use the serial for the map:
-	h.handlers[serial] = *r
-
-	// Append the serial into the list of keys:
-	h.horders = append(h.horders, serial)
+	serial := h.store(r)
 
 	return func() {
 		h.hmutex.Lock()
@@ -154,13 +271,78 @@ func (h *Handler) addHandler(handler interface{}) (rm func(), err error) {
 				break
 			}
 		}
-	}, nil
+	}
+}
+
+// store adds the handler and returns its serial. It must be called with
+// hmutex locked.
+func (h *Handler) store(r handler) uint64 {
+	// Get the current counter value and increment the counter:
+	serial := h.hserial
+	h.hserial++
+
+	// Use the serial for the map:
+	h.handlers[serial] = r
+
+	// Append the serial into the list of keys:
+	h.horders = append(h.horders, serial)
+
+	return serial
+}
+
+// Replace replaces all the handlers at once with the given ones, so that no
+// event is dispatched to a mix of both. It's meant to reload plugins without
+// reconnecting. The channels of ChanFor and WaitFor keep receiving events,
+// and nothing is replaced if any handler is invalid.
+//
+// A State adds its own handler to the Handler of its Session, which would be
+// replaced too. Replaceable handlers should then be in a Handler of their
+// own, which is called by the Session's:
+//
+//	plugins := handler.New()
+//	s.AddHandler(plugins.Call)
+//
+//	// On reload:
+//	err := plugins.Replace(onMessage, onReaction)
+func (h *Handler) Replace(handlers ...interface{}) error {
+	var reflected = make([]handler, len(handlers))
+
+	for i, fn := range handlers {
+		r, err := reflectFn(fn)
+		if err != nil {
+			return errors.Wrapf(err, "Handler %d reflect failed", i)
+		}
+		reflected[i] = *r
+	}
+
+	h.hmutex.Lock()
+	defer h.hmutex.Unlock()
+
+	var orders = h.horders
+	h.horders = nil
+
+	for _, serial := range orders {
+		if h.handlers[serial].internal {
+			h.horders = append(h.horders, serial)
+		} else {
+			delete(h.handlers, serial)
+		}
+	}
+
+	for _, r := range reflected {
+		h.store(r)
+	}
+
+	return nil
 }
 
 type handler struct {
 	event    reflect.Type
 	callback reflect.Value
 	isIface  bool
+
+	// internal is true for the handlers of ChanFor, which Replace keeps.
+	internal bool
 }
 
 func reflectFn(function interface{}) (*handler, error) {
@@ -190,6 +372,22 @@ func reflectFn(function interface{}) (*handler, error) {
 	}, nil
 }
 
+// reflectFilter reflects a filter function, which is a handler that returns a
+// bool.
+func reflectFilter(filter interface{}) (*handler, error) {
+	r, err := reflectFn(filter)
+	if err != nil {
+		return nil, errors.Wrap(err, "Filter reflect failed")
+	}
+
+	fnT := r.callback.Type()
+	if fnT.NumOut() != 1 || fnT.Out(0).Kind() != reflect.Bool {
+		return nil, errors.New("filter must return a bool")
+	}
+
+	return r, nil
+}
+
 func (h handler) not(event reflect.Type) bool {
 	if h.isIface {
 		return !event.Implements(h.event)
@@ -201,3 +399,14 @@ func (h handler) not(event reflect.Type) bool {
 func (h handler) call(event reflect.Value) {
 	h.callback.Call([]reflect.Value{event})
 }
+
+// match returns true if the event is of the filter's type and the filter
+// returns true.
+func (h handler) match(event interface{}) bool {
+	var evV = reflect.ValueOf(event)
+	if h.not(evV.Type()) {
+		return false
+	}
+
+	return h.callback.Call([]reflect.Value{evV})[0].Bool()
+}
@@ -3,12 +3,14 @@
 package handler
 
 import (
+	"context"
 	"reflect"
 	"strings"
 	"testing"
 	"time"
 
 	"github.com/diamondburned/arikawa/gateway"
+	"github.com/diamondburned/arikawa/tracing"
 )
 
 func TestCall(t *testing.T) {
@@ -123,6 +125,168 @@ func TestHandlerInterface(t *testing.T) {
 	t.Fatal("Assertion failed:", recv)
 }
 
+func TestWaitFor(t *testing.T) {
+	h := New()
+
+	go func() {
+		// Wait for WaitFor to add its handler.
+		for {
+			h.hmutex.Lock()
+			n := len(h.handlers)
+			h.hmutex.Unlock()
+
+			if n > 0 {
+				break
+			}
+			time.Sleep(time.Millisecond)
+		}
+
+		h.Call(&gateway.TypingStartEvent{})
+		h.Call(&gateway.MessageCreateEvent{Content: "skipped"})
+		h.Call(&gateway.MessageCreateEvent{Content: "wanted"})
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	v := h.WaitFor(ctx, func(m *gateway.MessageCreateEvent) bool {
+		return m.Content == "wanted"
+	})
+
+	if m, ok := v.(*gateway.MessageCreateEvent); !ok || m.Content != "wanted" {
+		t.Fatal("Unexpected event:", v)
+	}
+}
+
+func TestChanFor(t *testing.T) {
+	h := New()
+	h.Synchronous = true
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	ch := h.ChanFor(ctx, func(m *gateway.MessageCreateEvent) bool {
+		return true
+	})
+
+	go h.Call(&gateway.MessageCreateEvent{Content: "test"})
+
+	if m := (<-ch).(*gateway.MessageCreateEvent); m.Content != "test" {
+		t.Fatal("Unexpected content:", m.Content)
+	}
+
+	cancel()
+
+	// The channel should be closed and the handler removed.
+	select {
+	case _, ok := <-ch:
+		if ok {
+			t.Fatal("Unexpected event after cancel")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Channel not closed after cancel")
+	}
+
+	h.hmutex.Lock()
+	n := len(h.handlers)
+	h.hmutex.Unlock()
+
+	if n != 0 {
+		t.Fatal("Handler not removed after cancel")
+	}
+}
+
+func TestInvalidFilter(t *testing.T) {
+	_, err := reflectFilter(func(m *gateway.MessageCreateEvent) {})
+	if err == nil {
+		t.Fatal("Expected error for filter without bool return")
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	h := New()
+	h.Synchronous = true
+
+	var order []string
+
+	h.Use(
+		func(next Caller) Caller {
+			return CallerFunc(func(ev interface{}) {
+				order = append(order, "outer")
+				next.Call(ev)
+			})
+		},
+		Filter(func(ev interface{}) bool {
+			_, ok := ev.(*gateway.MessageCreateEvent)
+			return ok
+		}),
+		Recover(func(err error) {
+			order = append(order, "recovered")
+		}),
+	)
+
+	h.AddHandler(func(m *gateway.MessageCreateEvent) {
+		order = append(order, m.Content)
+		if m.Content == "panic" {
+			panic("test")
+		}
+	})
+
+	h.Call(&gateway.TypingStartEvent{})
+	h.Call(&gateway.MessageCreateEvent{Content: "message"})
+	h.Call(&gateway.MessageCreateEvent{Content: "panic"})
+
+	var expected = []string{
+		"outer",
+		"outer", "message",
+		"outer", "panic", "recovered",
+	}
+
+	if !reflect.DeepEqual(order, expected) {
+		t.Fatal("Unexpected order:", order)
+	}
+}
+
+type spanRecorder struct {
+	spans []string
+}
+
+func (r *spanRecorder) Start(ctx context.Context, name string,
+	attrs ...tracing.Attribute) (context.Context, tracing.Span) {
+
+	r.spans = append(r.spans, "start "+name)
+	return ctx, spanEnder{r}
+}
+
+type spanEnder struct {
+	r *spanRecorder
+}
+
+func (s spanEnder) SetAttributes(...tracing.Attribute) {}
+
+func (s spanEnder) End(err error) {
+	s.r.spans = append(s.r.spans, "end")
+}
+
+func TestTrace(t *testing.T) {
+	var r spanRecorder
+
+	h := New()
+	h.Synchronous = true
+	h.Use(Trace(&r))
+
+	h.AddHandler(func(m *gateway.MessageCreateEvent) {
+		r.spans = append(r.spans, m.Content)
+	})
+
+	h.Call(&gateway.MessageCreateEvent{Content: "message"})
+
+	var expected = []string{"start MessageCreateEvent", "message", "end"}
+
+	if !reflect.DeepEqual(r.spans, expected) {
+		t.Fatal("Unexpected spans:", r.spans)
+	}
+}
+
 func BenchmarkReflect(b *testing.B) {
 	h, err := reflectFn(func(m *gateway.MessageCreateEvent) {})
 	if err != nil {
@@ -144,3 +308,78 @@ func BenchmarkReflect(b *testing.B) {
 		h.call(msgV)
 	}
 }
+
+func TestDrain(t *testing.T) {
+	var release = make(chan struct{})
+	var calls = make(chan struct{}, 2)
+
+	h := New()
+	h.AddHandler(func(m *gateway.MessageCreateEvent) {
+		calls <- struct{}{}
+		<-release
+	})
+
+	h.Call(&gateway.MessageCreateEvent{})
+	<-calls
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+
+	if err := h.Drain(ctx); err != context.DeadlineExceeded {
+		t.Fatal("Drain didn't wait for the handler:", err)
+	}
+
+	// Events are dropped while draining.
+	h.Call(&gateway.MessageCreateEvent{})
+	close(release)
+
+	if err := h.Drain(context.Background()); err != nil {
+		t.Fatal("Failed to drain:", err)
+	}
+
+	if len(calls) != 0 {
+		t.Fatal("Handler was called while draining")
+	}
+}
+
+func TestReplace(t *testing.T) {
+	var results = make(chan string, 3)
+
+	h := New()
+	h.Synchronous = true
+
+	h.AddHandler(func(m *gateway.MessageCreateEvent) {
+		results <- "old"
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ch := h.ChanFor(ctx, func(m *gateway.MessageCreateEvent) bool {
+		return true
+	})
+
+	err := h.Replace(func(m *gateway.MessageCreateEvent) {
+		results <- "new"
+	})
+	if err != nil {
+		t.Fatal("Failed to replace handlers:", err)
+	}
+
+	if err := h.Replace("invalid"); err == nil {
+		t.Fatal("No error replacing with an invalid handler")
+	}
+
+	go h.Call(&gateway.MessageCreateEvent{})
+
+	if ev := <-ch; ev == nil {
+		t.Fatal("ChanFor channel was closed")
+	}
+
+	if result := <-results; result != "new" {
+		t.Fatal("Unexpected handler called:", result)
+	}
+	if len(results) > 0 {
+		t.Fatal("Old handler was called:", <-results)
+	}
+}
@@ -0,0 +1,101 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"github.com/diamondburned/arikawa/tracing"
+)
+
+// Caller is anything that events can be dispatched to, such as a Handler.
+type Caller interface {
+	Call(ev interface{})
+}
+
+// CallerFunc is a function that implements Caller.
+type CallerFunc func(ev interface{})
+
+func (f CallerFunc) Call(ev interface{}) {
+	f(ev)
+}
+
+// Middleware wraps the dispatching of every event. It should call next to let
+// the event through, or not to drop it:
+//
+//	h.Use(func(next handler.Caller) handler.Caller {
+//	    return handler.CallerFunc(func(ev interface{}) {
+//	        start := time.Now()
+//	        next.Call(ev)
+//	        log.Printf("%T took %v", ev, time.Since(start))
+//	    })
+//	})
+//
+// Unless the Handler is Synchronous, next only spawns the handlers and returns
+// before they're done.
+type Middleware func(next Caller) Caller
+
+// Use adds middlewares to the Handler. The first middleware added is the
+// outermost one, so it sees events first.
+func (h *Handler) Use(middlewares ...Middleware) {
+	h.mmutex.Lock()
+	defer h.mmutex.Unlock()
+
+	h.middlewares = append(h.middlewares, middlewares...)
+
+	// Build the chain from the inside out.
+	var caller Caller = CallerFunc(h.dispatch)
+	for i := len(h.middlewares) - 1; i >= 0; i-- {
+		caller = h.middlewares[i](caller)
+	}
+
+	h.caller = caller
+}
+
+// Filter returns a middleware that drops the events that fn returns false for.
+func Filter(fn func(ev interface{}) bool) Middleware {
+	return func(next Caller) Caller {
+		return CallerFunc(func(ev interface{}) {
+			if fn(ev) {
+				next.Call(ev)
+			}
+		})
+	}
+}
+
+// Recover returns a middleware that recovers panics from further down the chain
+// and passes them to fn as errors. Panics in handlers are only recovered if the
+// Handler is Synchronous, as they're otherwise called in their own goroutines.
+func Recover(fn func(err error)) Middleware {
+	return func(next Caller) Caller {
+		return CallerFunc(func(ev interface{}) {
+			defer func() {
+				if rec := recover(); rec != nil {
+					fn(fmt.Errorf("Panic while handling %T: %v", ev, rec))
+				}
+			}()
+
+			next.Call(ev)
+		})
+	}
+}
+
+// Trace returns a middleware that makes a span for the dispatch of every event,
+// named after the event, such as "MessageCreateEvent". Unless the Handler is
+// Synchronous, the span ends before the handlers are done.
+func Trace(t tracing.Tracer) Middleware {
+	return func(next Caller) Caller {
+		return CallerFunc(func(ev interface{}) {
+			var typ = reflect.TypeOf(ev)
+			if typ.Kind() == reflect.Ptr {
+				typ = typ.Elem()
+			}
+
+			_, span := t.Start(context.Background(), typ.Name(),
+				tracing.A("discord.event", typ.Name()))
+			defer span.End(nil)
+
+			next.Call(ev)
+		})
+	}
+}
@@ -0,0 +1,192 @@
+// Package health provides an http.Handler that reports the status of a State
+// as JSON, so it can be served by an existing web server for health checks
+// such as Kubernetes probes:
+//
+//	h := health.New(s)
+//	s.SetMetrics(h.Recorder(nil))
+//
+//	http.Handle("/healthz", h)
+//
+// The response has the status 200 if every shard is connected or resuming, or
+// 503 otherwise.
+package health
+
+import (
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/diamondburned/arikawa/gateway"
+	"github.com/diamondburned/arikawa/internal/json"
+	"github.com/diamondburned/arikawa/metrics"
+	"github.com/diamondburned/arikawa/state"
+)
+
+// Handler serves the status of a State.
+type Handler struct {
+	// Counters of the Recorder, kept first for their 64-bit alignment.
+	hits        uint64
+	misses      uint64
+	rateLimited uint64
+
+	State *state.State
+
+	// MaxLatency, if not 0, marks shards whose heartbeat latency is above it
+	// as unhealthy.
+	MaxLatency time.Duration
+}
+
+// New creates a Handler for the State. Store lookups and rate limited requests
+// are only counted if the Recorder of the Handler is set.
+func New(s *state.State) *Handler {
+	return &Handler{State: s}
+}
+
+// Status is the JSON response of the Handler.
+type Status struct {
+	Healthy bool          `json:"healthy"`
+	Shards  []ShardStatus `json:"shards"`
+	Store   StoreStatus   `json:"store"`
+	REST    RESTStatus    `json:"rest"`
+}
+
+type ShardStatus struct {
+	ShardID   int        `json:"shard_id"`
+	Connected bool       `json:"connected"`
+	Resuming  bool       `json:"resuming"`
+	LatencyMs int64      `json:"latency_ms"`
+	LastEvent *time.Time `json:"last_event,omitempty"`
+	Guilds    int        `json:"guilds"`
+}
+
+type StoreStatus struct {
+	// Guilds is the number of guilds of all shards. It's counted from the
+	// shards, as listing the guilds of the store on every probe could be slow.
+	Guilds int `json:"guilds"`
+
+	Hits   uint64 `json:"hits"`
+	Misses uint64 `json:"misses"`
+}
+
+type RESTStatus struct {
+	// Waiting is the number of requests waiting for a rate limit to reset.
+	Waiting int `json:"waiting"`
+	// GlobalReset is when the global rate limit resets, if there's one.
+	GlobalReset *time.Time `json:"global_reset,omitempty"`
+	// RateLimited is the number of requests that got a 429.
+	RateLimited uint64 `json:"rate_limited"`
+}
+
+// Status returns the status of the State.
+func (h *Handler) Status() Status {
+	var status = Status{
+		Healthy: true,
+		Store: StoreStatus{
+			Hits:   atomic.LoadUint64(&h.hits),
+			Misses: atomic.LoadUint64(&h.misses),
+		},
+		REST: RESTStatus{
+			RateLimited: atomic.LoadUint64(&h.rateLimited),
+		},
+	}
+
+	for _, shard := range h.shards() {
+		if !shard.Connected && !shard.Resuming {
+			status.Healthy = false
+		}
+		if h.MaxLatency > 0 && shard.Latency > h.MaxLatency {
+			status.Healthy = false
+		}
+
+		var s = ShardStatus{
+			ShardID:   shard.ShardID,
+			Connected: shard.Connected,
+			Resuming:  shard.Resuming,
+			LatencyMs: int64(shard.Latency / time.Millisecond),
+			Guilds:    shard.Guilds,
+		}
+		if !shard.LastEvent.IsZero() {
+			s.LastEvent = &shard.LastEvent
+		}
+
+		status.Shards = append(status.Shards, s)
+		status.Store.Guilds += shard.Guilds
+	}
+
+	if limiter := h.State.Client.Limiter; limiter != nil {
+		stats := limiter.Stats()
+		status.REST.Waiting = stats.Waiting
+
+		if !stats.GlobalReset.IsZero() {
+			status.REST.GlobalReset = &stats.GlobalReset
+		}
+	}
+
+	return status
+}
+
+func (h *Handler) shards() []gateway.ShardStatus {
+	switch {
+	case h.State.Shards != nil:
+		return h.State.Shards.Status()
+	case h.State.Gateway != nil:
+		return []gateway.ShardStatus{h.State.Gateway.Status()}
+	default:
+		return nil
+	}
+}
+
+// ServeHTTP writes the Status as JSON.
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var status = h.Status()
+
+	b, err := (json.Default{}).Marshal(status)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	if !status.Healthy {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+
+	w.Write(b)
+}
+
+// Recorder returns a Recorder that counts store lookups and rate limited
+// requests for the Handler, and then reports to next if it's not nil. It
+// should be set with the SetMetrics method of the Session.
+func (h *Handler) Recorder(next metrics.Recorder) metrics.Recorder {
+	if next == nil {
+		next = metrics.Nop{}
+	}
+
+	return recorder{next, h}
+}
+
+type recorder struct {
+	metrics.Recorder
+	h *Handler
+}
+
+func (r recorder) Request(
+	method, route string, status int, latency time.Duration) {
+
+	if status == http.StatusTooManyRequests {
+		atomic.AddUint64(&r.h.rateLimited, 1)
+	}
+
+	r.Recorder.Request(method, route, status, latency)
+}
+
+func (r recorder) StoreAccess(resource string, hit bool) {
+	if hit {
+		atomic.AddUint64(&r.h.hits, 1)
+	} else {
+		atomic.AddUint64(&r.h.misses, 1)
+	}
+
+	r.Recorder.StoreAccess(resource, hit)
+}
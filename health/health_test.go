@@ -0,0 +1,81 @@
+// +build unit
+
+package health
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/diamondburned/arikawa/api"
+	"github.com/diamondburned/arikawa/discord"
+	"github.com/diamondburned/arikawa/gateway"
+	"github.com/diamondburned/arikawa/internal/json"
+	"github.com/diamondburned/arikawa/session"
+	"github.com/diamondburned/arikawa/state"
+)
+
+func TestHandler(t *testing.T) {
+	g := &gateway.Gateway{
+		Driver:   json.Default{},
+		Events:   make(chan gateway.Event, 1),
+		Sequence: gateway.NewSequence(),
+	}
+
+	s := &state.State{
+		Session: &session.Session{
+			Client:  api.NewClient(""),
+			Gateway: g,
+		},
+		Store: state.NewDefaultStore(nil),
+	}
+
+	// The guilds are counted from the shards, not from the store.
+	s.Store.GuildSet(&discord.Guild{ID: 1})
+	s.Store.GuildSet(&discord.Guild{ID: 2})
+
+	err := gateway.HandleOP(g, &gateway.OP{
+		Code:      gateway.DispatchOP,
+		EventName: "GUILD_CREATE",
+		Data:      json.Raw(`{"id":"1"}`),
+	})
+	if err != nil {
+		t.Fatal("Failed to handle guild:", err)
+	}
+
+	h := New(s)
+
+	r := h.Recorder(nil)
+	r.StoreAccess("guild", true)
+	r.StoreAccess("guild", false)
+	r.Request("GET", "/guilds/:id", 429, 0)
+	r.Request("GET", "/guilds/:id", 200, 0)
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/healthz", nil))
+
+	// The Gateway isn't connected.
+	if w.Code != http.StatusServiceUnavailable {
+		t.Fatal("Unexpected status code", w.Code)
+	}
+
+	var status Status
+	if err := (json.Default{}).Unmarshal(w.Body.Bytes(), &status); err != nil {
+		t.Fatal("Failed to decode status:", err)
+	}
+
+	if status.Healthy || len(status.Shards) != 1 ||
+		status.Shards[0].Guilds != 1 {
+
+		t.Fatal("Unexpected shards:", status.Shards)
+	}
+
+	var store = StoreStatus{Guilds: 1, Hits: 1, Misses: 1}
+	if status.Store != store {
+		t.Fatal("Unexpected store status:", status.Store)
+	}
+
+	if status.REST.RateLimited != 1 || status.REST.GlobalReset != nil {
+		t.Fatal("Unexpected REST status:", status.REST)
+	}
+}
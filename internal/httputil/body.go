@@ -0,0 +1,60 @@
+package httputil
+
+import (
+	"bytes"
+	"io"
+	"sync"
+	"sync/atomic"
+)
+
+var bufferPool = sync.Pool{
+	New: func() interface{} { return new(bytes.Buffer) },
+}
+
+// pooledBody is a request body in a pooled buffer. Each attempt of the request
+// reads it with its own bodyReader, and the buffer is put back into the pool
+// once the request is done and all readers are closed, since the transport
+// could still be reading after the response.
+type pooledBody struct {
+	buf  *bytes.Buffer
+	refs int32
+}
+
+func newPooledBody() *pooledBody {
+	return &pooledBody{
+		buf:  bufferPool.Get().(*bytes.Buffer),
+		refs: 1, // the request's reference
+	}
+}
+
+func (b *pooledBody) reader() (io.ReadCloser, error) {
+	atomic.AddInt32(&b.refs, 1)
+	return &bodyReader{Reader: bytes.NewReader(b.buf.Bytes()), body: b}, nil
+}
+
+func (b *pooledBody) release() {
+	if atomic.AddInt32(&b.refs, -1) == 0 {
+		b.buf.Reset()
+		bufferPool.Put(b.buf)
+		b.buf = nil
+	}
+}
+
+type bodyReader struct {
+	*bytes.Reader
+	body *pooledBody
+	once sync.Once
+}
+
+func (r *bodyReader) Close() error {
+	r.once.Do(r.body.release)
+	return nil
+}
+
+// releaseBody releases the request's reference to its pooled body, if it has
+// one. It's called once the request is done.
+func releaseBody(body io.ReadCloser) {
+	if r, ok := body.(*bodyReader); ok {
+		r.body.release()
+	}
+}
@@ -8,21 +8,56 @@ import (
 	"io/ioutil"
 	"mime/multipart"
 	"net/http"
+	"strconv"
 	"time"
 
+	"github.com/diamondburned/arikawa/api/breaker"
 	"github.com/diamondburned/arikawa/internal/json"
+	"github.com/diamondburned/arikawa/metrics"
+	"github.com/diamondburned/arikawa/tracing"
 )
 
 // Retries is the default attempts to retry if the API returns an error before
 // giving up.
 var Retries uint = 5
 
+// Backoff is the default delay before the first retry. It's doubled after each
+// retry, up to MaxBackoff.
+var (
+	Backoff    = 500 * time.Millisecond
+	MaxBackoff = 10 * time.Second
+)
+
 type Client struct {
 	http.Client
 	json.Driver
 	SchemaEncoder
 
+	// Retries is the maximum number of attempts of a request. Requests are
+	// retried on network errors, 5xx errors and 429s. Requests with a body
+	// that can't be read twice, such as multipart uploads, aren't retried.
 	Retries uint
+	// Backoff is the delay before the first retry, which is doubled after
+	// each retry, up to MaxBackoff. The Retry-After header of a 429 is
+	// waited for instead, if it's longer.
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+
+	// Options are applied to every request, before the options of the
+	// request.
+	Options []RequestOption
+
+	// Breaker, if not nil, fails requests to routes that keep failing, with a
+	// *breaker.OpenError. Each attempt of a retried request counts.
+	Breaker *breaker.Breaker
+
+	// Tracer, if not nil, makes a span for every request, including its
+	// retries.
+	Tracer tracing.Tracer
+
+	// Context is used for requests made without one. It defaults to
+	// context.Background if nil.
+	Context context.Context
 }
 
 var DefaultClient = NewClient()
@@ -35,47 +70,36 @@ func NewClient() Client {
 		Driver:        json.Default{},
 		SchemaEncoder: &DefaultSchema{},
 		Retries:       Retries,
+		Backoff:       Backoff,
+		MaxBackoff:    MaxBackoff,
 	}
 }
 
+// MeanwhileMultipart streams the multipart body written by multipartWriter as
+// it's written, so files don't have to be read into memory. If
+// multipartWriter fails, so does the request. The client's Timeout doesn't
+// apply, since large uploads could take longer.
 func (c *Client) MeanwhileMultipart(
 	multipartWriter func(*multipart.Writer) error,
 	method, url string, opts ...RequestOption) (*http.Response, error) {
 
-	// We want to cancel the request if our bodyWriter fails
-	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
-
 	r, w := io.Pipe()
 	body := multipart.NewWriter(w)
 
-	var bgErr error
-
 	go func() {
-		if err := multipartWriter(body); err != nil {
-			bgErr = err
-			cancel()
-		}
-
-		// Close the writer so the body gets flushed to the HTTP reader.
-		w.Close()
+		// Closing the writer flushes the body to the HTTP reader. If there's
+		// an error, the reader returns it, which fails the request.
+		w.CloseWithError(multipartWriter(body))
 	}()
 
-	resp, err := c.RequestCtx(ctx, method, url,
+	var client = *c
+	client.Client.Timeout = 0
+
+	return client.RequestCtx(c.context(), method, url,
 		append([]RequestOption{
 			WithBody(r),
 			WithContentType(body.FormDataContentType()),
 		}, opts...)...)
-
-	if err != nil && bgErr != nil {
-		if resp.Body != nil {
-			resp.Body.Close()
-		}
-
-		return nil, bgErr
-	}
-
-	return resp, err
 }
 
 func (c *Client) FastRequest(
@@ -97,25 +121,81 @@ func (c *Client) RequestCtx(ctx context.Context,
 		return nil, RequestError{err}
 	}
 
-	for _, opt := range opts {
-		if err := opt(req); err != nil {
-			return nil, err
+	for _, opts := range [][]RequestOption{c.Options, opts} {
+		for _, opt := range opts {
+			if err := opt(req); err != nil {
+				return nil, err
+			}
 		}
 	}
 
+	// Every attempt reads from the same pooled body, if there's one.
+	defer releaseBody(req.Body)
+
+	if c.Tracer == nil {
+		return c.send(req)
+	}
+
+	route := metrics.Route(req.URL.Path)
+
+	ctx, span := c.Tracer.Start(ctx, method+" "+route,
+		tracing.A("http.method", method),
+		tracing.A("http.route", route),
+	)
+
+	r, err := c.send(req.WithContext(ctx))
+
+	switch err := err.(type) {
+	case nil:
+		span.SetAttributes(tracing.A("http.status_code", r.StatusCode))
+	case *HTTPError:
+		span.SetAttributes(tracing.A("http.status_code", err.Status))
+	}
+
+	span.End(err)
+	return r, err
+}
+
+// send sends the request, retrying it if needed, and turns failure statuses
+// into HTTPErrors.
+func (c *Client) send(req *http.Request) (*http.Response, error) {
+	var ctx = req.Context()
+
 	var r *http.Response
+	var err error
+	var backoff = c.Backoff
 
-	for i := uint(0); i < c.Retries; i++ {
-		r, err = c.Client.Do(req)
-		if err != nil {
-			continue
+	for i := uint(1); ; i++ {
+		r, err = c.do(req)
+
+		// Retrying wouldn't help until the cooldown is over.
+		if err, ok := err.(*breaker.OpenError); ok {
+			return nil, err
+		}
+
+		if i >= c.Retries || !shouldRetry(ctx, r, err) || !rewind(req) {
+			break
+		}
+
+		var delay = backoff
+		if after := retryAfter(r); after > delay {
+			delay = after
+		}
+
+		// The failed response is thrown away.
+		if r != nil {
+			r.Body.Close()
 		}
 
-		if r.StatusCode < 200 || r.StatusCode > 299 {
-			continue
+		select {
+		case <-ctx.Done():
+			return nil, RequestError{ctx.Err()}
+		case <-time.After(delay):
 		}
 
-		break
+		if backoff *= 2; backoff > c.MaxBackoff {
+			backoff = c.MaxBackoff
+		}
 	}
 
 	// If all retries failed:
@@ -143,6 +223,79 @@ func (c *Client) RequestCtx(ctx context.Context,
 	return r, nil
 }
 
+// do sends the request through the Breaker, if there's one. The route of the
+// request is its method and its path without IDs.
+func (c *Client) do(req *http.Request) (*http.Response, error) {
+	if c.Breaker == nil {
+		return c.Client.Do(req)
+	}
+
+	route := req.Method + " " + metrics.Route(req.URL.Path)
+
+	if err := c.Breaker.Allow(route); err != nil {
+		return nil, err
+	}
+
+	r, err := c.Client.Do(req)
+
+	switch {
+	case err != nil && req.Context().Err() != nil:
+		c.Breaker.Cancel(route)
+	case err != nil || r.StatusCode >= 500:
+		c.Breaker.Failure(route)
+	default:
+		c.Breaker.Success(route)
+	}
+
+	return r, err
+}
+
+// shouldRetry returns true if the request failed because of a network error, a
+// server error or a rate limit.
+func shouldRetry(ctx context.Context, r *http.Response, err error) bool {
+	if err != nil {
+		// Don't retry if the request was cancelled.
+		return ctx.Err() == nil
+	}
+
+	return r.StatusCode == http.StatusTooManyRequests || r.StatusCode >= 500
+}
+
+// rewind resets the body of the request, so it can be sent again. False is
+// returned if the body can't be read again.
+func rewind(req *http.Request) bool {
+	if req.Body == nil || req.Body == http.NoBody {
+		return true
+	}
+
+	if req.GetBody == nil {
+		return false
+	}
+
+	body, err := req.GetBody()
+	if err != nil {
+		return false
+	}
+
+	req.Body = body
+	return true
+}
+
+// retryAfter returns the Retry-After of a 429, or 0. It's in milliseconds, as
+// Discord sends it.
+func retryAfter(r *http.Response) time.Duration {
+	if r == nil || r.StatusCode != http.StatusTooManyRequests {
+		return 0
+	}
+
+	ms, err := strconv.Atoi(r.Header.Get("Retry-After"))
+	if err != nil {
+		return 0
+	}
+
+	return time.Duration(ms) * time.Millisecond
+}
+
 func (c *Client) RequestCtxJSON(ctx context.Context,
 	to interface{}, method, url string, opts ...RequestOption) error {
 
@@ -169,11 +322,18 @@ func (c *Client) RequestCtxJSON(ctx context.Context,
 func (c *Client) Request(
 	method, url string, opts ...RequestOption) (*http.Response, error) {
 
-	return c.RequestCtx(context.Background(), method, url, opts...)
+	return c.RequestCtx(c.context(), method, url, opts...)
 }
 
 func (c *Client) RequestJSON(
 	to interface{}, method, url string, opts ...RequestOption) error {
 
-	return c.RequestCtxJSON(context.Background(), to, method, url, opts...)
+	return c.RequestCtxJSON(c.context(), to, method, url, opts...)
+}
+
+func (c *Client) context() context.Context {
+	if c.Context == nil {
+		return context.Background()
+	}
+	return c.Context
 }
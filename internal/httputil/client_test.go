@@ -0,0 +1,311 @@
+// +build unit
+
+package httputil
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/ioutil"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/diamondburned/arikawa/api/breaker"
+	"github.com/diamondburned/arikawa/internal/json"
+	"github.com/diamondburned/arikawa/tracing"
+)
+
+func newTestClient() Client {
+	c := NewClient()
+	c.Backoff = time.Millisecond
+	c.MaxBackoff = time.Millisecond
+	return c
+}
+
+func TestRetry(t *testing.T) {
+	var attempts int
+	var bodies []string
+
+	srv := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			b, _ := ioutil.ReadAll(r.Body)
+			bodies = append(bodies, string(b))
+
+			if attempts++; attempts < 3 {
+				w.WriteHeader(http.StatusBadGateway)
+				return
+			}
+
+			w.WriteHeader(http.StatusNoContent)
+		},
+	))
+	defer srv.Close()
+
+	c := newTestClient()
+
+	err := c.RequestJSON(nil, "POST", srv.URL,
+		WithJSONBody(json.Default{}, map[string]int{"a": 1}))
+	if err != nil {
+		t.Fatal("Request failed:", err)
+	}
+
+	if attempts != 3 {
+		t.Fatal("Expected 3 attempts, got", attempts)
+	}
+
+	for _, body := range bodies {
+		if body != `{"a":1}` {
+			t.Fatal("Body wasn't sent again:", bodies)
+		}
+	}
+}
+
+func TestRetryGiveUp(t *testing.T) {
+	var attempts int
+
+	srv := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			attempts++
+
+			if r.URL.Path == "/429" {
+				w.Header().Set("Retry-After", "20")
+				w.WriteHeader(http.StatusTooManyRequests)
+				return
+			}
+
+			w.WriteHeader(http.StatusNotFound)
+		},
+	))
+	defer srv.Close()
+
+	c := newTestClient()
+
+	// Client errors aren't retried.
+	err := c.FastRequest("GET", srv.URL+"/404")
+	if err, ok := err.(*HTTPError); !ok || err.Status != 404 {
+		t.Fatal("Unexpected error:", err)
+	}
+	if attempts != 1 {
+		t.Fatal("Expected 1 attempt, got", attempts)
+	}
+
+	// Rate limits are, after Retry-After.
+	attempts = 0
+	c.Retries = 2
+	sent := time.Now()
+
+	err = c.FastRequest("GET", srv.URL+"/429")
+	if err, ok := err.(*HTTPError); !ok || err.Status != 429 {
+		t.Fatal("Unexpected error:", err)
+	}
+	if attempts != 2 {
+		t.Fatal("Expected 2 attempts, got", attempts)
+	}
+	if since := time.Since(sent); since < 20*time.Millisecond {
+		t.Fatal("Retry-After wasn't waited for, took", since)
+	}
+}
+
+func TestMeanwhileMultipart(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			f, _, err := r.FormFile("file0")
+			if err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			defer f.Close()
+
+			// Echo the file back.
+			io.Copy(w, f)
+		},
+	))
+	defer srv.Close()
+
+	c := newTestClient()
+
+	resp, err := c.MeanwhileMultipart(func(mw *multipart.Writer) error {
+		defer mw.Close()
+
+		w, err := mw.CreateFormFile("file0", "a.txt")
+		if err != nil {
+			return err
+		}
+
+		_, err = io.WriteString(w, "hello")
+		return err
+	}, "POST", srv.URL)
+
+	if err != nil {
+		t.Fatal("Request failed:", err)
+	}
+	defer resp.Body.Close()
+
+	// The body should still be readable after returning.
+	b, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal("Failed to read body:", err)
+	}
+
+	if string(b) != "hello" {
+		t.Fatal("Unexpected body:", string(b))
+	}
+
+	// A failing writer fails the request.
+	_, err = c.MeanwhileMultipart(func(mw *multipart.Writer) error {
+		return errors.New("oops")
+	}, "POST", srv.URL)
+
+	if err == nil {
+		t.Fatal("Expected the writer's error")
+	}
+}
+
+func TestClientOptions(t *testing.T) {
+	var reason string
+
+	srv := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			reason = r.Header.Get("X-Audit-Log-Reason")
+			w.WriteHeader(http.StatusNoContent)
+		},
+	))
+	defer srv.Close()
+
+	c := newTestClient()
+	c.Options = []RequestOption{WithReason("spam and more")}
+
+	if err := c.FastRequest("DELETE", srv.URL); err != nil {
+		t.Fatal("Request failed:", err)
+	}
+
+	if reason != "spam%20and%20more" {
+		t.Fatal("Unexpected reason:", reason)
+	}
+}
+
+func TestPooledBody(t *testing.T) {
+	body := newPooledBody()
+	body.buf.WriteString("hello")
+
+	r1, _ := body.reader()
+	r2, _ := body.reader()
+
+	// The request is done, but the readers aren't closed yet.
+	releaseBody(r1)
+
+	if b, _ := ioutil.ReadAll(r2); string(b) != "hello" {
+		t.Fatal("Body was released too early:", string(b))
+	}
+
+	r1.Close()
+	r1.Close() // closing twice doesn't release twice
+	if body.buf == nil {
+		t.Fatal("Body was released while a reader is open")
+	}
+
+	r2.Close()
+	if body.buf != nil {
+		t.Fatal("Body wasn't released")
+	}
+}
+
+func TestBreaker(t *testing.T) {
+	var attempts int
+
+	srv := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			attempts++
+			w.WriteHeader(http.StatusBadGateway)
+		},
+	))
+	defer srv.Close()
+
+	c := newTestClient()
+	c.Breaker = breaker.New(2, time.Minute)
+
+	// The retries stop once the circuit opens.
+	_, err := c.Request("GET", srv.URL+"/channels/1")
+	if _, ok := err.(*breaker.OpenError); !ok {
+		t.Fatal("Unexpected error:", err)
+	}
+
+	// Other IDs share the route.
+	_, err = c.Request("GET", srv.URL+"/channels/2")
+	if _, ok := err.(*breaker.OpenError); !ok {
+		t.Fatal("Unexpected error:", err)
+	}
+
+	if attempts != 2 {
+		t.Fatal("Expected 2 attempts, got", attempts)
+	}
+}
+
+type tracer struct {
+	names []string
+	attrs map[string]interface{}
+	err   error
+}
+
+func (t *tracer) Start(ctx context.Context, name string,
+	attrs ...tracing.Attribute) (context.Context, tracing.Span) {
+
+	t.names = append(t.names, name)
+	t.attrs = map[string]interface{}{}
+	(*span)(t).SetAttributes(attrs...)
+
+	return ctx, (*span)(t)
+}
+
+type span tracer
+
+func (s *span) SetAttributes(attrs ...tracing.Attribute) {
+	for _, attr := range attrs {
+		s.attrs[attr.Key] = attr.Value
+	}
+}
+
+func (s *span) End(err error) {
+	s.err = err
+}
+
+func TestTracer(t *testing.T) {
+	var attempts int
+
+	srv := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			if attempts++; attempts < 2 {
+				w.WriteHeader(http.StatusBadGateway)
+				return
+			}
+			w.WriteHeader(http.StatusNotFound)
+		},
+	))
+	defer srv.Close()
+
+	var tr tracer
+
+	c := newTestClient()
+	c.Tracer = &tr
+
+	_, err := c.Request("GET", srv.URL+"/channels/1/messages")
+	if err == nil {
+		t.Fatal("Request didn't fail")
+	}
+
+	// The retries are in the same span.
+	if len(tr.names) != 1 || tr.names[0] != "GET /channels/:id/messages" {
+		t.Fatal("Unexpected spans:", tr.names)
+	}
+
+	if status := tr.attrs["http.status_code"]; status != 404 {
+		t.Fatal("Unexpected status code:", status)
+	}
+
+	if tr.err != err {
+		t.Fatal("Span ended with a different error:", tr.err)
+	}
+}
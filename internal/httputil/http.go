@@ -7,7 +7,9 @@ import (
 type TransportWrapper struct {
 	Default http.RoundTripper
 	Pre     func(*http.Request) error
-	Post    func(*http.Response) error
+	// Post is called after every request that Pre succeeded for. The response
+	// is nil if the request failed.
+	Post func(*http.Request, *http.Response) error
 }
 
 var _ http.RoundTripper = (*TransportWrapper)(nil)
@@ -16,7 +18,7 @@ func NewTransportWrapper() *TransportWrapper {
 	return &TransportWrapper{
 		Default: http.DefaultTransport,
 		Pre:     func(*http.Request) error { return nil },
-		Post:    func(*http.Response) error { return nil },
+		Post:    func(*http.Request, *http.Response) error { return nil },
 	}
 }
 
@@ -27,10 +29,12 @@ func (c *TransportWrapper) RoundTrip(req *http.Request) (*http.Response, error)
 
 	r, err := c.Default.RoundTrip(req)
 	if err != nil {
+		c.Post(req, nil)
 		return nil, err
 	}
 
-	if err := c.Post(r); err != nil {
+	if err := c.Post(req, r); err != nil {
+		r.Body.Close()
 		return nil, err
 	}
 
@@ -3,6 +3,7 @@ package httputil
 import (
 	"io"
 	"net/http"
+	"net/url"
 
 	"github.com/diamondburned/arikawa/internal/json"
 )
@@ -26,6 +27,24 @@ func WithContentType(ctype string) RequestOption {
 	}
 }
 
+func WithHeaders(headers http.Header) RequestOption {
+	return func(r *http.Request) error {
+		for key, values := range headers {
+			r.Header[key] = append(r.Header[key], values...)
+		}
+		return nil
+	}
+}
+
+// WithReason sets the X-Audit-Log-Reason header, which Discord shows in the
+// audit log. The reason is escaped, as Discord expects.
+func WithReason(reason string) RequestOption {
+	return func(r *http.Request) error {
+		r.Header.Set("X-Audit-Log-Reason", url.PathEscape(reason))
+		return nil
+	}
+}
+
 func WithSchema(schema SchemaEncoder, v interface{}) RequestOption {
 	return func(r *http.Request) error {
 		params, err := schema.Encode(v)
@@ -53,6 +72,8 @@ func WithBody(body io.ReadCloser) RequestOption {
 	}
 }
 
+// WithJSONBody encodes v as the body of the request. The body is encoded once
+// into a pooled buffer, which is read again if the request is retried.
 func WithJSONBody(json json.Driver, v interface{}) RequestOption {
 	if v == nil {
 		return func(*http.Request) error {
@@ -60,21 +81,23 @@ func WithJSONBody(json json.Driver, v interface{}) RequestOption {
 		}
 	}
 
-	var err error
-	var rp, wp = io.Pipe()
-
-	go func() {
-		err = json.EncodeStream(wp, v)
-		wp.Close()
-	}()
-
 	return func(r *http.Request) error {
-		if err != nil {
+		body := newPooledBody()
+
+		if err := json.EncodeStream(body.buf, v); err != nil {
+			body.release()
 			return err
 		}
 
+		// Encoders end with a newline, which isn't needed.
+		if b := body.buf.Bytes(); len(b) > 0 && b[len(b)-1] == '\n' {
+			body.buf.Truncate(len(b) - 1)
+		}
+
 		r.Header.Set("Content-Type", "application/json")
-		r.Body = rp
+		r.ContentLength = int64(body.buf.Len())
+		r.GetBody = body.reader
+		r.Body, _ = body.reader()
 		return nil
 	}
 }
@@ -5,6 +5,7 @@ package json
 import (
 	"encoding/json"
 	"io"
+	"io/ioutil"
 )
 
 type (
@@ -74,20 +75,93 @@ type Driver interface {
 	EncodeStream(w io.Writer, v interface{}) error
 }
 
+// Default is the driver used wherever no driver is given. It uses the driver
+// given to SetDriver, or the standard library if there's none.
 type Default struct{}
 
+// driver is the driver used by Default. It's only set before it's used.
+var driver Driver = Std{}
+
+// SetDriver sets the driver used by Default. It must be called before the
+// driver is used, as it's not guarded.
+func SetDriver(d Driver) {
+	if d == nil {
+		d = Std{}
+	}
+	driver = d
+}
+
+// GetDriver returns the driver used by Default.
+func GetDriver() Driver {
+	return driver
+}
+
 func (d Default) Marshal(v interface{}) ([]byte, error) {
-	return json.Marshal(v)
+	return driver.Marshal(v)
 }
 
 func (d Default) Unmarshal(data []byte, v interface{}) error {
-	return json.Unmarshal(data, v)
+	return driver.Unmarshal(data, v)
 }
 
 func (d Default) DecodeStream(r io.Reader, v interface{}) error {
-	return json.NewDecoder(r).Decode(v)
+	return driver.DecodeStream(r, v)
 }
 
 func (d Default) EncodeStream(w io.Writer, v interface{}) error {
+	return driver.EncodeStream(w, v)
+}
+
+// Std is the driver that uses encoding/json.
+type Std struct{}
+
+func (Std) Marshal(v interface{}) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (Std) Unmarshal(data []byte, v interface{}) error {
+	return json.Unmarshal(data, v)
+}
+
+func (Std) DecodeStream(r io.Reader, v interface{}) error {
+	return json.NewDecoder(r).Decode(v)
+}
+
+func (Std) EncodeStream(w io.Writer, v interface{}) error {
 	return json.NewEncoder(w).Encode(v)
 }
+
+// Funcs is a driver made of a Marshal and an Unmarshal function, such as the
+// ones of jsoniter or sonic. Streams are read whole before being decoded.
+type Funcs struct {
+	MarshalFunc   func(v interface{}) ([]byte, error)
+	UnmarshalFunc func(data []byte, v interface{}) error
+}
+
+func (f Funcs) Marshal(v interface{}) ([]byte, error) {
+	return f.MarshalFunc(v)
+}
+
+func (f Funcs) Unmarshal(data []byte, v interface{}) error {
+	return f.UnmarshalFunc(data, v)
+}
+
+func (f Funcs) DecodeStream(r io.Reader, v interface{}) error {
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		return err
+	}
+
+	return f.UnmarshalFunc(b, v)
+}
+
+func (f Funcs) EncodeStream(w io.Writer, v interface{}) error {
+	b, err := f.MarshalFunc(v)
+	if err != nil {
+		return err
+	}
+
+	// Encoders end values with a new line, like encoding/json's.
+	_, err = w.Write(append(b, '\n'))
+	return err
+}
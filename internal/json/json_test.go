@@ -0,0 +1,46 @@
+// +build unit
+
+package json
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestSetDriver(t *testing.T) {
+	var calls int
+	SetDriver(Funcs{
+		MarshalFunc: func(v interface{}) ([]byte, error) {
+			calls++
+			return Std{}.Marshal(v)
+		},
+		UnmarshalFunc: func(data []byte, v interface{}) error {
+			calls++
+			return Std{}.Unmarshal(data, v)
+		},
+	})
+	defer SetDriver(nil)
+
+	var buf bytes.Buffer
+	if err := (Default{}).EncodeStream(&buf, 1); err != nil {
+		t.Fatal("Failed to encode:", err)
+	}
+
+	if buf.String() != "1\n" {
+		t.Fatalf("Unexpected encoding %q", buf.String())
+	}
+
+	var i int
+	if err := (Default{}).DecodeStream(&buf, &i); err != nil {
+		t.Fatal("Failed to decode:", err)
+	}
+
+	if i != 1 || calls != 2 {
+		t.Fatalf("Driver wasn't used: got %d after %d calls", i, calls)
+	}
+
+	SetDriver(nil)
+	if _, ok := GetDriver().(Std); !ok {
+		t.Fatal("Driver wasn't reset to Std")
+	}
+}
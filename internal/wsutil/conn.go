@@ -1,11 +1,17 @@
 package wsutil
 
 import (
+	"bufio"
 	"compress/zlib"
 	"context"
+	"crypto/tls"
+	"io"
 	"io/ioutil"
+	"math"
+	"net"
 	"net/http"
 	"sync"
+	"time"
 
 	"github.com/diamondburned/arikawa/internal/json"
 	"github.com/pkg/errors"
@@ -15,6 +21,10 @@ import (
 var WSBuffer = 12
 var WSReadLimit int64 = 8192000 // 8 MiB
 
+// ErrMessageTooBig is sent as the error of an Event when a message is larger
+// than the read limit. The message is skipped, and the connection is kept.
+var ErrMessageTooBig = errors.New("Message exceeds the read limit")
+
 // Connection is an interface that abstracts around a generic Websocket driver.
 // This connection expects the driver to handle compression by itself.
 type Connection interface {
@@ -37,26 +47,86 @@ type Connection interface {
 	Close(err error) error
 }
 
+// DialOptions are the options used by Conn to dial, for environments that need
+// them. The zero value uses the defaults.
+type DialOptions struct {
+	// HandshakeTimeout is the timeout for the TLS handshake. 0 means no
+	// timeout other than the context's.
+	HandshakeTimeout time.Duration
+	// TLSConfig is the TLS config, such as for custom root CAs. Nil uses the
+	// default config.
+	TLSConfig *tls.Config
+	// KeepAlive is the period of the TCP keep-alives. 0 uses the default of
+	// 15 seconds, and a negative value disables them.
+	KeepAlive time.Duration
+}
+
+func (opts DialOptions) httpClient() *http.Client {
+	return &http.Client{
+		Transport: &http.Transport{
+			Proxy:               http.ProxyFromEnvironment,
+			DialContext:         opts.dialer().DialContext,
+			TLSClientConfig:     opts.TLSConfig,
+			TLSHandshakeTimeout: opts.HandshakeTimeout,
+		},
+	}
+}
+
+func (opts DialOptions) dialer() *net.Dialer {
+	return &net.Dialer{
+		KeepAlive: opts.KeepAlive,
+	}
+}
+
 // Conn is the default Websocket connection. It compresses all payloads using
 // zlib.
 type Conn struct {
 	Conn *websocket.Conn
 	json.Driver
 
+	// ReadLimit is the maximum size of a message after decompression. Larger
+	// messages are skipped with an ErrMessageTooBig. 0 means no limit.
+	ReadLimit int64
+	// DialOptions are used on every Dial.
+	DialOptions DialOptions
+
 	mut    sync.Mutex
 	done   chan struct{}
 	events chan Event
+
+	// client is made from the DialOptions, and made again only if they change,
+	// so that every Dial doesn't create a new Transport.
+	client     *http.Client
+	clientOpts DialOptions
+
+	// zlib is non-nil if the address asked for zlib-stream compression.
+	zlib *zlibStream
+	// binary is true if the address asked for an encoding other than JSON,
+	// such as ETF, whose messages are sent as binary.
+	binary bool
 }
 
 var _ Connection = (*Conn)(nil)
 
 func NewConn(driver json.Driver) *Conn {
 	return &Conn{
-		Driver: driver,
-		events: make(chan Event, WSBuffer),
+		Driver:    driver,
+		ReadLimit: WSReadLimit,
+		events:    make(chan Event, WSBuffer),
 	}
 }
 
+// httpClient returns the client made from the DialOptions. It must be called
+// with the mutex locked.
+func (c *Conn) httpClient() *http.Client {
+	if c.client == nil || c.clientOpts != c.DialOptions {
+		c.client = c.DialOptions.httpClient()
+		c.clientOpts = c.DialOptions
+	}
+
+	return c.client
+}
+
 func (c *Conn) Dial(ctx context.Context, addr string) error {
 	var err error
 
@@ -67,24 +137,41 @@ func (c *Conn) Dial(ctx context.Context, addr string) error {
 	defer c.mut.Unlock()
 
 	c.Conn, _, err = websocket.Dial(ctx, addr, &websocket.DialOptions{
+		HTTPClient: c.httpClient(),
 		HTTPHeader: headers,
 	})
-	c.Conn.SetReadLimit(WSReadLimit)
+	if err != nil {
+		return err
+	}
 
-	c.readLoop(c.events)
-	return err
+	// The websocket library closes the connection if a message exceeds its
+	// limit, so the limit is checked in readAll instead, where the message
+	// can be skipped.
+	c.Conn.SetReadLimit(math.MaxInt64)
+
+	c.binary = isBinaryEncoding(addr)
+
+	// The messages are sent by the zlib stream instead, once inflated.
+	if isZlibStream(addr) {
+		c.zlib = newZlibStream(c.ReadLimit, c.events)
+	}
+
+	c.readLoop(c.events, c.zlib)
+	return nil
 }
 
 func (c *Conn) Listen() <-chan Event {
 	return c.events
 }
 
-func (c *Conn) readLoop(ch chan Event) {
+// readLoop reads the messages into ch. The zlib stream is given instead of read
+// from the Conn, as Close clears it while the loop could still be running.
+func (c *Conn) readLoop(ch chan Event, stream *zlibStream) {
 	c.done = make(chan struct{})
 
 	go func() {
 		for {
-			b, err := c.readAll(context.Background())
+			b, err := c.readAll(context.Background(), stream)
 			if err != nil {
 				// Check if the error is a fatal one
 				if code := websocket.CloseStatus(err); code > -1 {
@@ -103,17 +190,45 @@ func (c *Conn) readLoop(ch chan Event) {
 				continue
 			}
 
+			// The message was written into the zlib stream.
+			if b == nil {
+				continue
+			}
+
 			ch <- Event{b, nil}
 		}
 	}()
 }
 
-func (c *Conn) readAll(ctx context.Context) ([]byte, error) {
-	t, r, err := c.Conn.Reader(ctx)
+func (c *Conn) readAll(
+	ctx context.Context, stream *zlibStream) ([]byte, error) {
+
+	t, frame, err := c.Conn.Reader(ctx)
 	if err != nil {
 		return nil, err
 	}
 
+	var r = frame
+
+	if t == websocket.MessageBinary && stream != nil {
+		if _, err := io.Copy(stream, frame); err != nil {
+			c.Conn.CloseRead(ctx)
+			return nil, err
+		}
+
+		return nil, nil
+	}
+
+	if t == websocket.MessageBinary && c.binary {
+		// Binary encodings send every message as binary, so only those with
+		// a zlib header are compressed.
+		br := bufio.NewReader(frame)
+		if b, err := br.Peek(1); err == nil && b[0] != zlibHeader {
+			t = websocket.MessageText
+		}
+		r = br
+	}
+
 	if t == websocket.MessageBinary {
 		// Probably a zlib payload
 		z, err := zlib.NewReader(r)
@@ -127,12 +242,27 @@ func (c *Conn) readAll(ctx context.Context) ([]byte, error) {
 		r = z
 	}
 
+	if c.ReadLimit > 0 {
+		// Read one more byte, to tell if the message is over the limit.
+		r = io.LimitReader(r, c.ReadLimit+1)
+	}
+
 	b, err := ioutil.ReadAll(r)
 	if err != nil {
 		c.Conn.CloseRead(ctx)
 		return nil, err
 	}
 
+	if c.ReadLimit > 0 && int64(len(b)) > c.ReadLimit {
+		// Discard the rest of the message, so the next one can be read.
+		if _, err := io.Copy(ioutil.Discard, frame); err != nil {
+			c.Conn.CloseRead(ctx)
+			return nil, err
+		}
+
+		return nil, ErrMessageTooBig
+	}
+
 	return b, nil
 }
 
@@ -140,16 +270,29 @@ func (c *Conn) Send(ctx context.Context, b []byte) error {
 	c.mut.Lock()
 	defer c.mut.Unlock()
 
-	// TODO: zlib stream
-	return c.Conn.Write(ctx, websocket.MessageText, b)
+	var t = websocket.MessageText
+	if c.binary {
+		t = websocket.MessageBinary
+	}
+
+	return c.Conn.Write(ctx, t, b)
 }
 
 func (c *Conn) Close(err error) error {
 	// Wait for the read loop to exit after exiting.
 	defer func() {
+		// Stop the zlib stream first, as the read loop could be blocked on
+		// writing to it. The read loop has its own reference, so the field
+		// is only cleared once it exited.
+		if c.zlib != nil {
+			c.zlib.Close()
+		}
+
 		<-c.done
 		close(c.done)
 
+		c.zlib = nil
+
 		// Set the connection to nil.
 		c.Conn = nil
 
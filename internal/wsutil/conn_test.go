@@ -0,0 +1,53 @@
+// +build unit
+
+package wsutil
+
+import (
+	"crypto/tls"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/diamondburned/arikawa/internal/json"
+)
+
+func TestDialOptions(t *testing.T) {
+	var opts = DialOptions{
+		HandshakeTimeout: time.Second,
+		TLSConfig:        &tls.Config{ServerName: "gateway.discord.gg"},
+		KeepAlive:        -1,
+	}
+
+	transport, ok := opts.httpClient().Transport.(*http.Transport)
+	if !ok {
+		t.Fatal("Unexpected transport")
+	}
+
+	if transport.TLSClientConfig != opts.TLSConfig {
+		t.Fatal("TLS config wasn't used")
+	}
+	if transport.TLSHandshakeTimeout != time.Second {
+		t.Fatal("Unexpected handshake timeout:", transport.TLSHandshakeTimeout)
+	}
+	if transport.Proxy == nil || transport.DialContext == nil {
+		t.Fatal("The proxy or the dialer weren't set")
+	}
+	if opts.dialer().KeepAlive != -1 {
+		t.Fatal("Keep-alives weren't disabled")
+	}
+}
+
+func TestConnHTTPClient(t *testing.T) {
+	c := NewConn(json.Default{})
+
+	var client = c.httpClient()
+	if c.httpClient() != client {
+		t.Fatal("The client was made again without a change")
+	}
+
+	c.DialOptions.HandshakeTimeout = time.Second
+
+	if c.httpClient() == client {
+		t.Fatal("The client wasn't made again after a change")
+	}
+}
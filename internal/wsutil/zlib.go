@@ -0,0 +1,211 @@
+package wsutil
+
+import (
+	"bytes"
+	"compress/zlib"
+	"net/url"
+
+	"github.com/pkg/errors"
+)
+
+// zlibHeader is the first byte of a zlib payload with the default window size.
+const zlibHeader = 0x78
+
+// isZlibStream returns true if the address asks for zlib-stream transport
+// compression.
+func isZlibStream(addr string) bool {
+	return queryParam(addr, "compress") == "zlib-stream"
+}
+
+// isBinaryEncoding returns true if the address asks for an encoding other than
+// JSON.
+func isBinaryEncoding(addr string) bool {
+	var encoding = queryParam(addr, "encoding")
+	return encoding != "" && encoding != "json"
+}
+
+func queryParam(addr, key string) string {
+	u, err := url.Parse(addr)
+	if err != nil {
+		return ""
+	}
+
+	return u.Query().Get(key)
+}
+
+// zlibSuffix ends every message of a zlib-stream, as Discord flushes the zlib
+// context with Z_SYNC_FLUSH after each one.
+var zlibSuffix = []byte{0x00, 0x00, 0xff, 0xff}
+
+// zlibStream inflates the messages of a connection with zlib-stream transport
+// compression. Unlike zlib payload compression, all messages share the same
+// zlib context, and a message could be split into multiple frames, so the
+// frames are buffered until the zlib suffix, and the messages are inflated one
+// after another by the same zlib reader. The messages aren't parsed, so any
+// encoding works.
+type zlibStream struct {
+	// pending is the message being received. It's only used by Write.
+	pending []byte
+
+	msgs    chan []byte
+	closing chan struct{}
+	done    chan struct{}
+
+	// The current message and its inflated data, only used by the inflater.
+	msg     []byte
+	out     []byte
+	tooBig  bool
+	limit   int64
+	events  chan<- Event
+	started bool
+}
+
+var errZlibClosed = errors.New("zlib stream closed")
+
+// newZlibStream starts inflating the frames written to the stream, and sends
+// the messages into ch.
+func newZlibStream(readLimit int64, ch chan<- Event) *zlibStream {
+	s := &zlibStream{
+		msgs:    make(chan []byte),
+		closing: make(chan struct{}),
+		done:    make(chan struct{}),
+		limit:   readLimit,
+		events:  ch,
+	}
+
+	go func() {
+		defer close(s.done)
+
+		err := s.inflate()
+		if err == errZlibClosed {
+			return
+		}
+
+		s.send(Event{nil, errors.Wrap(err, "Failed to inflate zlib stream")})
+	}()
+
+	return s
+}
+
+func (s *zlibStream) inflate() error {
+	// The reader reads from the stream itself, which only gives it the bytes
+	// of one message at a time.
+	z, err := zlib.NewReader(s)
+	if err != nil {
+		return err
+	}
+
+	var buf = make([]byte, 32*1024)
+
+	for {
+		n, err := z.Read(buf)
+		if n > 0 {
+			s.output(buf[:n])
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// output adds inflated data to the current message, unless the message is
+// already over the read limit.
+func (s *zlibStream) output(b []byte) {
+	if s.tooBig {
+		return
+	}
+
+	if s.limit > 0 && int64(len(s.out)+len(b)) > s.limit {
+		s.out = nil
+		s.tooBig = true
+		return
+	}
+
+	s.out = append(s.out, b...)
+}
+
+// Read gives the zlib reader the bytes of the current message. Once they're
+// all read, the zlib context was flushed, so the message was entirely inflated
+// and is sent before waiting for the next one.
+func (s *zlibStream) Read(b []byte) (int, error) {
+	if err := s.next(); err != nil {
+		return 0, err
+	}
+
+	n := copy(b, s.msg)
+	s.msg = s.msg[n:]
+	return n, nil
+}
+
+// ReadByte is implemented so that the zlib reader doesn't read ahead into the
+// next message.
+func (s *zlibStream) ReadByte() (byte, error) {
+	if err := s.next(); err != nil {
+		return 0, err
+	}
+
+	c := s.msg[0]
+	s.msg = s.msg[1:]
+	return c, nil
+}
+
+func (s *zlibStream) next() error {
+	if len(s.msg) > 0 {
+		return nil
+	}
+
+	// The first message has no inflated data before it.
+	if s.started {
+		switch {
+		case s.tooBig:
+			s.send(Event{nil, ErrMessageTooBig})
+		case len(s.out) > 0:
+			s.send(Event{s.out, nil})
+		}
+
+		s.out = nil
+		s.tooBig = false
+	}
+
+	select {
+	case s.msg = <-s.msgs:
+		s.started = true
+		return nil
+	case <-s.closing:
+		return errZlibClosed
+	}
+}
+
+func (s *zlibStream) send(ev Event) {
+	select {
+	case s.events <- ev:
+	case <-s.closing:
+	}
+}
+
+// Write writes a compressed frame into the stream. The frames are buffered
+// until the end of the message.
+func (s *zlibStream) Write(b []byte) (int, error) {
+	s.pending = append(s.pending, b...)
+
+	if !bytes.HasSuffix(s.pending, zlibSuffix) {
+		return len(b), nil
+	}
+
+	var msg = s.pending
+	s.pending = nil
+
+	select {
+	case s.msgs <- msg:
+		return len(b), nil
+	case <-s.closing:
+		return 0, errZlibClosed
+	}
+}
+
+// Close stops inflating, and waits for the inflater to exit. Writes after
+// Close fail.
+func (s *zlibStream) Close() {
+	close(s.closing)
+	<-s.done
+}
@@ -0,0 +1,81 @@
+// +build unit
+
+package wsutil
+
+import (
+	"bytes"
+	"compress/zlib"
+	"testing"
+)
+
+func TestIsZlibStream(t *testing.T) {
+	if !isZlibStream("wss://gateway.discord.gg?v=6&compress=zlib-stream") {
+		t.Fatal("zlib-stream wasn't detected")
+	}
+	if isZlibStream("wss://gateway.discord.gg?v=6") {
+		t.Fatal("zlib-stream was detected without the parameter")
+	}
+}
+
+func TestIsBinaryEncoding(t *testing.T) {
+	if !isBinaryEncoding("wss://gateway.discord.gg?v=6&encoding=etf") {
+		t.Fatal("etf wasn't detected as binary")
+	}
+	if isBinaryEncoding("wss://gateway.discord.gg?v=6&encoding=json") {
+		t.Fatal("json was detected as binary")
+	}
+}
+
+func TestZlibStream(t *testing.T) {
+	// The messages aren't parsed, so they could be ETF too.
+	var messages = []string{
+		`{"op":10}`, "\x83t\x00\x00\x00\x01", `{"op":11}`, "over the read limit",
+	}
+
+	// All messages share the same zlib context, and are flushed one by one.
+	var compressed [][]byte
+	var buf bytes.Buffer
+	z := zlib.NewWriter(&buf)
+
+	for _, msg := range messages {
+		z.Write([]byte(msg))
+		z.Flush()
+
+		compressed = append(compressed, append([]byte(nil), buf.Bytes()...))
+		buf.Reset()
+	}
+
+	var ch = make(chan Event, len(messages)+1)
+	// Only the last message is over the read limit.
+	var s = newZlibStream(int64(len(messages[2])), ch)
+
+	// The second message is split into 2 frames.
+	var half = len(compressed[1]) / 2
+	var frames = [][]byte{
+		compressed[0], compressed[1][:half], compressed[1][half:],
+		compressed[2], compressed[3],
+	}
+
+	for _, frame := range frames {
+		if _, err := s.Write(frame); err != nil {
+			t.Fatal("Failed to write frame:", err)
+		}
+	}
+
+	for _, msg := range messages[:3] {
+		ev := <-ch
+		if ev.Error != nil {
+			t.Fatal("Unexpected error:", ev.Error)
+		}
+		if string(ev.Data) != msg {
+			t.Fatalf("Unexpected message %q, expected %q", ev.Data, msg)
+		}
+	}
+
+	if ev := <-ch; ev.Error != ErrMessageTooBig {
+		t.Fatal("Unexpected error for a message over the limit:", ev.Error)
+	}
+
+	// Closing doesn't block on the inflater.
+	s.Close()
+}
@@ -0,0 +1,107 @@
+// Package logger provides the structured Logger that the Gateway, Session and
+// State can log through, instead of their func(error) callbacks.
+//
+// Adapters are provided for the standard log package and for zap's
+// SugaredLogger. Other libraries can be adapted with Func; for example,
+// zerolog:
+//
+//	l := logger.Func(func(lvl logger.Level, msg string, f ...logger.Field) {
+//		e := zl.WithLevel(zerolog.Level(lvl))
+//		for _, field := range f {
+//			e = e.Interface(field.Key, field.Value)
+//		}
+//		e.Msg(msg)
+//	})
+//	s.SetLogger(l)
+package logger
+
+import "fmt"
+
+type Level uint8
+
+const (
+	DebugLevel Level = iota
+	InfoLevel
+	WarnLevel
+	ErrorLevel
+)
+
+func (lvl Level) String() string {
+	switch lvl {
+	case DebugLevel:
+		return "debug"
+	case InfoLevel:
+		return "info"
+	case WarnLevel:
+		return "warn"
+	case ErrorLevel:
+		return "error"
+	default:
+		return fmt.Sprintf("Level(%d)", lvl)
+	}
+}
+
+// Field is a key-value pair that gives context to a log message, such as the
+// component or the shard that logged it.
+type Field struct {
+	Key   string
+	Value interface{}
+}
+
+// F creates a Field.
+func F(key string, value interface{}) Field {
+	return Field{key, value}
+}
+
+type Logger interface {
+	Debug(msg string, fields ...Field)
+	Info(msg string, fields ...Field)
+	Warn(msg string, fields ...Field)
+	Error(msg string, fields ...Field)
+}
+
+// Func is a Logger that calls itself with the level of each message.
+type Func func(lvl Level, msg string, fields ...Field)
+
+var _ Logger = Func(nil)
+
+func (fn Func) Debug(msg string, fields ...Field) {
+	fn(DebugLevel, msg, fields...)
+}
+
+func (fn Func) Info(msg string, fields ...Field) {
+	fn(InfoLevel, msg, fields...)
+}
+
+func (fn Func) Warn(msg string, fields ...Field) {
+	fn(WarnLevel, msg, fields...)
+}
+
+func (fn Func) Error(msg string, fields ...Field) {
+	fn(ErrorLevel, msg, fields...)
+}
+
+// Nop discards everything.
+var Nop Logger = Func(func(Level, string, ...Field) {})
+
+// Log logs the message at the given level.
+func Log(l Logger, lvl Level, msg string, fields ...Field) {
+	switch lvl {
+	case DebugLevel:
+		l.Debug(msg, fields...)
+	case InfoLevel:
+		l.Info(msg, fields...)
+	case WarnLevel:
+		l.Warn(msg, fields...)
+	default:
+		l.Error(msg, fields...)
+	}
+}
+
+// ErrorFunc returns a callback for the ErrorLog-style fields, which logs the
+// errors at the given level with the given fields.
+func ErrorFunc(l Logger, lvl Level, fields ...Field) func(error) {
+	return func(err error) {
+		Log(l, lvl, err.Error(), fields...)
+	}
+}
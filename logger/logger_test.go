@@ -0,0 +1,54 @@
+// +build unit
+
+package logger
+
+import (
+	"bytes"
+	"errors"
+	"log"
+	"reflect"
+	"testing"
+)
+
+func TestStd(t *testing.T) {
+	var buf bytes.Buffer
+	var l = NewStd(log.New(&buf, "", 0), InfoLevel)
+
+	l.Debug("hidden")
+	l.Warn("Failed to reconnect", F("component", "gateway"), F("shard", 1))
+
+	const expect = "[WARN] Failed to reconnect component=gateway shard=1\n"
+	if s := buf.String(); s != expect {
+		t.Fatalf("Unexpected output: %q", s)
+	}
+}
+
+type sugared struct {
+	calls []interface{}
+}
+
+func (s *sugared) log(lvl, msg string, kvs []interface{}) {
+	s.calls = append(s.calls, append([]interface{}{lvl, msg}, kvs...))
+}
+
+func (s *sugared) Debugw(msg string, kv ...interface{}) { s.log("d", msg, kv) }
+func (s *sugared) Infow(msg string, kv ...interface{})  { s.log("i", msg, kv) }
+func (s *sugared) Warnw(msg string, kv ...interface{})  { s.log("w", msg, kv) }
+func (s *sugared) Errorw(msg string, kv ...interface{}) { s.log("e", msg, kv) }
+
+func TestZap(t *testing.T) {
+	var s sugared
+	var l = NewZap(&s)
+
+	l.Info("a")
+	ErrorFunc(l, ErrorLevel, F("component", "state"))(errors.New("b"))
+
+	var expect = []interface{}{
+		[]interface{}{"i", "a"},
+		[]interface{}{"e", "b", "component", "state"},
+	}
+
+	if !reflect.DeepEqual(s.calls, expect) {
+		t.Fatalf("Unexpected calls: %v", s.calls)
+	}
+}
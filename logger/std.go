@@ -0,0 +1,36 @@
+package logger
+
+import (
+	"fmt"
+	"log"
+	"strings"
+)
+
+// NewStd creates a Logger that prints messages at or above min to l, with the
+// level in front and the fields after, like so:
+//
+//	[WARN] Failed to reconnect component=gateway
+//
+// If l is nil, the standard logger of the log package is used.
+func NewStd(l *log.Logger, min Level) Logger {
+	return Func(func(lvl Level, msg string, fields ...Field) {
+		if lvl < min {
+			return
+		}
+
+		var b strings.Builder
+		b.WriteString("[" + strings.ToUpper(lvl.String()) + "] " + msg)
+
+		for _, f := range fields {
+			fmt.Fprintf(&b, " %s=%v", f.Key, f.Value)
+		}
+
+		// 3 skips this function and the Func method, so Lshortfile shows the
+		// caller.
+		if l == nil {
+			log.Output(3, b.String())
+		} else {
+			l.Output(3, b.String())
+		}
+	})
+}
@@ -0,0 +1,34 @@
+package logger
+
+// Sugared is the part of zap's *SugaredLogger that NewZap needs. It's an
+// interface so that zap isn't a dependency of arikawa.
+type Sugared interface {
+	Debugw(msg string, keysAndValues ...interface{})
+	Infow(msg string, keysAndValues ...interface{})
+	Warnw(msg string, keysAndValues ...interface{})
+	Errorw(msg string, keysAndValues ...interface{})
+}
+
+// NewZap creates a Logger that logs to a zap SugaredLogger:
+//
+//	l := logger.NewZap(zapLogger.Sugar())
+func NewZap(s Sugared) Logger {
+	return Func(func(lvl Level, msg string, fields ...Field) {
+		// Flatten the fields into alternating keys and values.
+		var kvs = make([]interface{}, 0, len(fields)*2)
+		for _, f := range fields {
+			kvs = append(kvs, f.Key, f.Value)
+		}
+
+		switch lvl {
+		case DebugLevel:
+			s.Debugw(msg, kvs...)
+		case InfoLevel:
+			s.Infow(msg, kvs...)
+		case WarnLevel:
+			s.Warnw(msg, kvs...)
+		default:
+			s.Errorw(msg, kvs...)
+		}
+	})
+}
@@ -0,0 +1,74 @@
+// Package metrics provides the Recorder interface, which the API client, the
+// Gateway and the State report to if one is set. It has no dependencies, so a
+// Recorder for Prometheus or anything else is implemented outside of arikawa:
+//
+//	type prom struct{ latency *prometheus.HistogramVec }
+//
+//	func (p prom) Request(method, route string, status int, d time.Duration) {
+//		p.latency.WithLabelValues(method, route, strconv.Itoa(status)).
+//			Observe(d.Seconds())
+//	}
+package metrics
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+type Recorder interface {
+	// Request is called after every REST request, with the route of the
+	// request as given by Route. The status is 0 if there's no response.
+	// The latency doesn't include the time spent waiting for rate limits.
+	Request(method, route string, status int, latency time.Duration)
+	// Event is called for every dispatched Gateway event, with its name,
+	// such as "MESSAGE_CREATE".
+	Event(shardID int, name string)
+	// Reconnect is called every time a Gateway reconnects.
+	Reconnect(shardID int)
+	// StoreAccess is called every time the State looks up a resource, such
+	// as "channel", in its Store. Hit is false if it has to be fetched from
+	// the API instead.
+	StoreAccess(resource string, hit bool)
+}
+
+// Nop is a Recorder that does nothing. It could be embedded in Recorders that
+// only record some of the metrics.
+type Nop struct{}
+
+var _ Recorder = Nop{}
+
+func (Nop) Request(string, string, int, time.Duration) {}
+func (Nop) Event(int, string)                          {}
+func (Nop) Reconnect(int)                              {}
+func (Nop) StoreAccess(string, bool)                   {}
+
+// Route returns the path without its IDs, tokens and query, so that it can be
+// used as a label:
+//
+//	/channels/1234/messages/5678 -> /channels/:id/messages/:id
+//	/webhooks/1234/abcd          -> /webhooks/:id/:token
+//
+// The tokens of webhooks and interactions are secrets, so they must never end
+// up in labels.
+func Route(path string) string {
+	path = strings.SplitN(path, "?", 2)[0]
+
+	parts := strings.Split(path, "/")
+	for i, part := range parts {
+		if _, err := strconv.ParseUint(part, 10, 64); err == nil {
+			parts[i] = ":id"
+
+			// The segment after a webhook or interaction ID is its token.
+			if i > 0 && i+1 < len(parts) && hasToken(parts[i-1]) {
+				parts[i+1] = ":token"
+			}
+		}
+	}
+
+	return strings.Join(parts, "/")
+}
+
+func hasToken(resource string) bool {
+	return resource == "webhooks" || resource == "interactions"
+}
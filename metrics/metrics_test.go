@@ -0,0 +1,25 @@
+// +build unit
+
+package metrics
+
+import "testing"
+
+func TestRoute(t *testing.T) {
+	var tests = map[string]string{
+		"/api/v6/channels/1234/messages/5678": "/api/v6/channels/:id/messages/:id",
+		"/api/v6/users/@me/guilds?limit=100":  "/api/v6/users/@me/guilds",
+		"/api/v6/gateway/bot":                 "/api/v6/gateway/bot",
+
+		// Tokens are secrets, even if they happen to be numbers.
+		"/webhooks/1234/abc-123":                  "/webhooks/:id/:token",
+		"/webhooks/1234":                          "/webhooks/:id",
+		"/webhooks/1234/123/messages/5678?wait=1": "/webhooks/:id/:token/messages/:id",
+		"/interactions/1234/abc/callback":         "/interactions/:id/:token/callback",
+	}
+
+	for path, expect := range tests {
+		if route := Route(path); route != expect {
+			t.Errorf("Route(%q) = %q, expected %q", path, route, expect)
+		}
+	}
+}
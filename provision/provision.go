@@ -0,0 +1,486 @@
+// Package provision converges a guild's roles and channels to a declarative
+// Spec. It is useful for bots that manage guilds from templates.
+//
+// Converge first plans the needed actions by comparing the Spec to the guild,
+// then applies them in order: roles, categories, other channels, and finally
+// the deletions. The planned actions are returned, so a dry run can be used to
+// print what would change.
+package provision
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/diamondburned/arikawa/api"
+	"github.com/diamondburned/arikawa/discord"
+	"github.com/diamondburned/arikawa/internal/json"
+	"github.com/pkg/errors"
+)
+
+type Op uint8
+
+const (
+	Create Op = iota
+	Modify
+	Delete
+)
+
+func (op Op) String() string {
+	switch op {
+	case Create:
+		return "create"
+	case Modify:
+		return "modify"
+	case Delete:
+		return "delete"
+	default:
+		return "unknown"
+	}
+}
+
+type Kind uint8
+
+const (
+	RoleKind Kind = iota
+	ChannelKind
+)
+
+func (k Kind) String() string {
+	switch k {
+	case RoleKind:
+		return "role"
+	case ChannelKind:
+		return "channel"
+	default:
+		return "unknown"
+	}
+}
+
+// Action is a single change needed to converge the guild.
+type Action struct {
+	Op   Op
+	Kind Kind
+	Name string
+
+	// ID is the ID of the existing role or channel. It is 0 for Create.
+	ID discord.Snowflake
+	// Changes contains the names of the fields that differ, for Modify.
+	Changes []string
+
+	role    *RoleSpec
+	channel *ChannelSpec
+}
+
+// String formats the action to be human-readable, which is useful for dry
+// runs.
+func (a Action) String() string {
+	var s = a.Op.String() + " " + a.Kind.String() + " " + a.Name
+	if len(a.Changes) > 0 {
+		s += " (" + strings.Join(a.Changes, ", ") + ")"
+	}
+	return s
+}
+
+// Converge fetches the guild's roles and channels and converges them to the
+// spec. If dryRun is true, nothing is changed. The actions, planned or
+// applied, are returned. If an action fails, the actions applied before it and
+// the error are returned.
+func Converge(c *api.Client, guildID discord.Snowflake,
+	spec Spec, dryRun bool) ([]Action, error) {
+
+	roles, err := c.Roles(guildID)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to get roles")
+	}
+
+	channels, err := c.Channels(guildID)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to get channels")
+	}
+
+	var actions = Plan(guildID, roles, channels, spec)
+	if dryRun {
+		return actions, nil
+	}
+
+	var a = applier{
+		client:     c,
+		guildID:    guildID,
+		roles:      map[string]discord.Snowflake{},
+		categories: map[string]discord.Snowflake{},
+	}
+
+	for _, r := range roles {
+		a.roles[roleName(guildID, r)] = r.ID
+	}
+	for _, ch := range channels {
+		if ch.Type == discord.GuildCategory {
+			a.categories[ch.Name] = ch.ID
+		}
+	}
+
+	for i, action := range actions {
+		if err := a.apply(action); err != nil {
+			return actions[:i], errors.Wrapf(err, "Failed to %s", action)
+		}
+	}
+
+	return actions, nil
+}
+
+// Plan compares the given roles and channels to the spec and returns the
+// actions needed to converge them, without changing anything.
+func Plan(guildID discord.Snowflake,
+	roles []discord.Role, channels []discord.Channel, spec Spec) []Action {
+
+	var actions []Action
+
+	var roleIDs = map[string]discord.Snowflake{}
+	var existingRoles = map[string]discord.Role{}
+	for _, r := range roles {
+		var name = roleName(guildID, r)
+		roleIDs[name] = r.ID
+		existingRoles[name] = r
+	}
+
+	var wantRoles = map[string]bool{}
+	for i := range spec.Roles {
+		var rs = &spec.Roles[i]
+		wantRoles[rs.Name] = true
+
+		r, ok := existingRoles[rs.Name]
+		if !ok {
+			actions = append(actions, Action{
+				Op: Create, Kind: RoleKind, Name: rs.Name, role: rs,
+			})
+			continue
+		}
+
+		if changes := diffRole(r, *rs); len(changes) > 0 {
+			actions = append(actions, Action{
+				Op: Modify, Kind: RoleKind, Name: rs.Name, ID: r.ID,
+				Changes: changes, role: rs,
+			})
+		}
+	}
+
+	// Categories have to be created before the channels in them.
+	var specs = make([]*ChannelSpec, len(spec.Channels))
+	for i := range spec.Channels {
+		specs[i] = &spec.Channels[i]
+	}
+	sort.SliceStable(specs, func(i, j int) bool {
+		return specs[i].Type == discord.GuildCategory &&
+			specs[j].Type != discord.GuildCategory
+	})
+
+	var categories = map[discord.Snowflake]string{}
+	for _, ch := range channels {
+		if ch.Type == discord.GuildCategory {
+			categories[ch.ID] = ch.Name
+		}
+	}
+
+	var wantChannels = map[channelKey]bool{}
+	for _, cs := range specs {
+		var key = channelKey{cs.Name, cs.Type}
+		wantChannels[key] = true
+
+		ch, ok := findChannel(channels, key)
+		if !ok {
+			actions = append(actions, Action{
+				Op: Create, Kind: ChannelKind, Name: cs.Name, channel: cs,
+			})
+			continue
+		}
+
+		var changes = diffChannel(ch, *cs, roleIDs, categories)
+		if len(changes) > 0 {
+			actions = append(actions, Action{
+				Op: Modify, Kind: ChannelKind, Name: cs.Name, ID: ch.ID,
+				Changes: changes, channel: cs,
+			})
+		}
+	}
+
+	if !spec.Prune {
+		return actions
+	}
+
+	// Delete channels before categories, so nothing gets orphaned midway.
+	var deletes []discord.Channel
+	for _, ch := range channels {
+		if !wantChannels[channelKey{ch.Name, ch.Type}] {
+			deletes = append(deletes, ch)
+		}
+	}
+	sort.SliceStable(deletes, func(i, j int) bool {
+		return deletes[i].Type != discord.GuildCategory &&
+			deletes[j].Type == discord.GuildCategory
+	})
+	for _, ch := range deletes {
+		actions = append(actions, Action{
+			Op: Delete, Kind: ChannelKind, Name: ch.Name, ID: ch.ID,
+		})
+	}
+
+	for _, r := range roles {
+		if r.Managed || r.ID == guildID || wantRoles[r.Name] {
+			continue
+		}
+		actions = append(actions, Action{
+			Op: Delete, Kind: RoleKind, Name: r.Name, ID: r.ID,
+		})
+	}
+
+	return actions
+}
+
+type channelKey struct {
+	Name string
+	Type discord.ChannelType
+}
+
+func findChannel(
+	channels []discord.Channel, key channelKey) (discord.Channel, bool) {
+
+	for _, ch := range channels {
+		if ch.Name == key.Name && ch.Type == key.Type {
+			return ch, true
+		}
+	}
+	return discord.Channel{}, false
+}
+
+// roleName returns the name the spec uses for the role, which is Everyone for
+// the @everyone role.
+func roleName(guildID discord.Snowflake, r discord.Role) string {
+	if r.ID == guildID {
+		return Everyone
+	}
+	return r.Name
+}
+
+func diffRole(r discord.Role, rs RoleSpec) []string {
+	var changes []string
+
+	if r.Color != rs.Color {
+		changes = append(changes, "color")
+	}
+	if r.Hoist != rs.Hoist {
+		changes = append(changes, "hoist")
+	}
+	if r.Mentionable != rs.Mentionable {
+		changes = append(changes, "mentionable")
+	}
+	if r.Permissions != rs.Permissions {
+		changes = append(changes, "permissions")
+	}
+
+	return changes
+}
+
+func diffChannel(ch discord.Channel, cs ChannelSpec,
+	roles map[string]discord.Snowflake,
+	categories map[discord.Snowflake]string) []string {
+
+	var changes []string
+
+	if ch.Topic != cs.Topic {
+		changes = append(changes, "topic")
+	}
+	if ch.NSFW != cs.NSFW {
+		changes = append(changes, "nsfw")
+	}
+	if categories[ch.CategoryID] != cs.Category {
+		changes = append(changes, "category")
+	}
+
+	if len(cs.Permissions) > 0 {
+		want, ok := resolveOverwrites(cs.Permissions, roles)
+		if !ok || !sameOverwrites(ch.Permissions, want) {
+			changes = append(changes, "permissions")
+		}
+	}
+
+	return changes
+}
+
+// resolveOverwrites turns the overwrite specs into overwrites. It returns false
+// if any of the roles don't exist (yet).
+func resolveOverwrites(specs []OverwriteSpec,
+	roles map[string]discord.Snowflake) ([]discord.Overwrite, bool) {
+
+	var overwrites = make([]discord.Overwrite, 0, len(specs))
+	var ok = true
+
+	for _, o := range specs {
+		id, found := roles[o.Role]
+		if !found {
+			ok = false
+			continue
+		}
+
+		overwrites = append(overwrites, discord.Overwrite{
+			ID:    id,
+			Type:  discord.OverwriteRole,
+			Allow: o.Allow,
+			Deny:  o.Deny,
+		})
+	}
+
+	return overwrites, ok
+}
+
+func sameOverwrites(have, want []discord.Overwrite) bool {
+	if len(have) != len(want) {
+		return false
+	}
+
+	var m = make(map[discord.Snowflake]discord.Overwrite, len(have))
+	for _, o := range have {
+		m[o.ID] = o
+	}
+
+	for _, o := range want {
+		h, ok := m[o.ID]
+		if !ok || h.Type != o.Type || h.Allow != o.Allow || h.Deny != o.Deny {
+			return false
+		}
+	}
+
+	return true
+}
+
+// applier applies actions, keeping track of the IDs of created roles and
+// categories so later actions can refer to them.
+type applier struct {
+	client  *api.Client
+	guildID discord.Snowflake
+
+	roles      map[string]discord.Snowflake
+	categories map[string]discord.Snowflake
+}
+
+func (a *applier) apply(action Action) error {
+	switch action.Kind {
+	case RoleKind:
+		return a.applyRole(action)
+	case ChannelKind:
+		return a.applyChannel(action)
+	default:
+		return errors.New("Unknown action kind")
+	}
+}
+
+func (a *applier) applyRole(action Action) error {
+	if action.Op == Delete {
+		return a.client.DeleteRole(a.guildID, action.ID)
+	}
+
+	var rs = action.role
+
+	if action.Op == Modify {
+		// Only send what changed, with optional fields so that they can be
+		// turned off.
+		var data api.ModifyRoleData
+
+		for _, change := range action.Changes {
+			switch change {
+			case "color":
+				data.Color = &rs.Color
+			case "hoist":
+				data.Hoist = &rs.Hoist
+			case "mentionable":
+				data.Mentionable = &rs.Mentionable
+			case "permissions":
+				data.Permissions = &rs.Permissions
+			}
+		}
+
+		_, err := a.client.ModifyRole(a.guildID, action.ID, data)
+		return err
+	}
+
+	var data = api.AnyRoleData{
+		Name:        rs.Name,
+		Color:       rs.Color,
+		Hoist:       rs.Hoist,
+		Mentionable: rs.Mentionable,
+		Permissions: rs.Permissions,
+	}
+
+	r, err := a.client.CreateRole(a.guildID, data)
+	if err != nil {
+		return err
+	}
+
+	a.roles[rs.Name] = r.ID
+	return nil
+}
+
+func (a *applier) applyChannel(action Action) error {
+	if action.Op == Delete {
+		return a.client.DeleteChannel(action.ID)
+	}
+
+	var cs = action.channel
+
+	var categoryID discord.Snowflake
+	if cs.Category != "" {
+		id, ok := a.categories[cs.Category]
+		if !ok {
+			return errors.New("Unknown category " + cs.Category)
+		}
+		categoryID = id
+	}
+
+	var overwrites []discord.Overwrite
+	if len(cs.Permissions) > 0 {
+		o, ok := resolveOverwrites(cs.Permissions, a.roles)
+		if !ok {
+			return errors.New("Overwrite refers to an unknown role")
+		}
+		overwrites = o
+	}
+
+	if action.Op == Modify {
+		// Only send what changed, as not all fields apply to all channel
+		// types.
+		var data = api.ModifyChannelData{ChannelID: action.ID}
+
+		for _, change := range action.Changes {
+			switch change {
+			case "topic":
+				data.Topic = json.String(cs.Topic)
+			case "nsfw":
+				data.NSFW = &cs.NSFW
+			case "category":
+				// A category of 0 is sent as null, which removes it.
+				data.CategoryID = &categoryID
+			case "permissions":
+				data.Permissions = overwrites
+			}
+		}
+
+		return a.client.ModifyChannel(data)
+	}
+
+	ch, err := a.client.CreateChannel(a.guildID, api.CreateChannelData{
+		Name:        cs.Name,
+		Topic:       cs.Topic,
+		Type:        cs.Type,
+		NSFW:        cs.NSFW,
+		Permissions: overwrites,
+		CategoryID:  categoryID,
+	})
+	if err != nil {
+		return err
+	}
+
+	if ch.Type == discord.GuildCategory {
+		a.categories[ch.Name] = ch.ID
+	}
+
+	return nil
+}
@@ -0,0 +1,122 @@
+// +build unit
+
+package provision
+
+import (
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/diamondburned/arikawa/api"
+	"github.com/diamondburned/arikawa/discord"
+)
+
+type roundTripFunc func(r *http.Request) *http.Response
+
+func (f roundTripFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r), nil
+}
+
+func TestPlan(t *testing.T) {
+	const guildID = 1
+
+	var roles = []discord.Role{
+		{ID: guildID, Name: "@everyone", Permissions: 0},
+		{ID: 2, Name: "Mods", Hoist: true},
+		{ID: 3, Name: "Old"},
+		{ID: 4, Name: "Bot", Managed: true},
+	}
+
+	var channels = []discord.Channel{
+		{ID: 10, Name: "general", Type: discord.GuildText, Topic: "hi"},
+		{ID: 11, Name: "random", Type: discord.GuildText},
+	}
+
+	var spec = Spec{
+		Roles: []RoleSpec{
+			{Name: Everyone, Permissions: discord.PermissionSendMessages},
+			{Name: "Mods", Hoist: true},
+			{Name: "Members"},
+		},
+		Channels: []ChannelSpec{
+			{Name: "general", Type: discord.GuildText, Topic: "hello",
+				Category: "Text"},
+			{Name: "Text", Type: discord.GuildCategory},
+		},
+		Prune: true,
+	}
+
+	var expect = []string{
+		"modify role @everyone (permissions)",
+		"create role Members",
+		"create channel Text",
+		"modify channel general (topic, category)",
+		"delete channel random",
+		"delete role Old",
+	}
+
+	var actions = Plan(guildID, roles, channels, spec)
+	if len(actions) != len(expect) {
+		t.Fatalf("Unexpected actions: %v", actions)
+	}
+
+	for i, action := range actions {
+		if s := action.String(); s != expect[i] {
+			t.Fatalf("Unexpected action %d %q, expected %q", i, s, expect[i])
+		}
+	}
+}
+
+func TestApplyZeroValues(t *testing.T) {
+	var requests []string
+
+	client := api.NewClient("")
+	client.Client.Client.Transport = roundTripFunc(
+		func(r *http.Request) *http.Response {
+			body, _ := ioutil.ReadAll(r.Body)
+			requests = append(requests, strings.TrimSpace(string(body)))
+
+			return &http.Response{
+				StatusCode: 204,
+				Header:     http.Header{},
+				Body:       ioutil.NopCloser(strings.NewReader("")),
+			}
+		},
+	)
+
+	var a = applier{client: client, guildID: 1}
+
+	// Turning hoist off and removing the category must be sent, as they'd be
+	// planned again on every run otherwise.
+	var actions = []Action{{
+		Op: Modify, Kind: RoleKind, ID: 2,
+		Changes: []string{"hoist", "permissions"},
+		role:    &RoleSpec{Name: "Mods"},
+	}, {
+		Op: Modify, Kind: ChannelKind, ID: 10,
+		Changes: []string{"category"},
+		channel: &ChannelSpec{Name: "general", Type: discord.GuildText},
+	}}
+
+	for _, action := range actions {
+		if err := a.apply(action); err != nil {
+			t.Fatal("Failed to apply action:", err)
+		}
+	}
+
+	var expect = []string{
+		`{"hoist":false,"permissions":0}`,
+		`{"parent_id":null}`,
+	}
+
+	if len(requests) != len(expect) {
+		t.Fatalf("Unexpected requests: %q", requests)
+	}
+
+	for i, body := range requests {
+		if body != expect[i] {
+			t.Fatalf("Unexpected body %s, expected %s", body, expect[i])
+		}
+	}
+}
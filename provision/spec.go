@@ -0,0 +1,51 @@
+package provision
+
+import "github.com/diamondburned/arikawa/discord"
+
+// Everyone is the role name used to refer to the @everyone role, whose ID is
+// the same as the guild's.
+const Everyone = "@everyone"
+
+// Spec is the desired state of a guild. Roles and channels are matched to the
+// existing ones by name, and channels additionally by type.
+type Spec struct {
+	Roles    []RoleSpec
+	Channels []ChannelSpec
+
+	// Prune, if true, deletes roles and channels that aren't in the spec.
+	// Managed roles and @everyone are never deleted.
+	Prune bool
+}
+
+// RoleSpec is the desired state of a role. A RoleSpec named Everyone modifies
+// the @everyone role.
+type RoleSpec struct {
+	Name        string
+	Color       discord.Color
+	Hoist       bool
+	Mentionable bool
+	Permissions discord.Permissions
+}
+
+// ChannelSpec is the desired state of a channel, including categories.
+type ChannelSpec struct {
+	Name  string
+	Type  discord.ChannelType
+	Topic string
+	NSFW  bool
+
+	// Category is the name of the category channel this channel is in. The
+	// category must also be in the spec, or already exist.
+	Category string
+
+	// Permissions are the role overwrites of the channel. If this is empty,
+	// the channel's overwrites are left untouched.
+	Permissions []OverwriteSpec
+}
+
+// OverwriteSpec is a permission overwrite for a role, referred to by name.
+type OverwriteSpec struct {
+	Role  string
+	Allow discord.Permissions
+	Deny  discord.Permissions
+}
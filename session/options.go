@@ -0,0 +1,64 @@
+package session
+
+import (
+	"github.com/diamondburned/arikawa/discord"
+	"github.com/diamondburned/arikawa/gateway"
+)
+
+// Option changes the identify data of the Gateway created by New. The data is
+// validated once all options are applied.
+type Option func(*gateway.IdentifyData)
+
+// WithLargeThreshold sets the member count from which guilds are large, which
+// means their offline members aren't sent. It must be between 50 and 250.
+func WithLargeThreshold(threshold uint) Option {
+	return func(data *gateway.IdentifyData) {
+		data.LargeThreshold = threshold
+	}
+}
+
+// WithPresence sets the presence that the Gateway identifies with.
+func WithPresence(presence gateway.UpdateStatusData) Option {
+	return func(data *gateway.IdentifyData) {
+		data.Presence = &presence
+	}
+}
+
+// WithStatus sets the status that the Gateway identifies with, with an
+// optional activity.
+func WithStatus(status discord.Status, activity *discord.Activity) Option {
+	return WithPresence(gateway.UpdateStatusData{
+		Status: status,
+		Game:   activity,
+	})
+}
+
+// WithIntents adds the Gateway Intents.
+func WithIntents(intents gateway.Intents) Option {
+	return func(data *gateway.IdentifyData) {
+		data.AddIntent(intents)
+	}
+}
+
+// WithShard sets the shard of the Gateway.
+func WithShard(id, num int) Option {
+	return func(data *gateway.IdentifyData) {
+		data.SetShard(id, num)
+	}
+}
+
+// WithCompress sets whether Discord compresses the Ready and GuildCreate
+// events. It's true by default.
+func WithCompress(compress bool) Option {
+	return func(data *gateway.IdentifyData) {
+		data.Compress = compress
+	}
+}
+
+// WithGuildSubscriptions sets whether presence and typing events are sent. It's
+// true by default.
+func WithGuildSubscriptions(subscribe bool) Option {
+	return func(data *gateway.IdentifyData) {
+		data.GuildSubscription = subscribe
+	}
+}
@@ -7,8 +7,11 @@ import (
 	"log"
 
 	"github.com/diamondburned/arikawa/api"
+	"github.com/diamondburned/arikawa/discord"
 	"github.com/diamondburned/arikawa/gateway"
 	"github.com/diamondburned/arikawa/handler"
+	"github.com/diamondburned/arikawa/logger"
+	"github.com/diamondburned/arikawa/metrics"
 	"github.com/pkg/errors"
 )
 
@@ -20,7 +23,13 @@ type Session struct {
 	*api.Client
 	Gateway *gateway.Gateway
 
-	// ErrorLog logs errors, including Gateway errors.
+	// Shards is non-nil if the Session is sharded, in which case Gateway is
+	// shard 0, which is the one that receives DMs. Commands for a guild
+	// should be sent through GatewayFor, so they go to the guild's shard.
+	Shards *gateway.ShardManager
+
+	// ErrorLog logs errors, including Gateway errors. SetLogger replaces it
+	// with a structured Logger.
 	ErrorLog func(err error) // default to log.Println
 
 	// Command handler with inherited methods.
@@ -31,9 +40,12 @@ type Session struct {
 	Ticket string
 
 	hstop chan struct{}
+	hdone chan struct{}
 }
 
-func New(token string) (*Session, error) {
+// New creates a new Session and its Gateway. The options change the Gateway's
+// identify data, which is validated.
+func New(token string, opts ...Option) (*Session, error) {
 	// Initialize the session and the API interface
 	s := &Session{}
 	s.Handler = handler.New()
@@ -54,6 +66,14 @@ func New(token string) (*Session, error) {
 		s.ErrorLog(err)
 	}
 
+	for _, opt := range opts {
+		opt(&g.Identifier.IdentifyData)
+	}
+
+	if err := g.Identifier.Validate(); err != nil {
+		return nil, errors.Wrap(err, "Invalid identify options")
+	}
+
 	return s, nil
 }
 
@@ -97,31 +117,135 @@ func NewWithGateway(gw *gateway.Gateway) *Session {
 		Handler: handler.New(),
 	}
 
-	gw.ErrorLog = func(err error) {
+	s.Gateway = gw
+	s.Gateway.ErrorLog = func(err error) {
+		s.ErrorLog(err)
+	}
+
+	return s
+}
+
+// NewWithShards creates a new Session that handles the events of all shards.
+// The shards mustn't be opened yet.
+func NewWithShards(m *gateway.ShardManager) *Session {
+	gw := m.Shard(0)
+
+	s := &Session{
+		Client:  api.NewClient(gw.Identifier.Token),
+		Gateway: gw,
+		Shards:  m,
+		ErrorLog: func(err error) {
+			log.Println("Arikawa/session error:", err)
+		},
+		Handler: handler.New(),
+	}
+
+	m.ErrorLog = func(err error) {
 		s.ErrorLog(err)
 	}
 
 	return s
 }
 
+// GatewayFor returns the Gateway to send commands for the guild through. If
+// the Session is sharded, this is the guild's shard, otherwise it's Gateway.
+func (s *Session) GatewayFor(guildID discord.Snowflake) *gateway.Gateway {
+	if s.Shards == nil {
+		return s.Gateway
+	}
+
+	return s.Shards.ShardForGuild(guildID)
+}
+
+// UpdateStatus validates and sets the presence of the current user, on all
+// shards if the Session is sharded. The presence is kept when the Gateway
+// identifies again. The data can be built with gateway.NewPresence.
+func (s *Session) UpdateStatus(data gateway.UpdateStatusData) error {
+	if err := data.Validate(); err != nil {
+		return err
+	}
+
+	if s.Shards != nil {
+		return s.Shards.UpdateStatusAll(data)
+	}
+
+	s.Gateway.SetPresence(&data)
+	return s.Gateway.UpdateStatus(data)
+}
+
+// SetLogger makes ErrorLog and the Gateway, or the shards if the Session is
+// sharded, log to l.
+func (s *Session) SetLogger(l logger.Logger) {
+	s.ErrorLog = logger.ErrorFunc(l, logger.ErrorLevel,
+		logger.F("component", "session"))
+
+	if s.Shards != nil {
+		s.Shards.SetLogger(l)
+	} else {
+		s.Gateway.SetLogger(l)
+	}
+}
+
+// SetMetrics makes the API client and the Gateway, or the shards if the
+// Session is sharded, report to r. A State also reports its store accesses to
+// the API client's Metrics. It should be called before Open.
+func (s *Session) SetMetrics(r metrics.Recorder) {
+	s.Client.Metrics = r
+
+	if s.Shards != nil {
+		s.Shards.SetMetrics(r)
+	} else {
+		s.Gateway.Metrics = r
+	}
+}
+
+// UpdateToken changes the token of the API client and the Gateway, or all
+// shards if the Session is sharded, for tokens that are rotated while
+// running. Connected gateways are reconnected with a new session.
+func (s *Session) UpdateToken(token string) error {
+	s.Client.SetToken(token)
+
+	if s.Shards != nil {
+		return s.Shards.UpdateToken(token)
+	}
+
+	return s.Gateway.UpdateToken(token)
+}
+
 func (s *Session) Open() error {
-	if err := s.Gateway.Open(); err != nil {
-		return errors.Wrap(err, "Failed to start gateway")
+	var events = s.Gateway.Events
+
+	if s.Shards != nil {
+		if err := s.Shards.Open(); err != nil {
+			return errors.Wrap(err, "Failed to start shards")
+		}
+
+		events = s.Shards.Events
+	} else {
+		if err := s.Gateway.Open(); err != nil {
+			return errors.Wrap(err, "Failed to start gateway")
+		}
 	}
 
 	stop := make(chan struct{})
+	done := make(chan struct{})
 	s.hstop = stop
-	go s.startHandler(stop)
+	s.hdone = done
+	go s.startHandler(events, stop, done)
 
 	return nil
 }
 
-func (s *Session) startHandler(stop <-chan struct{}) {
+func (s *Session) startHandler(
+	events <-chan gateway.Event, stop <-chan struct{}, done chan struct{}) {
+
+	defer close(done)
+
 	for {
 		select {
 		case <-stop:
 			return
-		case ev := <-s.Gateway.Events:
+		case ev := <-events:
 			s.Handler.Call(ev)
 		}
 	}
@@ -131,8 +255,70 @@ func (s *Session) Close() error {
 	// Stop the event handler
 	if s.hstop != nil {
 		close(s.hstop)
+		s.hstop = nil
+	}
+
+	if s.Shards != nil {
+		return s.Shards.Close()
 	}
 
 	// Close the websocket
 	return s.Gateway.Close()
 }
+
+// CloseResumable closes the Gateway, or all shards at once, without
+// invalidating the sessions, so that they can be resumed later with
+// ResumeState. The events already received are dispatched before it returns.
+func (s *Session) CloseResumable() error {
+	var err error
+	var events <-chan gateway.Event
+
+	// The event handler keeps running while closing, as the Gateway could be
+	// blocked sending events.
+	if s.Shards != nil {
+		err = s.Shards.CloseResumable()
+		events = s.Shards.Events
+	} else {
+		err = s.Gateway.CloseResumable()
+		events = s.Gateway.Events
+	}
+
+	if s.hstop != nil {
+		close(s.hstop)
+		<-s.hdone
+		s.hstop = nil
+
+		// These events won't be replayed when resuming, as their sequence
+		// was already counted.
+		for len(events) > 0 {
+			s.Handler.Call(<-events)
+		}
+	}
+
+	return err
+}
+
+// ResumeState returns the data needed to resume the session of the Gateway, or
+// of every shard if the Session is sharded.
+func (s *Session) ResumeState() []gateway.ResumeState {
+	if s.Shards != nil {
+		return s.Shards.ResumeState()
+	}
+
+	return []gateway.ResumeState{s.Gateway.ResumeState()}
+}
+
+// SetResumeState makes the Gateway, or the shards, resume the sessions on Open
+// instead of identifying. It should be called before Open.
+func (s *Session) SetResumeState(data []gateway.ResumeState) {
+	if s.Shards != nil {
+		s.Shards.SetResumeState(data)
+		return
+	}
+
+	for _, d := range data {
+		if d.ShardID == s.Gateway.Status().ShardID {
+			s.Gateway.SetResumeState(d)
+		}
+	}
+}
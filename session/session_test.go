@@ -0,0 +1,30 @@
+// +build unit
+
+package session
+
+import (
+	"testing"
+
+	"github.com/diamondburned/arikawa/discord"
+	"github.com/diamondburned/arikawa/gateway"
+)
+
+func TestGatewayFor(t *testing.T) {
+	var g = &gateway.Gateway{}
+	var s = &Session{Gateway: g}
+
+	if s.GatewayFor(123) != g {
+		t.Fatal("Unsharded Session didn't return its Gateway")
+	}
+
+	s.Shards = &gateway.ShardManager{
+		Shards: []*gateway.Gateway{g, {}},
+	}
+
+	// The guild ID is shifted by 22 bits before the modulo.
+	var guildID = discord.Snowflake(1 << 22)
+
+	if s.GatewayFor(guildID) != s.Shards.Shards[1] {
+		t.Fatal("Sharded Session didn't return the guild's shard")
+	}
+}
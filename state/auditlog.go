@@ -0,0 +1,143 @@
+package state
+
+import (
+	"sync"
+	"time"
+
+	"github.com/diamondburned/arikawa/api"
+	"github.com/diamondburned/arikawa/discord"
+	"github.com/pkg/errors"
+)
+
+var (
+	// AuditPollAttempts is the number of times FindAuditEntry fetches the
+	// audit log before giving up.
+	AuditPollAttempts = 4
+	// AuditPollDelay is the delay before FindAuditEntry fetches the audit log
+	// again. It doubles after every attempt.
+	AuditPollDelay = 500 * time.Millisecond
+)
+
+// auditCacheTTL is how long a fetched audit log is reused for. It's shorter
+// than AuditPollDelay, so retries always see a newer audit log.
+const auditCacheTTL = 250 * time.Millisecond
+
+var ErrAuditEntryNotFound = errors.New("Audit log entry not found")
+
+// FindAuditEntry finds the audit log entry of an action that just happened,
+// which is useful to know who performed it: for example, who banned a member
+// after a GuildBanAddEvent. The entry must have been created within window
+// before the call. If targetID is 0, any target matches.
+//
+// Audit log entries may appear a bit after the event, so the audit log is
+// polled up to AuditPollAttempts times, with a growing delay, until the entry
+// is found. Concurrent calls for the same guild and action share the fetched
+// audit logs. ErrAuditEntryNotFound is returned if the entry isn't found.
+//
+// This requires the VIEW_AUDIT_LOG permission.
+func (s *State) FindAuditEntry(
+	guildID discord.Snowflake, action discord.AuditLogEvent,
+	targetID discord.Snowflake,
+	window time.Duration) (*discord.AuditLogEntry, error) {
+
+	var since = time.Now().Add(-window)
+	var delay = AuditPollDelay
+
+	for i := 0; i < AuditPollAttempts; i++ {
+		if i > 0 {
+			time.Sleep(delay)
+			delay *= 2
+		}
+
+		entries, err := s.auditEntries(guildID, action)
+		if err != nil {
+			return nil, err
+		}
+
+		// Entries are sorted newest first.
+		for _, entry := range entries {
+			if entry.ID.Time().Before(since) {
+				break
+			}
+
+			if targetID.Valid() && entry.TargetID != targetID {
+				continue
+			}
+
+			var found = entry
+			return &found, nil
+		}
+	}
+
+	return nil, ErrAuditEntryNotFound
+}
+
+type auditKey struct {
+	guildID discord.Snowflake
+	action  discord.AuditLogEvent
+}
+
+type auditLog struct {
+	// used is guarded by the auditCache's mutex.
+	used time.Time
+
+	mutex   sync.Mutex
+	fetched time.Time
+	entries []discord.AuditLogEntry
+}
+
+// auditCache holds the audit logs recently fetched by FindAuditEntry.
+type auditCache struct {
+	mutex sync.Mutex
+	logs  map[auditKey]*auditLog
+}
+
+// auditEntries returns the guild's latest audit log entries of the action,
+// reusing a recently fetched audit log if there's one.
+func (s *State) auditEntries(guildID discord.Snowflake,
+	action discord.AuditLogEvent) ([]discord.AuditLogEntry, error) {
+
+	var key = auditKey{guildID, action}
+
+	s.audits.mutex.Lock()
+	if s.audits.logs == nil {
+		s.audits.logs = map[auditKey]*auditLog{}
+	}
+
+	log, ok := s.audits.logs[key]
+	if !ok {
+		log = &auditLog{}
+	}
+
+	// Drop the audit logs that are too old to be reused, so the cache doesn't
+	// grow forever.
+	var now = time.Now()
+	for k, l := range s.audits.logs {
+		if now.Sub(l.used) > auditCacheTTL {
+			delete(s.audits.logs, k)
+		}
+	}
+
+	log.used = now
+	s.audits.logs[key] = log
+	s.audits.mutex.Unlock()
+
+	// Holding the lock while fetching makes concurrent callers wait for the
+	// fetch and reuse it.
+	log.mutex.Lock()
+	defer log.mutex.Unlock()
+
+	if time.Since(log.fetched) < auditCacheTTL {
+		return log.entries, nil
+	}
+
+	audit, err := s.AuditLog(guildID, api.AuditLogData{ActionType: action})
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to get audit log")
+	}
+
+	log.fetched = time.Now()
+	log.entries = audit.Entries
+
+	return log.entries, nil
+}
@@ -0,0 +1,192 @@
+package state
+
+import (
+	"sync"
+	"time"
+
+	"github.com/diamondburned/arikawa/discord"
+	"github.com/diamondburned/arikawa/gateway"
+	"github.com/pkg/errors"
+)
+
+// BackfillLimit is the maximum number of messages backfilled per channel, as
+// a channel could have missed a lot of messages during a long disconnection.
+var BackfillLimit = 500
+
+// BackfillMaxChannels is the maximum number of channels kept track of for
+// backfilling. Once there are more, the channel whose last message is the
+// oldest is forgotten.
+var BackfillMaxChannels = 100
+
+// BackfillMaxAge is how recent the last message of a channel must be for the
+// channel to be backfilled. Older channels are forgotten.
+var BackfillMaxAge = 24 * time.Hour
+
+// BackfilledMessageEvent is sent to the handler for each message that was
+// missed while the Gateway was disconnected, if Backfill is true. They're sent
+// oldest first, after the Gateway identified again. It's a different type than
+// gateway.MessageCreateEvent, so commands aren't run for old messages.
+type BackfilledMessageEvent gateway.MessageCreateEvent
+
+// activeChannels are the channels that got messages, if Backfill is true.
+type activeChannels struct {
+	mutex    sync.Mutex
+	channels map[discord.Snowflake]activeChannel
+	// ready has the IDs of the shards that were ready at least once.
+	ready map[int]bool
+}
+
+type activeChannel struct {
+	id      discord.Snowflake
+	guildID discord.Snowflake
+	// lastID is the ID of the last message received.
+	lastID discord.Snowflake
+}
+
+func (a *activeChannels) mark(m *discord.Message) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	if a.channels == nil {
+		a.channels = map[discord.Snowflake]activeChannel{}
+	}
+
+	ch, ok := a.channels[m.ChannelID]
+	if ok && ch.lastID >= m.ID {
+		return
+	}
+
+	a.channels[m.ChannelID] = activeChannel{m.ChannelID, m.GuildID, m.ID}
+
+	if !ok && len(a.channels) > BackfillMaxChannels {
+		var oldest = m.ChannelID
+		for id, ch := range a.channels {
+			if ch.lastID < a.channels[oldest].lastID {
+				oldest = id
+			}
+		}
+
+		delete(a.channels, oldest)
+	}
+}
+
+// recent returns the channels of the shard whose last message is more recent
+// than BackfillMaxAge, and forgets the older ones. The mutex must be held.
+func (a *activeChannels) recent(shard *gateway.Shard) []activeChannel {
+	var since = discord.NewSnowflake(time.Now().Add(-BackfillMaxAge))
+	var channels []activeChannel
+
+	for id, ch := range a.channels {
+		if ch.lastID < since {
+			delete(a.channels, id)
+			continue
+		}
+
+		sh := gateway.ShardIDForGuild(ch.guildID, shard.NumShards())
+		if sh == shard.ShardID() {
+			channels = append(channels, ch)
+		}
+	}
+
+	return channels
+}
+
+func (s *State) onBackfillEvent(iface interface{}) {
+	if !s.Backfill {
+		return
+	}
+
+	switch ev := iface.(type) {
+	case *gateway.MessageCreateEvent:
+		s.active.mark((*discord.Message)(ev))
+
+	case *gateway.ChannelDeleteEvent:
+		s.active.mutex.Lock()
+		delete(s.active.channels, ev.ID)
+		s.active.mutex.Unlock()
+
+	case *gateway.ReadyEvent:
+		// Discord replays the missed events when resuming, but not when the
+		// Gateway identifies again, which is when there's a READY.
+		var shard = gateway.DefaultShard()
+		if ev.Shard != nil {
+			shard = ev.Shard
+		}
+
+		s.active.mutex.Lock()
+		defer s.active.mutex.Unlock()
+
+		if s.active.ready == nil {
+			s.active.ready = map[int]bool{}
+		}
+
+		if !s.active.ready[shard.ShardID()] {
+			s.active.ready[shard.ShardID()] = true
+			return
+		}
+
+		var channels = s.active.recent(shard)
+
+		// Later messages are received live.
+		var until = discord.NewSnowflake(time.Now())
+
+		go func() {
+			for _, ch := range channels {
+				if err := s.backfill(ch, until); err != nil {
+					s.stateErr(err, "Failed to backfill a channel")
+				}
+			}
+		}()
+	}
+}
+
+// backfill fetches the messages of the channel after its last one, up to the
+// ID, and dispatches them.
+func (s *State) backfill(ch activeChannel, until discord.Snowflake) error {
+	var after = ch.lastID
+
+	for fetched := 0; fetched < BackfillLimit; {
+		ms, err := s.Session.MessagesAfter(ch.id, after, 100)
+		if err != nil {
+			return errors.Wrap(err, "Failed to get messages")
+		}
+
+		// Messages are returned newest first.
+		for i := len(ms) - 1; i >= 0; i-- {
+			var m = &ms[i]
+			if m.ID >= until {
+				return nil
+			}
+
+			m.GuildID = ch.guildID
+
+			// Stores keep messages newest first, so messages older than the
+			// ones received live in the meantime aren't stored.
+			if s.newestMessage(m) {
+				if err := s.Store.MessageSet(m); err != nil {
+					s.stateErr(err,
+						"Failed to add a backfilled message in state")
+				}
+			}
+			s.active.mark(m)
+
+			s.Handler.Call((*BackfilledMessageEvent)(m))
+		}
+
+		if len(ms) < 100 {
+			return nil
+		}
+
+		fetched += len(ms)
+		after = ms[0].ID
+	}
+
+	return nil
+}
+
+// newestMessage returns true if the message is newer than the messages of its
+// channel in the store.
+func (s *State) newestMessage(m *discord.Message) bool {
+	ms, err := s.Store.Messages(m.ChannelID)
+	return err != nil || len(ms) == 0 || ms[0].ID < m.ID
+}
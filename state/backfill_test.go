@@ -0,0 +1,122 @@
+// +build unit
+
+package state
+
+import (
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/diamondburned/arikawa/api"
+	"github.com/diamondburned/arikawa/discord"
+	"github.com/diamondburned/arikawa/gateway"
+	"github.com/diamondburned/arikawa/handler"
+	"github.com/diamondburned/arikawa/session"
+)
+
+func TestBackfill(t *testing.T) {
+	var past = discord.NewSnowflake(time.Now().Add(-time.Hour))
+	var ids = []discord.Snowflake{past, past + 1, past + 2}
+
+	client := api.NewClient("")
+	client.Client.Client.Transport = roundTripFunc(
+		func(r *http.Request) *http.Response {
+			// Newest first, like Discord.
+			return &http.Response{
+				StatusCode: 200,
+				Header:     http.Header{},
+				Body: ioutil.NopCloser(strings.NewReader(
+					`[{"id":"` + ids[2].String() + `","channel_id":"1"},` +
+						`{"id":"` + ids[1].String() + `","channel_id":"1"}]`,
+				)),
+			}
+		},
+	)
+
+	s := &State{
+		Session: &session.Session{
+			Client:  client,
+			Handler: handler.New(),
+		},
+		Store:    NewDefaultStore(nil),
+		StateLog: func(err error) { t.Error(err) },
+		Backfill: true,
+	}
+
+	// Keep the order of the messages.
+	s.Handler.Synchronous = true
+
+	var backfilled = make(chan *BackfilledMessageEvent, 2)
+	s.AddHandler(func(m *BackfilledMessageEvent) { backfilled <- m })
+
+	s.onBackfillEvent(&gateway.ReadyEvent{})
+	s.onBackfillEvent(&gateway.MessageCreateEvent{
+		ID: ids[0], ChannelID: 1, GuildID: 2,
+	})
+
+	// The Gateway identified again.
+	s.onBackfillEvent(&gateway.ReadyEvent{})
+
+	for _, id := range ids[1:] {
+		select {
+		case m := <-backfilled:
+			if m.ID != id || m.GuildID != 2 {
+				t.Fatalf("Unexpected message %d in guild %d", m.ID, m.GuildID)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("Timed out waiting for messages")
+		}
+	}
+
+	if _, err := s.Store.Message(1, ids[2]); err != nil {
+		t.Fatal("Backfilled message wasn't stored:", err)
+	}
+
+	// A message received live during the backfill is newer than the
+	// backfilled ones, which then aren't stored, so the store stays newest
+	// first.
+	s.Store.Reset()
+	s.Store.MessageSet(&discord.Message{ID: ids[2] + 1, ChannelID: 1})
+
+	if err := s.backfill(activeChannel{1, 2, ids[0]}, ids[2]+1); err != nil {
+		t.Fatal("Failed to backfill:", err)
+	}
+	<-backfilled
+	<-backfilled
+
+	ms, err := s.Store.Messages(1)
+	if err != nil || len(ms) != 1 || ms[0].ID != ids[2]+1 {
+		t.Fatalf("Unexpected messages in the store: %v", ms)
+	}
+}
+
+func TestActiveChannels(t *testing.T) {
+	defer func(max int) { BackfillMaxChannels = max }(BackfillMaxChannels)
+	BackfillMaxChannels = 2
+
+	var now = discord.NewSnowflake(time.Now())
+	var old = discord.NewSnowflake(time.Now().Add(-2 * BackfillMaxAge))
+
+	var a activeChannels
+	a.mark(&discord.Message{ID: now, ChannelID: 1})
+	a.mark(&discord.Message{ID: old, ChannelID: 2})
+	a.mark(&discord.Message{ID: now + 1, ChannelID: 3})
+	a.mark(&discord.Message{ID: now + 2, ChannelID: 1})
+
+	// The channel with the oldest message is forgotten.
+	if _, ok := a.channels[2]; ok || len(a.channels) != 2 {
+		t.Fatalf("Unexpected channels: %v", a.channels)
+	}
+
+	a.channels[4] = activeChannel{4, 0, old}
+
+	// Channels that are too old aren't backfilled, and forgotten.
+	if channels := a.recent(gateway.DefaultShard()); len(channels) != 2 {
+		t.Fatalf("Unexpected recent channels: %v", channels)
+	}
+	if _, ok := a.channels[4]; ok {
+		t.Fatal("Old channel wasn't forgotten")
+	}
+}
@@ -0,0 +1,66 @@
+package state
+
+import (
+	"net/http"
+
+	"github.com/diamondburned/arikawa/discord"
+	"github.com/diamondburned/arikawa/internal/httputil"
+	"github.com/pkg/errors"
+)
+
+// IsBanned returns true if the user is banned from the guild. If the Store is
+// a BanStore, all pages of the bans of the guild are fetched once and then kept
+// up to date from the gateway; otherwise, the API is asked every time. This
+// requires the BAN_MEMBERS permission.
+func (s *State) IsBanned(guildID, userID discord.Snowflake) (bool, error) {
+	bs, ok := s.Store.(BanStore)
+	// NoopStore would forget the bans, which would then be fetched every time.
+	if _, noop := bs.(noopStore); !ok || noop {
+		_, err := s.Session.GetBan(guildID, userID)
+		if err == nil {
+			return true, nil
+		}
+
+		// Users that aren't banned have no ban to get.
+		var httpErr, isHTTP = errors.Cause(err).(*httputil.HTTPError)
+		if isHTTP && httpErr.Status == http.StatusNotFound {
+			return false, nil
+		}
+
+		return false, err
+	}
+
+	_, err := bs.Ban(guildID, userID)
+	s.storeAccess("ban", err == nil)
+	if err == nil {
+		return true, nil
+	}
+
+	// The ban list is complete if it's known.
+	if _, err := bs.Bans(guildID); err == nil {
+		return false, nil
+	}
+
+	// A single request only returns the first 1000 bans.
+	var bans []discord.Ban
+
+	it := s.Session.BansIter(guildID)
+	for it.Next() {
+		bans = append(bans, it.Page()...)
+	}
+	if err := it.Err(); err != nil {
+		return false, err
+	}
+
+	if err := bs.BanListSet(guildID, bans); err != nil {
+		return false, err
+	}
+
+	for _, b := range bans {
+		if b.User.ID == userID {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
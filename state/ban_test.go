@@ -0,0 +1,194 @@
+// +build unit
+
+package state
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/diamondburned/arikawa/api"
+	"github.com/diamondburned/arikawa/discord"
+	"github.com/diamondburned/arikawa/gateway"
+	"github.com/diamondburned/arikawa/session"
+)
+
+type roundTripFunc func(r *http.Request) *http.Response
+
+func (fn roundTripFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return fn(r), nil
+}
+
+func TestIsBanned(t *testing.T) {
+	var requests int
+
+	client := api.NewClient("")
+	client.Client.Client.Transport = roundTripFunc(
+		func(r *http.Request) *http.Response {
+			requests++
+
+			return &http.Response{
+				StatusCode: 200,
+				Header:     http.Header{},
+				Body: ioutil.NopCloser(strings.NewReader(
+					`[{"user":{"id":"2"}}]`)),
+			}
+		},
+	)
+
+	s := &State{
+		Session: &session.Session{
+			Client:   client,
+			ErrorLog: func(err error) { t.Error(err) },
+		},
+		Store: NewDefaultStore(nil),
+	}
+
+	var isBanned = func(user discord.Snowflake) bool {
+		banned, err := s.IsBanned(1, user)
+		if err != nil {
+			t.Fatal("Failed to check ban:", err)
+		}
+		return banned
+	}
+
+	if !isBanned(2) || isBanned(3) {
+		t.Fatal("Unexpected bans")
+	}
+
+	s.onEvent(&gateway.GuildBanAddEvent{
+		GuildID: 1,
+		User:    discord.User{ID: 3},
+	})
+	s.onEvent(&gateway.GuildBanRemoveEvent{
+		GuildID: 1,
+		User:    discord.User{ID: 2},
+	})
+
+	if isBanned(2) || !isBanned(3) {
+		t.Fatal("Bans weren't updated")
+	}
+
+	if requests != 1 {
+		t.Fatal("Unexpected number of requests:", requests)
+	}
+}
+
+func TestIsBannedPages(t *testing.T) {
+	var requests int
+
+	client := api.NewClient("")
+	client.Client.Client.Transport = roundTripFunc(
+		func(r *http.Request) *http.Response {
+			requests++
+
+			after, _ := strconv.Atoi(r.URL.Query().Get("after"))
+
+			// 1500 bans, of users 1 to 1500, a page of 1000 at a time.
+			var bans []discord.Ban
+			for id := after + 1; id <= 1500 && len(bans) < 1000; id++ {
+				bans = append(bans, discord.Ban{
+					User: discord.User{ID: discord.Snowflake(id)},
+				})
+			}
+
+			b, err := json.Marshal(bans)
+			if err != nil {
+				t.Error("Failed to marshal bans:", err)
+			}
+
+			return &http.Response{
+				StatusCode: 200,
+				Header:     http.Header{},
+				Body:       ioutil.NopCloser(bytes.NewReader(b)),
+			}
+		},
+	)
+
+	s := &State{
+		Session: &session.Session{
+			Client:   client,
+			ErrorLog: func(err error) { t.Error(err) },
+		},
+		Store: NewDefaultStore(nil),
+	}
+
+	for user, expected := range map[discord.Snowflake]bool{
+		1: true, 1000: true, 1400: true, 1500: true, 1501: false,
+	} {
+		banned, err := s.IsBanned(1, user)
+		if err != nil {
+			t.Fatal("Failed to check ban:", err)
+		}
+		if banned != expected {
+			t.Fatal("Unexpected ban of user", user, "banned:", banned)
+		}
+	}
+
+	if requests != 2 {
+		t.Fatal("Unexpected number of requests:", requests)
+	}
+}
+
+func TestIsBannedWithoutBanStore(t *testing.T) {
+	var paths []string
+
+	client := api.NewClient("")
+	client.Client.Client.Transport = roundTripFunc(
+		func(r *http.Request) *http.Response {
+			paths = append(paths, r.URL.Path)
+
+			if strings.HasSuffix(r.URL.Path, "/bans/2") {
+				return &http.Response{
+					StatusCode: 200,
+					Header:     http.Header{},
+					Body: ioutil.NopCloser(strings.NewReader(
+						`{"user":{"id":"2"}}`)),
+				}
+			}
+
+			return &http.Response{
+				StatusCode: 404,
+				Header:     http.Header{},
+				Body: ioutil.NopCloser(strings.NewReader(
+					`{"code":10026,"message":"Unknown Ban"}`)),
+			}
+		},
+	)
+
+	s := &State{
+		Session: &session.Session{
+			Client:   client,
+			ErrorLog: func(err error) { t.Error(err) },
+		},
+		Store: NewStoreFromParts(StoreParts{Guild: NewDefaultStore(nil)}),
+	}
+
+	if _, ok := s.Store.(BanStore); ok {
+		t.Fatal("Store without a Ban part is a BanStore")
+	}
+
+	for user, expected := range map[discord.Snowflake]bool{2: true, 3: false} {
+		banned, err := s.IsBanned(1, user)
+		if err != nil {
+			t.Fatal("Failed to check ban:", err)
+		}
+		if banned != expected {
+			t.Fatal("Unexpected ban of user", user, "banned:", banned)
+		}
+	}
+
+	// Only the bans of the users are fetched, not the ban list.
+	for _, path := range paths {
+		if strings.HasSuffix(path, "/bans") {
+			t.Fatal("Ban list fetched:", path)
+		}
+	}
+	if len(paths) != 2 {
+		t.Fatal("Unexpected requests:", paths)
+	}
+}
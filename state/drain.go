@@ -0,0 +1,54 @@
+package state
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+)
+
+// Drain shuts the State down gracefully, such as during a rolling deploy,
+// keeping the sessions resumable by the next process. It replaces Close:
+//
+//	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+//	defer cancel()
+//
+//	if err := s.Drain(ctx); err != nil {
+//		log.Println("Failed to drain:", err)
+//	}
+//
+// The Gateway, or all shards at once, stop receiving events, so no new
+// commands are invoked. Then the handlers being called, including the ones of
+// the bot package, are waited for until the context is done. Afterwards, the
+// Store is flushed if it's a Flusher, and the data to resume the sessions is
+// given to SaveResume.
+//
+// All steps are done even if one fails, and the first error is returned.
+func (s *State) Drain(ctx context.Context) error {
+	var errs []error
+
+	if err := s.Session.CloseResumable(); err != nil {
+		errs = append(errs, errors.Wrap(err, "Failed to close the Gateway"))
+	}
+
+	if err := s.Handler.Drain(ctx); err != nil {
+		errs = append(errs, errors.Wrap(err, "Failed to wait for handlers"))
+	}
+
+	if f, ok := s.Store.(Flusher); ok {
+		if err := f.Flush(); err != nil {
+			errs = append(errs, errors.Wrap(err, "Failed to flush the store"))
+		}
+	}
+
+	if s.SaveResume != nil {
+		if err := s.SaveResume(s.Session.ResumeState()); err != nil {
+			errs = append(errs, errors.Wrap(err, "Failed to save resume data"))
+		}
+	}
+
+	if len(errs) > 0 {
+		return errs[0]
+	}
+
+	return nil
+}
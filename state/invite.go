@@ -0,0 +1,223 @@
+package state
+
+import (
+	"sync"
+
+	"github.com/diamondburned/arikawa/discord"
+	"github.com/diamondburned/arikawa/gateway"
+	"github.com/pkg/errors"
+)
+
+// trackedInvites are the invites of each guild, if TrackInvites is true.
+type trackedInvites struct {
+	mutex  sync.Mutex
+	guilds map[discord.Snowflake]map[string]discord.InviteMeta
+}
+
+// InviteUses returns the use counts of the invites of the guild, by code.
+// ErrStoreNotFound is returned if the invites of the guild aren't tracked,
+// which is always the case unless TrackInvites is true.
+func (s *State) InviteUses(guildID discord.Snowflake) (map[string]uint, error) {
+	s.invites.mutex.Lock()
+	defer s.invites.mutex.Unlock()
+
+	invites, ok := s.invites.guilds[guildID]
+	if !ok {
+		return nil, ErrStoreNotFound
+	}
+
+	var uses = make(map[string]uint, len(invites))
+	for code, inv := range invites {
+		uses[code] = inv.Uses
+	}
+
+	return uses, nil
+}
+
+// TrackedInvites returns the tracked invites of the guild, like InviteUses.
+func (s *State) TrackedInvites(
+	guildID discord.Snowflake) ([]discord.InviteMeta, error) {
+
+	s.invites.mutex.Lock()
+	defer s.invites.mutex.Unlock()
+
+	invites, ok := s.invites.guilds[guildID]
+	if !ok {
+		return nil, ErrStoreNotFound
+	}
+
+	var list = make([]discord.InviteMeta, 0, len(invites))
+	for _, inv := range invites {
+		list = append(list, inv)
+	}
+
+	return list, nil
+}
+
+// RefreshInvites fetches the invites of the guild and tracks them, which
+// requires MANAGE_GUILD. The State does it in the background on GuildCreate if
+// TrackInvites is true.
+func (s *State) RefreshInvites(guildID discord.Snowflake) error {
+	_, err := s.refreshInvites(guildID)
+	return err
+}
+
+// JoinedVia guesses the invite that a member just joined the guild with, and
+// is meant to be called from a GuildMemberAddEvent handler. TrackInvites must
+// be true.
+//
+// The invites are fetched again and compared to the tracked ones: the invite
+// whose use count went up is the one that was used. An invite that
+// disappeared while one use away from its limit also counts. Nil is returned
+// if there isn't exactly one candidate, such as when the member joined with
+// the vanity URL, or when multiple members joined at once.
+func (s *State) JoinedVia(
+	guildID discord.Snowflake) (*discord.InviteMeta, error) {
+
+	old, err := s.refreshInvites(guildID)
+	if err != nil {
+		return nil, err
+	}
+
+	current, err := s.TrackedInvites(guildID)
+	if err != nil {
+		return nil, err
+	}
+
+	if inv, ok := usedInvite(old, current); ok {
+		return &inv, nil
+	}
+
+	return nil, nil
+}
+
+// refreshInvites fetches and tracks the invites of the guild, and returns the
+// ones tracked before, which are nil if there were none.
+func (s *State) refreshInvites(
+	guildID discord.Snowflake) (map[string]discord.InviteMeta, error) {
+
+	invites, err := s.Session.GuildInvites(guildID)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to get guild invites")
+	}
+
+	var tracked = make(map[string]discord.InviteMeta, len(invites))
+	for _, inv := range invites {
+		tracked[inv.Code] = inv
+	}
+
+	s.invites.mutex.Lock()
+	defer s.invites.mutex.Unlock()
+
+	if s.invites.guilds == nil {
+		s.invites.guilds = map[discord.Snowflake]map[string]discord.InviteMeta{}
+	}
+
+	var old = s.invites.guilds[guildID]
+	s.invites.guilds[guildID] = tracked
+
+	return old, nil
+}
+
+func (s *State) onInviteEvent(iface interface{}) {
+	if !s.TrackInvites {
+		return
+	}
+
+	switch ev := iface.(type) {
+	case *gateway.GuildCreateEvent:
+		s.invites.mutex.Lock()
+		_, ok := s.invites.guilds[ev.ID]
+		s.invites.mutex.Unlock()
+
+		// The guild only became available again.
+		if ok {
+			return
+		}
+
+		// The request shouldn't hold up the other events.
+		go func() {
+			if err := s.RefreshInvites(ev.ID); err != nil {
+				s.stateErr(err, "Failed to track the invites of a guild")
+			}
+		}()
+
+	case *gateway.GuildDeleteEvent:
+		if !ev.Unavailable {
+			s.invites.mutex.Lock()
+			delete(s.invites.guilds, ev.ID)
+			s.invites.mutex.Unlock()
+		}
+
+	case *gateway.InviteCreateEvent:
+		s.invites.mutex.Lock()
+		defer s.invites.mutex.Unlock()
+
+		invites, ok := s.invites.guilds[ev.GuildID]
+		if !ok {
+			return
+		}
+
+		invites[ev.Code] = discord.InviteMeta{
+			Invite: discord.Invite{
+				Code:       ev.Code,
+				Channel:    discord.Channel{ID: ev.ChannelID},
+				Target:     ev.Target,
+				TargetType: ev.TargetType,
+			},
+			Inviter:   ev.Inviter,
+			Uses:      ev.Uses,
+			MaxUses:   ev.MaxUses,
+			MaxAge:    ev.MaxAge,
+			Temporary: ev.Temporary,
+			CreatedAt: ev.CreatedAt,
+		}
+
+	case *gateway.InviteDeleteEvent:
+		s.invites.mutex.Lock()
+		defer s.invites.mutex.Unlock()
+
+		inv, ok := s.invites.guilds[ev.GuildID][ev.Code]
+		if !ok {
+			return
+		}
+
+		// Invites that reach their max uses are deleted right after the
+		// member joins, so they're kept until the next refresh, which
+		// JoinedVia does.
+		if inv.MaxUses == 0 || inv.Uses+1 < inv.MaxUses {
+			delete(s.invites.guilds[ev.GuildID], ev.Code)
+		}
+	}
+}
+
+// usedInvite finds the only invite whose use count went up. An invite that
+// disappeared and was one use away from its limit also counts. If there's not
+// exactly one candidate, false is returned.
+func usedInvite(old map[string]discord.InviteMeta,
+	current []discord.InviteMeta) (discord.InviteMeta, bool) {
+
+	var found []discord.InviteMeta
+	var seen = make(map[string]bool, len(current))
+
+	for _, inv := range current {
+		seen[inv.Code] = true
+
+		if prev, ok := old[inv.Code]; ok && inv.Uses > prev.Uses {
+			found = append(found, inv)
+		}
+	}
+
+	for code, prev := range old {
+		if !seen[code] && prev.MaxUses > 0 && prev.Uses+1 == prev.MaxUses {
+			prev.Uses++
+			found = append(found, prev)
+		}
+	}
+
+	if len(found) != 1 {
+		return discord.InviteMeta{}, false
+	}
+
+	return found[0], true
+}
@@ -0,0 +1,210 @@
+// +build unit
+
+package state
+
+import (
+	"io/ioutil"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/diamondburned/arikawa/api"
+	"github.com/diamondburned/arikawa/discord"
+	"github.com/diamondburned/arikawa/gateway"
+	"github.com/diamondburned/arikawa/session"
+)
+
+func invite(code string, uses, maxUses uint) discord.InviteMeta {
+	return discord.InviteMeta{
+		Invite:  discord.Invite{Code: code},
+		Uses:    uses,
+		MaxUses: maxUses,
+	}
+}
+
+func TestUsedInvite(t *testing.T) {
+	var old = map[string]discord.InviteMeta{
+		"a": invite("a", 1, 0),
+		"b": invite("b", 4, 5),
+		"c": invite("c", 0, 0),
+	}
+
+	// Uses of a went up.
+	inv, ok := usedInvite(old, []discord.InviteMeta{
+		invite("a", 2, 0), invite("b", 4, 5), invite("c", 0, 0),
+	})
+	if !ok || inv.Code != "a" || inv.Uses != 2 {
+		t.Fatal("Unexpected invite:", inv, ok)
+	}
+
+	// b reached its max uses and was deleted.
+	inv, ok = usedInvite(old, []discord.InviteMeta{
+		invite("a", 1, 0), invite("c", 0, 0),
+	})
+	if !ok || inv.Code != "b" || inv.Uses != 5 {
+		t.Fatal("Unexpected invite:", inv, ok)
+	}
+
+	// Ambiguous.
+	_, ok = usedInvite(old, []discord.InviteMeta{
+		invite("a", 2, 0), invite("b", 4, 5), invite("c", 1, 0),
+	})
+	if ok {
+		t.Fatal("Expected no invite")
+	}
+}
+
+func TestInviteEvents(t *testing.T) {
+	s := &State{TrackInvites: true}
+	s.invites.guilds = map[discord.Snowflake]map[string]discord.InviteMeta{
+		1: {"a": invite("a", 4, 5)},
+	}
+
+	s.onInviteEvent(&gateway.InviteCreateEvent{GuildID: 1, Code: "b", Uses: 2})
+	s.onInviteEvent(&gateway.InviteCreateEvent{GuildID: 2, Code: "c"})
+
+	// a is one use away from its limit, so it's kept for JoinedVia.
+	s.onInviteEvent(&gateway.InviteDeleteEvent{GuildID: 1, Code: "a"})
+
+	uses, err := s.InviteUses(1)
+	if err != nil {
+		t.Fatal("Failed to get invite uses:", err)
+	}
+
+	if !reflect.DeepEqual(uses, map[string]uint{"a": 4, "b": 2}) {
+		t.Fatal("Unexpected invite uses:", uses)
+	}
+
+	if _, err := s.InviteUses(2); err != ErrStoreNotFound {
+		t.Fatal("Untracked guild has invites:", err)
+	}
+}
+
+// inviteClient serves the invites of the guild from the function, which is
+// called for each request.
+func inviteClient(invites func() string) *api.Client {
+	client := api.NewClient("")
+	client.Client.Client.Transport = roundTripFunc(
+		func(r *http.Request) *http.Response {
+			return &http.Response{
+				StatusCode: 200,
+				Header:     http.Header{},
+				Body:       ioutil.NopCloser(strings.NewReader(invites())),
+			}
+		},
+	)
+
+	return client
+}
+
+func TestJoinedVia(t *testing.T) {
+	var invites = `[
+		{"code":"a","uses":1},
+		{"code":"b","uses":4,"max_uses":5},
+		{"code":"c","uses":0}
+	]`
+
+	s := &State{
+		Session: &session.Session{
+			Client: inviteClient(func() string { return invites }),
+		},
+		TrackInvites: true,
+	}
+
+	if err := s.RefreshInvites(1); err != nil {
+		t.Fatal("Failed to refresh invites:", err)
+	}
+
+	// A member joins with a.
+	invites = `[
+		{"code":"a","uses":2},
+		{"code":"b","uses":4,"max_uses":5},
+		{"code":"c","uses":0}
+	]`
+
+	inv, err := s.JoinedVia(1)
+	if err != nil {
+		t.Fatal("Failed to find the invite:", err)
+	}
+	if inv == nil || inv.Code != "a" || inv.Uses != 2 {
+		t.Fatal("Unexpected invite:", inv)
+	}
+
+	// A member joins with b, which is then deleted.
+	s.onInviteEvent(&gateway.InviteDeleteEvent{GuildID: 1, Code: "b"})
+	invites = `[{"code":"a","uses":2},{"code":"c","uses":0}]`
+
+	inv, err = s.JoinedVia(1)
+	if err != nil {
+		t.Fatal("Failed to find the invite:", err)
+	}
+	if inv == nil || inv.Code != "b" || inv.Uses != 5 {
+		t.Fatal("Unexpected invite:", inv)
+	}
+
+	// A member joins with the vanity URL.
+	inv, err = s.JoinedVia(1)
+	if err != nil {
+		t.Fatal("Failed to find the invite:", err)
+	}
+	if inv != nil {
+		t.Fatal("Unexpected invite:", inv)
+	}
+
+	uses, err := s.InviteUses(1)
+	if err != nil {
+		t.Fatal("Failed to get invite uses:", err)
+	}
+	if !reflect.DeepEqual(uses, map[string]uint{"a": 2, "c": 0}) {
+		t.Fatal("Unexpected invite uses:", uses)
+	}
+}
+
+func TestInviteGuildCreate(t *testing.T) {
+	var release = make(chan struct{})
+
+	s := &State{
+		Session: &session.Session{
+			Client: inviteClient(func() string {
+				<-release
+				return `[{"code":"a","uses":1}]`
+			}),
+		},
+		StateLog:     func(err error) { t.Error(err) },
+		TrackInvites: true,
+	}
+
+	var done = make(chan struct{})
+	go func() {
+		s.onInviteEvent(&gateway.GuildCreateEvent{Guild: discord.Guild{ID: 1}})
+		close(done)
+	}()
+
+	// The invites are fetched without blocking the event.
+	select {
+	case <-done:
+		close(release)
+	case <-time.After(time.Second):
+		t.Fatal("The event waited for the invites")
+	}
+
+	var timeout = time.After(time.Second)
+
+	for {
+		uses, err := s.InviteUses(1)
+		if err == nil {
+			if !reflect.DeepEqual(uses, map[string]uint{"a": 1}) {
+				t.Fatal("Unexpected invite uses:", uses)
+			}
+			return
+		}
+
+		select {
+		case <-timeout:
+			t.Fatal("Invites weren't tracked")
+		case <-time.After(time.Millisecond):
+		}
+	}
+}
@@ -0,0 +1,103 @@
+// +build unit
+
+package state
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/diamondburned/arikawa/discord"
+	"github.com/diamondburned/arikawa/gateway"
+	"github.com/diamondburned/arikawa/handler"
+	"github.com/diamondburned/arikawa/internal/json"
+	"github.com/diamondburned/arikawa/internal/wsutil"
+	"github.com/diamondburned/arikawa/session"
+)
+
+// chunkConn answers member requests with chunks of one member each.
+type chunkConn struct {
+	wsutil.Connection
+	state  *State
+	chunks int
+}
+
+func (c chunkConn) Send(_ context.Context, b []byte) error {
+	var op struct {
+		Data gateway.RequestGuildMembersData `json:"d"`
+	}
+	if err := (json.Default{}).Unmarshal(b, &op); err != nil {
+		return err
+	}
+
+	go func() {
+		for i := 0; i < c.chunks; i++ {
+			c.state.Call(&gateway.GuildMembersChunkEvent{
+				GuildID: op.Data.GuildID[0],
+				Members: []discord.Member{
+					{User: discord.User{ID: discord.Snowflake(i + 1)}},
+				},
+				ChunkIndex: i,
+				ChunkCount: c.chunks,
+				Nonce:      op.Data.Nonce,
+			})
+		}
+	}()
+
+	return nil
+}
+
+func TestEachMember(t *testing.T) {
+	s := &State{
+		Session: &session.Session{
+			Handler: handler.New(),
+			Gateway: &gateway.Gateway{
+				Driver:    json.Default{},
+				WSTimeout: time.Second,
+			},
+		},
+		Store:    NewDefaultStore(nil),
+		StateLog: func(err error) { t.Error(err) },
+	}
+	s.Handler.Synchronous = true
+	s.Gateway.WS = &wsutil.Websocket{
+		Conn:        chunkConn{state: s, chunks: 3},
+		SendLimiter: wsutil.NewSendLimiter(),
+	}
+
+	if err := s.hookSession(); err != nil {
+		t.Fatal("Failed to hook the session:", err)
+	}
+
+	var ids []discord.Snowflake
+
+	err := s.EachMember(1, func(m *discord.Member) error {
+		ids = append(ids, m.User.ID)
+		return nil
+	})
+	if err != nil {
+		t.Fatal("Failed to get members:", err)
+	}
+
+	if len(ids) != 3 || ids[0] != 1 || ids[2] != 3 {
+		t.Fatal("Unexpected members:", ids)
+	}
+
+	if _, err := s.Store.Member(1, 1); err != ErrStoreNotFound {
+		t.Fatal("Member was stored:", err)
+	}
+
+	// MembersAll stores them.
+	members, err := s.MembersAll(1)
+	if err != nil {
+		t.Fatal("Failed to get members:", err)
+	}
+
+	if _, err := s.Store.Member(1, 1); err != nil || len(members) != 3 {
+		t.Fatal("Members weren't stored:", err, len(members))
+	}
+
+	if _, ok := s.fullMembers.Load(discord.Snowflake(1)); !ok {
+		t.Fatal("Guild wasn't marked as fully requested")
+	}
+}
@@ -0,0 +1,88 @@
+package state
+
+import (
+	"sort"
+
+	"github.com/diamondburned/arikawa/api"
+	"github.com/diamondburned/arikawa/discord"
+)
+
+// MessageIterator goes through the history of a channel backwards like
+// api.MessageIterator, but starts with the messages in the store.
+type MessageIterator struct {
+	state     *State
+	channelID discord.Snowflake
+	guildID   discord.Snowflake
+
+	cached []discord.Message
+	before discord.Snowflake
+	api    *api.MessageIterator
+	msg    *discord.Message
+}
+
+// MessagesIter returns an iterator over all the messages of the channel,
+// starting from the latest one. The messages in the store are given first,
+// and the older ones are then fetched from the API. The store is assumed to
+// have the latest messages of the channel, as with Messages.
+func (s *State) MessagesIter(channelID discord.Snowflake) *MessageIterator {
+	it := &MessageIterator{
+		state:     s,
+		channelID: channelID,
+	}
+
+	ms, err := s.Store.Messages(channelID)
+	s.storeAccess("messages", err == nil)
+	if err == nil {
+		// Stores aren't required to keep the messages in order.
+		sort.Slice(ms, func(i, j int) bool { return ms[i].ID > ms[j].ID })
+		it.cached = ms
+	}
+
+	// Messages from the API don't have the GuildID, so it's filled like for
+	// incoming messages.
+	if c, err := s.Channel(channelID); err == nil {
+		it.guildID = c.GuildID
+	}
+
+	return it
+}
+
+// Next advances to the next message, which is then returned by Message. False
+// is returned once there are no messages left or if a request failed, which
+// Err tells apart.
+func (it *MessageIterator) Next() bool {
+	if len(it.cached) > 0 {
+		it.msg = &it.cached[0]
+		it.before = it.msg.ID
+		it.cached = it.cached[1:]
+		return true
+	}
+
+	if it.api == nil {
+		it.api = it.state.Session.MessagesIterBefore(it.channelID, it.before)
+	}
+
+	if !it.api.Next() {
+		it.msg = nil
+		return false
+	}
+
+	it.msg = it.api.Message()
+	it.msg.GuildID = it.guildID
+
+	return true
+}
+
+// Message returns the current message, which is nil before Next is called or
+// after it returned false.
+func (it *MessageIterator) Message() *discord.Message {
+	return it.msg
+}
+
+// Err returns the error of the request that failed, if any.
+func (it *MessageIterator) Err() error {
+	if it.api == nil {
+		return nil
+	}
+	return it.api.Err()
+}
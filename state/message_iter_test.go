@@ -0,0 +1,58 @@
+// +build unit
+
+package state
+
+import (
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/diamondburned/arikawa/api"
+	"github.com/diamondburned/arikawa/discord"
+	"github.com/diamondburned/arikawa/session"
+)
+
+func TestMessagesIter(t *testing.T) {
+	var requests int
+
+	client := api.NewClient("")
+	client.Client.Client.Transport = roundTripFunc(
+		func(r *http.Request) *http.Response {
+			requests++
+			return &http.Response{
+				StatusCode: 200,
+				Header:     http.Header{},
+				Body: ioutil.NopCloser(strings.NewReader(
+					`[{"id":"2","channel_id":"1"},{"id":"1","channel_id":"1"}]`,
+				)),
+			}
+		},
+	)
+
+	s := &State{
+		Session: &session.Session{Client: client},
+		Store:   NewDefaultStore(nil),
+	}
+	s.Store.ChannelSet(&discord.Channel{ID: 1, GuildID: 7})
+	s.Store.MessageSet(&discord.Message{ID: 4, ChannelID: 1, GuildID: 7})
+	s.Store.MessageSet(&discord.Message{ID: 3, ChannelID: 1, GuildID: 7})
+
+	var ids []discord.Snowflake
+
+	it := s.MessagesIter(1)
+	for it.Next() {
+		if it.Message().GuildID != 7 {
+			t.Fatal("Message has no guild ID:", it.Message().ID)
+		}
+		ids = append(ids, it.Message().ID)
+	}
+
+	if err := it.Err(); err != nil {
+		t.Fatal("Failed to iterate:", err)
+	}
+
+	if len(ids) != 4 || ids[0] != 4 || ids[3] != 1 || requests != 1 {
+		t.Fatal("Unexpected messages:", ids, requests)
+	}
+}
@@ -3,11 +3,17 @@
 package state
 
 import (
+	"context"
+	"strconv"
+	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/diamondburned/arikawa/discord"
 	"github.com/diamondburned/arikawa/gateway"
 	"github.com/diamondburned/arikawa/handler"
+	"github.com/diamondburned/arikawa/logger"
 	"github.com/diamondburned/arikawa/session"
 	"github.com/pkg/errors"
 )
@@ -15,8 +21,16 @@ import (
 var (
 	MaxFetchMembers uint = 1000
 	MaxFetchGuilds  uint = 100
+
+	// MemberChunkTimeout is how long MembersAll waits for each chunk.
+	MemberChunkTimeout = 10 * time.Second
 )
 
+var ErrMemberChunkTimeout = errors.New("Timed out waiting for member chunks")
+
+// memberNonce is incremented for each request of all members.
+var memberNonce uint64
+
 type State struct {
 	*session.Session
 	Store
@@ -34,14 +48,56 @@ type State struct {
 	// PreHandler is the manual hook that is executed before the State handler
 	// is. This should only be used for low-level operations.
 	// It's recommended to set Synchronous to true if you mutate the events.
+	// Middlewares added with Use wrap both this and the State handler.
 	PreHandler *handler.Handler // default nil
 
+	// TrackTyping enables TypingUsers and TypingIn, as well as the
+	// TypingStartedEvent and TypingStoppedEvent events. It's off by default,
+	// as bots rarely need it.
+	TrackTyping bool
+
+	// TrackInvites enables InviteUses and JoinedVia. The invites of every
+	// guild are fetched in the background on GuildCreate, which requires
+	// MANAGE_GUILD, and then kept up to date with the invite events. It should
+	// be set before Open.
+	TrackInvites bool
+
+	// Backfill enables fetching the messages missed in the channels that got
+	// messages, when the Gateway has to identify again after losing its
+	// session. They're sent as BackfilledMessageEvents, up to BackfillLimit
+	// per channel, for up to BackfillMaxChannels channels that got messages
+	// in the last BackfillMaxAge.
+	Backfill bool
+
+	// SaveResume, if not nil, is called by Drain to persist the data needed
+	// to resume the sessions, which the next process gives to SetResumeState
+	// before opening.
+	SaveResume func(states []gateway.ResumeState) error
+
 	unhooker func()
 
 	// List of channels with few messages, so it doesn't bother hitting the API
 	// again.
 	fewMessages []discord.Snowflake
 	fewMutex    sync.Mutex
+
+	// Audit logs fetched by FindAuditEntry.
+	audits auditCache
+
+	// Read states of user accounts.
+	reads readStates
+	// Users typing, if TrackTyping is true.
+	typing typingUsers
+	// Invites of the guilds, if TrackInvites is true.
+	invites trackedInvites
+	// Channels to backfill, if Backfill is true.
+	active activeChannels
+	// Last voice servers of the guilds.
+	voice voiceServers
+	// Nonces of the member chunks that aren't stored, for EachMember.
+	uncachedChunks sync.Map
+	// Guilds whose members were all stored by MembersAll, for VerifyCache.
+	fullMembers sync.Map
 }
 
 func NewFromSession(s *session.Session, store Store) (*State, error) {
@@ -56,12 +112,16 @@ func NewFromSession(s *session.Session, store Store) (*State, error) {
 	return state, state.hookSession()
 }
 
-func New(token string) (*State, error) {
-	return NewWithStore(token, NewDefaultStore(nil))
+// New creates a new State with the DefaultStore. The options are passed to
+// session.New.
+func New(token string, opts ...session.Option) (*State, error) {
+	return NewWithStore(token, NewDefaultStore(nil), opts...)
 }
 
-func NewWithStore(token string, store Store) (*State, error) {
-	s, err := session.New(token)
+func NewWithStore(
+	token string, store Store, opts ...session.Option) (*State, error) {
+
+	s, err := session.New(token, opts...)
 	if err != nil {
 		return nil, err
 	}
@@ -69,6 +129,21 @@ func NewWithStore(token string, store Store) (*State, error) {
 	return NewFromSession(s, store)
 }
 
+// SetLogger makes the Session log to l, and StateLog log to l at the debug
+// level.
+func (s *State) SetLogger(l logger.Logger) {
+	s.Session.SetLogger(l)
+	s.StateLog = logger.ErrorFunc(l, logger.DebugLevel,
+		logger.F("component", "state"))
+}
+
+// storeAccess reports to Metrics whether the resource was found in the store.
+func (s *State) storeAccess(resource string, hit bool) {
+	if s.Metrics != nil {
+		s.Metrics.StoreAccess(resource, hit)
+	}
+}
+
 // Unhook removes all state handlers from the session handlers.
 func (s *State) Unhook() {
 	s.unhooker()
@@ -78,12 +153,12 @@ func (s *State) Unhook() {
 
 func (s *State) AuthorDisplayName(message discord.Message) string {
 	if !message.GuildID.Valid() {
-		return message.Author.Username
+		return message.Author.DisplayName()
 	}
 
 	n, err := s.MemberDisplayName(message.GuildID, message.Author.ID)
 	if err != nil {
-		return message.Author.Username
+		return message.Author.DisplayName()
 	}
 
 	return n
@@ -98,7 +173,7 @@ func (s *State) MemberDisplayName(
 	}
 
 	if member.Nick == "" {
-		return member.User.Username, nil
+		return member.User.DisplayName(), nil
 	}
 
 	return member.Nick, nil
@@ -126,6 +201,30 @@ func (s *State) MemberColor(guildID, userID discord.Snowflake) discord.Color {
 	return discord.MemberColor(*guild, *member)
 }
 
+// MemberList returns the members of the guild grouped like the member list of
+// clients. Refer to discord.MemberList. Presences are only known from the
+// gateway, so all members are offline without them.
+func (s *State) MemberList(
+	guildID discord.Snowflake) ([]discord.MemberGroup, error) {
+
+	guild, err := s.Guild(guildID)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to get guild")
+	}
+
+	members, err := s.Members(guildID)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to get members")
+	}
+
+	presences, err := s.Presences(guildID)
+	if err != nil && err != ErrStoreNotFound {
+		return nil, errors.Wrap(err, "Failed to get presences")
+	}
+
+	return discord.MemberList(*guild, members, presences), nil
+}
+
 ////
 
 func (s *State) Permissions(
@@ -153,6 +252,7 @@ func (s *State) Permissions(
 
 func (s *State) Self() (*discord.User, error) {
 	u, err := s.Store.Self()
+	s.storeAccess("self", err == nil)
 	if err == nil {
 		return u, nil
 	}
@@ -169,6 +269,7 @@ func (s *State) Self() (*discord.User, error) {
 
 func (s *State) Channel(id discord.Snowflake) (*discord.Channel, error) {
 	c, err := s.Store.Channel(id)
+	s.storeAccess("channel", err == nil)
 	if err == nil {
 		return c, nil
 	}
@@ -183,6 +284,7 @@ func (s *State) Channel(id discord.Snowflake) (*discord.Channel, error) {
 
 func (s *State) Channels(guildID discord.Snowflake) ([]discord.Channel, error) {
 	c, err := s.Store.Channels(guildID)
+	s.storeAccess("channels", err == nil)
 	if err == nil {
 		return c, nil
 	}
@@ -201,12 +303,70 @@ func (s *State) Channels(guildID discord.Snowflake) ([]discord.Channel, error) {
 	return c, nil
 }
 
+// ActiveThreads returns the active threads in the guild that the current user
+// can see.
+func (s *State) ActiveThreads(
+	guildID discord.Snowflake) ([]discord.Channel, error) {
+
+	ts, err := s.Store.Threads(guildID)
+	s.storeAccess("threads", err == nil)
+	if err == nil {
+		return ts, nil
+	}
+
+	threads, err := s.Session.ListActiveThreads(guildID)
+	if err != nil {
+		return nil, err
+	}
+
+	return threads.Threads, s.Store.ThreadListSet(guildID, threads.Threads)
+}
+
+// Threads returns the active threads in the channel that the current user can
+// see.
+func (s *State) Threads(
+	channelID discord.Snowflake) ([]discord.Channel, error) {
+
+	ch, err := s.Channel(channelID)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to get channel")
+	}
+
+	ts, err := s.ActiveThreads(ch.GuildID)
+	if err != nil {
+		return nil, err
+	}
+
+	var threads []discord.Channel
+	for _, t := range ts {
+		if t.CategoryID == channelID {
+			threads = append(threads, t)
+		}
+	}
+
+	return threads, nil
+}
+
+// ChannelsByCategory returns the channels of the guild grouped by category,
+// in the order clients show them. Refer to discord.ChannelsByCategory.
+func (s *State) ChannelsByCategory(
+	guildID discord.Snowflake) ([]discord.CategoryChannels, error) {
+
+	chs, err := s.Channels(guildID)
+	if err != nil {
+		return nil, err
+	}
+
+	return discord.ChannelsByCategory(chs), nil
+}
+
 ////
 
 func (s *State) Emoji(
 	guildID, emojiID discord.Snowflake) (*discord.Emoji, error) {
 
 	e, err := s.Store.Emoji(guildID, emojiID)
+	s.storeAccess("emoji", err == nil)
 	if err == nil {
 		return e, nil
 	}
@@ -231,6 +391,7 @@ func (s *State) Emoji(
 
 func (s *State) Emojis(guildID discord.Snowflake) ([]discord.Emoji, error) {
 	e, err := s.Store.Emojis(guildID)
+	s.storeAccess("emojis", err == nil)
 	if err == nil {
 		return e, nil
 	}
@@ -247,6 +408,7 @@ func (s *State) Emojis(guildID discord.Snowflake) ([]discord.Emoji, error) {
 
 func (s *State) Guild(id discord.Snowflake) (*discord.Guild, error) {
 	c, err := s.Store.Guild(id)
+	s.storeAccess("guild", err == nil)
 	if err == nil {
 		return c, nil
 	}
@@ -262,6 +424,7 @@ func (s *State) Guild(id discord.Snowflake) (*discord.Guild, error) {
 // Guilds will only fill a maximum of 100 guilds from the API.
 func (s *State) Guilds() ([]discord.Guild, error) {
 	c, err := s.Store.Guilds()
+	s.storeAccess("guilds", err == nil)
 	if err == nil {
 		return c, nil
 	}
@@ -286,6 +449,7 @@ func (s *State) Member(
 	guildID, userID discord.Snowflake) (*discord.Member, error) {
 
 	m, err := s.Store.Member(guildID, userID)
+	s.storeAccess("member", err == nil)
 	if err == nil {
 		return m, nil
 	}
@@ -300,6 +464,7 @@ func (s *State) Member(
 
 func (s *State) Members(guildID discord.Snowflake) ([]discord.Member, error) {
 	ms, err := s.Store.Members(guildID)
+	s.storeAccess("members", err == nil)
 	if err == nil {
 		return ms, nil
 	}
@@ -315,18 +480,188 @@ func (s *State) Members(guildID discord.Snowflake) ([]discord.Member, error) {
 		}
 	}
 
-	return ms, s.Gateway.RequestGuildMembers(gateway.RequestGuildMembersData{
+	// Request the rest of the members from the guild's shard.
+	gw := s.GatewayFor(guildID)
+
+	return ms, gw.RequestGuildMembers(gateway.RequestGuildMembersData{
 		GuildID:   []discord.Snowflake{guildID},
 		Presences: true,
 	})
 }
 
+// SearchMembers returns the members whose username or nickname starts with
+// the query, case-insensitively, like the API does. The members in the store
+// are used if there are enough of them to reach the limit, which is 1-1000
+// and defaults to 1. Otherwise, the API is searched, and the members found
+// are added to the store.
+func (s *State) SearchMembers(
+	guildID discord.Snowflake, query string,
+	limit uint) ([]discord.Member, error) {
+
+	switch {
+	case limit == 0:
+		limit = 1
+	case limit > 1000:
+		limit = 1000
+	}
+
+	if ms, err := s.Store.Members(guildID); err == nil {
+		var found = searchMembers(ms, query, limit)
+		if uint(len(found)) == limit {
+			s.storeAccess("members", true)
+			return found, nil
+		}
+	}
+
+	s.storeAccess("members", false)
+
+	ms, err := s.Session.SearchMembers(guildID, query, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range ms {
+		if err := s.Store.MemberSet(guildID, &ms[i]); err != nil {
+			return nil, err
+		}
+	}
+
+	return ms, nil
+}
+
+// searchMembers returns up to limit members whose username or nickname starts
+// with the query, case-insensitively.
+func searchMembers(
+	members []discord.Member, query string, limit uint) []discord.Member {
+
+	var found []discord.Member
+	query = strings.ToLower(query)
+
+	for _, m := range members {
+		if uint(len(found)) == limit {
+			break
+		}
+
+		if strings.HasPrefix(strings.ToLower(m.User.Username), query) ||
+			strings.HasPrefix(strings.ToLower(m.Nick), query) {
+
+			found = append(found, m)
+		}
+	}
+
+	return found
+}
+
+// MembersAll requests all members of the guild from the Gateway, then waits
+// for all of their chunks and returns them. The members are also added to the
+// Store. Unlike Members, this doesn't use the Store or the API.
+//
+// The GUILD_MEMBERS intent is needed if the Gateway is identified with
+// intents. ErrMemberChunkTimeout is returned with the members received so far
+// if a chunk doesn't arrive within MemberChunkTimeout.
+func (s *State) MembersAll(
+	guildID discord.Snowflake) ([]discord.Member, error) {
+
+	var members []discord.Member
+
+	err := s.memberChunks(guildID, true,
+		func(c *gateway.GuildMembersChunkEvent) error {
+			members = append(members, c.Members...)
+			return nil
+		},
+	)
+
+	return members, err
+}
+
+// EachMember requests all members of the guild from the Gateway like
+// MembersAll, but calls fn with the members of each chunk as they arrive, so
+// that the members of a large guild are never all in memory at once. The
+// members aren't added to the Store.
+//
+// If fn returns an error, EachMember stops and returns it. The chunks that
+// are still coming are then added to the Store like any other.
+func (s *State) EachMember(
+	guildID discord.Snowflake, fn func(m *discord.Member) error) error {
+
+	return s.memberChunks(guildID, false,
+		func(c *gateway.GuildMembersChunkEvent) error {
+			for i := range c.Members {
+				if err := fn(&c.Members[i]); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	)
+}
+
+// memberChunks requests all members of the guild, and calls fn with each
+// chunk until all of them are received. The chunks are only added to the
+// Store if store is true.
+func (s *State) memberChunks(guildID discord.Snowflake, store bool,
+	fn func(c *gateway.GuildMembersChunkEvent) error) error {
+
+	var nonce = strconv.FormatUint(atomic.AddUint64(&memberNonce, 1), 10)
+
+	if !store {
+		s.uncachedChunks.Store(nonce, struct{}{})
+		defer s.uncachedChunks.Delete(nonce)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Listen before requesting, so no chunk is missed.
+	chunks := s.ChanFor(ctx, func(c *gateway.GuildMembersChunkEvent) bool {
+		return c.GuildID == guildID && c.Nonce == nonce
+	})
+
+	gw := s.GatewayFor(guildID)
+
+	err := gw.RequestGuildMembers(gateway.RequestGuildMembersData{
+		GuildID: []discord.Snowflake{guildID},
+		Nonce:   nonce,
+	})
+	if err != nil {
+		return errors.Wrap(err, "Failed to request members")
+	}
+
+	var received, count = 0, 1
+
+	for received < count {
+		select {
+		case v := <-chunks:
+			c := v.(*gateway.GuildMembersChunkEvent)
+			received++
+
+			if c.ChunkCount > 0 {
+				count = c.ChunkCount
+			}
+
+			if err := fn(c); err != nil {
+				return err
+			}
+
+		case <-time.After(MemberChunkTimeout):
+			return ErrMemberChunkTimeout
+		}
+	}
+
+	if store {
+		s.fullMembers.Store(guildID, struct{}{})
+	}
+
+	return nil
+}
+
 ////
 
 func (s *State) Message(
 	channelID, messageID discord.Snowflake) (*discord.Message, error) {
 
 	m, err := s.Store.Message(channelID, messageID)
+	s.storeAccess("message", err == nil)
 	if err == nil {
 		return m, nil
 	}
@@ -346,6 +681,17 @@ func (s *State) Message(
 	return m, s.Store.MessageSet(m)
 }
 
+// MessageFromURL resolves a message from its Discord client URL, such as one
+// copied with "Copy Message Link". Refer to discord.ParseMessageURL.
+func (s *State) MessageFromURL(messageURL string) (*discord.Message, error) {
+	_, channelID, messageID, err := discord.ParseMessageURL(messageURL)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.Message(channelID, messageID)
+}
+
 // Messages fetches maximum 100 messages from the API, if it has to. There is no
 // limit if it's from the State storage.
 func (s *State) Messages(channelID discord.Snowflake) ([]discord.Message, error) {
@@ -356,6 +702,7 @@ func (s *State) Messages(channelID discord.Snowflake) ([]discord.Message, error)
 	if err == nil {
 		// If the state already has as many messages as it can, skip the API.
 		if maxMsgs <= len(ms) {
+			s.storeAccess("messages", true)
 			return ms, nil
 		}
 
@@ -365,6 +712,7 @@ func (s *State) Messages(channelID discord.Snowflake) ([]discord.Message, error)
 			if ch == channelID {
 				// Yes, skip the state.
 				s.fewMutex.Unlock()
+				s.storeAccess("messages", true)
 				return ms, nil
 			}
 		}
@@ -373,6 +721,8 @@ func (s *State) Messages(channelID discord.Snowflake) ([]discord.Message, error)
 		s.fewMutex.Unlock()
 	}
 
+	s.storeAccess("messages", false)
+
 	ms, err = s.Session.Messages(channelID, 100)
 	if err != nil {
 		return nil, err
@@ -432,6 +782,7 @@ func (s *State) Role(
 	guildID, roleID discord.Snowflake) (*discord.Role, error) {
 
 	r, err := s.Store.Role(guildID, roleID)
+	s.storeAccess("role", err == nil)
 	if err == nil {
 		return r, nil
 	}
@@ -456,8 +807,20 @@ func (s *State) Role(
 	return role, nil
 }
 
+// EveryoneRole returns the @everyone role of the guild, which has the same ID
+// as the guild.
+func (s *State) EveryoneRole(guildID discord.Snowflake) (*discord.Role, error) {
+	r, err := s.Role(guildID, guildID)
+	if err == nil && r == nil {
+		return nil, ErrStoreNotFound
+	}
+
+	return r, err
+}
+
 func (s *State) Roles(guildID discord.Snowflake) ([]discord.Role, error) {
 	rs, err := s.Store.Roles(guildID)
+	s.storeAccess("roles", err == nil)
 	if err == nil {
 		return rs, nil
 	}
@@ -12,14 +12,17 @@ func (s *State) hookSession() error {
 			s.PreHandler.Call(iface)
 		}
 		s.onEvent(iface)
+		s.onUnreadEvent(iface)
+		s.onTypingEvent(iface)
+		s.onInviteEvent(iface)
+		s.onBackfillEvent(iface)
+		s.onVoiceEvent(iface)
 	})
 
 	return nil
 }
 
 func (s *State) onEvent(iface interface{}) {
-	// TODO: voice states
-
 	switch ev := iface.(type) {
 	case *gateway.ReadyEvent:
 		// Handle guilds
@@ -68,6 +71,14 @@ func (s *State) onEvent(iface interface{}) {
 				s.stateErr(err, "Failed to add a presence from guild in state")
 			}
 		}
+
+		// Threads are only sent by newer gateway versions.
+		if ev.Threads != nil {
+			err := s.Store.ThreadListSet(ev.Guild.ID, ev.Threads)
+			if err != nil {
+				s.stateErr(err, "Failed to set threads from guild in state")
+			}
+		}
 	case *gateway.GuildUpdateEvent:
 		if err := s.Store.GuildSet((*discord.Guild)(ev)); err != nil {
 			s.stateErr(err, "Failed to update guild in state")
@@ -77,7 +88,32 @@ func (s *State) onEvent(iface interface{}) {
 			s.stateErr(err, "Failed to delete guild in state")
 		}
 
+		s.fullMembers.Delete(ev.ID)
+
+	case *gateway.GuildBanAddEvent:
+		if bs, ok := s.Store.(BanStore); ok {
+			err := bs.BanSet(ev.GuildID, &discord.Ban{User: ev.User})
+			if err != nil && err != ErrStoreNotFound {
+				s.stateErr(err, "Failed to add a ban in state")
+			}
+		}
+	case *gateway.GuildBanRemoveEvent:
+		if bs, ok := s.Store.(BanStore); ok {
+			err := bs.BanRemove(ev.GuildID, ev.User.ID)
+			if err != nil && err != ErrStoreNotFound {
+				s.stateErr(err, "Failed to remove a ban in state")
+			}
+		}
+
 	case *gateway.GuildMemberAddEvent:
+		// A member that's already there with the same or a later join is
+		// from an event replayed after a resume, which would undo the updates
+		// that came after it.
+		m, err := s.Store.Member(ev.GuildID, ev.User.ID)
+		if err == nil && !ev.Joined.Time().After(m.Joined.Time()) {
+			break
+		}
+
 		if err := s.Store.MemberSet(ev.GuildID, &ev.Member); err != nil {
 			s.stateErr(err, "Failed to add a member in state")
 		}
@@ -86,6 +122,8 @@ func (s *State) onEvent(iface interface{}) {
 		if err != nil {
 			// We can't do much here.
 			m = &discord.Member{}
+		} else if staleMemberUpdate(m, ev) {
+			break
 		}
 
 		// Update available fields from ev into m
@@ -100,6 +138,11 @@ func (s *State) onEvent(iface interface{}) {
 		}
 
 	case *gateway.GuildMembersChunkEvent:
+		// The members streamed by EachMember aren't stored.
+		if _, ok := s.uncachedChunks.Load(ev.Nonce); ok {
+			break
+		}
+
 		for _, m := range ev.Members {
 			if err := s.Store.MemberSet(ev.GuildID, &m); err != nil {
 				s.stateErr(err, "Failed to add a member from chunk in state")
@@ -145,7 +188,41 @@ func (s *State) onEvent(iface interface{}) {
 
 		// *gateway.ChannelPinsUpdateEvent is not tracked.
 
+	case *gateway.ThreadCreateEvent:
+		if err := s.Store.ThreadSet((*discord.Channel)(ev)); err != nil {
+			s.stateErr(err, "Failed to create a thread in state")
+		}
+	case *gateway.ThreadUpdateEvent:
+		var err error
+
+		// Only active threads are kept.
+		if ev.ThreadMetadata != nil && ev.ThreadMetadata.Archived {
+			err = s.Store.ThreadRemove(ev.GuildID, ev.ID)
+		} else {
+			err = s.Store.ThreadSet((*discord.Channel)(ev))
+		}
+
+		if err != nil && err != ErrStoreNotFound {
+			s.stateErr(err, "Failed to update a thread in state")
+		}
+	case *gateway.ThreadDeleteEvent:
+		if err := s.Store.ThreadRemove(ev.GuildID, ev.ID); err != nil {
+			s.stateErr(err, "Failed to remove a thread in state")
+		}
+	case *gateway.ThreadListSyncEvent:
+		if err := s.syncThreads(ev); err != nil {
+			s.stateErr(err, "Failed to sync threads in state")
+		}
+
+		// Thread members are not tracked.
+
 	case *gateway.MessageCreateEvent:
+		// The message is already there if the event was replayed after a
+		// resume, and it could have been edited since.
+		if _, err := s.Store.Message(ev.ChannelID, ev.ID); err == nil {
+			break
+		}
+
 		if err := s.Store.MessageSet((*discord.Message)(ev)); err != nil {
 			s.stateErr(err, "Failed to add a message in state")
 		}
@@ -176,3 +253,51 @@ func (s *State) onEvent(iface interface{}) {
 func (s *State) stateErr(err error, wrap string) {
 	s.ErrorLog(errors.Wrap(err, wrap))
 }
+
+// syncThreads replaces the threads of the synced channels, or of the whole
+// guild if no channels are given.
+func (s *State) syncThreads(ev *gateway.ThreadListSyncEvent) error {
+	if len(ev.ChannelIDs) == 0 {
+		return s.Store.ThreadListSet(ev.GuildID, ev.Threads)
+	}
+
+	old, err := s.Store.Threads(ev.GuildID)
+	if err != nil && err != ErrStoreNotFound {
+		return err
+	}
+
+	var threads = append([]discord.Channel{}, ev.Threads...)
+
+Old:
+	for _, t := range old {
+		for _, id := range ev.ChannelIDs {
+			if t.CategoryID == id {
+				continue Old
+			}
+		}
+
+		threads = append(threads, t)
+	}
+
+	return s.Store.ThreadListSet(ev.GuildID, threads)
+}
+
+// staleMemberUpdate returns true if the update is older than the member, such
+// as one replayed after a resume. It's only a guess, as updates have no
+// timestamp: an update from before the member last joined is stale, and so is
+// one from an older boost.
+func staleMemberUpdate(
+	m *discord.Member, ev *gateway.GuildMemberUpdateEvent) bool {
+
+	if !ev.Joined.Valid() || !m.Joined.Valid() {
+		return false
+	}
+
+	if ev.Joined.Time().Before(m.Joined.Time()) {
+		return true
+	}
+
+	return ev.Joined.Time().Equal(m.Joined.Time()) &&
+		ev.BoostedSince.Valid() && m.BoostedSince.Valid() &&
+		ev.BoostedSince.Time().Before(m.BoostedSince.Time())
+}
@@ -0,0 +1,239 @@
+// +build unit
+
+package state
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/diamondburned/arikawa/discord"
+	"github.com/diamondburned/arikawa/gateway"
+	"github.com/diamondburned/arikawa/handler"
+	"github.com/diamondburned/arikawa/session"
+)
+
+func TestThreadEvents(t *testing.T) {
+	s := &State{
+		Session: &session.Session{
+			ErrorLog: func(err error) { t.Error(err) },
+		},
+		Store: NewDefaultStore(nil),
+	}
+
+	var thread = func(id, parent discord.Snowflake) discord.Channel {
+		return discord.Channel{
+			ID:         id,
+			GuildID:    1,
+			CategoryID: parent,
+			Type:       discord.GuildPublicThread,
+		}
+	}
+
+	s.onEvent(&gateway.ThreadListSyncEvent{
+		GuildID: 1,
+		Threads: []discord.Channel{thread(10, 2), thread(11, 3)},
+	})
+
+	// Only the threads of channel 2 are replaced.
+	s.onEvent(&gateway.ThreadListSyncEvent{
+		GuildID:    1,
+		ChannelIDs: []discord.Snowflake{2},
+		Threads:    []discord.Channel{thread(12, 2)},
+	})
+
+	var created = gateway.ThreadCreateEvent(thread(13, 3))
+	s.onEvent(&created)
+
+	var archived = gateway.ThreadUpdateEvent(thread(11, 3))
+	archived.ThreadMetadata = &discord.ThreadMetadata{Archived: true}
+	s.onEvent(&archived)
+
+	threads, err := s.Store.Threads(1)
+	if err != nil {
+		t.Fatal("Failed to get threads:", err)
+	}
+
+	var ids []discord.Snowflake
+	for _, t := range threads {
+		ids = append(ids, t.ID)
+	}
+
+	if expect := []discord.Snowflake{12, 13}; !reflect.DeepEqual(ids, expect) {
+		t.Fatalf("Unexpected threads %v, expected %v", ids, expect)
+	}
+}
+
+func TestUnreadCount(t *testing.T) {
+	s := &State{
+		Session: &session.Session{
+			Handler:  handler.New(),
+			ErrorLog: func(err error) { t.Error(err) },
+		},
+		Store: NewDefaultStore(nil),
+	}
+	s.Handler.Synchronous = true
+
+	var updates []UnreadUpdateEvent
+	s.AddHandler(func(ev *UnreadUpdateEvent) {
+		updates = append(updates, *ev)
+	})
+
+	var dispatch = func(ev interface{}) {
+		s.onEvent(ev)
+		s.onUnreadEvent(ev)
+	}
+
+	dispatch(&gateway.ReadyEvent{
+		User: discord.User{ID: 1},
+		ReadState: []gateway.ReadState{
+			{ChannelID: 2, LastMessageID: 10, MentionCount: 1},
+		},
+	})
+
+	type msg = gateway.MessageCreateEvent
+
+	var message = func(id, author discord.Snowflake) *msg {
+		return &gateway.MessageCreateEvent{
+			ID:        id,
+			ChannelID: 2,
+			Author:    discord.User{ID: author},
+		}
+	}
+
+	dispatch(message(11, 3))
+
+	var mention = message(12, 3)
+	mention.Mentions = []discord.GuildUser{{User: discord.User{ID: 1}}}
+	dispatch(mention)
+
+	if n := s.UnreadCount(2); n != 2 {
+		t.Fatal("Unexpected unread count:", n)
+	}
+	if n := s.MentionCount(2); n != 2 {
+		t.Fatal("Unexpected mention count:", n)
+	}
+
+	dispatch(&gateway.MessageAckEvent{ChannelID: 2, MessageID: 11})
+
+	var expect = []UnreadUpdateEvent{
+		{ChannelID: 2, Unread: 1, Mentions: 1},
+		{ChannelID: 2, Unread: 2, Mentions: 2},
+		{ChannelID: 2, Unread: 1, Mentions: 0},
+	}
+	if !reflect.DeepEqual(updates, expect) {
+		t.Fatalf("Unexpected updates %+v, expected %+v", updates, expect)
+	}
+}
+
+func TestTypingUsers(t *testing.T) {
+	defer func(timeout time.Duration) { TypingTimeout = timeout }(TypingTimeout)
+	TypingTimeout = 50 * time.Millisecond
+
+	s := &State{
+		Session:     &session.Session{Handler: handler.New()},
+		Store:       NewDefaultStore(nil),
+		TrackTyping: true,
+	}
+
+	var stopped = make(chan *TypingStoppedEvent, 2)
+	s.AddHandler(func(ev *TypingStoppedEvent) { stopped <- ev })
+
+	s.onTypingEvent(&gateway.TypingStartEvent{ChannelID: 1, UserID: 2})
+	s.onTypingEvent(&gateway.TypingStartEvent{ChannelID: 1, UserID: 3})
+
+	if users := s.TypingUsers(1); len(users) != 2 || users[0].UserID != 2 {
+		t.Fatalf("Unexpected typing users: %+v", users)
+	}
+
+	if ids := s.TypingIn(1); len(ids) != 2 || ids[0] != 2 || ids[1] != 3 {
+		t.Fatal("Unexpected typing user IDs:", ids)
+	}
+
+	// Sending a message stops typing.
+	s.onTypingEvent(&gateway.MessageCreateEvent{
+		ChannelID: 1,
+		Author:    discord.User{ID: 2},
+	})
+
+	for _, user := range []discord.Snowflake{2, 3} {
+		select {
+		case ev := <-stopped:
+			if ev.UserID != user {
+				t.Fatal("Unexpected user stopped typing:", ev.UserID)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("Timed out waiting for user", user)
+		}
+	}
+
+	if users := s.TypingUsers(1); len(users) != 0 {
+		t.Fatalf("Unexpected typing users: %+v", users)
+	}
+}
+
+func TestResumeReplay(t *testing.T) {
+	s := &State{
+		Session: &session.Session{
+			ErrorLog: func(err error) { t.Error(err) },
+		},
+		Store: NewDefaultStore(nil),
+	}
+
+	var now = time.Now().UTC()
+	var joined = discord.Timestamp(now)
+
+	// The events before and after the session was resumed.
+	var events = []interface{}{
+		&gateway.MessageCreateEvent{ID: 1, ChannelID: 2, Content: "a"},
+		&gateway.GuildMemberAddEvent{
+			Member: discord.Member{User: discord.User{ID: 3}, Joined: joined},
+		},
+		&gateway.MessageUpdateEvent{ID: 1, ChannelID: 2, Content: "b"},
+		&gateway.GuildMemberUpdateEvent{
+			User:         discord.User{ID: 3},
+			RoleIDs:      []discord.Snowflake{4},
+			Joined:       joined,
+			BoostedSince: discord.Timestamp(now.Add(time.Hour)),
+		},
+	}
+
+	// The resume replays the events that weren't acknowledged.
+	events = append(events, events...)
+	events = append(events,
+		// From a previous membership.
+		&gateway.GuildMemberUpdateEvent{
+			User:   discord.User{ID: 3},
+			Joined: discord.Timestamp(now.Add(-time.Hour)),
+		},
+		// From a previous boost.
+		&gateway.GuildMemberUpdateEvent{
+			User:         discord.User{ID: 3},
+			Joined:       joined,
+			BoostedSince: discord.Timestamp(now),
+		},
+	)
+
+	for _, ev := range events {
+		s.onEvent(ev)
+	}
+
+	m, err := s.Store.Message(2, 1)
+	if err != nil {
+		t.Fatal("Failed to get message:", err)
+	}
+	if m.Content != "b" {
+		t.Fatalf("Edit was undone: %q", m.Content)
+	}
+
+	member, err := s.Store.Member(0, 3)
+	if err != nil {
+		t.Fatal("Failed to get member:", err)
+	}
+	if len(member.RoleIDs) != 1 || !member.Joined.Time().Equal(now) {
+		t.Fatalf("Update was undone: %+v", member)
+	}
+	if !member.BoostedSince.Time().Equal(now.Add(time.Hour)) {
+		t.Fatal("Boost was undone:", member.BoostedSince.Time())
+	}
+}
@@ -0,0 +1,72 @@
+// +build unit
+
+package state
+
+import (
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/diamondburned/arikawa/api"
+	"github.com/diamondburned/arikawa/discord"
+	"github.com/diamondburned/arikawa/session"
+)
+
+func TestSearchMembers(t *testing.T) {
+	var paths []string
+
+	client := api.NewClient("")
+	client.Client.Client.Transport = roundTripFunc(
+		func(r *http.Request) *http.Response {
+			paths = append(paths, r.URL.Path)
+			return &http.Response{
+				StatusCode: 200,
+				Header:     http.Header{},
+				Body: ioutil.NopCloser(strings.NewReader(
+					`[{"user":{"id":"3","username":"Alex"}},` +
+						`{"user":{"id":"4","username":"alfred"}}]`,
+				)),
+			}
+		},
+	)
+
+	s := &State{
+		Session: &session.Session{Client: client},
+		Store:   NewDefaultStore(nil),
+	}
+
+	for _, m := range []discord.Member{
+		{User: discord.User{ID: 1, Username: "bob"}, Nick: "Alice"},
+		{User: discord.User{ID: 2, Username: "carol"}},
+	} {
+		s.Store.MemberSet(1, &m)
+	}
+
+	// The store has enough members.
+	ms, err := s.SearchMembers(1, "al", 1)
+	if err != nil {
+		t.Fatal("Failed to search members:", err)
+	}
+	if len(ms) != 1 || ms[0].User.ID != 1 || len(paths) != 0 {
+		t.Fatal("Unexpected members:", ms, paths)
+	}
+
+	// The store doesn't.
+	ms, err = s.SearchMembers(1, "al", 2)
+	if err != nil {
+		t.Fatal("Failed to search members:", err)
+	}
+	if len(ms) != 2 || len(paths) != 1 {
+		t.Fatal("Unexpected members:", ms, paths)
+	}
+
+	if paths[0] != "/api/v6/guilds/1/members/search" {
+		t.Fatal("Unexpected path:", paths[0])
+	}
+
+	// The members found were stored.
+	if _, err := s.Store.Member(1, 4); err != nil {
+		t.Fatal("Member wasn't stored:", err)
+	}
+}
@@ -8,74 +8,152 @@ import (
 
 // Store is the state storage. It should handle mutex itself, and it should only
 // concern itself with the local state.
-type Store interface {
-	StoreGetter
-	StoreModifier
-}
-
-// All methods in StoreGetter will be wrapped by the State. If the State can't
-// find anything in the storage, it will call the API itself and automatically
-// add what's missing into the storage.
+//
+// Store is made of one interface per resource, so that each resource can be
+// stored differently; see NewStoreFromParts.
+//
+// All getters will be wrapped by the State. If the State can't find anything in
+// the storage, it will call the API itself and automatically add what's missing
+// into the storage.
 //
 // Methods that return with a slice should pay attention to race conditions that
 // would mutate the underlying slice (and as a result the returned slice as
 // well). The best way to avoid this is to copy the whole slice, like
 // DefaultStore does.
-type StoreGetter interface {
-	Self() (*discord.User, error)
-
-	Channel(id discord.Snowflake) (*discord.Channel, error)
-	Channels(guildID discord.Snowflake) ([]discord.Channel, error)
-	PrivateChannels() ([]discord.Channel, error)
+type Store interface {
+	MeStore
+	ChannelStore
+	EmojiStore
+	GuildStore
+	MemberStore
+	MessageStore
+	PresenceStore
+	RoleStore
+	ThreadStore
 
-	Emoji(guildID, emojiID discord.Snowflake) (*discord.Emoji, error)
-	Emojis(guildID discord.Snowflake) ([]discord.Emoji, error)
+	// This should reset all the state to zero/null.
+	Reset() error
+}
 
-	Guild(id discord.Snowflake) (*discord.Guild, error)
-	Guilds() ([]discord.Guild, error)
+// Resetter is implemented by the parts given to NewStoreFromParts that can be
+// reset.
+type Resetter interface {
+	Reset() error
+}
 
-	Member(guildID, userID discord.Snowflake) (*discord.Member, error)
-	Members(guildID discord.Snowflake) ([]discord.Member, error)
+// Flusher is implemented by stores that write behind, which State.Drain
+// flushes before closing. None of the stores in this package need it, as they
+// write right away.
+type Flusher interface {
+	Flush() error
+}
 
-	Message(channelID, messageID discord.Snowflake) (*discord.Message, error)
-	Messages(channelID discord.Snowflake) ([]discord.Message, error)
-	MaxMessages() int // used to know if the state is filled or not.
+// BanStore is an optional part of a Store that caches the bans of guilds, which
+// State.IsBanned uses if the Store implements it. DefaultStore does.
+//
+// BanSet and BanRemove should only change the bans of guilds whose ban list
+// was set with BanListSet, and return ErrStoreNotFound otherwise, so that the
+// ban lists are always complete.
+type BanStore interface {
+	Ban(guildID, userID discord.Snowflake) (*discord.Ban, error)
+	Bans(guildID discord.Snowflake) ([]discord.Ban, error)
+
+	BanSet(guildID discord.Snowflake, ban *discord.Ban) error
+	BanRemove(guildID, userID discord.Snowflake) error
+	// BanListSet replaces all the bans of the guild.
+	BanListSet(guildID discord.Snowflake, bans []discord.Ban) error
+}
 
-	// These don't get fetched from the API, it's Gateway only.
-	Presence(guildID, userID discord.Snowflake) (*discord.Presence, error)
-	Presences(guildID discord.Snowflake) ([]discord.Presence, error)
+// VoiceStateStore is an optional part of a Store that caches the voice states
+// of guilds, which State.VoiceState and State.VoiceStatesIn use if the Store
+// implements it. DefaultStore does. The voice states are those of the users
+// connected to a voice channel, and the State removes the ones that leave.
+type VoiceStateStore interface {
+	VoiceState(guildID, userID discord.Snowflake) (*discord.VoiceState, error)
+	VoiceStates(guildID discord.Snowflake) ([]discord.VoiceState, error)
 
-	Role(guildID, roleID discord.Snowflake) (*discord.Role, error)
-	Roles(guildID discord.Snowflake) ([]discord.Role, error)
+	VoiceStateSet(guildID discord.Snowflake, state *discord.VoiceState) error
+	VoiceStateRemove(guildID, userID discord.Snowflake) error
 }
 
-type StoreModifier interface {
+type MeStore interface {
+	Self() (*discord.User, error)
 	SelfSet(me *discord.User) error
+}
+
+type ChannelStore interface {
+	Channel(id discord.Snowflake) (*discord.Channel, error)
+	Channels(guildID discord.Snowflake) ([]discord.Channel, error)
+	PrivateChannels() ([]discord.Channel, error)
 
 	// ChannelSet should switch on Type to know if it's a private channel or
 	// not.
 	ChannelSet(*discord.Channel) error
 	ChannelRemove(*discord.Channel) error
+}
+
+type EmojiStore interface {
+	Emoji(guildID, emojiID discord.Snowflake) (*discord.Emoji, error)
+	Emojis(guildID discord.Snowflake) ([]discord.Emoji, error)
 
 	EmojiSet(guildID discord.Snowflake, emojis []discord.Emoji) error
+}
+
+type GuildStore interface {
+	Guild(id discord.Snowflake) (*discord.Guild, error)
+	Guilds() ([]discord.Guild, error)
 
 	GuildSet(*discord.Guild) error
 	GuildRemove(id discord.Snowflake) error
+}
+
+type MemberStore interface {
+	Member(guildID, userID discord.Snowflake) (*discord.Member, error)
+	Members(guildID discord.Snowflake) ([]discord.Member, error)
 
 	MemberSet(guildID discord.Snowflake, member *discord.Member) error
 	MemberRemove(guildID, userID discord.Snowflake) error
+}
+
+type MessageStore interface {
+	Message(channelID, messageID discord.Snowflake) (*discord.Message, error)
+	Messages(channelID discord.Snowflake) ([]discord.Message, error)
+	MaxMessages() int // used to know if the state is filled or not.
 
 	MessageSet(*discord.Message) error
 	MessageRemove(channelID, messageID discord.Snowflake) error
+}
+
+// PresenceStore's getters don't get fetched from the API, it's Gateway only.
+type PresenceStore interface {
+	Presence(guildID, userID discord.Snowflake) (*discord.Presence, error)
+	Presences(guildID discord.Snowflake) ([]discord.Presence, error)
 
 	PresenceSet(guildID discord.Snowflake, presence *discord.Presence) error
 	PresenceRemove(guildID, userID discord.Snowflake) error
+}
+
+// RoleStore stores roles, which DefaultStore keeps inside their guild, so it
+// should usually be the same as the GuildStore.
+type RoleStore interface {
+	Role(guildID, roleID discord.Snowflake) (*discord.Role, error)
+	Roles(guildID discord.Snowflake) ([]discord.Role, error)
 
 	RoleSet(guildID discord.Snowflake, role *discord.Role) error
 	RoleRemove(guildID, roleID discord.Snowflake) error
+}
 
-	// This should reset all the state to zero/null.
-	Reset() error
+// ThreadStore stores the active threads of guilds, which are only known from
+// the gateway or from the API. Threads are channels, but they are kept apart
+// from the ChannelStore, like the API does.
+type ThreadStore interface {
+	Threads(guildID discord.Snowflake) ([]discord.Channel, error)
+
+	ThreadSet(thread *discord.Channel) error
+	ThreadRemove(guildID, threadID discord.Snowflake) error
+	// ThreadListSet replaces all the active threads of the guild. An empty
+	// list means that the guild has no active threads.
+	ThreadListSet(guildID discord.Snowflake, threads []discord.Channel) error
 }
 
 // ErrStoreNotFound is an error that a store can use to return when something
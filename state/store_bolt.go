@@ -0,0 +1,693 @@
+package state
+
+import (
+	"encoding/binary"
+	"sync"
+	"time"
+
+	"github.com/diamondburned/arikawa/discord"
+	"github.com/diamondburned/arikawa/internal/json"
+	"github.com/pkg/errors"
+	bolt "go.etcd.io/bbolt"
+)
+
+var (
+	boltSelf     = []byte("self")
+	boltGuilds   = []byte("guilds")
+	boltChannels = []byte("channels")
+	boltMembers  = []byte("members")  // guildID -> userID:member
+	boltMessages = []byte("messages") // channelID -> messageID:message
+)
+
+// BoltStore is a Store that persists the user, guilds, channels, members and
+// messages into a bbolt database file, so the cache survives restarts.
+// Presences and threads are only kept in memory.
+//
+// Everything read from the database is kept in a DefaultStore. Guilds and
+// channels are loaded on first access, while members and messages are loaded
+// one guild or channel at a time, as they're accessed.
+type BoltStore struct {
+	*BoltStoreOptions
+
+	DB *bolt.DB
+	json.Driver
+
+	mem    *DefaultStore
+	loaded map[string]bool
+	mutex  sync.Mutex
+}
+
+type BoltStoreOptions struct {
+	MaxMessages uint // default 50
+}
+
+var _ Store = (*BoltStore)(nil)
+
+// NewBoltStore opens or creates the database file at path and creates a
+// BoltStore with it.
+func NewBoltStore(path string, opts *BoltStoreOptions) (*BoltStore, error) {
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: 5 * time.Second})
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to open database")
+	}
+
+	s, err := NewBoltStoreFromDB(db, opts)
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// NewBoltStoreFromDB creates a BoltStore with an already opened database.
+func NewBoltStoreFromDB(
+	db *bolt.DB, opts *BoltStoreOptions) (*BoltStore, error) {
+
+	// The options are copied, so the defaults don't change the caller's.
+	var o BoltStoreOptions
+	if opts != nil {
+		o = *opts
+	}
+
+	if o.MaxMessages == 0 {
+		o.MaxMessages = 50
+	}
+
+	s := &BoltStore{
+		BoltStoreOptions: &o,
+		DB:               db,
+		Driver:           json.Default{},
+		mem: NewDefaultStore(&DefaultStoreOptions{
+			MaxMessages: o.MaxMessages,
+		}),
+		loaded: map[string]bool{},
+	}
+
+	if err := db.Update(createBuckets); err != nil {
+		return nil, errors.Wrap(err, "Failed to create buckets")
+	}
+
+	return s, nil
+}
+
+func createBuckets(tx *bolt.Tx) error {
+	for _, name := range [][]byte{
+		boltSelf, boltGuilds, boltChannels, boltMembers, boltMessages} {
+
+		if _, err := tx.CreateBucketIfNotExists(name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Close closes the database.
+func (s *BoltStore) Close() error {
+	return s.DB.Close()
+}
+
+func (s *BoltStore) Reset() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	err := s.DB.Update(func(tx *bolt.Tx) error {
+		for _, name := range [][]byte{
+			boltSelf, boltGuilds, boltChannels, boltMembers, boltMessages} {
+
+			if err := tx.DeleteBucket(name); err != nil {
+				return err
+			}
+		}
+		return createBuckets(tx)
+	})
+	if err != nil {
+		return errors.Wrap(err, "Failed to reset database")
+	}
+
+	s.loaded = map[string]bool{}
+	return s.mem.Reset()
+}
+
+// load calls fn to load a collection from the database into memory, unless
+// it's already loaded. Every method loads the collections it uses before
+// accessing the memory store, so that it's always complete.
+func (s *BoltStore) load(key string, fn func(tx *bolt.Tx) error) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.loaded[key] {
+		return nil
+	}
+
+	if err := s.DB.View(fn); err != nil {
+		return errors.Wrap(err, "Failed to load "+key)
+	}
+
+	s.loaded[key] = true
+	return nil
+}
+
+// put encodes v and puts it into the bucket, which is nested in parent if
+// parent isn't nil.
+func (s *BoltStore) put(parent, bucket, key []byte, v interface{}) error {
+	b, err := s.Marshal(v)
+	if err != nil {
+		return errors.Wrap(err, "Failed to encode")
+	}
+
+	return s.DB.Update(func(tx *bolt.Tx) error {
+		bk, err := boltBucket(tx, parent, bucket, true)
+		if err != nil {
+			return err
+		}
+		return bk.Put(key, b)
+	})
+}
+
+func (s *BoltStore) delete(parent, bucket, key []byte) error {
+	return s.DB.Update(func(tx *bolt.Tx) error {
+		bk, err := boltBucket(tx, parent, bucket, false)
+		if bk == nil || err != nil {
+			return err
+		}
+		return bk.Delete(key)
+	})
+}
+
+// each calls fn with every value of the bucket, which is nested in parent if
+// parent isn't nil. Nothing is done if the bucket doesn't exist.
+func (s *BoltStore) each(tx *bolt.Tx,
+	parent, bucket []byte, fn func(b []byte) error) error {
+
+	bk, err := boltBucket(tx, parent, bucket, false)
+	if bk == nil || err != nil {
+		return err
+	}
+
+	return bk.ForEach(func(k, b []byte) error {
+		if b == nil {
+			// Nested bucket.
+			return nil
+		}
+		return fn(b)
+	})
+}
+
+// boltBucket returns the bucket, which is nested in parent if parent isn't
+// nil. If create is false, nil is returned if it doesn't exist.
+func boltBucket(tx *bolt.Tx,
+	parent, bucket []byte, create bool) (*bolt.Bucket, error) {
+
+	if parent == nil {
+		return tx.Bucket(bucket), nil
+	}
+
+	p := tx.Bucket(parent)
+	if !create {
+		return p.Bucket(bucket), nil
+	}
+
+	if tx.Writable() {
+		return p.CreateBucketIfNotExists(bucket)
+	}
+	return p.Bucket(bucket), nil
+}
+
+// boltKey encodes the ID in big endian, so keys are sorted by ID, which is
+// chronological.
+func boltKey(id discord.Snowflake) []byte {
+	var b = make([]byte, 8)
+	binary.BigEndian.PutUint64(b, uint64(id))
+	return b
+}
+
+////
+
+func (s *BoltStore) loadSelf() error {
+	return s.load("self", func(tx *bolt.Tx) error {
+		b := tx.Bucket(boltSelf).Get(boltSelf)
+		if b == nil {
+			return nil
+		}
+
+		var me discord.User
+		if err := s.Unmarshal(b, &me); err != nil {
+			return errors.Wrap(err, "Failed to decode self")
+		}
+
+		return s.mem.SelfSet(&me)
+	})
+}
+
+func (s *BoltStore) Self() (*discord.User, error) {
+	if err := s.loadSelf(); err != nil {
+		return nil, err
+	}
+	return s.mem.Self()
+}
+
+func (s *BoltStore) SelfSet(me *discord.User) error {
+	if err := s.put(nil, boltSelf, boltSelf, me); err != nil {
+		return err
+	}
+	return s.mem.SelfSet(me)
+}
+
+////
+
+func (s *BoltStore) loadChannels() error {
+	return s.load("channels", func(tx *bolt.Tx) error {
+		return s.each(tx, nil, boltChannels, func(b []byte) error {
+			var ch discord.Channel
+			if err := s.Unmarshal(b, &ch); err != nil {
+				return errors.Wrap(err, "Failed to decode channel")
+			}
+			return s.mem.ChannelSet(&ch)
+		})
+	})
+}
+
+func (s *BoltStore) Channel(id discord.Snowflake) (*discord.Channel, error) {
+	if err := s.loadChannels(); err != nil {
+		return nil, err
+	}
+
+	// DefaultStore only looks up guild channels by ID.
+	if ch, err := s.mem.Channel(id); err == nil {
+		return ch, nil
+	}
+
+	chs, err := s.mem.PrivateChannels()
+	if err != nil {
+		return nil, err
+	}
+	for _, ch := range chs {
+		if ch.ID == id {
+			return &ch, nil
+		}
+	}
+
+	return nil, ErrStoreNotFound
+}
+
+func (s *BoltStore) Channels(
+	guildID discord.Snowflake) ([]discord.Channel, error) {
+
+	if err := s.loadChannels(); err != nil {
+		return nil, err
+	}
+	return s.mem.Channels(guildID)
+}
+
+func (s *BoltStore) PrivateChannels() ([]discord.Channel, error) {
+	if err := s.loadChannels(); err != nil {
+		return nil, err
+	}
+	return s.mem.PrivateChannels()
+}
+
+func (s *BoltStore) ChannelSet(channel *discord.Channel) error {
+	if err := s.loadChannels(); err != nil {
+		return err
+	}
+
+	err := s.put(nil, boltChannels, boltKey(channel.ID), channel)
+	if err != nil {
+		return err
+	}
+
+	return s.mem.ChannelSet(channel)
+}
+
+func (s *BoltStore) ChannelRemove(channel *discord.Channel) error {
+	if err := s.loadChannels(); err != nil {
+		return err
+	}
+
+	if err := s.delete(nil, boltChannels, boltKey(channel.ID)); err != nil {
+		return err
+	}
+
+	return s.mem.ChannelRemove(channel)
+}
+
+////
+
+// Emojis and roles are stored in the guild.
+
+func (s *BoltStore) Emoji(
+	guildID, emojiID discord.Snowflake) (*discord.Emoji, error) {
+
+	if err := s.loadGuilds(); err != nil {
+		return nil, err
+	}
+	return s.mem.Emoji(guildID, emojiID)
+}
+
+func (s *BoltStore) Emojis(
+	guildID discord.Snowflake) ([]discord.Emoji, error) {
+
+	if err := s.loadGuilds(); err != nil {
+		return nil, err
+	}
+	return s.mem.Emojis(guildID)
+}
+
+func (s *BoltStore) EmojiSet(
+	guildID discord.Snowflake, emojis []discord.Emoji) error {
+
+	if err := s.loadGuilds(); err != nil {
+		return err
+	}
+	if err := s.mem.EmojiSet(guildID, emojis); err != nil {
+		return err
+	}
+	return s.saveGuild(guildID)
+}
+
+////
+
+func (s *BoltStore) loadGuilds() error {
+	return s.load("guilds", func(tx *bolt.Tx) error {
+		return s.each(tx, nil, boltGuilds, func(b []byte) error {
+			var g discord.Guild
+			if err := s.Unmarshal(b, &g); err != nil {
+				return errors.Wrap(err, "Failed to decode guild")
+			}
+			return s.mem.GuildSet(&g)
+		})
+	})
+}
+
+// saveGuild writes the guild in memory into the database.
+func (s *BoltStore) saveGuild(guildID discord.Snowflake) error {
+	g, err := s.mem.Guild(guildID)
+	if err != nil {
+		return err
+	}
+	return s.put(nil, boltGuilds, boltKey(guildID), g)
+}
+
+func (s *BoltStore) Guild(id discord.Snowflake) (*discord.Guild, error) {
+	if err := s.loadGuilds(); err != nil {
+		return nil, err
+	}
+	return s.mem.Guild(id)
+}
+
+func (s *BoltStore) Guilds() ([]discord.Guild, error) {
+	if err := s.loadGuilds(); err != nil {
+		return nil, err
+	}
+	return s.mem.Guilds()
+}
+
+func (s *BoltStore) GuildSet(guild *discord.Guild) error {
+	if err := s.loadGuilds(); err != nil {
+		return err
+	}
+	// The memory store keeps the roles and emojis if they're missing.
+	if err := s.mem.GuildSet(guild); err != nil {
+		return err
+	}
+	return s.saveGuild(guild.ID)
+}
+
+func (s *BoltStore) GuildRemove(id discord.Snowflake) error {
+	if err := s.loadGuilds(); err != nil {
+		return err
+	}
+
+	err := s.DB.Update(func(tx *bolt.Tx) error {
+		if err := tx.Bucket(boltGuilds).Delete(boltKey(id)); err != nil {
+			return err
+		}
+
+		err := tx.Bucket(boltMembers).DeleteBucket(boltKey(id))
+		if err != nil && err != bolt.ErrBucketNotFound {
+			return err
+		}
+
+		return nil
+	})
+	if err != nil {
+		return errors.Wrap(err, "Failed to remove guild")
+	}
+
+	return s.mem.GuildRemove(id)
+}
+
+////
+
+func (s *BoltStore) loadMembers(guildID discord.Snowflake) error {
+	return s.load("members:"+guildID.String(), func(tx *bolt.Tx) error {
+		return s.each(tx, boltMembers, boltKey(guildID), func(b []byte) error {
+			var m discord.Member
+			if err := s.Unmarshal(b, &m); err != nil {
+				return errors.Wrap(err, "Failed to decode member")
+			}
+			return s.mem.MemberSet(guildID, &m)
+		})
+	})
+}
+
+func (s *BoltStore) Member(
+	guildID, userID discord.Snowflake) (*discord.Member, error) {
+
+	if err := s.loadMembers(guildID); err != nil {
+		return nil, err
+	}
+	return s.mem.Member(guildID, userID)
+}
+
+func (s *BoltStore) Members(
+	guildID discord.Snowflake) ([]discord.Member, error) {
+
+	if err := s.loadMembers(guildID); err != nil {
+		return nil, err
+	}
+	return s.mem.Members(guildID)
+}
+
+func (s *BoltStore) MemberSet(
+	guildID discord.Snowflake, member *discord.Member) error {
+
+	if err := s.loadMembers(guildID); err != nil {
+		return err
+	}
+
+	err := s.put(boltMembers, boltKey(guildID), boltKey(member.User.ID), member)
+	if err != nil {
+		return err
+	}
+
+	return s.mem.MemberSet(guildID, member)
+}
+
+func (s *BoltStore) MemberRemove(guildID, userID discord.Snowflake) error {
+	if err := s.loadMembers(guildID); err != nil {
+		return err
+	}
+
+	err := s.delete(boltMembers, boltKey(guildID), boltKey(userID))
+	if err != nil {
+		return err
+	}
+
+	return s.mem.MemberRemove(guildID, userID)
+}
+
+////
+
+func (s *BoltStore) loadMessages(channelID discord.Snowflake) error {
+	return s.load("messages:"+channelID.String(), func(tx *bolt.Tx) error {
+		// Messages are sorted oldest first, which is the order the memory
+		// store expects them to be added in.
+		var key = boltKey(channelID)
+		return s.each(tx, boltMessages, key, func(b []byte) error {
+			var m discord.Message
+			if err := s.Unmarshal(b, &m); err != nil {
+				return errors.Wrap(err, "Failed to decode message")
+			}
+			return s.mem.MessageSet(&m)
+		})
+	})
+}
+
+func (s *BoltStore) Message(
+	channelID, messageID discord.Snowflake) (*discord.Message, error) {
+
+	if err := s.loadMessages(channelID); err != nil {
+		return nil, err
+	}
+	return s.mem.Message(channelID, messageID)
+}
+
+func (s *BoltStore) Messages(
+	channelID discord.Snowflake) ([]discord.Message, error) {
+
+	if err := s.loadMessages(channelID); err != nil {
+		return nil, err
+	}
+	return s.mem.Messages(channelID)
+}
+
+func (s *BoltStore) MaxMessages() int {
+	return int(s.BoltStoreOptions.MaxMessages)
+}
+
+func (s *BoltStore) MessageSet(message *discord.Message) error {
+	if err := s.loadMessages(message.ChannelID); err != nil {
+		return err
+	}
+
+	// The memory store merges updates into the existing message, so save the
+	// merged message.
+	if err := s.mem.MessageSet(message); err != nil {
+		return err
+	}
+
+	m, err := s.mem.Message(message.ChannelID, message.ID)
+	if err != nil {
+		// The message was too old to be kept.
+		return nil
+	}
+
+	b, err := s.Marshal(m)
+	if err != nil {
+		return errors.Wrap(err, "Failed to encode")
+	}
+
+	return s.DB.Update(func(tx *bolt.Tx) error {
+		bk, err := tx.Bucket(boltMessages).CreateBucketIfNotExists(
+			boltKey(message.ChannelID))
+		if err != nil {
+			return err
+		}
+
+		if err := bk.Put(boltKey(message.ID), b); err != nil {
+			return err
+		}
+
+		// Drop the oldest messages over the limit.
+		var c = bk.Cursor()
+		var n = 0
+		for k, _ := c.First(); k != nil; k, _ = c.Next() {
+			n++
+		}
+		for ; n > s.MaxMessages(); n-- {
+			c.First()
+			if err := c.Delete(); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+func (s *BoltStore) MessageRemove(
+	channelID, messageID discord.Snowflake) error {
+
+	if err := s.loadMessages(channelID); err != nil {
+		return err
+	}
+
+	err := s.delete(boltMessages, boltKey(channelID), boltKey(messageID))
+	if err != nil {
+		return err
+	}
+
+	return s.mem.MessageRemove(channelID, messageID)
+}
+
+////
+
+// Presences are only kept in memory, as they're outdated after a restart.
+
+func (s *BoltStore) Presence(
+	guildID, userID discord.Snowflake) (*discord.Presence, error) {
+
+	return s.mem.Presence(guildID, userID)
+}
+
+func (s *BoltStore) Presences(
+	guildID discord.Snowflake) ([]discord.Presence, error) {
+
+	return s.mem.Presences(guildID)
+}
+
+func (s *BoltStore) PresenceSet(
+	guildID discord.Snowflake, presence *discord.Presence) error {
+
+	return s.mem.PresenceSet(guildID, presence)
+}
+
+func (s *BoltStore) PresenceRemove(guildID, userID discord.Snowflake) error {
+	return s.mem.PresenceRemove(guildID, userID)
+}
+
+////
+
+func (s *BoltStore) Role(
+	guildID, roleID discord.Snowflake) (*discord.Role, error) {
+
+	if err := s.loadGuilds(); err != nil {
+		return nil, err
+	}
+	return s.mem.Role(guildID, roleID)
+}
+
+func (s *BoltStore) Roles(guildID discord.Snowflake) ([]discord.Role, error) {
+	if err := s.loadGuilds(); err != nil {
+		return nil, err
+	}
+	return s.mem.Roles(guildID)
+}
+
+func (s *BoltStore) RoleSet(
+	guildID discord.Snowflake, role *discord.Role) error {
+
+	if err := s.loadGuilds(); err != nil {
+		return err
+	}
+	if err := s.mem.RoleSet(guildID, role); err != nil {
+		return err
+	}
+	return s.saveGuild(guildID)
+}
+
+func (s *BoltStore) RoleRemove(guildID, roleID discord.Snowflake) error {
+	if err := s.loadGuilds(); err != nil {
+		return err
+	}
+	if err := s.mem.RoleRemove(guildID, roleID); err != nil {
+		return err
+	}
+	return s.saveGuild(guildID)
+}
+
+////
+
+// Threads are only kept in memory, as they're synced again after a restart.
+
+func (s *BoltStore) Threads(
+	guildID discord.Snowflake) ([]discord.Channel, error) {
+
+	return s.mem.Threads(guildID)
+}
+
+func (s *BoltStore) ThreadSet(thread *discord.Channel) error {
+	return s.mem.ThreadSet(thread)
+}
+
+func (s *BoltStore) ThreadRemove(guildID, threadID discord.Snowflake) error {
+	return s.mem.ThreadRemove(guildID, threadID)
+}
+
+func (s *BoltStore) ThreadListSet(
+	guildID discord.Snowflake, threads []discord.Channel) error {
+
+	return s.mem.ThreadListSet(guildID, threads)
+}
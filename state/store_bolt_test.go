@@ -0,0 +1,110 @@
+// +build unit
+
+package state
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/diamondburned/arikawa/discord"
+)
+
+func TestBoltStore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "arikawa-bolt")
+	if err != nil {
+		t.Fatal("Failed to create temp dir:", err)
+	}
+	defer os.RemoveAll(dir)
+
+	var path = filepath.Join(dir, "state.db")
+
+	s, err := NewBoltStore(path, &BoltStoreOptions{MaxMessages: 2})
+	if err != nil {
+		t.Fatal("Failed to create store:", err)
+	}
+
+	var guild = discord.Guild{ID: 1, Name: "guild"}
+	if err := s.GuildSet(&guild); err != nil {
+		t.Fatal("Failed to set guild:", err)
+	}
+	if err := s.RoleSet(1, &discord.Role{ID: 2, Name: "role"}); err != nil {
+		t.Fatal("Failed to set role:", err)
+	}
+	if err := s.MemberSet(1, &discord.Member{
+		User: discord.User{ID: 3}, Nick: "nick"}); err != nil {
+
+		t.Fatal("Failed to set member:", err)
+	}
+	if err := s.MemberSet(1, &discord.Member{
+		User: discord.User{ID: 4}}); err != nil {
+
+		t.Fatal("Failed to set member:", err)
+	}
+
+	for id := discord.Snowflake(10); id < 13; id++ {
+		err := s.MessageSet(&discord.Message{ID: id, ChannelID: 5})
+		if err != nil {
+			t.Fatal("Failed to set message:", err)
+		}
+	}
+
+	// Restart.
+	if err := s.Close(); err != nil {
+		t.Fatal("Failed to close store:", err)
+	}
+
+	s, err = NewBoltStore(path, &BoltStoreOptions{MaxMessages: 2})
+	if err != nil {
+		t.Fatal("Failed to reopen store:", err)
+	}
+	defer s.Close()
+
+	g, err := s.Guild(1)
+	if err != nil || g.Name != "guild" {
+		t.Fatal("Unexpected guild:", g, err)
+	}
+
+	r, err := s.Role(1, 2)
+	if err != nil || r.Name != "role" {
+		t.Fatal("Unexpected role:", r, err)
+	}
+
+	m, err := s.Member(1, 4)
+	if err != nil || m.Nick != "" {
+		t.Fatal("Unexpected member:", m, err)
+	}
+
+	msgs, err := s.Messages(5)
+	if err != nil {
+		t.Fatal("Failed to get messages:", err)
+	}
+	if len(msgs) != 2 || msgs[0].ID != 12 || msgs[1].ID != 11 {
+		t.Fatal("Unexpected messages:", msgs)
+	}
+}
+
+func TestNewBoltStoreDefaults(t *testing.T) {
+	dir, err := ioutil.TempDir("", "arikawa-bolt")
+	if err != nil {
+		t.Fatal("Failed to create temp dir:", err)
+	}
+	defer os.RemoveAll(dir)
+
+	var opts = &BoltStoreOptions{}
+
+	s, err := NewBoltStore(filepath.Join(dir, "state.db"), opts)
+	if err != nil {
+		t.Fatal("Failed to create store:", err)
+	}
+	defer s.Close()
+
+	if s.MaxMessages() != 50 {
+		t.Fatalf("Unexpected options: %+v", *s.BoltStoreOptions)
+	}
+
+	if *opts != (BoltStoreOptions{}) {
+		t.Fatalf("The given options were changed: %+v", *opts)
+	}
+}
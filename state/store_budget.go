@@ -0,0 +1,192 @@
+package state
+
+import (
+	"reflect"
+	"sort"
+	"time"
+
+	"github.com/diamondburned/arikawa/discord"
+)
+
+// StoreEntry is a message or a presence of the DefaultStore, which can be
+// evicted once the MemoryBudget is exceeded.
+type StoreEntry struct {
+	// Message is the message, or nil if the entry is a presence.
+	Message *discord.Message
+	// Presence is the presence, or nil if the entry is a message.
+	Presence *discord.Presence
+	// GuildID is the guild of the presence.
+	GuildID discord.Snowflake
+
+	// Time is when the message was sent, or when the presence was last set.
+	Time time.Time
+	// Size is the estimated size of the entry in bytes.
+	Size int
+}
+
+// EvictionPolicy sorts the entries in the order they're evicted in. The
+// entries must not be changed or kept, as they point into the store.
+type EvictionPolicy func(entries []StoreEntry)
+
+// EvictOldest is the default EvictionPolicy. The presences of offline users go
+// first, as they're rarely needed, and then the oldest entries.
+func EvictOldest(entries []StoreEntry) {
+	sort.SliceStable(entries, func(i, j int) bool {
+		oi, oj := offlinePresence(entries[i]), offlinePresence(entries[j])
+		if oi != oj {
+			return oi
+		}
+		return entries[i].Time.Before(entries[j].Time)
+	})
+}
+
+func offlinePresence(e StoreEntry) bool {
+	if e.Presence == nil {
+		return false
+	}
+
+	switch e.Presence.Status {
+	case discord.UnknownStatus, discord.OfflineStatus,
+		discord.InvisibleStatus:
+		return true
+	default:
+		return false
+	}
+}
+
+// The estimated sizes of what messages and presences point to, on top of the
+// size of their structs.
+const (
+	embedSize      = 1024
+	attachmentSize = 256
+	activitySize   = 512
+)
+
+var (
+	messageStructSize  = int(reflect.TypeOf(discord.Message{}).Size())
+	presenceStructSize = int(reflect.TypeOf(discord.Presence{}).Size())
+	userStructSize     = int(reflect.TypeOf(discord.User{}).Size())
+)
+
+func messageSize(m *discord.Message) int {
+	return messageStructSize + len(m.Content) +
+		len(m.Embeds)*embedSize +
+		len(m.Attachments)*attachmentSize +
+		len(m.Mentions)*userStructSize
+}
+
+func presenceSize(p *discord.Presence) int {
+	var size = presenceStructSize + len(p.Activities)*activitySize
+	if p.Game != nil {
+		size += activitySize
+	}
+	return size
+}
+
+type presenceKey struct {
+	guild discord.Snowflake
+	user  discord.Snowflake
+}
+
+// Usage returns the estimated size in bytes of the messages and presences in
+// the store, which is only kept track of if MemoryBudget is not 0.
+func (s *DefaultStore) Usage() int {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	return s.usage
+}
+
+// grow adds the size difference of a message or presence to the usage.
+func (s *DefaultStore) grow(delta int) {
+	if s.MemoryBudget > 0 {
+		s.usage += delta
+	}
+}
+
+// evict evicts entries if the usage is above the MemoryBudget, until it's 10%
+// below it, so that eviction doesn't happen on every new entry. The mutex must
+// be locked.
+func (s *DefaultStore) evict() {
+	if s.MemoryBudget == 0 || s.usage <= int(s.MemoryBudget) {
+		return
+	}
+
+	var entries []StoreEntry
+
+	for _, ms := range s.messages {
+		for i := range ms {
+			entries = append(entries, StoreEntry{
+				Message: &ms[i],
+				Time:    ms[i].ID.Time(),
+				Size:    messageSize(&ms[i]),
+			})
+		}
+	}
+
+	for guildID, ps := range s.presences {
+		for i := range ps {
+			entries = append(entries, StoreEntry{
+				Presence: &ps[i],
+				GuildID:  guildID,
+				Time:     s.presenceTimes[presenceKey{guildID, ps[i].User.ID}],
+				Size:     presenceSize(&ps[i]),
+			})
+		}
+	}
+
+	var policy = s.EvictionPolicy
+	if policy == nil {
+		policy = EvictOldest
+	}
+	policy(entries)
+
+	var target = int(s.MemoryBudget) / 10 * 9
+
+	var messages = map[discord.Snowflake]map[discord.Snowflake]bool{}
+	var presences = map[discord.Snowflake]map[discord.Snowflake]bool{}
+
+	for _, e := range entries {
+		if s.usage <= target {
+			break
+		}
+
+		s.usage -= e.Size
+
+		if e.Message != nil {
+			m := e.Message
+			if messages[m.ChannelID] == nil {
+				messages[m.ChannelID] = map[discord.Snowflake]bool{}
+			}
+			messages[m.ChannelID][m.ID] = true
+		} else {
+			if presences[e.GuildID] == nil {
+				presences[e.GuildID] = map[discord.Snowflake]bool{}
+			}
+			presences[e.GuildID][e.Presence.User.ID] = true
+		}
+	}
+
+	// The entries point into the slices, so they're only filtered now.
+	for channelID, ids := range messages {
+		var kept []discord.Message
+		for _, m := range s.messages[channelID] {
+			if !ids[m.ID] {
+				kept = append(kept, m)
+			}
+		}
+		s.messages[channelID] = kept
+	}
+
+	for guildID, ids := range presences {
+		var kept []discord.Presence
+		for _, p := range s.presences[guildID] {
+			if ids[p.User.ID] {
+				delete(s.presenceTimes, presenceKey{guildID, p.User.ID})
+			} else {
+				kept = append(kept, p)
+			}
+		}
+		s.presences[guildID] = kept
+	}
+}
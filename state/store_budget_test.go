@@ -0,0 +1,90 @@
+// +build unit
+
+package state
+
+import (
+	"testing"
+	"time"
+
+	"github.com/diamondburned/arikawa/discord"
+)
+
+func TestMemoryBudget(t *testing.T) {
+	var size = messageSize(&discord.Message{})
+
+	s := NewDefaultStore(&DefaultStoreOptions{
+		MaxMessages:  100,
+		MemoryBudget: uint(size*10 + presenceSize(&discord.Presence{})),
+	})
+
+	s.PresenceSet(1, &discord.Presence{
+		User:   discord.User{ID: 1},
+		Status: discord.OfflineStatus,
+	})
+
+	var now = time.Now()
+	for i := 0; i < 11; i++ {
+		s.MessageSet(&discord.Message{
+			ID: discord.NewSnowflake(
+				now.Add(time.Duration(i) * time.Second)),
+			ChannelID: 2,
+		})
+	}
+
+	// The offline presence went first, and then the oldest messages, until
+	// the store was 10% below the budget.
+	if _, err := s.Presence(1, 1); err != ErrStoreNotFound {
+		t.Fatal("Offline presence wasn't evicted:", err)
+	}
+
+	ms, _ := s.Messages(2)
+	if len(ms) != 9 {
+		t.Fatal("Unexpected number of messages:", len(ms))
+	}
+
+	for _, m := range ms {
+		if !m.ID.Time().After(now.Add(time.Second)) {
+			t.Fatal("Old message wasn't evicted:", m.ID.Time())
+		}
+	}
+
+	if s.Usage() != size*9 {
+		t.Fatal("Unexpected usage:", s.Usage(), "expected", size*9)
+	}
+
+	if err := s.MessageRemove(2, ms[0].ID); err != nil {
+		t.Fatal("Failed to remove message:", err)
+	}
+
+	if s.Usage() != size*8 {
+		t.Fatal("Usage wasn't updated:", s.Usage())
+	}
+}
+
+func TestEvictionPolicy(t *testing.T) {
+	var size = messageSize(&discord.Message{})
+
+	s := NewDefaultStore(&DefaultStoreOptions{
+		MaxMessages:  100,
+		MemoryBudget: uint(size * 2),
+		// Evict the newest first.
+		EvictionPolicy: func(entries []StoreEntry) {
+			EvictOldest(entries)
+			for i, j := 0, len(entries)-1; i < j; i, j = i+1, j-1 {
+				entries[i], entries[j] = entries[j], entries[i]
+			}
+		},
+	})
+
+	for i := 1; i <= 3; i++ {
+		s.MessageSet(&discord.Message{
+			ID:        discord.Snowflake(i) << 22,
+			ChannelID: 1,
+		})
+	}
+
+	ms, _ := s.Messages(1)
+	if len(ms) != 1 || ms[0].ID != 1<<22 {
+		t.Fatal("Unexpected messages:", ms)
+	}
+}
@@ -3,6 +3,7 @@ package state
 import (
 	"sort"
 	"sync"
+	"time"
 
 	"github.com/diamondburned/arikawa/discord"
 )
@@ -22,15 +23,38 @@ type DefaultStore struct {
 	members   map[discord.Snowflake][]discord.Member   // guildID:members
 	presences map[discord.Snowflake][]discord.Presence // guildID:presences
 	messages  map[discord.Snowflake][]discord.Message  // channelID:messages
+	threads   map[discord.Snowflake][]discord.Channel  // guildID:threads
+	bans      map[discord.Snowflake][]discord.Ban      // guildID:bans
+
+	voiceStates map[discord.Snowflake][]discord.VoiceState // guildID:states
+
+	// usage is the estimated size of the messages and presences, and
+	// presenceTimes are when the presences were set, if there's a
+	// MemoryBudget.
+	usage         int
+	presenceTimes map[presenceKey]time.Time
 
 	mut sync.Mutex
 }
 
 type DefaultStoreOptions struct {
 	MaxMessages uint // default 50
+
+	// MemoryBudget, if not 0, is the estimated size in bytes that the
+	// messages and presences can take, which are the sections that grow the
+	// most with the bot. Once it's exceeded, entries are evicted according to
+	// the EvictionPolicy until the store is 10% below it.
+	MemoryBudget uint
+	// EvictionPolicy decides the entries evicted first. It defaults to
+	// EvictOldest.
+	EvictionPolicy EvictionPolicy
 }
 
-var _ Store = (*DefaultStore)(nil)
+var (
+	_ Store           = (*DefaultStore)(nil)
+	_ BanStore        = (*DefaultStore)(nil)
+	_ VoiceStateStore = (*DefaultStore)(nil)
+)
 
 func NewDefaultStore(opts *DefaultStoreOptions) *DefaultStore {
 	if opts == nil {
@@ -60,6 +84,12 @@ func (s *DefaultStore) Reset() error {
 	s.members = map[discord.Snowflake][]discord.Member{}
 	s.presences = map[discord.Snowflake][]discord.Presence{}
 	s.messages = map[discord.Snowflake][]discord.Message{}
+	s.threads = map[discord.Snowflake][]discord.Channel{}
+	s.bans = map[discord.Snowflake][]discord.Ban{}
+	s.voiceStates = map[discord.Snowflake][]discord.VoiceState{}
+
+	s.usage = 0
+	s.presenceTimes = map[presenceKey]time.Time{}
 
 	return nil
 }
@@ -446,6 +476,7 @@ func (s *DefaultStore) MaxMessages() int {
 func (s *DefaultStore) MessageSet(message *discord.Message) error {
 	s.mut.Lock()
 	defer s.mut.Unlock()
+	defer s.evict()
 
 	ms, ok := s.messages[message.ChannelID]
 	if !ok {
@@ -478,6 +509,8 @@ func (s *DefaultStore) MessageSet(message *discord.Message) error {
 				m.Author = message.Author
 			}
 
+			s.grow(messageSize(&m) - messageSize(&ms[i]))
+
 			ms[i] = m
 			return nil
 		}
@@ -485,20 +518,21 @@ func (s *DefaultStore) MessageSet(message *discord.Message) error {
 
 	// Prepend the latest message at the end
 
+	// Grow the slice until it reaches the limit, after which the oldest
+	// message is dropped.
+	if len(ms) < s.MaxMessages() {
+		ms = append(ms, discord.Message{})
+	} else if len(ms) > 0 {
+		s.grow(-messageSize(&ms[len(ms)-1]))
+	}
+
 	if len(ms) > 0 {
-		var end = s.MaxMessages()
-		if len(ms) < end {
-			end = len(ms)
-		}
+		s.grow(messageSize(message))
 
-		// Copy hack to prepend. This copies the 0th-(end-1)th entries to
-		// 1st-endth.
-		copy(ms[1:end], ms[0:end-1])
+		// Copy hack to prepend. This shifts every entry right by one.
+		copy(ms[1:], ms[:len(ms)-1])
 		// Then, set the 0th entry.
 		ms[0] = *message
-
-	} else {
-		ms = append(ms, *message)
 	}
 
 	s.messages[message.ChannelID] = ms
@@ -518,6 +552,8 @@ func (s *DefaultStore) MessageRemove(
 
 	for i, m := range ms {
 		if m.ID == messageID {
+			s.grow(-messageSize(&m))
+
 			ms = append(ms[:i], ms[i+1:]...)
 			s.messages[channelID] = ms
 			return nil
@@ -568,11 +604,18 @@ func (s *DefaultStore) PresenceSet(
 
 	s.mut.Lock()
 	defer s.mut.Unlock()
+	defer s.evict()
+
+	if s.MemoryBudget > 0 {
+		s.presenceTimes[presenceKey{guildID, presence.User.ID}] = time.Now()
+	}
 
 	ps := s.presences[guildID]
 
 	for i, p := range ps {
 		if p.User.ID == presence.User.ID {
+			s.grow(presenceSize(presence) - presenceSize(&p))
+
 			ps[i] = *presence
 			s.presences[guildID] = ps
 
@@ -580,6 +623,8 @@ func (s *DefaultStore) PresenceSet(
 		}
 	}
 
+	s.grow(presenceSize(presence))
+
 	ps = append(ps, *presence)
 	s.presences[guildID] = ps
 	return nil
@@ -596,6 +641,9 @@ func (s *DefaultStore) PresenceRemove(guildID, userID discord.Snowflake) error {
 
 	for i, p := range ps {
 		if p.User.ID == userID {
+			s.grow(-presenceSize(&p))
+			delete(s.presenceTimes, presenceKey{guildID, userID})
+
 			ps = append(ps[:i], ps[i+1:]...)
 			s.presences[guildID] = ps
 
@@ -682,3 +730,213 @@ func (s *DefaultStore) RoleRemove(guildID, roleID discord.Snowflake) error {
 
 	return ErrStoreNotFound
 }
+
+////
+
+func (s *DefaultStore) Threads(
+	guildID discord.Snowflake) ([]discord.Channel, error) {
+
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	ts, ok := s.threads[guildID]
+	if !ok {
+		return nil, ErrStoreNotFound
+	}
+
+	return append([]discord.Channel{}, ts...), nil
+}
+
+func (s *DefaultStore) ThreadSet(thread *discord.Channel) error {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	ts := s.threads[thread.GuildID]
+
+	for i, t := range ts {
+		if t.ID == thread.ID {
+			ts[i] = *thread
+			return nil
+		}
+	}
+
+	s.threads[thread.GuildID] = append(ts, *thread)
+	return nil
+}
+
+func (s *DefaultStore) ThreadRemove(guildID, threadID discord.Snowflake) error {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	ts, ok := s.threads[guildID]
+	if !ok {
+		return ErrStoreNotFound
+	}
+
+	for i, t := range ts {
+		if t.ID == threadID {
+			s.threads[guildID] = append(ts[:i], ts[i+1:]...)
+			return nil
+		}
+	}
+
+	return ErrStoreNotFound
+}
+
+func (s *DefaultStore) ThreadListSet(
+	guildID discord.Snowflake, threads []discord.Channel) error {
+
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	s.threads[guildID] = append([]discord.Channel{}, threads...)
+	return nil
+}
+
+////
+
+func (s *DefaultStore) Ban(
+	guildID, userID discord.Snowflake) (*discord.Ban, error) {
+
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	for _, b := range s.bans[guildID] {
+		if b.User.ID == userID {
+			return &b, nil
+		}
+	}
+
+	return nil, ErrStoreNotFound
+}
+
+func (s *DefaultStore) Bans(guildID discord.Snowflake) ([]discord.Ban, error) {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	bs, ok := s.bans[guildID]
+	if !ok {
+		return nil, ErrStoreNotFound
+	}
+
+	return append([]discord.Ban{}, bs...), nil
+}
+
+func (s *DefaultStore) BanSet(
+	guildID discord.Snowflake, ban *discord.Ban) error {
+
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	bs, ok := s.bans[guildID]
+	if !ok {
+		return ErrStoreNotFound
+	}
+
+	for i, b := range bs {
+		if b.User.ID == ban.User.ID {
+			bs[i] = *ban
+			return nil
+		}
+	}
+
+	s.bans[guildID] = append(bs, *ban)
+	return nil
+}
+
+func (s *DefaultStore) BanRemove(guildID, userID discord.Snowflake) error {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	bs, ok := s.bans[guildID]
+	if !ok {
+		return ErrStoreNotFound
+	}
+
+	for i, b := range bs {
+		if b.User.ID == userID {
+			s.bans[guildID] = append(bs[:i], bs[i+1:]...)
+			return nil
+		}
+	}
+
+	return ErrStoreNotFound
+}
+
+func (s *DefaultStore) BanListSet(
+	guildID discord.Snowflake, bans []discord.Ban) error {
+
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	s.bans[guildID] = append([]discord.Ban{}, bans...)
+	return nil
+}
+
+////
+
+func (s *DefaultStore) VoiceState(
+	guildID, userID discord.Snowflake) (*discord.VoiceState, error) {
+
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	for _, vs := range s.voiceStates[guildID] {
+		if vs.UserID == userID {
+			return &vs, nil
+		}
+	}
+
+	return nil, ErrStoreNotFound
+}
+
+func (s *DefaultStore) VoiceStates(
+	guildID discord.Snowflake) ([]discord.VoiceState, error) {
+
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	vss, ok := s.voiceStates[guildID]
+	if !ok {
+		return nil, ErrStoreNotFound
+	}
+
+	return append([]discord.VoiceState{}, vss...), nil
+}
+
+func (s *DefaultStore) VoiceStateSet(
+	guildID discord.Snowflake, state *discord.VoiceState) error {
+
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	vss := s.voiceStates[guildID]
+
+	for i, vs := range vss {
+		if vs.UserID == state.UserID {
+			vss[i] = *state
+			return nil
+		}
+	}
+
+	s.voiceStates[guildID] = append(vss, *state)
+	return nil
+}
+
+func (s *DefaultStore) VoiceStateRemove(
+	guildID, userID discord.Snowflake) error {
+
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	vss := s.voiceStates[guildID]
+
+	for i, vs := range vss {
+		if vs.UserID == userID {
+			s.voiceStates[guildID] = append(vss[:i], vss[i+1:]...)
+			return nil
+		}
+	}
+
+	return ErrStoreNotFound
+}
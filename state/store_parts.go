@@ -0,0 +1,310 @@
+package state
+
+import (
+	"reflect"
+
+	"github.com/diamondburned/arikawa/discord"
+)
+
+// StoreParts contains the stores of each resource. Nil parts aren't stored at
+// all.
+type StoreParts struct {
+	Me       MeStore
+	Channel  ChannelStore
+	Emoji    EmojiStore
+	Guild    GuildStore
+	Member   MemberStore
+	Message  MessageStore
+	Presence PresenceStore
+	Role     RoleStore
+	Thread   ThreadStore
+	// Ban is optional, as BanStore isn't part of Store. Without it,
+	// State.IsBanned always asks the API.
+	Ban BanStore
+	// VoiceState is optional like Ban. Without it, voice states aren't
+	// cached.
+	VoiceState VoiceStateStore
+}
+
+// partsStore is a Store made of parts.
+type partsStore struct {
+	MeStore
+	ChannelStore
+	EmojiStore
+	GuildStore
+	MemberStore
+	MessageStore
+	PresenceStore
+	RoleStore
+	ThreadStore
+	VoiceStateStore
+
+	resetters []Resetter
+	flushers  []Flusher
+}
+
+// NewStoreFromParts creates a Store that uses a different store for each
+// resource. Parts that are nil default to NoopStore, so nothing is kept for
+// them, except for Ban, which is then left out of the Store. For example, to
+// keep members in Redis and everything else in memory:
+//
+//	mem := state.NewDefaultStore(nil)
+//	store := state.NewStoreFromParts(state.StoreParts{
+//		Me:       mem,
+//		Channel:  mem,
+//		Emoji:    mem,
+//		Guild:    mem,
+//		Member:   state.NewRedisStore(client, nil),
+//		Message:  mem,
+//		Presence: mem,
+//		Role:     mem,
+//		Thread:   mem,
+//	})
+//
+// Reset resets every part that implements Resetter, and Flush flushes every
+// part that implements Flusher, once each.
+func NewStoreFromParts(parts StoreParts) Store {
+	var s = partsStore{
+		MeStore:       parts.Me,
+		ChannelStore:  parts.Channel,
+		EmojiStore:    parts.Emoji,
+		GuildStore:    parts.Guild,
+		MemberStore:   parts.Member,
+		MessageStore:  parts.Message,
+		PresenceStore: parts.Presence,
+		RoleStore:     parts.Role,
+		ThreadStore:   parts.Thread,
+
+		VoiceStateStore: parts.VoiceState,
+	}
+
+	if s.MeStore == nil {
+		s.MeStore = NoopStore
+	}
+	if s.ChannelStore == nil {
+		s.ChannelStore = NoopStore
+	}
+	if s.EmojiStore == nil {
+		s.EmojiStore = NoopStore
+	}
+	if s.GuildStore == nil {
+		s.GuildStore = NoopStore
+	}
+	if s.MemberStore == nil {
+		s.MemberStore = NoopStore
+	}
+	if s.MessageStore == nil {
+		s.MessageStore = NoopStore
+	}
+	if s.PresenceStore == nil {
+		s.PresenceStore = NoopStore
+	}
+	if s.RoleStore == nil {
+		s.RoleStore = NoopStore
+	}
+	if s.ThreadStore == nil {
+		s.ThreadStore = NoopStore
+	}
+	if s.VoiceStateStore == nil {
+		s.VoiceStateStore = NoopStore
+	}
+
+	for _, part := range []interface{}{
+		s.MeStore, s.ChannelStore, s.EmojiStore, s.GuildStore,
+		s.MemberStore, s.MessageStore, s.PresenceStore, s.RoleStore,
+		s.ThreadStore, parts.Ban, s.VoiceStateStore,
+	} {
+		if r, ok := part.(Resetter); ok && !hasResetter(s.resetters, r) {
+			s.resetters = append(s.resetters, r)
+		}
+		if f, ok := part.(Flusher); ok && !hasFlusher(s.flushers, f) {
+			s.flushers = append(s.flushers, f)
+		}
+	}
+
+	// The Store is only a BanStore if there's a Ban part, so that
+	// State.IsBanned asks the API otherwise.
+	if parts.Ban != nil {
+		return &banPartsStore{&s, parts.Ban}
+	}
+
+	return &s
+}
+
+// banPartsStore is a partsStore with a Ban part.
+type banPartsStore struct {
+	*partsStore
+	BanStore
+}
+
+// hasResetter returns true if r is already in the list, so that stores used
+// for multiple parts are only reset once.
+func hasResetter(resetters []Resetter, r Resetter) bool {
+	for _, resetter := range resetters {
+		if samePart(resetter, r) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// hasFlusher returns true if f is already in the list.
+func hasFlusher(flushers []Flusher, f Flusher) bool {
+	for _, flusher := range flushers {
+		if samePart(flusher, f) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func samePart(a, b interface{}) bool {
+	// Comparing uncomparable types would panic.
+	return reflect.TypeOf(a).Comparable() && a == b
+}
+
+func (s *partsStore) Reset() error {
+	for _, r := range s.resetters {
+		if err := r.Reset(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *partsStore) Flush() error {
+	for _, f := range s.flushers {
+		if err := f.Flush(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// NoopStore is a Store that stores nothing: getters always return
+// ErrStoreNotFound, and setters do nothing. The State then always hits the
+// API.
+var NoopStore = noopStore{}
+
+type noopStore struct{}
+
+var (
+	_ Store           = NoopStore
+	_ BanStore        = NoopStore
+	_ VoiceStateStore = NoopStore
+)
+
+func (noopStore) Reset() error { return nil }
+
+func (noopStore) Self() (*discord.User, error) {
+	return nil, ErrStoreNotFound
+}
+func (noopStore) SelfSet(*discord.User) error { return nil }
+
+func (noopStore) Channel(discord.Snowflake) (*discord.Channel, error) {
+	return nil, ErrStoreNotFound
+}
+func (noopStore) Channels(discord.Snowflake) ([]discord.Channel, error) {
+	return nil, ErrStoreNotFound
+}
+func (noopStore) PrivateChannels() ([]discord.Channel, error) {
+	return nil, ErrStoreNotFound
+}
+func (noopStore) ChannelSet(*discord.Channel) error    { return nil }
+func (noopStore) ChannelRemove(*discord.Channel) error { return nil }
+
+func (noopStore) Emoji(_, _ discord.Snowflake) (*discord.Emoji, error) {
+	return nil, ErrStoreNotFound
+}
+func (noopStore) Emojis(discord.Snowflake) ([]discord.Emoji, error) {
+	return nil, ErrStoreNotFound
+}
+func (noopStore) EmojiSet(discord.Snowflake, []discord.Emoji) error {
+	return nil
+}
+
+func (noopStore) Guild(discord.Snowflake) (*discord.Guild, error) {
+	return nil, ErrStoreNotFound
+}
+func (noopStore) Guilds() ([]discord.Guild, error) {
+	return nil, ErrStoreNotFound
+}
+func (noopStore) GuildSet(*discord.Guild) error       { return nil }
+func (noopStore) GuildRemove(discord.Snowflake) error { return nil }
+
+func (noopStore) Member(_, _ discord.Snowflake) (*discord.Member, error) {
+	return nil, ErrStoreNotFound
+}
+func (noopStore) Members(discord.Snowflake) ([]discord.Member, error) {
+	return nil, ErrStoreNotFound
+}
+func (noopStore) MemberSet(discord.Snowflake, *discord.Member) error {
+	return nil
+}
+func (noopStore) MemberRemove(_, _ discord.Snowflake) error { return nil }
+
+func (noopStore) Message(_, _ discord.Snowflake) (*discord.Message, error) {
+	return nil, ErrStoreNotFound
+}
+func (noopStore) Messages(discord.Snowflake) ([]discord.Message, error) {
+	return nil, ErrStoreNotFound
+}
+func (noopStore) MaxMessages() int                           { return 0 }
+func (noopStore) MessageSet(*discord.Message) error          { return nil }
+func (noopStore) MessageRemove(_, _ discord.Snowflake) error { return nil }
+
+func (noopStore) Presence(_, _ discord.Snowflake) (*discord.Presence, error) {
+	return nil, ErrStoreNotFound
+}
+func (noopStore) Presences(discord.Snowflake) ([]discord.Presence, error) {
+	return nil, ErrStoreNotFound
+}
+func (noopStore) PresenceSet(discord.Snowflake, *discord.Presence) error {
+	return nil
+}
+func (noopStore) PresenceRemove(_, _ discord.Snowflake) error { return nil }
+
+func (noopStore) Role(_, _ discord.Snowflake) (*discord.Role, error) {
+	return nil, ErrStoreNotFound
+}
+func (noopStore) Roles(discord.Snowflake) ([]discord.Role, error) {
+	return nil, ErrStoreNotFound
+}
+func (noopStore) RoleSet(discord.Snowflake, *discord.Role) error { return nil }
+func (noopStore) RoleRemove(_, _ discord.Snowflake) error        { return nil }
+
+func (noopStore) Threads(discord.Snowflake) ([]discord.Channel, error) {
+	return nil, ErrStoreNotFound
+}
+func (noopStore) ThreadSet(*discord.Channel) error          { return nil }
+func (noopStore) ThreadRemove(_, _ discord.Snowflake) error { return nil }
+func (noopStore) ThreadListSet(discord.Snowflake, []discord.Channel) error {
+	return nil
+}
+
+func (noopStore) Ban(_, _ discord.Snowflake) (*discord.Ban, error) {
+	return nil, ErrStoreNotFound
+}
+func (noopStore) Bans(discord.Snowflake) ([]discord.Ban, error) {
+	return nil, ErrStoreNotFound
+}
+func (noopStore) BanSet(discord.Snowflake, *discord.Ban) error { return nil }
+func (noopStore) BanRemove(_, _ discord.Snowflake) error       { return nil }
+func (noopStore) BanListSet(discord.Snowflake, []discord.Ban) error {
+	return nil
+}
+
+func (noopStore) VoiceState(
+	_, _ discord.Snowflake) (*discord.VoiceState, error) {
+
+	return nil, ErrStoreNotFound
+}
+func (noopStore) VoiceStates(discord.Snowflake) ([]discord.VoiceState, error) {
+	return nil, ErrStoreNotFound
+}
+func (noopStore) VoiceStateSet(discord.Snowflake, *discord.VoiceState) error {
+	return nil
+}
+func (noopStore) VoiceStateRemove(_, _ discord.Snowflake) error { return nil }
@@ -0,0 +1,91 @@
+// +build unit
+
+package state
+
+import (
+	"testing"
+
+	"github.com/diamondburned/arikawa/discord"
+)
+
+type countingStore struct {
+	*DefaultStore
+	resets  int
+	flushes int
+}
+
+func (s *countingStore) Reset() error {
+	s.resets++
+	return s.DefaultStore.Reset()
+}
+
+func (s *countingStore) Flush() error {
+	s.flushes++
+	return nil
+}
+
+func TestStoreFromParts(t *testing.T) {
+	var mem = &countingStore{DefaultStore: NewDefaultStore(nil)}
+	var members = NewDefaultStore(nil)
+
+	var store = NewStoreFromParts(StoreParts{
+		Guild:   mem,
+		Message: mem,
+		Member:  members,
+	})
+
+	var member = discord.Member{User: discord.User{ID: 2}}
+	if err := store.MemberSet(1, &member); err != nil {
+		t.Fatal("Failed to set member:", err)
+	}
+	if _, err := members.Member(1, 2); err != nil {
+		t.Fatal("Member not set in the member store:", err)
+	}
+
+	// Channels aren't stored at all.
+	if err := store.ChannelSet(&discord.Channel{ID: 3, GuildID: 1}); err != nil {
+		t.Fatal("Failed to set channel:", err)
+	}
+	if _, err := store.Channel(3); err != ErrStoreNotFound {
+		t.Fatal("Unexpected error from noop channel store:", err)
+	}
+
+	if err := store.Reset(); err != nil {
+		t.Fatal("Failed to reset:", err)
+	}
+	if mem.resets != 1 {
+		t.Fatal("Store used for multiple parts reset", mem.resets, "times")
+	}
+
+	if err := store.(Flusher).Flush(); err != nil {
+		t.Fatal("Failed to flush:", err)
+	}
+	if mem.flushes != 1 {
+		t.Fatal("Store used for multiple parts flushed", mem.flushes, "times")
+	}
+	if _, err := members.Member(1, 2); err != ErrStoreNotFound {
+		t.Fatal("Member store not reset:", err)
+	}
+}
+
+func TestStoreFromPartsBan(t *testing.T) {
+	var store = NewStoreFromParts(StoreParts{})
+	if _, ok := store.(BanStore); ok {
+		t.Fatal("Store without a Ban part is a BanStore")
+	}
+
+	var bans = NewDefaultStore(nil)
+
+	store = NewStoreFromParts(StoreParts{Ban: bans})
+	bs, ok := store.(BanStore)
+	if !ok {
+		t.Fatal("Store with a Ban part isn't a BanStore")
+	}
+
+	if err := bs.BanListSet(1, []discord.Ban{{}}); err != nil {
+		t.Fatal("Failed to set bans:", err)
+	}
+	if _, err := bans.Bans(1); err != nil {
+		t.Fatal("Bans not set in the ban store:", err)
+	}
+}
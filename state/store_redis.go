@@ -0,0 +1,718 @@
+package state
+
+import (
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/diamondburned/arikawa/discord"
+	"github.com/diamondburned/arikawa/internal/json"
+	"github.com/pkg/errors"
+)
+
+// RedisClient is an interface that abstracts around a generic Redis driver.
+// This allows RedisStore to be used with any Redis library, with only a thin
+// wrapper around it.
+//
+// Get and HGet should return a nil slice and a nil error if the key or field
+// does not exist.
+type RedisClient interface {
+	Get(key string) ([]byte, error)
+	Set(key string, value []byte) error
+	Del(keys ...string) error
+
+	HGet(key, field string) ([]byte, error)
+	HGetAll(key string) (map[string][]byte, error)
+	HSet(key, field string, value []byte) error
+	HDel(key string, fields ...string) error
+
+	// Expire sets the TTL of the key. A TTL of 0 should never be given.
+	Expire(key string, ttl time.Duration) error
+	// Keys returns all keys matching the glob pattern. It is only used for
+	// Reset.
+	Keys(pattern string) ([]string, error)
+}
+
+// RedisStore is a Store that keeps everything in Redis, which allows multiple
+// bot processes to share the same cache. Every item is encoded with the JSON
+// driver.
+//
+// Since Redis can't expire hash fields individually, the TTLs apply to the
+// whole collection (e.g. all members of a guild), and are refreshed on every
+// write to that collection.
+type RedisStore struct {
+	*RedisStoreOptions
+
+	Client RedisClient
+	json.Driver
+}
+
+type RedisStoreOptions struct {
+	// Prefix is prepended to all keys, default "arikawa:". An empty prefix is
+	// not allowed, as Reset would delete all keys.
+	Prefix string
+
+	MaxMessages uint // default 50
+
+	// TTLs for each collection. 0 means the keys never expire.
+	GuildTTL    time.Duration
+	ChannelTTL  time.Duration
+	MemberTTL   time.Duration
+	PresenceTTL time.Duration
+	MessageTTL  time.Duration
+}
+
+var _ Store = (*RedisStore)(nil)
+
+func NewRedisStore(client RedisClient, opts *RedisStoreOptions) *RedisStore {
+	// The options are copied, so the defaults don't change the caller's.
+	var o RedisStoreOptions
+	if opts != nil {
+		o = *opts
+	}
+
+	if o.Prefix == "" {
+		o.Prefix = "arikawa:"
+	}
+
+	if o.MaxMessages == 0 {
+		o.MaxMessages = 50
+	}
+
+	return &RedisStore{
+		RedisStoreOptions: &o,
+		Client:            client,
+		Driver:            json.Default{},
+	}
+}
+
+func (s *RedisStore) Reset() error {
+	keys, err := s.Client.Keys(s.Prefix + "*")
+	if err != nil {
+		return errors.Wrap(err, "Failed to get keys")
+	}
+
+	if len(keys) == 0 {
+		return nil
+	}
+
+	return s.Client.Del(keys...)
+}
+
+func (s *RedisStore) key(parts ...string) string {
+	var key = s.Prefix
+
+	for i, part := range parts {
+		if i > 0 {
+			key += ":"
+		}
+		key += part
+	}
+
+	return key
+}
+
+func (s *RedisStore) guildKey(guildID discord.Snowflake, what string) string {
+	return s.key("guild", guildID.String(), what)
+}
+
+func (s *RedisStore) messagesKey(channelID discord.Snowflake) string {
+	return s.key("channel", channelID.String(), "messages")
+}
+
+// Helpers
+
+func (s *RedisStore) hget(key, field string, v interface{}) error {
+	b, err := s.Client.HGet(key, field)
+	if err != nil {
+		return err
+	}
+
+	if b == nil {
+		return ErrStoreNotFound
+	}
+
+	return s.Unmarshal(b, v)
+}
+
+// hgetall calls fn for each field in the hash. It returns ErrStoreNotFound if
+// the hash is empty.
+func (s *RedisStore) hgetall(key string, fn func([]byte) error) error {
+	m, err := s.Client.HGetAll(key)
+	if err != nil {
+		return err
+	}
+
+	if len(m) == 0 {
+		return ErrStoreNotFound
+	}
+
+	for _, b := range m {
+		if err := fn(b); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *RedisStore) hset(
+	key, field string, ttl time.Duration, v interface{}) error {
+
+	b, err := s.Marshal(v)
+	if err != nil {
+		return errors.Wrap(err, "Failed to encode")
+	}
+
+	if err := s.Client.HSet(key, field, b); err != nil {
+		return err
+	}
+
+	if ttl > 0 {
+		return s.Client.Expire(key, ttl)
+	}
+
+	return nil
+}
+
+// hdel deletes the field, returning ErrStoreNotFound if it doesn't exist.
+func (s *RedisStore) hdel(key, field string) error {
+	b, err := s.Client.HGet(key, field)
+	if err != nil {
+		return err
+	}
+
+	if b == nil {
+		return ErrStoreNotFound
+	}
+
+	return s.Client.HDel(key, field)
+}
+
+////
+
+func (s *RedisStore) Self() (*discord.User, error) {
+	b, err := s.Client.Get(s.key("self"))
+	if err != nil {
+		return nil, err
+	}
+
+	if b == nil {
+		return nil, ErrStoreNotFound
+	}
+
+	var u *discord.User
+	return u, s.Unmarshal(b, &u)
+}
+
+func (s *RedisStore) SelfSet(me *discord.User) error {
+	b, err := s.Marshal(me)
+	if err != nil {
+		return errors.Wrap(err, "Failed to encode")
+	}
+
+	return s.Client.Set(s.key("self"), b)
+}
+
+////
+
+func (s *RedisStore) Channel(id discord.Snowflake) (*discord.Channel, error) {
+	var ch *discord.Channel
+	return ch, s.hget(s.key("channels"), id.String(), &ch)
+}
+
+func (s *RedisStore) Channels(
+	guildID discord.Snowflake) ([]discord.Channel, error) {
+
+	var chs []discord.Channel
+
+	return chs, s.hgetall(s.guildKey(guildID, "channels"), func(b []byte) error {
+		var ch discord.Channel
+		if err := s.Unmarshal(b, &ch); err != nil {
+			return err
+		}
+
+		chs = append(chs, ch)
+		return nil
+	})
+}
+
+func (s *RedisStore) PrivateChannels() ([]discord.Channel, error) {
+	var chs []discord.Channel
+
+	err := s.hgetall(s.key("privates"), func(b []byte) error {
+		var ch discord.Channel
+		if err := s.Unmarshal(b, &ch); err != nil {
+			return err
+		}
+
+		chs = append(chs, ch)
+		return nil
+	})
+
+	if err != nil && err != ErrStoreNotFound {
+		return nil, err
+	}
+
+	sort.Slice(chs, func(i, j int) bool {
+		// Latest first
+		return chs[i].LastMessageID > chs[j].LastMessageID
+	})
+
+	return chs, nil
+}
+
+func (s *RedisStore) ChannelSet(channel *discord.Channel) error {
+	if channel.Permissions == nil {
+		// Also from discordgo.
+		if old, err := s.Channel(channel.ID); err == nil {
+			channel.Permissions = old.Permissions
+		}
+	}
+
+	var id = channel.ID.String()
+
+	if err := s.hset(s.key("channels"), id, s.ChannelTTL, channel); err != nil {
+		return err
+	}
+
+	switch channel.Type {
+	case discord.DirectMessage, discord.GroupDM:
+		return s.hset(s.key("privates"), id, s.ChannelTTL, channel)
+	default:
+		return s.hset(
+			s.guildKey(channel.GuildID, "channels"), id, s.ChannelTTL, channel)
+	}
+}
+
+func (s *RedisStore) ChannelRemove(channel *discord.Channel) error {
+	var id = channel.ID.String()
+
+	if err := s.hdel(s.key("channels"), id); err != nil {
+		return err
+	}
+
+	switch channel.Type {
+	case discord.DirectMessage, discord.GroupDM:
+		return s.Client.HDel(s.key("privates"), id)
+	default:
+		return s.Client.HDel(s.guildKey(channel.GuildID, "channels"), id)
+	}
+}
+
+////
+
+func (s *RedisStore) Emoji(
+	guildID, emojiID discord.Snowflake) (*discord.Emoji, error) {
+
+	var e *discord.Emoji
+	return e, s.hget(s.guildKey(guildID, "emojis"), emojiID.String(), &e)
+}
+
+func (s *RedisStore) Emojis(
+	guildID discord.Snowflake) ([]discord.Emoji, error) {
+
+	var es []discord.Emoji
+
+	return es, s.hgetall(s.guildKey(guildID, "emojis"), func(b []byte) error {
+		var e discord.Emoji
+		if err := s.Unmarshal(b, &e); err != nil {
+			return err
+		}
+
+		es = append(es, e)
+		return nil
+	})
+}
+
+func (s *RedisStore) EmojiSet(
+	guildID discord.Snowflake, emojis []discord.Emoji) error {
+
+	var key = s.guildKey(guildID, "emojis")
+
+	for _, e := range emojis {
+		if err := s.hset(key, e.ID.String(), s.GuildTTL, e); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+////
+
+func (s *RedisStore) Guild(id discord.Snowflake) (*discord.Guild, error) {
+	var g *discord.Guild
+	if err := s.hget(s.key("guilds"), id.String(), &g); err != nil {
+		return nil, err
+	}
+
+	return g, s.fillGuild(g)
+}
+
+func (s *RedisStore) Guilds() ([]discord.Guild, error) {
+	var gs []discord.Guild
+
+	err := s.hgetall(s.key("guilds"), func(b []byte) error {
+		var g discord.Guild
+		if err := s.Unmarshal(b, &g); err != nil {
+			return err
+		}
+
+		if err := s.fillGuild(&g); err != nil {
+			return err
+		}
+
+		gs = append(gs, g)
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(gs, func(i, j int) bool {
+		return gs[i].ID > gs[j].ID
+	})
+
+	return gs, nil
+}
+
+// fillGuild fills the guild with roles and emojis, which are stored separately.
+func (s *RedisStore) fillGuild(g *discord.Guild) error {
+	rs, err := s.Roles(g.ID)
+	if err != nil && err != ErrStoreNotFound {
+		return err
+	}
+	g.Roles = rs
+
+	es, err := s.Emojis(g.ID)
+	if err != nil && err != ErrStoreNotFound {
+		return err
+	}
+	g.Emojis = es
+
+	return nil
+}
+
+func (s *RedisStore) GuildSet(guild *discord.Guild) error {
+	// Roles and emojis are stored in their own hashes, so they could be
+	// modified without rewriting the whole guild.
+	if guild.Roles != nil {
+		if err := s.Client.Del(s.guildKey(guild.ID, "roles")); err != nil {
+			return err
+		}
+
+		for i := range guild.Roles {
+			if err := s.RoleSet(guild.ID, &guild.Roles[i]); err != nil {
+				return err
+			}
+		}
+	}
+
+	if guild.Emojis != nil {
+		if err := s.Client.Del(s.guildKey(guild.ID, "emojis")); err != nil {
+			return err
+		}
+
+		if err := s.EmojiSet(guild.ID, guild.Emojis); err != nil {
+			return err
+		}
+	}
+
+	var g = *guild
+	g.Roles = nil
+	g.Emojis = nil
+
+	return s.hset(s.key("guilds"), g.ID.String(), s.GuildTTL, g)
+}
+
+func (s *RedisStore) GuildRemove(id discord.Snowflake) error {
+	if err := s.Client.HDel(s.key("guilds"), id.String()); err != nil {
+		return err
+	}
+
+	return s.Client.Del(
+		s.guildKey(id, "roles"),
+		s.guildKey(id, "emojis"),
+	)
+}
+
+////
+
+func (s *RedisStore) Member(
+	guildID, userID discord.Snowflake) (*discord.Member, error) {
+
+	var m *discord.Member
+	return m, s.hget(s.guildKey(guildID, "members"), userID.String(), &m)
+}
+
+func (s *RedisStore) Members(
+	guildID discord.Snowflake) ([]discord.Member, error) {
+
+	var ms []discord.Member
+
+	return ms, s.hgetall(s.guildKey(guildID, "members"), func(b []byte) error {
+		var m discord.Member
+		if err := s.Unmarshal(b, &m); err != nil {
+			return err
+		}
+
+		ms = append(ms, m)
+		return nil
+	})
+}
+
+func (s *RedisStore) MemberSet(
+	guildID discord.Snowflake, member *discord.Member) error {
+
+	return s.hset(s.guildKey(guildID, "members"),
+		member.User.ID.String(), s.MemberTTL, member)
+}
+
+func (s *RedisStore) MemberRemove(guildID, userID discord.Snowflake) error {
+	return s.hdel(s.guildKey(guildID, "members"), userID.String())
+}
+
+////
+
+func (s *RedisStore) Message(
+	channelID, messageID discord.Snowflake) (*discord.Message, error) {
+
+	var m *discord.Message
+	return m, s.hget(s.messagesKey(channelID), messageID.String(), &m)
+}
+
+// Messages returns messages with the latest first, like DefaultStore.
+func (s *RedisStore) Messages(
+	channelID discord.Snowflake) ([]discord.Message, error) {
+
+	var ms []discord.Message
+
+	err := s.hgetall(s.messagesKey(channelID), func(b []byte) error {
+		var m discord.Message
+		if err := s.Unmarshal(b, &m); err != nil {
+			return err
+		}
+
+		ms = append(ms, m)
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(ms, func(i, j int) bool {
+		return ms[i].ID > ms[j].ID
+	})
+
+	if max := s.MaxMessages(); len(ms) > max {
+		ms = ms[:max]
+	}
+
+	return ms, nil
+}
+
+func (s *RedisStore) MaxMessages() int {
+	return int(s.RedisStoreOptions.MaxMessages)
+}
+
+func (s *RedisStore) MessageSet(message *discord.Message) error {
+	var key = s.messagesKey(message.ChannelID)
+
+	// Check if we already have the message.
+	if m, err := s.Message(message.ChannelID, message.ID); err == nil {
+		// Thanks, Discord.
+		if message.Content != "" {
+			m.Content = message.Content
+		}
+		if message.EditedTimestamp != nil {
+			m.EditedTimestamp = message.EditedTimestamp
+		}
+		if message.Mentions != nil {
+			m.Mentions = message.Mentions
+		}
+		if message.Embeds != nil {
+			m.Embeds = message.Embeds
+		}
+		if message.Attachments != nil {
+			m.Attachments = message.Attachments
+		}
+		if message.Timestamp.Valid() {
+			m.Timestamp = message.Timestamp
+		}
+		if message.Author.ID.Valid() {
+			m.Author = message.Author
+		}
+
+		return s.hset(key, m.ID.String(), s.MessageTTL, m)
+	}
+
+	if err := s.hset(key, message.ID.String(), s.MessageTTL, message); err != nil {
+		return err
+	}
+
+	return s.trimMessages(key)
+}
+
+// trimMessages deletes the oldest messages until only MaxMessages are left.
+func (s *RedisStore) trimMessages(key string) error {
+	m, err := s.Client.HGetAll(key)
+	if err != nil {
+		return err
+	}
+
+	var max = s.MaxMessages()
+	if len(m) <= max {
+		return nil
+	}
+
+	var ids = make([]discord.Snowflake, 0, len(m))
+	for field := range m {
+		id, err := discord.ParseSnowflake(field)
+		if err != nil {
+			continue
+		}
+		ids = append(ids, id)
+	}
+
+	sort.Slice(ids, func(i, j int) bool {
+		return ids[i] > ids[j]
+	})
+
+	if len(ids) <= max {
+		return nil
+	}
+
+	var old = make([]string, 0, len(ids)-max)
+	for _, id := range ids[max:] {
+		old = append(old, strconv.FormatInt(int64(id), 10))
+	}
+
+	return s.Client.HDel(key, old...)
+}
+
+func (s *RedisStore) MessageRemove(
+	channelID, messageID discord.Snowflake) error {
+
+	return s.hdel(s.messagesKey(channelID), messageID.String())
+}
+
+////
+
+func (s *RedisStore) Presence(
+	guildID, userID discord.Snowflake) (*discord.Presence, error) {
+
+	var p *discord.Presence
+	return p, s.hget(s.guildKey(guildID, "presences"), userID.String(), &p)
+}
+
+func (s *RedisStore) Presences(
+	guildID discord.Snowflake) ([]discord.Presence, error) {
+
+	var ps []discord.Presence
+
+	return ps, s.hgetall(s.guildKey(guildID, "presences"), func(b []byte) error {
+		var p discord.Presence
+		if err := s.Unmarshal(b, &p); err != nil {
+			return err
+		}
+
+		ps = append(ps, p)
+		return nil
+	})
+}
+
+func (s *RedisStore) PresenceSet(
+	guildID discord.Snowflake, presence *discord.Presence) error {
+
+	return s.hset(s.guildKey(guildID, "presences"),
+		presence.User.ID.String(), s.PresenceTTL, presence)
+}
+
+func (s *RedisStore) PresenceRemove(guildID, userID discord.Snowflake) error {
+	return s.hdel(s.guildKey(guildID, "presences"), userID.String())
+}
+
+////
+
+func (s *RedisStore) Role(
+	guildID, roleID discord.Snowflake) (*discord.Role, error) {
+
+	var r *discord.Role
+	return r, s.hget(s.guildKey(guildID, "roles"), roleID.String(), &r)
+}
+
+func (s *RedisStore) Roles(guildID discord.Snowflake) ([]discord.Role, error) {
+	var rs []discord.Role
+
+	return rs, s.hgetall(s.guildKey(guildID, "roles"), func(b []byte) error {
+		var r discord.Role
+		if err := s.Unmarshal(b, &r); err != nil {
+			return err
+		}
+
+		rs = append(rs, r)
+		return nil
+	})
+}
+
+func (s *RedisStore) RoleSet(
+	guildID discord.Snowflake, role *discord.Role) error {
+
+	return s.hset(s.guildKey(guildID, "roles"),
+		role.ID.String(), s.GuildTTL, role)
+}
+
+func (s *RedisStore) RoleRemove(guildID, roleID discord.Snowflake) error {
+	return s.hdel(s.guildKey(guildID, "roles"), roleID.String())
+}
+
+////
+
+// Threads are kept with the ChannelTTL. A guild without active threads can't
+// be told apart from an unknown one, so its threads are always fetched.
+
+func (s *RedisStore) Threads(
+	guildID discord.Snowflake) ([]discord.Channel, error) {
+
+	var ts []discord.Channel
+
+	return ts, s.hgetall(s.guildKey(guildID, "threads"), func(b []byte) error {
+		var t discord.Channel
+		if err := s.Unmarshal(b, &t); err != nil {
+			return err
+		}
+
+		ts = append(ts, t)
+		return nil
+	})
+}
+
+func (s *RedisStore) ThreadSet(thread *discord.Channel) error {
+	return s.hset(s.guildKey(thread.GuildID, "threads"),
+		thread.ID.String(), s.ChannelTTL, thread)
+}
+
+func (s *RedisStore) ThreadRemove(guildID, threadID discord.Snowflake) error {
+	return s.hdel(s.guildKey(guildID, "threads"), threadID.String())
+}
+
+func (s *RedisStore) ThreadListSet(
+	guildID discord.Snowflake, threads []discord.Channel) error {
+
+	if err := s.Client.Del(s.guildKey(guildID, "threads")); err != nil {
+		return err
+	}
+
+	for _, t := range threads {
+		t.GuildID = guildID
+
+		if err := s.ThreadSet(&t); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
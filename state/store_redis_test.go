@@ -0,0 +1,18 @@
+// +build unit
+
+package state
+
+import "testing"
+
+func TestNewRedisStoreDefaults(t *testing.T) {
+	var opts = &RedisStoreOptions{MessageTTL: 1}
+
+	s := NewRedisStore(nil, opts)
+	if s.Prefix != "arikawa:" || s.MaxMessages() != 50 || s.MessageTTL != 1 {
+		t.Fatalf("Unexpected options: %+v", *s.RedisStoreOptions)
+	}
+
+	if *opts != (RedisStoreOptions{MessageTTL: 1}) {
+		t.Fatalf("The given options were changed: %+v", *opts)
+	}
+}
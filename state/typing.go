@@ -0,0 +1,169 @@
+package state
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/diamondburned/arikawa/discord"
+	"github.com/diamondburned/arikawa/gateway"
+)
+
+// TypingTimeout is how long a user is typing for after a TypingStartEvent,
+// which clients send again every few seconds while the user types.
+var TypingTimeout = 10 * time.Second
+
+// TypingStartedEvent is sent to the handler when a user starts typing in a
+// channel. Unlike gateway.TypingStartEvent, it's not sent again while the user
+// keeps typing.
+type TypingStartedEvent gateway.TypingStartEvent
+
+// TypingStoppedEvent is sent to the handler when a user stops typing, which
+// is when they send a message or after TypingTimeout.
+type TypingStoppedEvent struct {
+	ChannelID discord.Snowflake
+	UserID    discord.Snowflake
+	GuildID   discord.Snowflake
+}
+
+type typingKey struct {
+	channel discord.Snowflake
+	user    discord.Snowflake
+}
+
+type typingUser struct {
+	ev    gateway.TypingStartEvent
+	since time.Time
+	timer *time.Timer
+	// gen is the generation of the timer, so a timer that fired before being
+	// restarted doesn't remove the user.
+	gen uint64
+}
+
+type typingUsers struct {
+	mutex sync.Mutex
+	users map[typingKey]*typingUser
+	gen   uint64
+}
+
+// TypingUsers returns the users typing in the channel, in the order they
+// started typing. It's always empty unless TrackTyping is true.
+func (s *State) TypingUsers(
+	channelID discord.Snowflake) []gateway.TypingStartEvent {
+
+	s.typing.mutex.Lock()
+	defer s.typing.mutex.Unlock()
+
+	var users []*typingUser
+	for k, u := range s.typing.users {
+		if k.channel == channelID {
+			users = append(users, u)
+		}
+	}
+
+	sort.Slice(users, func(i, j int) bool {
+		return users[i].since.Before(users[j].since)
+	})
+
+	var evs = make([]gateway.TypingStartEvent, len(users))
+	for i, u := range users {
+		evs[i] = u.ev
+	}
+
+	return evs
+}
+
+// TypingIn returns the IDs of the users typing in the channel, in the order
+// they started typing, such as to render a typing indicator. Like TypingUsers,
+// it's always empty unless TrackTyping is true.
+func (s *State) TypingIn(channelID discord.Snowflake) []discord.Snowflake {
+	var users = s.TypingUsers(channelID)
+
+	var ids = make([]discord.Snowflake, len(users))
+	for i, u := range users {
+		ids[i] = u.UserID
+	}
+
+	return ids
+}
+
+func (s *State) onTypingEvent(iface interface{}) {
+	if !s.TrackTyping {
+		return
+	}
+
+	switch ev := iface.(type) {
+	case *gateway.ReadyEvent:
+		s.typing.mutex.Lock()
+		for _, u := range s.typing.users {
+			u.timer.Stop()
+		}
+		s.typing.users = nil
+		s.typing.mutex.Unlock()
+
+	case *gateway.TypingStartEvent:
+		s.typingStart(ev)
+
+	case *gateway.MessageCreateEvent:
+		s.typingStop(typingKey{ev.ChannelID, ev.Author.ID}, 0)
+	}
+}
+
+func (s *State) typingStart(ev *gateway.TypingStartEvent) {
+	var key = typingKey{ev.ChannelID, ev.UserID}
+
+	s.typing.mutex.Lock()
+
+	if u, ok := s.typing.users[key]; ok {
+		u.ev = *ev
+		u.timer.Stop()
+		u.gen, u.timer = s.typingTimer(key)
+
+		s.typing.mutex.Unlock()
+		return
+	}
+
+	if s.typing.users == nil {
+		s.typing.users = make(map[typingKey]*typingUser)
+	}
+
+	var u = &typingUser{ev: *ev, since: time.Now()}
+	u.gen, u.timer = s.typingTimer(key)
+	s.typing.users[key] = u
+
+	s.typing.mutex.Unlock()
+
+	s.Handler.Call((*TypingStartedEvent)(ev))
+}
+
+// typingTimer starts a timer that removes the typing user, and returns it with
+// its generation. The mutex must be held.
+func (s *State) typingTimer(key typingKey) (uint64, *time.Timer) {
+	s.typing.gen++
+	var gen = s.typing.gen
+
+	return gen, time.AfterFunc(TypingTimeout, func() { s.typingStop(key, gen) })
+}
+
+// typingStop removes the typing user. If gen isn't 0, the user is only removed
+// if their timer is still of that generation.
+func (s *State) typingStop(key typingKey, gen uint64) {
+	s.typing.mutex.Lock()
+
+	u, ok := s.typing.users[key]
+	if !ok || (gen > 0 && u.gen != gen) {
+		s.typing.mutex.Unlock()
+		return
+	}
+
+	u.timer.Stop()
+	delete(s.typing.users, key)
+
+	s.typing.mutex.Unlock()
+
+	s.Handler.Call(&TypingStoppedEvent{
+		ChannelID: key.channel,
+		UserID:    key.user,
+		GuildID:   u.ev.GuildID,
+	})
+}
@@ -0,0 +1,187 @@
+package state
+
+import (
+	"sync"
+
+	"github.com/diamondburned/arikawa/discord"
+	"github.com/diamondburned/arikawa/gateway"
+)
+
+// UnreadUpdateEvent is sent to the handler when the unread or mention count
+// of a channel may have changed. It's only sent to user accounts, as bots
+// don't have read states.
+type UnreadUpdateEvent struct {
+	ChannelID discord.Snowflake
+	Unread    int
+	Mentions  int
+}
+
+// readStates is nil for bots, which don't get read states.
+type readStates struct {
+	mutex  sync.Mutex
+	states map[discord.Snowflake]gateway.ReadState
+}
+
+func (r *readStates) get(channelID discord.Snowflake) gateway.ReadState {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	return r.states[channelID]
+}
+
+// UnreadCount returns the number of unread messages in the channel. Only the
+// messages in the store are counted, so there are at most MaxMessages. Bots
+// have no read states, so all their messages are unread.
+func (s *State) UnreadCount(channelID discord.Snowflake) int {
+	var last = s.reads.get(channelID).LastMessageID
+
+	msgs, err := s.Store.Messages(channelID)
+	if err != nil {
+		return 0
+	}
+
+	var unread int
+	for _, m := range msgs {
+		if m.ID > last {
+			unread++
+		}
+	}
+
+	return unread
+}
+
+// MentionCount returns the number of unread messages in the channel that
+// mention the current user, directly, through one of their roles or with
+// @everyone. It's always 0 for bots.
+func (s *State) MentionCount(channelID discord.Snowflake) int {
+	return s.reads.get(channelID).MentionCount
+}
+
+func (s *State) onUnreadEvent(iface interface{}) {
+	var channelID discord.Snowflake
+
+	switch ev := iface.(type) {
+	case *gateway.ReadyEvent:
+		s.reads.mutex.Lock()
+		s.reads.states = nil
+
+		if ev.ReadState != nil {
+			s.reads.states = make(map[discord.Snowflake]gateway.ReadState)
+			for _, rs := range ev.ReadState {
+				s.reads.states[rs.ChannelID] = rs
+			}
+		}
+
+		s.reads.mutex.Unlock()
+		return
+
+	case *gateway.MessageCreateEvent:
+		var mentioned = ev.Author.ID != s.Ready.User.ID &&
+			s.mentionsSelf((*discord.Message)(ev))
+
+		ok := s.updateReadState(ev.ChannelID, func(rs *gateway.ReadState) {
+			switch {
+			case ev.Author.ID == s.Ready.User.ID:
+				// Sending a message reads the channel.
+				rs.LastMessageID = ev.ID
+				rs.MentionCount = 0
+			case mentioned:
+				rs.MentionCount++
+			}
+		})
+		if !ok {
+			return
+		}
+		channelID = ev.ChannelID
+
+	case *gateway.MessageAckEvent:
+		ok := s.updateReadState(ev.ChannelID, func(rs *gateway.ReadState) {
+			rs.LastMessageID = ev.MessageID
+			rs.MentionCount = 0
+		})
+		if !ok {
+			return
+		}
+		channelID = ev.ChannelID
+
+	// Mention counts aren't lowered for deleted messages, as there's no way
+	// to know if they were mentions.
+	case *gateway.MessageDeleteEvent:
+		channelID = ev.ChannelID
+	case *gateway.MessageDeleteBulkEvent:
+		channelID = ev.ChannelID
+
+	default:
+		return
+	}
+
+	if !s.tracksReads() {
+		return
+	}
+
+	s.Handler.Call(&UnreadUpdateEvent{
+		ChannelID: channelID,
+		Unread:    s.UnreadCount(channelID),
+		Mentions:  s.MentionCount(channelID),
+	})
+}
+
+func (s *State) tracksReads() bool {
+	s.reads.mutex.Lock()
+	defer s.reads.mutex.Unlock()
+
+	return s.reads.states != nil
+}
+
+// updateReadState calls fn with the read state of the channel. False is
+// returned if read states aren't tracked.
+func (s *State) updateReadState(
+	channelID discord.Snowflake, fn func(rs *gateway.ReadState)) bool {
+
+	s.reads.mutex.Lock()
+	defer s.reads.mutex.Unlock()
+
+	if s.reads.states == nil {
+		return false
+	}
+
+	rs := s.reads.states[channelID]
+	rs.ChannelID = channelID
+	fn(&rs)
+	s.reads.states[channelID] = rs
+
+	return true
+}
+
+// mentionsSelf returns true if the message mentions the current user. Role
+// mentions are only known if the member of the current user is in the store.
+func (s *State) mentionsSelf(m *discord.Message) bool {
+	if m.MentionEveryone {
+		return true
+	}
+
+	for _, u := range m.Mentions {
+		if u.ID == s.Ready.User.ID {
+			return true
+		}
+	}
+
+	if len(m.MentionRoleIDs) == 0 || !m.GuildID.Valid() {
+		return false
+	}
+
+	member, err := s.Store.Member(m.GuildID, s.Ready.User.ID)
+	if err != nil {
+		return false
+	}
+
+	for _, id := range m.MentionRoleIDs {
+		for _, roleID := range member.RoleIDs {
+			if id == roleID {
+				return true
+			}
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,306 @@
+package state
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/diamondburned/arikawa/discord"
+	"github.com/pkg/errors"
+)
+
+type DiscrepancyKind uint8
+
+const (
+	// MissingFromStore means the API has something that the store doesn't.
+	MissingFromStore DiscrepancyKind = iota
+	// ExtraInStore means the store has something that the API doesn't, such
+	// as a deleted channel.
+	ExtraInStore
+	// StaleInStore means the store has something that differs from the API.
+	StaleInStore
+)
+
+func (k DiscrepancyKind) String() string {
+	switch k {
+	case MissingFromStore:
+		return "missing from store"
+	case ExtraInStore:
+		return "extra in store"
+	case StaleInStore:
+		return "stale in store"
+	default:
+		return "unknown"
+	}
+}
+
+// Discrepancy is a difference between the store and the API, found by
+// VerifyCache.
+type Discrepancy struct {
+	Kind DiscrepancyKind
+	// Resource is "guild", "channel", "role" or "member count".
+	Resource string
+	// ID is the ID of the resource. It's the guild ID for the member count.
+	ID discord.Snowflake
+
+	// Fields are the names of the fields that differ, for StaleInStore.
+	Fields []string
+	// Stored and Actual are the member counts, for the member count.
+	Stored, Actual uint64
+}
+
+func (d Discrepancy) String() string {
+	var s = d.Resource + " " + d.ID.String() + " " + d.Kind.String()
+
+	switch {
+	case d.Resource == "member count":
+		s += ": " + strconv.FormatUint(d.Stored, 10) + " stored, about " +
+			strconv.FormatUint(d.Actual, 10) + " in the API"
+	case len(d.Fields) > 0:
+		s += " (" + strings.Join(d.Fields, ", ") + ")"
+	}
+
+	return s
+}
+
+// VerifyCache compares the guild, its channels, its roles and its member count
+// in the store against fresh data from the API, and returns the
+// discrepancies. This is a diagnostic tool for custom stores and missed
+// events; it makes 2 API calls and shouldn't be called often.
+//
+// The member count is only compared once MembersAll has stored every member
+// of the guild, as the store otherwise only has some of them. The API's count
+// is approximate, so small differences are expected.
+func (s *State) VerifyCache(guildID discord.Snowflake) ([]Discrepancy, error) {
+	guild, err := s.Session.GuildWithCount(guildID)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to get guild")
+	}
+
+	channels, err := s.Session.Channels(guildID)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to get channels")
+	}
+
+	var ds []Discrepancy
+
+	storedGuild, err := s.Store.Guild(guildID)
+	switch {
+	case err != nil:
+		ds = append(ds, Discrepancy{
+			Kind: MissingFromStore, Resource: "guild", ID: guildID,
+		})
+	default:
+		if fields := diffGuild(*storedGuild, *guild); len(fields) > 0 {
+			ds = append(ds, Discrepancy{
+				Kind: StaleInStore, Resource: "guild", ID: guildID,
+				Fields: fields,
+			})
+		}
+	}
+
+	// The store may not know the channels or roles of the guild at all, in
+	// which case they're all missing.
+	storedChannels, _ := s.Store.Channels(guildID)
+	storedRoles, _ := s.Store.Roles(guildID)
+
+	ds = append(ds, verifyChannels(storedChannels, channels)...)
+	ds = append(ds, verifyRoles(storedRoles, guild.Roles)...)
+
+	if d, ok := s.verifyMemberCount(guild); ok {
+		ds = append(ds, d)
+	}
+
+	return ds, nil
+}
+
+// verifyMemberCount compares the stored members of the guild against its
+// approximate member count, if all of them were requested.
+func (s *State) verifyMemberCount(guild *discord.Guild) (Discrepancy, bool) {
+	if _, ok := s.fullMembers.Load(guild.ID); !ok {
+		return Discrepancy{}, false
+	}
+
+	members, err := s.Store.Members(guild.ID)
+	if err != nil || uint64(len(members)) == guild.ApproximateMembers {
+		return Discrepancy{}, false
+	}
+
+	return Discrepancy{
+		Kind:     StaleInStore,
+		Resource: "member count",
+		ID:       guild.ID,
+		Stored:   uint64(len(members)),
+		Actual:   guild.ApproximateMembers,
+	}, true
+}
+
+func verifyChannels(stored, actual []discord.Channel) []Discrepancy {
+	var ds []Discrepancy
+	var storedMap = make(map[discord.Snowflake]discord.Channel, len(stored))
+	for _, ch := range stored {
+		storedMap[ch.ID] = ch
+	}
+
+	for _, ch := range actual {
+		st, ok := storedMap[ch.ID]
+		if !ok {
+			ds = append(ds, Discrepancy{
+				Kind: MissingFromStore, Resource: "channel", ID: ch.ID,
+			})
+			continue
+		}
+
+		delete(storedMap, ch.ID)
+
+		if fields := diffChannel(st, ch); len(fields) > 0 {
+			ds = append(ds, Discrepancy{
+				Kind: StaleInStore, Resource: "channel", ID: ch.ID,
+				Fields: fields,
+			})
+		}
+	}
+
+	for id := range storedMap {
+		ds = append(ds, Discrepancy{
+			Kind: ExtraInStore, Resource: "channel", ID: id,
+		})
+	}
+
+	sortDiscrepancies(ds)
+	return ds
+}
+
+func verifyRoles(stored, actual []discord.Role) []Discrepancy {
+	var ds []Discrepancy
+	var storedMap = make(map[discord.Snowflake]discord.Role, len(stored))
+	for _, r := range stored {
+		storedMap[r.ID] = r
+	}
+
+	for _, r := range actual {
+		st, ok := storedMap[r.ID]
+		if !ok {
+			ds = append(ds, Discrepancy{
+				Kind: MissingFromStore, Resource: "role", ID: r.ID,
+			})
+			continue
+		}
+
+		delete(storedMap, r.ID)
+
+		if fields := diffRole(st, r); len(fields) > 0 {
+			ds = append(ds, Discrepancy{
+				Kind: StaleInStore, Resource: "role", ID: r.ID,
+				Fields: fields,
+			})
+		}
+	}
+
+	for id := range storedMap {
+		ds = append(ds, Discrepancy{
+			Kind: ExtraInStore, Resource: "role", ID: id,
+		})
+	}
+
+	sortDiscrepancies(ds)
+	return ds
+}
+
+// sortDiscrepancies sorts the discrepancies by ID, so the results are stable.
+func sortDiscrepancies(ds []Discrepancy) {
+	sort.Slice(ds, func(i, j int) bool {
+		return ds[i].ID < ds[j].ID
+	})
+}
+
+func diffGuild(stored, actual discord.Guild) []string {
+	var fields []string
+
+	if stored.Name != actual.Name {
+		fields = append(fields, "name")
+	}
+	if stored.Icon != actual.Icon {
+		fields = append(fields, "icon")
+	}
+	if stored.OwnerID != actual.OwnerID {
+		fields = append(fields, "owner")
+	}
+	if stored.SystemChannelID != actual.SystemChannelID {
+		fields = append(fields, "system channel")
+	}
+	if len(stored.Emojis) != len(actual.Emojis) {
+		fields = append(fields, "emojis")
+	}
+
+	return fields
+}
+
+func diffChannel(stored, actual discord.Channel) []string {
+	var fields []string
+
+	if stored.Type != actual.Type {
+		fields = append(fields, "type")
+	}
+	if stored.Name != actual.Name {
+		fields = append(fields, "name")
+	}
+	if stored.Position != actual.Position {
+		fields = append(fields, "position")
+	}
+	if stored.Topic != actual.Topic {
+		fields = append(fields, "topic")
+	}
+	if stored.NSFW != actual.NSFW {
+		fields = append(fields, "nsfw")
+	}
+	if stored.CategoryID != actual.CategoryID {
+		fields = append(fields, "category")
+	}
+	if !sameOverwrites(stored.Permissions, actual.Permissions) {
+		fields = append(fields, "permissions")
+	}
+
+	return fields
+}
+
+func diffRole(stored, actual discord.Role) []string {
+	var fields []string
+
+	if stored.Name != actual.Name {
+		fields = append(fields, "name")
+	}
+	if stored.Color != actual.Color {
+		fields = append(fields, "color")
+	}
+	if stored.Position != actual.Position {
+		fields = append(fields, "position")
+	}
+	if stored.Permissions != actual.Permissions {
+		fields = append(fields, "permissions")
+	}
+	if stored.Hoist != actual.Hoist || stored.Mentionable != actual.Mentionable {
+		fields = append(fields, "flags")
+	}
+
+	return fields
+}
+
+func sameOverwrites(stored, actual []discord.Overwrite) bool {
+	if len(stored) != len(actual) {
+		return false
+	}
+
+	var m = make(map[discord.Snowflake]discord.Overwrite, len(stored))
+	for _, o := range stored {
+		m[o.ID] = o
+	}
+
+	for _, o := range actual {
+		if m[o.ID] != o {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,64 @@
+// +build unit
+
+package state
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/diamondburned/arikawa/discord"
+)
+
+func TestVerifyChannels(t *testing.T) {
+	var stored = []discord.Channel{
+		{ID: 1, Name: "general"},
+		{ID: 2, Name: "old-name", Topic: "topic"},
+		{ID: 3, Name: "deleted"},
+	}
+	var actual = []discord.Channel{
+		{ID: 1, Name: "general"},
+		{ID: 2, Name: "new-name", Topic: "topic"},
+		{ID: 4, Name: "created"},
+	}
+
+	var expected = []Discrepancy{
+		{Kind: StaleInStore, Resource: "channel", ID: 2,
+			Fields: []string{"name"}},
+		{Kind: ExtraInStore, Resource: "channel", ID: 3},
+		{Kind: MissingFromStore, Resource: "channel", ID: 4},
+	}
+
+	if ds := verifyChannels(stored, actual); !reflect.DeepEqual(ds, expected) {
+		t.Fatalf("Unexpected discrepancies: %v", ds)
+	}
+}
+
+func TestVerifyMemberCount(t *testing.T) {
+	s := &State{Store: NewDefaultStore(nil)}
+
+	for i := 1; i <= 2; i++ {
+		m := discord.Member{User: discord.User{ID: discord.Snowflake(i)}}
+		if err := s.Store.MemberSet(1, &m); err != nil {
+			t.Fatal("Failed to set member:", err)
+		}
+	}
+
+	var guild = &discord.Guild{ID: 1, ApproximateMembers: 10}
+
+	// Only some of the members are stored.
+	if d, ok := s.verifyMemberCount(guild); ok {
+		t.Fatal("Unexpected discrepancy:", d)
+	}
+
+	s.fullMembers.Store(discord.Snowflake(1), struct{}{})
+
+	var expected = Discrepancy{
+		Kind: StaleInStore, Resource: "member count", ID: 1,
+		Stored: 2, Actual: 10,
+	}
+
+	d, ok := s.verifyMemberCount(guild)
+	if !ok || !reflect.DeepEqual(d, expected) {
+		t.Fatal("Unexpected discrepancy:", d, ok)
+	}
+}
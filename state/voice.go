@@ -0,0 +1,138 @@
+package state
+
+import (
+	"sync"
+
+	"github.com/diamondburned/arikawa/discord"
+	"github.com/diamondburned/arikawa/gateway"
+)
+
+// voiceServers are the last voice servers of each guild.
+type voiceServers struct {
+	mutex  sync.Mutex
+	guilds map[discord.Snowflake]gateway.VoiceServerUpdateEvent
+}
+
+// VoiceState returns the voice state of the user in the guild. There's no API
+// to fetch it, so ErrStoreNotFound is returned if the user isn't connected to
+// a voice channel, or if the Store isn't a VoiceStateStore.
+func (s *State) VoiceState(
+	guildID, userID discord.Snowflake) (*discord.VoiceState, error) {
+
+	vss, ok := s.Store.(VoiceStateStore)
+	if !ok {
+		return nil, ErrStoreNotFound
+	}
+
+	vs, err := vss.VoiceState(guildID, userID)
+	s.storeAccess("voice_state", err == nil)
+	return vs, err
+}
+
+// VoiceStatesIn returns the voice states of the users connected to the voice
+// channel, which may be none. Like VoiceState, it requires the Store to be a
+// VoiceStateStore.
+func (s *State) VoiceStatesIn(
+	channelID discord.Snowflake) ([]discord.VoiceState, error) {
+
+	vss, ok := s.Store.(VoiceStateStore)
+	if !ok {
+		return nil, ErrStoreNotFound
+	}
+
+	ch, err := s.Channel(channelID)
+	if err != nil {
+		return nil, err
+	}
+
+	all, err := vss.VoiceStates(ch.GuildID)
+	s.storeAccess("voice_states", err == nil)
+	if err != nil {
+		if err == ErrStoreNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var states []discord.VoiceState
+	for _, vs := range all {
+		if vs.ChannelID == channelID {
+			states = append(states, vs)
+		}
+	}
+
+	return states, nil
+}
+
+// VoiceServer returns the last voice server that the gateway sent for the
+// guild, which is the one the current user is connected to, if any. Its
+// Endpoint is empty if the server went down and a new one isn't allocated yet.
+func (s *State) VoiceServer(
+	guildID discord.Snowflake) (*gateway.VoiceServerUpdateEvent, error) {
+
+	s.voice.mutex.Lock()
+	defer s.voice.mutex.Unlock()
+
+	ev, ok := s.voice.guilds[guildID]
+	if !ok {
+		return nil, ErrStoreNotFound
+	}
+
+	return &ev, nil
+}
+
+func (s *State) onVoiceEvent(iface interface{}) {
+	switch ev := iface.(type) {
+	case *gateway.GuildCreateEvent:
+		vss, ok := s.Store.(VoiceStateStore)
+		if !ok {
+			return
+		}
+
+		for _, vs := range ev.VoiceStates {
+			vs.GuildID = ev.ID
+
+			if err := vss.VoiceStateSet(ev.ID, &vs); err != nil {
+				s.stateErr(err, "Failed to add a voice state from guild")
+			}
+		}
+
+	case *gateway.GuildDeleteEvent:
+		if !ev.Unavailable {
+			s.voice.mutex.Lock()
+			delete(s.voice.guilds, ev.ID)
+			s.voice.mutex.Unlock()
+		}
+
+	case *gateway.VoiceStateUpdateEvent:
+		vss, ok := s.Store.(VoiceStateStore)
+		if !ok {
+			return
+		}
+
+		// Users that leave have no channel anymore.
+		if !ev.ChannelID.Valid() {
+			err := vss.VoiceStateRemove(ev.GuildID, ev.UserID)
+			if err != nil && err != ErrStoreNotFound {
+				s.stateErr(err, "Failed to remove a voice state in state")
+			}
+			return
+		}
+
+		err := vss.VoiceStateSet(ev.GuildID, (*discord.VoiceState)(ev))
+		if err != nil {
+			s.stateErr(err, "Failed to update a voice state in state")
+		}
+
+	case *gateway.VoiceServerUpdateEvent:
+		s.voice.mutex.Lock()
+		defer s.voice.mutex.Unlock()
+
+		if s.voice.guilds == nil {
+			s.voice.guilds =
+				map[discord.Snowflake]gateway.VoiceServerUpdateEvent{}
+		}
+
+		s.voice.guilds[ev.GuildID] = *ev
+	}
+}
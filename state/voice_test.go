@@ -0,0 +1,93 @@
+// +build unit
+
+package state
+
+import (
+	"testing"
+
+	"github.com/diamondburned/arikawa/api"
+	"github.com/diamondburned/arikawa/discord"
+	"github.com/diamondburned/arikawa/gateway"
+	"github.com/diamondburned/arikawa/session"
+)
+
+func TestVoiceStates(t *testing.T) {
+	s := &State{
+		Session:  &session.Session{Client: api.NewClient("")},
+		Store:    NewDefaultStore(nil),
+		StateLog: func(err error) { t.Error(err) },
+	}
+
+	s.Store.ChannelSet(&discord.Channel{ID: 10, GuildID: 1})
+	s.Store.ChannelSet(&discord.Channel{ID: 11, GuildID: 1})
+
+	s.onVoiceEvent(&gateway.GuildCreateEvent{
+		Guild: discord.Guild{ID: 1},
+		VoiceStates: []discord.VoiceState{
+			{ChannelID: 10, UserID: 2},
+			{ChannelID: 11, UserID: 3},
+		},
+	})
+
+	s.onVoiceEvent(&gateway.VoiceStateUpdateEvent{
+		GuildID: 1, ChannelID: 10, UserID: 4, SelfMute: true,
+	})
+	// User 3 moves to the first channel, and then user 2 leaves.
+	s.onVoiceEvent(&gateway.VoiceStateUpdateEvent{
+		GuildID: 1, ChannelID: 10, UserID: 3,
+	})
+	s.onVoiceEvent(&gateway.VoiceStateUpdateEvent{
+		GuildID: 1, UserID: 2,
+	})
+
+	vs, err := s.VoiceState(1, 4)
+	if err != nil {
+		t.Fatal("Failed to get voice state:", err)
+	}
+	if vs.GuildID != 1 || !vs.SelfMute {
+		t.Fatal("Unexpected voice state:", vs)
+	}
+
+	if _, err := s.VoiceState(1, 2); err != ErrStoreNotFound {
+		t.Fatal("Unexpected error for a user that left:", err)
+	}
+
+	states, err := s.VoiceStatesIn(10)
+	if err != nil {
+		t.Fatal("Failed to get voice states:", err)
+	}
+	if len(states) != 2 || states[0].UserID != 3 || states[1].UserID != 4 {
+		t.Fatal("Unexpected voice states:", states)
+	}
+
+	states, err = s.VoiceStatesIn(11)
+	if err != nil || len(states) != 0 {
+		t.Fatal("Unexpected voice states in an empty channel:", states, err)
+	}
+}
+
+func TestVoiceServer(t *testing.T) {
+	s := &State{Store: NoopStore}
+
+	if _, err := s.VoiceServer(1); err != ErrStoreNotFound {
+		t.Fatal("Unexpected error without a voice server:", err)
+	}
+
+	s.onVoiceEvent(&gateway.VoiceServerUpdateEvent{
+		GuildID: 1, Token: "token", Endpoint: "example.com:80",
+	})
+
+	ev, err := s.VoiceServer(1)
+	if err != nil {
+		t.Fatal("Failed to get voice server:", err)
+	}
+	if ev.Token != "token" || ev.Endpoint != "example.com:80" {
+		t.Fatal("Unexpected voice server:", ev)
+	}
+
+	s.onVoiceEvent(&gateway.GuildDeleteEvent{ID: 1})
+
+	if _, err := s.VoiceServer(1); err != ErrStoreNotFound {
+		t.Fatal("Voice server kept after leaving the guild:", err)
+	}
+}
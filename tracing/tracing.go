@@ -0,0 +1,48 @@
+// Package tracing provides the Tracer interface, which the API client and the
+// handler.Trace middleware make spans with. It has no dependencies, so
+// OpenTelemetry is adapted outside of arikawa, roughly like so:
+//
+//	type otelTracer struct{ trace.Tracer }
+//
+//	func (t otelTracer) Start(ctx context.Context, name string,
+//		attrs ...tracing.Attribute) (context.Context, tracing.Span) {
+//
+//		ctx, span := t.Tracer.Start(ctx, name)
+//		s := otelSpan{span}
+//		s.SetAttributes(attrs...)
+//		return ctx, s
+//	}
+//
+// where otelSpan converts the attributes with attribute.String and friends,
+// and records the error given to End.
+//
+// REST requests are made as children of the span in their context, which is
+// the one given to api.WithContext.
+package tracing
+
+import "context"
+
+// Attribute is a key-value pair that describes a span, such as the route of
+// a request.
+type Attribute struct {
+	Key   string
+	Value interface{}
+}
+
+// A creates an Attribute.
+func A(key string, value interface{}) Attribute {
+	return Attribute{key, value}
+}
+
+type Tracer interface {
+	// Start starts a span as a child of the span in ctx, if there's one, and
+	// returns a context with the new span.
+	Start(ctx context.Context, name string,
+		attrs ...Attribute) (context.Context, Span)
+}
+
+type Span interface {
+	SetAttributes(attrs ...Attribute)
+	// End ends the span. Err is what the span failed with, or nil.
+	End(err error)
+}
@@ -0,0 +1,37 @@
+// Package json sets the JSON driver used by the whole library, such as for the
+// events of the Gateway, the requests of the API and the stores of the State.
+// The standard library is used by default.
+//
+// A faster library such as jsoniter or sonic can be used with Funcs:
+//
+//	json.SetDriver(json.Funcs{
+//		MarshalFunc:   jsoniter.ConfigFastest.Marshal,
+//		UnmarshalFunc: jsoniter.ConfigFastest.Unmarshal,
+//	})
+//
+// SetDriver must be called before anything else, such as in an init function
+// or at the start of main.
+package json
+
+import (
+	"github.com/diamondburned/arikawa/internal/json"
+)
+
+type (
+	// Driver marshals and unmarshals JSON.
+	Driver = json.Driver
+	// Funcs is a Driver made of a Marshal and an Unmarshal function.
+	Funcs = json.Funcs
+	// Std is the Driver that uses encoding/json.
+	Std = json.Std
+)
+
+// SetDriver sets the driver used by the library. Nil resets it to Std.
+func SetDriver(d Driver) {
+	json.SetDriver(d)
+}
+
+// GetDriver returns the driver used by the library.
+func GetDriver() Driver {
+	return json.GetDriver()
+}
@@ -0,0 +1,94 @@
+package voice
+
+import (
+	"time"
+
+	"github.com/diamondburned/arikawa/discord"
+)
+
+// EncryptionMode is the only encryption mode supported, which is
+// xsalsa20_poly1305 with the RTP header as the nonce.
+const EncryptionMode = "xsalsa20_poly1305"
+
+// https://discordapp.com/developers/docs/topics/voice-connections#establishing-a-voice-websocket-connection-example-voice-identify-payload
+type IdentifyData struct {
+	GuildID   discord.Snowflake `json:"server_id"`
+	UserID    discord.Snowflake `json:"user_id"`
+	SessionID string            `json:"session_id"`
+	Token     string            `json:"token"`
+}
+
+// Identify sends an Identify operation (opcode 0) to the voice gateway.
+func (g *Gateway) Identify() error {
+	return g.Send(IdentifyOP, &IdentifyData{
+		GuildID:   g.state.GuildID,
+		UserID:    g.state.UserID,
+		SessionID: g.state.SessionID,
+		Token:     g.state.Token,
+	})
+}
+
+// https://discordapp.com/developers/docs/topics/voice-connections#establishing-a-voice-udp-connection-example-select-protocol-payload
+type SelectProtocolData struct {
+	Protocol string             `json:"protocol"` // "udp"
+	Data     SelectProtocolInfo `json:"data"`
+}
+
+type SelectProtocolInfo struct {
+	Address string `json:"address"`
+	Port    uint16 `json:"port"`
+	Mode    string `json:"mode"`
+}
+
+// SelectProtocol sends a Select Protocol operation (opcode 1) to the voice
+// gateway.
+func (g *Gateway) SelectProtocol(data SelectProtocolData) error {
+	return g.Send(SelectProtocolOP, data)
+}
+
+// Heartbeat sends a Heartbeat operation (opcode 3) to the voice gateway. The
+// nonce is the current Unix time in milliseconds.
+func (g *Gateway) Heartbeat() error {
+	return g.Send(HeartbeatOP, time.Now().UnixNano()/int64(time.Millisecond))
+}
+
+// SpeakingFlag is a bitmask of the speaking modes.
+type SpeakingFlag uint64
+
+const (
+	Microphone SpeakingFlag = 1 << iota
+	Soundshare
+	Priority
+)
+
+type SpeakingData struct {
+	Speaking SpeakingFlag `json:"speaking"`
+	Delay    int          `json:"delay"`
+	SSRC     uint32       `json:"ssrc"`
+}
+
+// Speaking sends a Speaking operation (opcode 5) to the voice gateway. This
+// has to be sent at least once before sending any audio.
+func (g *Gateway) Speaking(flag SpeakingFlag) error {
+	return g.Send(SpeakingOP, SpeakingData{
+		Speaking: flag,
+		Delay:    0,
+		SSRC:     g.ready.SSRC,
+	})
+}
+
+// https://discordapp.com/developers/docs/topics/voice-connections#resuming-voice-connection-example-resume-connection-payload
+type ResumeData struct {
+	GuildID   discord.Snowflake `json:"server_id"`
+	SessionID string            `json:"session_id"`
+	Token     string            `json:"token"`
+}
+
+// Resume sends a Resume operation (opcode 7) to the voice gateway.
+func (g *Gateway) Resume() error {
+	return g.Send(ResumeOP, &ResumeData{
+		GuildID:   g.state.GuildID,
+		SessionID: g.state.SessionID,
+		Token:     g.state.Token,
+	})
+}
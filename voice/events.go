@@ -0,0 +1,30 @@
+package voice
+
+import "github.com/diamondburned/arikawa/discord"
+
+// https://discordapp.com/developers/docs/topics/voice-connections#heartbeating
+type HelloEvent struct {
+	HeartbeatInterval float64 `json:"heartbeat_interval"` // milliseconds
+}
+
+// https://discordapp.com/developers/docs/topics/voice-connections#establishing-a-voice-websocket-connection-example-voice-ready-payload
+type ReadyEvent struct {
+	SSRC  uint32   `json:"ssrc"`
+	IP    string   `json:"ip"`
+	Port  int      `json:"port"`
+	Modes []string `json:"modes"`
+}
+
+// https://discordapp.com/developers/docs/topics/voice-connections#establishing-a-voice-udp-connection-example-session-description-payload
+type SessionDescriptionEvent struct {
+	Mode      string   `json:"mode"`
+	SecretKey [32]byte `json:"secret_key"`
+}
+
+// https://discordapp.com/developers/docs/topics/voice-connections#speaking-example-speaking-payload
+type SpeakingEvent struct {
+	Speaking SpeakingFlag      `json:"speaking"`
+	Delay    int               `json:"delay"`
+	SSRC     uint32            `json:"ssrc"`
+	UserID   discord.Snowflake `json:"user_id,omitempty"`
+}
@@ -0,0 +1,261 @@
+package voice
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/diamondburned/arikawa/discord"
+	"github.com/diamondburned/arikawa/gateway"
+	"github.com/diamondburned/arikawa/internal/json"
+	"github.com/diamondburned/arikawa/internal/wsutil"
+	"github.com/pkg/errors"
+)
+
+// Version is the voice gateway version used.
+const Version = "4"
+
+var (
+	ErrNoSessionID = errors.New("no SessionID given")
+	ErrNoEndpoint  = errors.New("no Endpoint given")
+	ErrNoToken     = errors.New("no Token given")
+)
+
+// State contains everything needed to connect to the voice gateway. It is
+// filled from the VoiceStateUpdate and VoiceServerUpdate events.
+type State struct {
+	GuildID   discord.Snowflake
+	ChannelID discord.Snowflake
+	UserID    discord.Snowflake
+
+	SessionID string
+	Token     string
+	Endpoint  string
+}
+
+// Gateway is a connection to a voice gateway. It is usually managed by a
+// Session.
+type Gateway struct {
+	WS *wsutil.Websocket
+	json.Driver
+
+	// Timeout for connecting and writing to the Websocket, uses the same
+	// default as the main gateway.
+	WSTimeout time.Duration
+
+	Pacemaker *gateway.Pacemaker
+
+	ErrorLog func(err error)
+
+	state State
+	ready ReadyEvent
+
+	mutex       sync.Mutex
+	description chan *SessionDescriptionEvent
+	done        chan struct{}
+	paceDeath   chan error
+}
+
+// NewGateway creates a new voice gateway. The state must have the SessionID,
+// Token and Endpoint filled.
+func NewGateway(state State, driver json.Driver) (*Gateway, error) {
+	switch {
+	case state.SessionID == "":
+		return nil, ErrNoSessionID
+	case state.Endpoint == "":
+		return nil, ErrNoEndpoint
+	case state.Token == "":
+		return nil, ErrNoToken
+	}
+
+	// The endpoint sometimes comes with a useless port.
+	var endpoint = strings.TrimSuffix(state.Endpoint, ":80")
+	var URL = "wss://" + endpoint + "/?v=" + Version
+
+	g := &Gateway{
+		Driver:      driver,
+		WSTimeout:   gateway.WSTimeout,
+		ErrorLog:    gateway.WSError,
+		state:       state,
+		description: make(chan *SessionDescriptionEvent, 1),
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), g.WSTimeout)
+	defer cancel()
+
+	conn := wsutil.NewConn(driver)
+	conn.DialOptions = gateway.WSDialOptions
+
+	ws, err := wsutil.NewCustom(ctx, conn, URL)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to connect to voice gateway "+URL)
+	}
+	g.WS = ws
+
+	return g, nil
+}
+
+// Ready returns the Ready event received when the gateway was opened. It
+// contains the UDP address and the SSRC.
+func (g *Gateway) Ready() ReadyEvent {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	return g.ready
+}
+
+// Open connects to the voice gateway and identifies, or resumes if resume is
+// true. It blocks until the gateway is ready.
+func (g *Gateway) Open(resume bool) error {
+	ctx, cancel := context.WithTimeout(context.Background(), g.WSTimeout)
+	defer cancel()
+
+	if err := g.WS.Dial(ctx); err != nil {
+		return errors.Wrap(err, "Failed to dial voice gateway")
+	}
+
+	if err := g.start(resume); err != nil {
+		g.Close()
+		return err
+	}
+
+	return nil
+}
+
+func (g *Gateway) start(resume bool) error {
+	ch := g.WS.Listen()
+
+	// Wait for an OP 8 Hello
+	var hello HelloEvent
+	if _, err := AssertEvent(g, <-ch, HelloOP, &hello); err != nil {
+		return errors.Wrap(err, "Error at Hello")
+	}
+
+	var heartrate = hello.HeartbeatInterval * float64(time.Millisecond)
+
+	g.Pacemaker = &gateway.Pacemaker{
+		Heartrate: time.Duration(heartrate),
+		Pace:      g.Heartbeat,
+	}
+	g.paceDeath = g.Pacemaker.StartAsync()
+
+	if resume {
+		if err := g.Resume(); err != nil {
+			return errors.Wrap(err, "Failed to resume")
+		}
+
+		if _, err := AssertEvent(g, <-ch, ResumedOP, nil); err != nil {
+			return errors.Wrap(err, "Error at Resumed")
+		}
+
+	} else {
+		if err := g.Identify(); err != nil {
+			return errors.Wrap(err, "Failed to identify")
+		}
+
+		var ready ReadyEvent
+		if _, err := AssertEvent(g, <-ch, ReadyOP, &ready); err != nil {
+			return errors.Wrap(err, "Error at Ready")
+		}
+
+		g.mutex.Lock()
+		g.ready = ready
+		g.mutex.Unlock()
+	}
+
+	g.done = make(chan struct{})
+	go g.handleWS(g.done)
+
+	return nil
+}
+
+// handleWS handles the voice gateway events until the Pacemaker stops.
+func (g *Gateway) handleWS(done chan struct{}) {
+	ch := g.WS.Listen()
+
+	defer close(done)
+
+	for {
+		select {
+		case err := <-g.paceDeath:
+			if err != nil {
+				g.ErrorLog(errors.Wrap(err, "Voice pacemaker died"))
+			}
+			return
+
+		case ev := <-ch:
+			if ev.Error != nil {
+				g.ErrorLog(ev.Error)
+				continue
+			}
+
+			if err := HandleEvent(g, ev.Data); err != nil {
+				g.ErrorLog(errors.Wrap(err, "Voice WS handler error"))
+			}
+		}
+	}
+}
+
+// SessionDescription sends the Select Protocol command and waits for the
+// Session Description, which contains the secret key used for encryption.
+func (g *Gateway) SessionDescription(
+	data SelectProtocolData) (*SessionDescriptionEvent, error) {
+
+	if err := g.SelectProtocol(data); err != nil {
+		return nil, errors.Wrap(err, "Failed to select protocol")
+	}
+
+	select {
+	case desc := <-g.description:
+		return desc, nil
+	case <-time.After(g.WSTimeout):
+		return nil, errors.New("Timed out waiting for session description")
+	}
+}
+
+// Close closes the voice gateway.
+func (g *Gateway) Close() error {
+	if g.Pacemaker != nil {
+		g.Pacemaker.Stop()
+	}
+
+	switch {
+	case g.done != nil:
+		// Wait for the event handler to exit, which also consumes the
+		// pacemaker's death.
+		<-g.done
+		g.done = nil
+	case g.paceDeath != nil:
+		// The event handler never started, so nothing would read this.
+		<-g.paceDeath
+	}
+	g.paceDeath = nil
+
+	return g.WS.Close(nil)
+}
+
+func (g *Gateway) Send(code OPCode, v interface{}) error {
+	var op = OP{
+		Code: code,
+	}
+
+	if v != nil {
+		b, err := g.Driver.Marshal(v)
+		if err != nil {
+			return errors.Wrap(err, "Failed to encode v")
+		}
+
+		op.Data = b
+	}
+
+	b, err := g.Driver.Marshal(op)
+	if err != nil {
+		return errors.Wrap(err, "Failed to encode payload")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), g.WSTimeout)
+	defer cancel()
+
+	return g.WS.Send(ctx, b)
+}
@@ -0,0 +1,109 @@
+package voice
+
+import (
+	"fmt"
+
+	"github.com/diamondburned/arikawa/internal/json"
+	"github.com/diamondburned/arikawa/internal/wsutil"
+	"github.com/pkg/errors"
+)
+
+// OPCode is a voice gateway OP code, documented at
+// https://discordapp.com/developers/docs/topics/opcodes-and-status-codes#voice.
+type OPCode uint8
+
+const (
+	IdentifyOP           OPCode = 0  // send
+	SelectProtocolOP     OPCode = 1  // send
+	ReadyOP              OPCode = 2  // recv
+	HeartbeatOP          OPCode = 3  // send
+	SessionDescriptionOP OPCode = 4  // recv
+	SpeakingOP           OPCode = 5  // send/recv
+	HeartbeatAckOP       OPCode = 6  // recv
+	ResumeOP             OPCode = 7  // send
+	HelloOP              OPCode = 8  // recv
+	ResumedOP            OPCode = 9  // recv
+	ClientDisconnectOP   OPCode = 13 // recv
+)
+
+type OP struct {
+	Code OPCode   `json:"op"`
+	Data json.Raw `json:"d,omitempty"`
+}
+
+func DecodeOP(driver json.Driver, ev wsutil.Event) (*OP, error) {
+	if ev.Error != nil {
+		return nil, ev.Error
+	}
+
+	var op *OP
+	if err := driver.Unmarshal(ev.Data, &op); err != nil {
+		return nil, errors.Wrap(err, "Failed to decode payload")
+	}
+
+	return op, nil
+}
+
+func AssertEvent(driver json.Driver,
+	ev wsutil.Event, code OPCode, v interface{}) (*OP, error) {
+
+	op, err := DecodeOP(driver, ev)
+	if err != nil {
+		return nil, err
+	}
+
+	if op.Code != code {
+		return op, fmt.Errorf(
+			"Unexpected OP Code: %d, expected %d (%s)",
+			op.Code, code, op.Data,
+		)
+	}
+
+	// Some events, such as Resumed, don't have any data.
+	if v == nil {
+		return op, nil
+	}
+
+	if err := driver.Unmarshal(op.Data, v); err != nil {
+		return op, errors.Wrap(err, "Failed to decode data")
+	}
+
+	return op, nil
+}
+
+func HandleEvent(g *Gateway, data []byte) error {
+	var op *OP
+	if err := g.Driver.Unmarshal(data, &op); err != nil {
+		return errors.Wrap(err, "OP error")
+	}
+
+	return HandleOP(g, op)
+}
+
+func HandleOP(g *Gateway, op *OP) error {
+	switch op.Code {
+	case HeartbeatAckOP:
+		g.Pacemaker.Echo()
+
+	case SessionDescriptionOP:
+		var desc *SessionDescriptionEvent
+		if err := g.Driver.Unmarshal(op.Data, &desc); err != nil {
+			return errors.Wrap(err, "Failed to parse SessionDescription")
+		}
+
+		// Don't block if nobody is waiting for the description.
+		select {
+		case g.description <- desc:
+		default:
+		}
+
+	case SpeakingOP, ClientDisconnectOP, ResumedOP, HelloOP:
+		// Not handled, as we only send audio.
+		return nil
+
+	default:
+		return fmt.Errorf("Unknown OP code %d", op.Code)
+	}
+
+	return nil
+}
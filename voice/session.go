@@ -0,0 +1,246 @@
+package voice
+
+import (
+	"sync"
+	"time"
+
+	"github.com/diamondburned/arikawa/discord"
+	"github.com/diamondburned/arikawa/gateway"
+	"github.com/diamondburned/arikawa/internal/json"
+	"github.com/diamondburned/arikawa/session"
+	"github.com/pkg/errors"
+)
+
+// WSTimeout is the duration to wait for the VoiceStateUpdate and
+// VoiceServerUpdate events after asking to join a channel.
+var WSTimeout = 10 * time.Second
+
+var (
+	ErrJoinTimeout = errors.New("Timed out waiting for voice events")
+	// ErrNotConnected is returned if the session isn't connected to a channel.
+	ErrNotConnected = errors.New("Voice session not connected")
+)
+
+// Session is a voice connection to a single guild. It implements io.Writer,
+// where each Write sends a single Opus frame; Speaking must be called before
+// the first Write.
+type Session struct {
+	ErrorLog func(err error)
+
+	session *session.Session
+
+	mutex   sync.Mutex
+	state   State
+	gateway *Gateway
+	udp     *UDPConnection
+
+	// stateUpdated and serverUpdated are signaled on every VoiceStateUpdate
+	// and VoiceServerUpdate, respectively.
+	stateUpdated  chan struct{}
+	serverUpdated chan struct{}
+}
+
+// NewSession creates a new voice Session using the given main session. The
+// userID is the ID of the current user.
+func NewSession(ses *session.Session, userID discord.Snowflake) *Session {
+	return &Session{
+		ErrorLog: gateway.WSError,
+		session:  ses,
+		state: State{
+			UserID: userID,
+		},
+		stateUpdated:  make(chan struct{}, 1),
+		serverUpdated: make(chan struct{}, 1),
+	}
+}
+
+// UpdateServer updates the session with a VoiceServerUpdate event.
+func (s *Session) UpdateServer(ev *gateway.VoiceServerUpdateEvent) {
+	s.mutex.Lock()
+	s.state.Endpoint = ev.Endpoint
+	s.state.Token = ev.Token
+	s.mutex.Unlock()
+
+	signal(s.serverUpdated)
+}
+
+// UpdateState updates the session with a VoiceStateUpdate event.
+func (s *Session) UpdateState(ev *gateway.VoiceStateUpdateEvent) {
+	if ev.UserID != s.state.UserID {
+		return
+	}
+
+	s.mutex.Lock()
+	s.state.SessionID = ev.SessionID
+	s.state.ChannelID = ev.ChannelID
+	s.mutex.Unlock()
+
+	signal(s.stateUpdated)
+}
+
+func signal(ch chan struct{}) {
+	select {
+	case ch <- struct{}{}:
+	default:
+	}
+}
+
+// JoinChannel joins the given voice channel and connects to the voice
+// gateway and UDP server. It blocks until the session is ready to send audio.
+func (s *Session) JoinChannel(
+	guildID, channelID discord.Snowflake, muted, deafened bool) error {
+
+	// Disconnect from any previous channel.
+	s.closeConns()
+
+	s.mutex.Lock()
+	s.state.GuildID = guildID
+	s.state.ChannelID = channelID
+	s.mutex.Unlock()
+
+	// Drop stale signals from earlier events.
+	select {
+	case <-s.stateUpdated:
+	default:
+	}
+	select {
+	case <-s.serverUpdated:
+	default:
+	}
+
+	gw := s.session.GatewayFor(guildID)
+	err := gw.UpdateVoiceState(gateway.UpdateVoiceStateData{
+		GuildID:   guildID,
+		ChannelID: channelID,
+		SelfMute:  muted,
+		SelfDeaf:  deafened,
+	})
+	if err != nil {
+		return errors.Wrap(err, "Failed to send voice state update")
+	}
+
+	// Wait for both VoiceStateUpdate and VoiceServerUpdate, as the session
+	// ID and the endpoint are both needed.
+	var timeout = time.After(WSTimeout)
+	var stateUpdated, serverUpdated = s.stateUpdated, s.serverUpdated
+
+	for stateUpdated != nil || serverUpdated != nil {
+		select {
+		case <-stateUpdated:
+			stateUpdated = nil
+		case <-serverUpdated:
+			serverUpdated = nil
+		case <-timeout:
+			return ErrJoinTimeout
+		}
+	}
+
+	return s.connect()
+}
+
+func (s *Session) connect() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	g, err := NewGateway(s.state, json.Default{})
+	if err != nil {
+		return errors.Wrap(err, "Failed to create voice gateway")
+	}
+	g.ErrorLog = s.ErrorLog
+
+	if err := g.Open(false); err != nil {
+		return errors.Wrap(err, "Failed to open voice gateway")
+	}
+
+	var ready = g.Ready()
+
+	udp, err := DialUDP(udpAddr(ready.IP, ready.Port), ready.SSRC)
+	if err != nil {
+		g.Close()
+		return errors.Wrap(err, "Failed to open voice UDP connection")
+	}
+
+	desc, err := g.SessionDescription(SelectProtocolData{
+		Protocol: "udp",
+		Data: SelectProtocolInfo{
+			Address: udp.GatewayIP,
+			Port:    udp.GatewayPort,
+			Mode:    EncryptionMode,
+		},
+	})
+	if err != nil {
+		udp.Close()
+		g.Close()
+		return errors.Wrap(err, "Failed to get session description")
+	}
+
+	udp.UseSecret(desc.SecretKey)
+
+	s.gateway = g
+	s.udp = udp
+
+	return nil
+}
+
+// Speaking tells Discord that the user is speaking. This has to be called
+// before sending audio.
+func (s *Session) Speaking(flag SpeakingFlag) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.gateway == nil {
+		return ErrNotConnected
+	}
+
+	return s.gateway.Speaking(flag)
+}
+
+// Write sends a single Opus frame. It blocks until the frame is due, as
+// frames are sent every FrameDuration.
+func (s *Session) Write(b []byte) (int, error) {
+	s.mutex.Lock()
+	var udp = s.udp
+	s.mutex.Unlock()
+
+	if udp == nil {
+		return 0, ErrNotConnected
+	}
+
+	return udp.Write(b)
+}
+
+// Disconnect leaves the voice channel and closes all connections.
+func (s *Session) Disconnect() error {
+	s.mutex.Lock()
+	var guildID = s.state.GuildID
+	s.mutex.Unlock()
+
+	// A zero ChannelID is sent as null, which means leaving.
+	gw := s.session.GatewayFor(guildID)
+	err := gw.UpdateVoiceState(gateway.UpdateVoiceStateData{
+		GuildID: guildID,
+	})
+
+	s.closeConns()
+
+	return err
+}
+
+func (s *Session) closeConns() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.udp != nil {
+		if err := s.udp.Close(); err != nil {
+			s.ErrorLog(errors.Wrap(err, "Failed to close voice UDP"))
+		}
+		s.udp = nil
+	}
+
+	if s.gateway != nil {
+		if err := s.gateway.Close(); err != nil {
+			s.ErrorLog(errors.Wrap(err, "Failed to close voice gateway"))
+		}
+		s.gateway = nil
+	}
+}
@@ -0,0 +1,156 @@
+package voice
+
+import (
+	"bytes"
+	"encoding/binary"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/nacl/secretbox"
+)
+
+const (
+	// FrameDuration is the duration of an Opus frame. Writes to a
+	// UDPConnection are paced to one frame every FrameDuration.
+	FrameDuration = 20 * time.Millisecond
+	// FrameSamples is the number of samples per channel in a single Opus frame
+	// at 48kHz.
+	FrameSamples = 960
+)
+
+// OpusSilence is an Opus frame of silence. It should be sent 5 times after
+// the last frame to avoid interpolation.
+var OpusSilence = []byte{0xF8, 0xFF, 0xFE}
+
+// UDPConnection is the UDP connection that audio is sent over. Each Write call
+// sends exactly one Opus frame, encrypted with xsalsa20_poly1305.
+type UDPConnection struct {
+	// GatewayIP and GatewayPort are our external address, found with IP
+	// discovery. These are sent to Discord with Select Protocol.
+	GatewayIP   string
+	GatewayPort uint16
+
+	conn net.Conn
+	ssrc uint32
+
+	mutex     sync.Mutex
+	sequence  uint16
+	timestamp uint32
+	nonce     [24]byte
+	secret    [32]byte
+	ticker    *time.Ticker
+}
+
+// DialUDP dials the voice UDP server at addr and does IP discovery.
+func DialUDP(addr string, ssrc uint32) (*UDPConnection, error) {
+	conn, err := net.Dial("udp", addr)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to dial "+addr)
+	}
+
+	ip, port, err := discoverIP(conn, ssrc)
+	if err != nil {
+		conn.Close()
+		return nil, errors.Wrap(err, "Failed to discover IP")
+	}
+
+	return &UDPConnection{
+		GatewayIP:   ip,
+		GatewayPort: port,
+		conn:        conn,
+		ssrc:        ssrc,
+	}, nil
+}
+
+// discoverIP does IP discovery, documented at
+// https://discordapp.com/developers/docs/topics/voice-connections#ip-discovery.
+func discoverIP(conn net.Conn, ssrc uint32) (string, uint16, error) {
+	var packet [74]byte
+	binary.BigEndian.PutUint16(packet[0:2], 0x1) // request
+	binary.BigEndian.PutUint16(packet[2:4], 70)  // length
+	binary.BigEndian.PutUint32(packet[4:8], ssrc)
+
+	if _, err := conn.Write(packet[:]); err != nil {
+		return "", 0, errors.Wrap(err, "Failed to write")
+	}
+
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	defer conn.SetReadDeadline(time.Time{})
+
+	var resp [74]byte
+	if _, err := conn.Read(resp[:]); err != nil {
+		return "", 0, errors.Wrap(err, "Failed to read")
+	}
+
+	// The address is a null-terminated string.
+	var ip = resp[8:72]
+	if i := bytes.IndexByte(ip, 0); i > -1 {
+		ip = ip[:i]
+	}
+
+	var port = binary.BigEndian.Uint16(resp[72:74])
+
+	return string(ip), port, nil
+}
+
+// UseSecret sets the secret key used for encryption, which is given in the
+// Session Description.
+func (c *UDPConnection) UseSecret(secret [32]byte) {
+	c.mutex.Lock()
+	c.secret = secret
+	c.mutex.Unlock()
+}
+
+// Write sends a single Opus frame. It blocks until the next frame is due, so
+// the caller can write frames as fast as it wants.
+func (c *UDPConnection) Write(b []byte) (int, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.ticker == nil {
+		c.ticker = time.NewTicker(FrameDuration)
+	} else {
+		<-c.ticker.C
+	}
+
+	// Construct the RTP header, which is also used as the nonce.
+	var header [12]byte
+	header[0] = 0x80
+	header[1] = 0x78
+	binary.BigEndian.PutUint16(header[2:4], c.sequence)
+	binary.BigEndian.PutUint32(header[4:8], c.timestamp)
+	binary.BigEndian.PutUint32(header[8:12], c.ssrc)
+
+	copy(c.nonce[:], header[:])
+
+	var packet = secretbox.Seal(header[:], b, &c.nonce, &c.secret)
+
+	if _, err := c.conn.Write(packet); err != nil {
+		return 0, errors.Wrap(err, "Failed to write packet")
+	}
+
+	c.sequence++
+	c.timestamp += FrameSamples
+
+	return len(b), nil
+}
+
+// Close closes the UDP connection.
+func (c *UDPConnection) Close() error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.ticker != nil {
+		c.ticker.Stop()
+		c.ticker = nil
+	}
+
+	return c.conn.Close()
+}
+
+func udpAddr(ip string, port int) string {
+	return net.JoinHostPort(ip, strconv.Itoa(port))
+}
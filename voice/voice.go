@@ -0,0 +1,132 @@
+// Package voice handles voice connections: the voice gateway, the UDP
+// connection and the encryption of Opus frames sent over it. It only supports
+// sending audio.
+//
+// Voice hooks into a State to receive the VoiceStateUpdate and
+// VoiceServerUpdate events, which are needed to connect to the voice gateway.
+package voice
+
+import (
+	"sync"
+
+	"github.com/diamondburned/arikawa/discord"
+	"github.com/diamondburned/arikawa/gateway"
+	"github.com/diamondburned/arikawa/state"
+	"github.com/pkg/errors"
+)
+
+// Voice manages all voice sessions, one per guild.
+type Voice struct {
+	*state.State
+
+	// ErrorLog is called on errors from any of the sessions.
+	ErrorLog func(err error)
+
+	mutex    sync.Mutex
+	sessions map[discord.Snowflake]*Session
+	unhook   []func()
+}
+
+// NewVoice creates a new Voice and adds the needed handlers to the State.
+func NewVoice(s *state.State) *Voice {
+	v := &Voice{
+		State:    s,
+		ErrorLog: gateway.WSError,
+		sessions: make(map[discord.Snowflake]*Session),
+	}
+
+	v.unhook = []func(){
+		s.AddHandler(v.onVoiceStateUpdate),
+		s.AddHandler(v.onVoiceServerUpdate),
+	}
+
+	return v
+}
+
+func (v *Voice) onVoiceStateUpdate(e *gateway.VoiceStateUpdateEvent) {
+	if ses, ok := v.GetSession(e.GuildID); ok {
+		ses.UpdateState(e)
+	}
+}
+
+func (v *Voice) onVoiceServerUpdate(e *gateway.VoiceServerUpdateEvent) {
+	if ses, ok := v.GetSession(e.GuildID); ok {
+		ses.UpdateServer(e)
+	}
+}
+
+// GetSession returns the session for the given guild, if there's one.
+func (v *Voice) GetSession(guildID discord.Snowflake) (*Session, bool) {
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+
+	ses, ok := v.sessions[guildID]
+	return ses, ok
+}
+
+// JoinChannel joins the given voice channel, reusing the guild's session if
+// there's already one. Only one channel per guild can be joined.
+func (v *Voice) JoinChannel(
+	guildID, channelID discord.Snowflake,
+	muted, deafened bool) (*Session, error) {
+
+	ses, ok := v.GetSession(guildID)
+	if !ok {
+		u, err := v.Self()
+		if err != nil {
+			return nil, errors.Wrap(err, "Failed to get self")
+		}
+
+		ses = NewSession(v.Session, u.ID)
+		ses.ErrorLog = func(err error) {
+			v.ErrorLog(err)
+		}
+
+		v.mutex.Lock()
+		v.sessions[guildID] = ses
+		v.mutex.Unlock()
+	}
+
+	if err := ses.JoinChannel(guildID, channelID, muted, deafened); err != nil {
+		return nil, errors.Wrap(err, "Failed to join channel")
+	}
+
+	return ses, nil
+}
+
+// RemoveSession disconnects and removes the session of the given guild.
+func (v *Voice) RemoveSession(guildID discord.Snowflake) error {
+	v.mutex.Lock()
+	ses, ok := v.sessions[guildID]
+	delete(v.sessions, guildID)
+	v.mutex.Unlock()
+
+	if !ok {
+		return nil
+	}
+
+	return ses.Disconnect()
+}
+
+// Close disconnects all sessions and removes the handlers from the State. The
+// first error is returned, but all sessions are closed regardless.
+func (v *Voice) Close() error {
+	v.mutex.Lock()
+	var sessions = v.sessions
+	v.sessions = make(map[discord.Snowflake]*Session)
+	v.mutex.Unlock()
+
+	var firstErr error
+
+	for guildID, ses := range sessions {
+		if err := ses.Disconnect(); err != nil && firstErr == nil {
+			firstErr = errors.Wrapf(err, "Failed to disconnect from %d", guildID)
+		}
+	}
+
+	for _, rm := range v.unhook {
+		rm()
+	}
+
+	return firstErr
+}